@@ -0,0 +1,12 @@
+-- Every status an order has been in, with the time it entered it
+CREATE TABLE servu.order_status_history (
+    history_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID REFERENCES servu.orders(order_id) ON DELETE CASCADE,
+    restaurant_id UUID REFERENCES servu.restaurants(restaurant_id) ON DELETE CASCADE,
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_order_status_history_order_id ON servu.order_status_history(order_id);
+CREATE INDEX idx_order_status_history_restaurant_id_changed_at ON servu.order_status_history(restaurant_id, changed_at);
@@ -0,0 +1,17 @@
+-- Kitchen stations that order items are routed to
+CREATE TABLE servu.stations (
+    station_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    restaurant_id UUID REFERENCES servu.restaurants(restaurant_id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    kind VARCHAR(20) CHECK (kind IN ('grill', 'cold', 'bar', 'dessert')) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (restaurant_id, name)
+);
+
+CREATE INDEX idx_stations_restaurant_id ON servu.stations(restaurant_id);
+
+-- Menu items without a station go to the first station of their category's kind
+ALTER TABLE servu.menu_items
+    ADD COLUMN station_id UUID REFERENCES servu.stations(station_id) ON DELETE SET NULL;
+
+CREATE INDEX idx_order_items_status_created_at ON servu.order_items(status, created_at);
@@ -0,0 +1,11 @@
+-- Order items are served in courses. Items may be entered on hold and only
+-- reach the kitchen once their course is fired.
+ALTER TABLE servu.order_items DROP CONSTRAINT order_items_status_check;
+ALTER TABLE servu.order_items
+    ADD CONSTRAINT order_items_status_check CHECK (status IN ('held', 'pending', 'completed', 'cancelled', 'prepared', 'delivered'));
+
+ALTER TABLE servu.order_items
+    ADD COLUMN course INTEGER NOT NULL DEFAULT 1 CHECK (course > 0),
+    ADD COLUMN fired_at TIMESTAMP;
+
+CREATE INDEX idx_order_items_order_id_course ON servu.order_items(order_id, course);
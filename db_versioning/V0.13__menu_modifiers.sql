@@ -0,0 +1,76 @@
+-- Structured customisations of a menu item: groups of choices with selection
+-- limits, a price delta per choice and the ingredients each choice adds or
+-- removes from the recipe.
+CREATE TABLE servu.modifier_groups (
+    modifier_group_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    menu_item_id UUID NOT NULL REFERENCES servu.menu_items(menu_item_id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    min_selections INT NOT NULL DEFAULT 0 CHECK (min_selections >= 0),
+    max_selections INT NOT NULL DEFAULT 1 CHECK (max_selections >= 1),
+    position INT NOT NULL DEFAULT 0,
+    CHECK (max_selections >= min_selections)
+);
+
+CREATE INDEX idx_modifier_groups_menu_item_id ON servu.modifier_groups(menu_item_id);
+
+CREATE TABLE servu.modifiers (
+    modifier_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    modifier_group_id UUID NOT NULL REFERENCES servu.modifier_groups(modifier_group_id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    price_delta DECIMAL(10,2) NOT NULL DEFAULT 0,
+    position INT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_modifiers_modifier_group_id ON servu.modifiers(modifier_group_id);
+
+-- Negative amounts take an ingredient out of the recipe
+CREATE TABLE servu.modifier_ingredients (
+    modifier_ingredient_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    modifier_id UUID NOT NULL REFERENCES servu.modifiers(modifier_id) ON DELETE CASCADE,
+    raw_ingredient_id INT NOT NULL REFERENCES servu.raw_ingredients(raw_ingredient_id),
+    amount DECIMAL(10,2) NOT NULL,
+    unit VARCHAR(20) NOT NULL
+);
+
+CREATE INDEX idx_modifier_ingredients_modifier_id ON servu.modifier_ingredients(modifier_id);
+
+-- Order items get a stable key instead of being identified by their observation
+ALTER TABLE servu.order_items ADD COLUMN order_item_id UUID NOT NULL DEFAULT uuid_generate_v4();
+ALTER TABLE servu.order_items DROP CONSTRAINT order_items_pkey;
+ALTER TABLE servu.order_items ADD PRIMARY KEY (order_item_id);
+
+-- The modifiers chosen for an order item, with the name and price they had
+-- when the item was ordered
+CREATE TABLE servu.order_item_modifiers (
+    order_item_modifier_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_item_id UUID NOT NULL REFERENCES servu.order_items(order_item_id) ON DELETE CASCADE,
+    modifier_id UUID REFERENCES servu.modifiers(modifier_id) ON DELETE SET NULL,
+    name VARCHAR(100) NOT NULL,
+    price_delta DECIMAL(10,2) NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_order_item_modifiers_order_item_id ON servu.order_item_modifiers(order_item_id);
+
+-- Side dishes used to be ordered as separate items marked "Guarnición" in the
+-- observation. They become an optional group of free choices among the sides
+-- of the restaurant, each carrying the recipe of its side.
+INSERT INTO servu.modifier_groups (menu_item_id, name, required, min_selections, max_selections)
+SELECT m.menu_item_id, 'Guarnición', FALSE, 0, m.side_dishes
+FROM servu.menu_items m
+WHERE m.side_dishes > 0
+  AND EXISTS (SELECT 1 FROM servu.menu_items s WHERE s.restaurant_id = m.restaurant_id AND s.category = 'Side');
+
+INSERT INTO servu.modifiers (modifier_group_id, name, price_delta, position)
+SELECT g.modifier_group_id, s.name, 0, ROW_NUMBER() OVER (PARTITION BY g.modifier_group_id ORDER BY s.name)
+FROM servu.modifier_groups g
+JOIN servu.menu_items m ON m.menu_item_id = g.menu_item_id
+JOIN servu.menu_items s ON s.restaurant_id = m.restaurant_id AND s.category = 'Side';
+
+INSERT INTO servu.modifier_ingredients (modifier_id, raw_ingredient_id, amount, unit)
+SELECT mo.modifier_id, i.raw_ingredient_id, i.amount, i.unit
+FROM servu.modifiers mo
+JOIN servu.modifier_groups g ON g.modifier_group_id = mo.modifier_group_id
+JOIN servu.menu_items m ON m.menu_item_id = g.menu_item_id
+JOIN servu.menu_items s ON s.restaurant_id = m.restaurant_id AND s.category = 'Side' AND s.name = mo.name
+JOIN servu.ingredients i ON i.menu_item_id = s.menu_item_id;
@@ -0,0 +1,25 @@
+-- Combo menu items are sold at a bundle price and made of slots, each either a
+-- fixed menu item or a choice among the menu items of a category.
+CREATE TABLE servu.combo_slots (
+    slot_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    combo_item_id UUID NOT NULL REFERENCES servu.menu_items(menu_item_id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    quantity INT NOT NULL DEFAULT 1 CHECK (quantity > 0),
+    menu_item_id UUID REFERENCES servu.menu_items(menu_item_id) ON DELETE CASCADE,
+    category VARCHAR(20) CHECK (category IN ('Appetizer', 'Dessert', 'Main', 'Soup', 'Salad', 'Drinks', 'Side')),
+    position INT NOT NULL DEFAULT 0,
+    CHECK ((menu_item_id IS NULL) <> (category IS NULL))
+);
+
+CREATE INDEX idx_combo_slots_combo_item_id ON servu.combo_slots(combo_item_id);
+
+-- The menu items an ordered combo was made of
+CREATE TABLE servu.order_item_components (
+    order_item_component_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_item_id UUID NOT NULL REFERENCES servu.order_items(order_item_id) ON DELETE CASCADE,
+    slot_id UUID REFERENCES servu.combo_slots(slot_id) ON DELETE SET NULL,
+    menu_item_id UUID NOT NULL REFERENCES servu.menu_items(menu_item_id) ON DELETE CASCADE,
+    quantity INT NOT NULL DEFAULT 1 CHECK (quantity > 0)
+);
+
+CREATE INDEX idx_order_item_components_order_item_id ON servu.order_item_components(order_item_id);
@@ -0,0 +1,20 @@
+-- Orders are settled by one or more payments. A payment may cover specific
+-- units of the order's items, which lets a table split its check by item or
+-- by seat; payments without allocations cover an even share of the balance.
+ALTER TABLE servu.order_items
+    ADD COLUMN seat INTEGER CHECK (seat > 0);
+
+ALTER TABLE servu.payments
+    ADD COLUMN created_by UUID REFERENCES servu.users(user_id) ON DELETE SET NULL,
+    ADD CONSTRAINT payments_amount_check CHECK (amount > 0);
+
+CREATE TABLE servu.payment_allocations (
+    payment_allocation_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    payment_id UUID NOT NULL REFERENCES servu.payments(payment_id) ON DELETE CASCADE,
+    order_item_id UUID NOT NULL REFERENCES servu.order_items(order_item_id) ON DELETE CASCADE,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    amount DECIMAL(10,2) NOT NULL
+);
+
+CREATE INDEX idx_payment_allocations_payment_id ON servu.payment_allocations(payment_id);
+CREATE INDEX idx_payment_allocations_order_item_id ON servu.payment_allocations(order_item_id);
@@ -0,0 +1,23 @@
+-- Suggested service charge ("propina voluntaria") rules. The active rule with
+-- the highest minimum the order total reaches is the one suggested.
+CREATE TABLE servu.service_charge_rules (
+    rule_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    restaurant_id UUID NOT NULL REFERENCES servu.restaurants(restaurant_id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    percentage DECIMAL(5,2) NOT NULL CHECK (percentage > 0 AND percentage <= 100),
+    minimum_order_total DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (minimum_order_total >= 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_service_charge_rules_restaurant_id ON servu.service_charge_rules(restaurant_id);
+
+-- Tips are taken on top of a payment and never count towards the order balance
+ALTER TABLE servu.payments
+    ADD COLUMN tip DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (tip >= 0);
+
+CREATE INDEX idx_payments_restaurant_id_created_at ON servu.payments(restaurant_id, created_at);
+
+-- Cash closings keep tips apart from sales and revenue
+ALTER TABLE servu.cash_closings
+    ADD COLUMN total_tips DECIMAL(10,2) NOT NULL DEFAULT 0.0;
@@ -0,0 +1,66 @@
+-- Happy hours are local to the restaurant
+ALTER TABLE servu.restaurants
+    ADD COLUMN time_zone VARCHAR(64) NOT NULL DEFAULT 'America/Bogota';
+
+-- Promotions take a percentage or a fixed amount off an item, the items of a
+-- category or the whole order, or give units away ("2x1"). They may be limited
+-- to a date range and a daily happy hour, and coupon promotions only apply to
+-- the orders their code is redeemed on, up to a usage limit.
+CREATE TABLE servu.promotions (
+    promotion_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    restaurant_id UUID NOT NULL REFERENCES servu.restaurants(restaurant_id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('percentage', 'fixed', 'buy_x_get_y')),
+    scope VARCHAR(20) NOT NULL CHECK (scope IN ('item', 'category', 'order')),
+    value DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (value >= 0),
+    menu_item_id UUID REFERENCES servu.menu_items(menu_item_id) ON DELETE CASCADE,
+    category VARCHAR(20) CHECK (category IN ('Appetizer', 'Dessert', 'Main', 'Soup', 'Salad', 'Drinks', 'Side')),
+    buy_quantity INT CHECK (buy_quantity > 1),
+    free_quantity INT CHECK (free_quantity > 0),
+    minimum_order_total DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (minimum_order_total >= 0),
+    starts_at TIMESTAMP,
+    ends_at TIMESTAMP,
+    happy_hour_start VARCHAR(5) CHECK (happy_hour_start ~ '^([01][0-9]|2[0-3]):[0-5][0-9]$'),
+    happy_hour_end VARCHAR(5) CHECK (happy_hour_end ~ '^([01][0-9]|2[0-3]):[0-5][0-9]$'),
+    coupon_code VARCHAR(50),
+    usage_limit INT CHECK (usage_limit > 0),
+    times_used INT NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((scope = 'item') = (menu_item_id IS NOT NULL)),
+    CHECK ((scope = 'category') = (category IS NOT NULL)),
+    CHECK ((kind = 'buy_x_get_y') = (buy_quantity IS NOT NULL AND free_quantity IS NOT NULL)),
+    CHECK (kind <> 'buy_x_get_y' OR (scope <> 'order' AND free_quantity < buy_quantity)),
+    CHECK ((happy_hour_start IS NULL) = (happy_hour_end IS NULL)),
+    CHECK (ends_at IS NULL OR starts_at IS NULL OR ends_at > starts_at)
+);
+
+CREATE INDEX idx_promotions_restaurant_id ON servu.promotions(restaurant_id);
+CREATE UNIQUE INDEX idx_promotions_coupon_code ON servu.promotions(restaurant_id, UPPER(coupon_code)) WHERE coupon_code IS NOT NULL;
+
+-- Coupons redeemed on an order
+CREATE TABLE servu.order_coupons (
+    order_coupon_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES servu.orders(order_id) ON DELETE CASCADE,
+    promotion_id UUID NOT NULL REFERENCES servu.promotions(promotion_id) ON DELETE CASCADE,
+    code VARCHAR(50) NOT NULL,
+    redeemed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (order_id, promotion_id)
+);
+
+-- Discount lines, recomputed whenever the order's items change. Lines of item
+-- promotions name the item they apply to.
+CREATE TABLE servu.order_discounts (
+    order_discount_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES servu.orders(order_id) ON DELETE CASCADE,
+    order_item_id UUID REFERENCES servu.order_items(order_item_id) ON DELETE CASCADE,
+    promotion_id UUID REFERENCES servu.promotions(promotion_id) ON DELETE SET NULL,
+    name VARCHAR(100) NOT NULL,
+    amount DECIMAL(10,2) NOT NULL CHECK (amount > 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_order_discounts_order_id ON servu.order_discounts(order_id);
+
+ALTER TABLE servu.cash_closings
+    ADD COLUMN total_discounts DECIMAL(10,2) NOT NULL DEFAULT 0.0;
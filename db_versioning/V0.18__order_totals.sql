@@ -0,0 +1,20 @@
+-- Order totals are priced by the server from the order's items and discount
+-- lines. The service charge is only suggested and is not part of the total.
+ALTER TABLE servu.orders
+    ADD COLUMN subtotal DECIMAL(10,2) NOT NULL DEFAULT 0.0,
+    ADD COLUMN discount_total DECIMAL(10,2) NOT NULL DEFAULT 0.0,
+    ADD COLUMN tax_total DECIMAL(10,2) NOT NULL DEFAULT 0.0,
+    ADD COLUMN service_charge DECIMAL(10,2) NOT NULL DEFAULT 0.0;
+
+UPDATE servu.orders o
+SET subtotal = COALESCE((
+        SELECT SUM(oi.price * oi.quantity) FROM servu.order_items oi
+        WHERE oi.order_id = o.order_id AND oi.status <> 'cancelled'), 0),
+    discount_total = COALESCE((
+        SELECT SUM(od.amount) FROM servu.order_discounts od
+        WHERE od.order_id = o.order_id), 0);
+
+-- Closed orders keep the total they were settled with
+UPDATE servu.orders
+SET total_price = GREATEST(subtotal - discount_total, 0)
+WHERE status NOT IN ('paid', 'cancelled');
@@ -0,0 +1,25 @@
+-- Colombian taxes. Restaurants charge the INC (impuesto nacional al consumo)
+-- or IVA on their sales, or none when they are not responsible for either.
+-- Menu items may override the regime's default category, and order items keep
+-- the category and rate they were sold with along with the tax worked out on
+-- their net amount.
+ALTER TABLE servu.restaurants
+    ADD COLUMN tax_regime VARCHAR(20) NOT NULL DEFAULT 'inc'
+        CHECK (tax_regime IN ('inc', 'iva', 'not_responsible')),
+    ADD COLUMN prices_include_tax BOOLEAN NOT NULL DEFAULT TRUE;
+
+ALTER TABLE servu.menu_items
+    ADD COLUMN tax_category VARCHAR(20)
+        CHECK (tax_category IN ('none', 'inc_8', 'iva_19', 'iva_5', 'iva_0'));
+
+-- Items sold before taxes were tracked stay untaxed
+ALTER TABLE servu.order_items
+    ADD COLUMN tax_category VARCHAR(20) NOT NULL DEFAULT 'none'
+        CHECK (tax_category IN ('none', 'inc_8', 'iva_19', 'iva_5', 'iva_0')),
+    ADD COLUMN tax_rate DECIMAL(5,2) NOT NULL DEFAULT 0.0,
+    ADD COLUMN tax_included BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN tax_base DECIMAL(10,2) NOT NULL DEFAULT 0.0,
+    ADD COLUMN tax_amount DECIMAL(10,2) NOT NULL DEFAULT 0.0;
+
+ALTER TABLE servu.cash_closings
+    ADD COLUMN total_taxes DECIMAL(10,2) NOT NULL DEFAULT 0.0;
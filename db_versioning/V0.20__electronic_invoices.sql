@@ -0,0 +1,53 @@
+-- Electronic invoices (facturas electrónicas de venta). Restaurants invoice
+-- under the NIT and legal name they are registered with.
+ALTER TABLE servu.restaurants
+    ADD COLUMN nit_number VARCHAR(20),
+    ADD COLUMN legal_name VARCHAR(200);
+
+-- Numbering resolutions authorise a range of invoice numbers for a period.
+-- next_number is the number the next invoice issued under it takes.
+CREATE TABLE servu.invoice_resolutions (
+    resolution_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    restaurant_id UUID NOT NULL REFERENCES servu.restaurants(restaurant_id) ON DELETE CASCADE,
+    resolution_number VARCHAR(50) NOT NULL,
+    prefix VARCHAR(4) NOT NULL DEFAULT '',
+    range_from BIGINT NOT NULL CHECK (range_from > 0),
+    range_to BIGINT NOT NULL,
+    next_number BIGINT NOT NULL,
+    valid_from DATE NOT NULL,
+    valid_to DATE NOT NULL,
+    technical_key VARCHAR(100) NOT NULL,
+    environment VARCHAR(20) NOT NULL DEFAULT 'testing' CHECK (environment IN ('production', 'testing')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (range_to >= range_from),
+    CHECK (valid_to >= valid_from)
+);
+
+CREATE INDEX idx_invoice_resolutions_restaurant_id ON servu.invoice_resolutions(restaurant_id);
+
+-- An order is invoiced once and cannot be deleted afterwards. The UBL
+-- document is kept as issued.
+CREATE TABLE servu.invoices (
+    invoice_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    restaurant_id UUID NOT NULL REFERENCES servu.restaurants(restaurant_id) ON DELETE CASCADE,
+    order_id UUID NOT NULL UNIQUE REFERENCES servu.orders(order_id),
+    resolution_id UUID NOT NULL REFERENCES servu.invoice_resolutions(resolution_id),
+    sequence BIGINT NOT NULL,
+    number VARCHAR(30) NOT NULL,
+    cufe VARCHAR(96) NOT NULL,
+    buyer_id UUID REFERENCES servu.users(user_id) ON DELETE SET NULL,
+    buyer_name VARCHAR(200) NOT NULL,
+    buyer_nit VARCHAR(20) NOT NULL,
+    subtotal DECIMAL(10,2) NOT NULL,
+    tax_total DECIMAL(10,2) NOT NULL,
+    total DECIMAL(10,2) NOT NULL,
+    document TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'rejected')),
+    tracking_id VARCHAR(100),
+    status_message TEXT,
+    issued_at TIMESTAMP NOT NULL,
+    transmitted_at TIMESTAMP,
+    UNIQUE (resolution_id, sequence)
+);
+
+CREATE INDEX idx_invoices_restaurant_id_issued_at ON servu.invoices(restaurant_id, issued_at);
@@ -0,0 +1,14 @@
+-- Receipts and kitchen tickets are printed on 58mm or 80mm rolls, receipts
+-- between a header and footer of the restaurant's choosing.
+ALTER TABLE servu.restaurants
+    ADD COLUMN receipt_header TEXT,
+    ADD COLUMN receipt_footer TEXT,
+    ADD COLUMN paper_width SMALLINT NOT NULL DEFAULT 80 CHECK (paper_width IN (58, 80));
+
+-- ticketed_quantity counts the units of an item already sent to the kitchen,
+-- so units added to it later are ticketed on their own. Items ordered before
+-- kitchen tickets were printed count as sent.
+ALTER TABLE servu.order_items
+    ADD COLUMN ticketed_quantity INT NOT NULL DEFAULT 0 CHECK (ticketed_quantity >= 0);
+
+UPDATE servu.order_items SET ticketed_quantity = quantity;
@@ -0,0 +1,4 @@
+-- Orders merged into another are cancelled and point at the order that took
+-- their items.
+ALTER TABLE servu.orders
+    ADD COLUMN merged_into UUID REFERENCES servu.orders(order_id);
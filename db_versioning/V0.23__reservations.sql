@@ -0,0 +1,45 @@
+-- Reservations hold one or more tables for a party from starts_at for
+-- duration_minutes. Booked reservations turn into seated ones when the party
+-- arrives, or no-shows when it does not.
+CREATE TABLE servu.reservations (
+    reservation_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    restaurant_id UUID NOT NULL REFERENCES servu.restaurants(restaurant_id) ON DELETE CASCADE,
+    guest_name VARCHAR(100) NOT NULL,
+    phone VARCHAR(30) NOT NULL DEFAULT '',
+    party_size INT NOT NULL CHECK (party_size > 0),
+    starts_at TIMESTAMP NOT NULL,
+    duration_minutes INT NOT NULL DEFAULT 90 CHECK (duration_minutes > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'booked' CHECK (status IN ('booked', 'seated', 'no_show', 'cancelled')),
+    notes TEXT,
+    seated_at TIMESTAMP,
+    cancelled_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_reservations_restaurant_starts_at ON servu.reservations (restaurant_id, starts_at);
+
+CREATE TABLE servu.reservation_tables (
+    reservation_id UUID NOT NULL REFERENCES servu.reservations(reservation_id) ON DELETE CASCADE,
+    table_id UUID NOT NULL REFERENCES servu.tables(table_id) ON DELETE CASCADE,
+    PRIMARY KEY (reservation_id, table_id)
+);
+
+CREATE INDEX idx_reservation_tables_table ON servu.reservation_tables (table_id);
+
+-- Walk-in parties wait in line for a table; each is quoted a wait when it
+-- joins.
+CREATE TABLE servu.waitlist_entries (
+    entry_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    restaurant_id UUID NOT NULL REFERENCES servu.restaurants(restaurant_id) ON DELETE CASCADE,
+    guest_name VARCHAR(100) NOT NULL,
+    phone VARCHAR(30) NOT NULL DEFAULT '',
+    party_size INT NOT NULL CHECK (party_size > 0),
+    quoted_wait_minutes INT NOT NULL DEFAULT 0 CHECK (quoted_wait_minutes >= 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'waiting' CHECK (status IN ('waiting', 'seated', 'left')),
+    table_id UUID REFERENCES servu.tables(table_id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    seated_at TIMESTAMP,
+    left_at TIMESTAMP
+);
+
+CREATE INDEX idx_waitlist_entries_restaurant_status ON servu.waitlist_entries (restaurant_id, status, created_at);
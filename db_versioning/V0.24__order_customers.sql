@@ -0,0 +1,6 @@
+-- Orders placed by customers remember who placed them, so customers can only
+-- reach their own orders.
+ALTER TABLE servu.orders
+    ADD COLUMN customer_id UUID REFERENCES servu.users(user_id) ON DELETE SET NULL;
+
+CREATE INDEX idx_orders_customer_id ON servu.orders(customer_id);
@@ -0,0 +1,10 @@
+-- Access tokens (by jti) issued on PIN login, so a user's live PIN sessions can be revoked
+CREATE TABLE servu.pin_sessions (
+    token_id TEXT PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES servu.users(user_id) ON DELETE CASCADE,
+    restaurant_id UUID NOT NULL REFERENCES servu.restaurants(restaurant_id) ON DELETE CASCADE,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_pin_sessions_user_id ON servu.pin_sessions(user_id);
@@ -0,0 +1,6 @@
+-- Allow kitchen staff accounts
+ALTER TABLE servu.users DROP CONSTRAINT IF EXISTS users_role_check;
+
+ALTER TABLE servu.users
+ADD CONSTRAINT users_role_check
+CHECK (role IN ('admin', 'waiter', 'customer', 'kitchen'));
@@ -0,0 +1,23 @@
+-- Rotating refresh tokens; only a SHA-256 hash of the token is stored
+CREATE TABLE servu.refresh_tokens (
+    token_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES servu.users(user_id) ON DELETE CASCADE,
+    token_hash TEXT UNIQUE NOT NULL,
+    access_token_id TEXT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    replaced_by UUID,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_refresh_tokens_user_id ON servu.refresh_tokens(user_id);
+
+-- Access tokens (by jti) revoked before their expiry
+CREATE TABLE servu.revoked_tokens (
+    token_id TEXT PRIMARY KEY,
+    user_id UUID NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_revoked_tokens_expires_at ON servu.revoked_tokens(expires_at);
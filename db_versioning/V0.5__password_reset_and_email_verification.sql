@@ -0,0 +1,16 @@
+-- Email verification; existing accounts are considered verified
+ALTER TABLE servu.users ADD COLUMN email_verified_at TIMESTAMP;
+UPDATE servu.users SET email_verified_at = created_at;
+
+-- Single-use, expiring tokens sent by email; only a SHA-256 hash of the token is stored
+CREATE TABLE servu.user_tokens (
+    token_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES servu.users(user_id) ON DELETE CASCADE,
+    purpose VARCHAR(30) CHECK (purpose IN ('password_reset', 'email_verification')) NOT NULL,
+    token_hash TEXT UNIQUE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_user_tokens_user_id ON servu.user_tokens(user_id);
@@ -0,0 +1,8 @@
+-- Staff PIN quick-login on shared terminals; the PIN is stored as a bcrypt hash
+ALTER TABLE servu.users ADD COLUMN pin_hash TEXT;
+ALTER TABLE servu.users ADD COLUMN pin_failed_attempts INT DEFAULT 0 NOT NULL;
+ALTER TABLE servu.users ADD COLUMN pin_locked_until TIMESTAMP;
+
+-- Staff member who took the order
+ALTER TABLE servu.orders ADD COLUMN waiter_id UUID REFERENCES servu.users(user_id) ON DELETE SET NULL;
+CREATE INDEX idx_orders_waiter_id ON servu.orders(waiter_id);
@@ -0,0 +1,15 @@
+-- Staff shifts recorded by the time clock or entered by an admin
+CREATE TABLE servu.shifts (
+    shift_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES servu.users(user_id) ON DELETE CASCADE,
+    restaurant_id UUID NOT NULL REFERENCES servu.restaurants(restaurant_id) ON DELETE CASCADE,
+    clock_in TIMESTAMP NOT NULL,
+    clock_out TIMESTAMP,
+    notes TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (clock_out IS NULL OR clock_out > clock_in)
+);
+
+CREATE INDEX idx_shifts_restaurant_id_clock_in ON servu.shifts(restaurant_id, clock_in);
+-- A user can only have one open shift at a time
+CREATE UNIQUE INDEX idx_shifts_open_shift ON servu.shifts(user_id) WHERE clock_out IS NULL;
@@ -0,0 +1,27 @@
+-- Append-only log of sensitive actions. Restaurant and actor are not foreign
+-- keys so events outlive the rows they describe.
+CREATE TABLE servu.audit_events (
+    event_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    restaurant_id UUID NOT NULL,
+    actor_id UUID,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id TEXT NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    before JSONB,
+    after JSONB,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_events_restaurant_id_created_at ON servu.audit_events(restaurant_id, created_at);
+CREATE INDEX idx_audit_events_entity ON servu.audit_events(entity_type, entity_id);
+
+CREATE OR REPLACE FUNCTION servu.reject_audit_event_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_append_only
+BEFORE UPDATE OR DELETE ON servu.audit_events
+FOR EACH ROW EXECUTE FUNCTION servu.reject_audit_event_change();
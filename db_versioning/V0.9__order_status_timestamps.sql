@@ -0,0 +1,13 @@
+-- Timestamps stamped by the order and order item state machines
+ALTER TABLE servu.orders
+    ADD COLUMN prepared_at TIMESTAMP,
+    ADD COLUMN delivered_at TIMESTAMP,
+    ADD COLUMN paid_at TIMESTAMP,
+    ADD COLUMN cancelled_at TIMESTAMP;
+
+ALTER TABLE servu.order_items
+    ADD COLUMN created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN prepared_at TIMESTAMP,
+    ADD COLUMN delivered_at TIMESTAMP,
+    ADD COLUMN completed_at TIMESTAMP,
+    ADD COLUMN cancelled_at TIMESTAMP;
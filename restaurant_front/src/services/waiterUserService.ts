@@ -10,7 +10,7 @@ export const createWaiterUser = async (
 ): Promise<string> => {
   try {
     const response = await axios.post<string>(
-      `${API_URL}/register`,
+      `${API_URL}/users`,
       { ...userData, role: 'waiter' },
       {
         headers: {
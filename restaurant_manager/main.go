@@ -11,13 +11,18 @@ import (
 	"restaurant_manager/src/application/services"
 	"restaurant_manager/src/application/utils"
 	"restaurant_manager/src/config"
+	"time"
+	_ "time/tzdata" // the runtime image ships without a zoneinfo database
 )
 
 func main() {
 	cfg := config.LoadConfig()
 	config.ConnectDB(cfg)
-	utils.SetJWT(cfg)
+	if err := utils.SetJWT(cfg); err != nil {
+		log.Fatalf("Failed to load JWT keys: %v", err)
+	}
 	aws3 := ports.InitS3(cfg)
+	smtpSender := ports.InitSMTP(cfg)
 
 	userRepo := repositories.NewUserRepository(config.DB)
 	restaurantRepo := repositories.NewRestaurantRepository(config.DB)
@@ -28,28 +33,60 @@ func main() {
 	ingredientRepo := repositories.NewIngredientRepository(config.DB)
 	rawIngredientRepo := repositories.NewRawIngredientsRepository(config.DB)
 	cashClosingRepo := repositories.NewCashClosingRepository(config.DB)
+	tokenRepo := repositories.NewTokenRepository(config.DB)
+	shiftRepo := repositories.NewShiftRepository(config.DB)
+	auditRepo := repositories.NewAuditRepository(config.DB)
+	stationRepo := repositories.NewStationRepository(config.DB)
+	paymentRepo := repositories.NewPaymentRepository(config.DB)
+	promotionRepo := repositories.NewPromotionRepository(config.DB)
+	invoiceRepo := repositories.NewInvoiceRepository(config.DB)
+	reservationRepo := repositories.NewReservationRepository(config.DB)
 
+	auditService := services.NewAuditService(auditRepo)
+	eventHub := services.NewEventHub()
 	ingredientService := services.NewIngredientsService(ingredientRepo)
-	userService := services.NewUserService(userRepo)
+	userService := services.NewUserService(userRepo, tokenRepo, &smtpSender, cfg.RestaurantManager.Mail.ResetPasswordURL, cfg.RestaurantManager.Mail.VerifyEmailURL)
 	restaurantService := services.NewRestaurantService(restaurantRepo, &aws3)
-	menuService := services.NewMenuService(menuRepo, &aws3, ingredientService)
+	menuService := services.NewMenuService(menuRepo, &aws3, ingredientService, auditService)
 	tableService := services.NewTableService(tableRepo, cfg.RestaurantManager.QRTemplate)
-	inventoryService := services.NewInventoryService(inventoryRepo, menuService)
-	orderService := services.NewOrderService(orderRepo, tableService, menuService, inventoryService)
+	inventoryService := services.NewInventoryService(inventoryRepo, menuService, auditService)
+	promotionService := services.NewPromotionService(promotionRepo, restaurantRepo)
+	orderService := services.NewOrderService(orderRepo, tableService, menuService, inventoryService, auditService, eventHub, promotionService, paymentRepo, restaurantRepo)
+	stationService := services.NewStationService(stationRepo, orderService)
+	paymentService := services.NewPaymentService(paymentRepo, orderService)
 	rawIngredientService := services.NewRawIngredientsService(rawIngredientRepo)
-	cashClosingService := services.NewCashClosingService(cashClosingRepo, orderRepo, menuRepo)
+	invoiceService := services.NewInvoiceService(invoiceRepo, orderRepo, restaurantRepo, userRepo, ports.NewLocalInvoiceTransmitter())
+	receiptService := services.NewReceiptService(orderRepo, paymentRepo, restaurantRepo, stationRepo)
+	reservationService := services.NewReservationService(reservationRepo, tableService, restaurantRepo)
+	cashClosingService := services.NewCashClosingService(cashClosingRepo, orderRepo, menuRepo, paymentRepo, auditService)
+	tenantService := services.NewTenantService(restaurantRepo)
+	shiftService := services.NewShiftService(shiftRepo, userRepo)
+	tokenService := services.NewTokenService(tokenRepo, userRepo, cfg.RestaurantManager.JWT.RefreshTokenDuration(), cfg.RestaurantManager.JWT.PinTokenDuration())
+	utils.SetRevocationList(tokenService)
 
-	userHandler := handlers.NewUserHandler(userService)
+	authMiddleware := routes.NewAuthMiddleware(tenantService)
+
+	userHandler := handlers.NewUserHandler(userService, tenantService, tokenService)
 	restaurantHandler := handlers.NewRestaurantHandler(restaurantService)
 	menuHandler := handlers.NewMenuHandler(menuService)
-	orderHandler := handlers.NewOrderHandler(orderService)
-	tableHandler := handlers.NewTableHandler(tableService)
+	orderHandler := handlers.NewOrderHandler(orderService, tenantService)
+	tableHandler := handlers.NewTableHandler(tableService, tenantService)
 	inventoryHandler := handlers.NewInventoryHandler(inventoryService)
 	ingredientHandler := handlers.NewIngredientHandler(ingredientService)
 	rawIngredientsHandler := handlers.NewRawIngredientsHandler(rawIngredientService)
 	cashClosingHandler := handlers.NewCashClosingHandler(cashClosingService)
+	shiftHandler := handlers.NewShiftHandler(shiftService, tenantService)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	eventHandler := handlers.NewEventHandler(eventHub)
+	stationHandler := handlers.NewStationHandler(stationService, tenantService)
+	paymentHandler := handlers.NewPaymentHandler(paymentService)
+	promotionHandler := handlers.NewPromotionHandler(promotionService)
+	invoiceHandler := handlers.NewInvoiceHandler(invoiceService)
+	receiptHandler := handlers.NewReceiptHandler(receiptService)
+	reservationHandler := handlers.NewReservationHandler(reservationService)
 
 	r := routes.SetupRoutes(
+		authMiddleware,
 		userHandler,
 		restaurantHandler,
 		menuHandler,
@@ -58,7 +95,18 @@ func main() {
 		inventoryHandler,
 		ingredientHandler,
 		rawIngredientsHandler,
-		cashClosingHandler)
+		cashClosingHandler,
+		shiftHandler,
+		auditHandler,
+		eventHandler,
+		stationHandler,
+		paymentHandler,
+		promotionHandler,
+		invoiceHandler,
+		receiptHandler,
+		reservationHandler)
+
+	go reservationService.SyncTableStatusesEvery(time.Minute)
 
 	fmt.Println("🚀 Server running on port 8080")
 	log.Fatal(http.ListenAndServe(":8080", r))
@@ -0,0 +1,24 @@
+package ports
+
+import (
+	"restaurant_manager/src/domain/models"
+
+	"github.com/rs/zerolog/log"
+)
+
+// LocalInvoiceTransmitter stands in for the tax authority: it accepts every
+// invoice, tracking it by its CUFE, without sending it anywhere.
+type LocalInvoiceTransmitter struct{}
+
+func NewLocalInvoiceTransmitter() *LocalInvoiceTransmitter {
+	return &LocalInvoiceTransmitter{}
+}
+
+func (t *LocalInvoiceTransmitter) Transmit(invoice models.Invoice) (models.InvoiceTransmission, error) {
+	log.Info().Msgf("Invoice %s accepted locally, not sent to the tax authority", invoice.Number)
+	return models.InvoiceTransmission{
+		Status:     models.InvoiceAccepted,
+		TrackingID: invoice.Cufe,
+		Message:    "Accepted by the local transmitter",
+	}, nil
+}
@@ -0,0 +1,52 @@
+package ports
+
+import (
+	"fmt"
+	"net/smtp"
+	"restaurant_manager/src/config"
+	"strings"
+)
+
+type SMTPMailSender struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+func InitSMTP(cfg *config.Properties) SMTPMailSender {
+	mailCfg := cfg.RestaurantManager.Mail
+
+	var auth smtp.Auth
+	if mailCfg.Username != "" {
+		auth = smtp.PlainAuth("", mailCfg.Username, mailCfg.Password, mailCfg.Host)
+	}
+
+	return SMTPMailSender{
+		addr: fmt.Sprintf("%s:%s", mailCfg.Host, mailCfg.Port),
+		auth: auth,
+		from: mailCfg.From,
+	}
+}
+
+func (m *SMTPMailSender) SendMail(to string, subject string, body string) error {
+	to = stripLineBreaks(to)
+	message := strings.Join([]string{
+		"From: " + m.from,
+		"To: " + to,
+		"Subject: " + stripLineBreaks(subject),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n")
+
+	if err := smtp.SendMail(m.addr, m.auth, m.from, []string{to}, []byte(message)); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	return nil
+}
+
+// stripLineBreaks keeps header values from injecting additional headers.
+func stripLineBreaks(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
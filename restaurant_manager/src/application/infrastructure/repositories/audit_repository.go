@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"restaurant_manager/src/domain/models"
+	"restaurant_manager/src/domain/repositories"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AuditRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) repositories.AuditRepository {
+	return &AuditRepositoryImpl{db: db}
+}
+
+func (repo *AuditRepositoryImpl) CreateAuditEvent(event *models.AuditEvent) error {
+	return repo.db.Clauses(clause.Returning{}).Omit("event_id", "Actor").Create(event).Error
+}
+
+func (repo *AuditRepositoryImpl) GetAuditEvents(filter models.AuditFilter) ([]models.AuditEvent, error) {
+	query := repo.db.Preload("Actor").Where("restaurant_id = ?", filter.RestaurantID)
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.ActorID != "" {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.StartDate != nil {
+		query = query.Where("created_at >= ?", *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		query = query.Where("created_at < ?", *filter.EndDate)
+	}
+
+	var events []models.AuditEvent
+	err := query.Order("created_at DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&events).Error
+	return events, err
+}
@@ -40,6 +40,15 @@ func (r *CashClosingRepositoryImpl) GetCashClosingByDate(restaurantID string, da
 	return &cashClosing, nil
 }
 
+func (r *CashClosingRepositoryImpl) GetCashClosingByID(restaurantID string, cashClosingID string) (*models.CashClosing, error) {
+	var cashClosing models.CashClosing
+	err := r.db.Where("cash_closing_id = ? AND restaurant_id = ?", cashClosingID, restaurantID).First(&cashClosing).Error
+	if err != nil {
+		return nil, err
+	}
+	return &cashClosing, nil
+}
+
 func (r *CashClosingRepositoryImpl) GetCashClosingHistory(restaurantID string, startDate, endDate time.Time) ([]models.CashClosing, error) {
 	var cashClosings []models.CashClosing
 	err := r.db.Where("restaurant_id = ? AND closing_date BETWEEN ? AND ?", restaurantID, startDate, endDate).
@@ -52,8 +61,8 @@ func (r *CashClosingRepositoryImpl) UpdateCashClosing(cashClosing *models.CashCl
 	return r.db.Save(cashClosing).Error
 }
 
-func (r *CashClosingRepositoryImpl) DeleteCashClosing(cashClosingID string) error {
-	return r.db.Where("cash_closing_id = ?", cashClosingID).Delete(&models.CashClosing{}).Error
+func (r *CashClosingRepositoryImpl) DeleteCashClosing(restaurantID string, cashClosingID string) error {
+	return r.db.Where("cash_closing_id = ? AND restaurant_id = ?", cashClosingID, restaurantID).Delete(&models.CashClosing{}).Error
 }
 
 func (r *CashClosingRepositoryImpl) GetCashClosingStats(restaurantID string, startDate, endDate time.Time) (*models.CashClosing, error) {
@@ -64,6 +73,9 @@ func (r *CashClosingRepositoryImpl) GetCashClosingStats(restaurantID string, sta
 		TotalProfit       float64   `gorm:"column:total_profit"`
 		OrderCount        int       `gorm:"column:order_count"`
 		AverageOrderValue float64   `gorm:"column:average_order_value"`
+		TotalTips         float64   `gorm:"column:total_tips"`
+		TotalDiscounts    float64   `gorm:"column:total_discounts"`
+		TotalTaxes        float64   `gorm:"column:total_taxes"`
 		CreatedAt         time.Time `gorm:"column:created_at"`
 		UpdatedAt         time.Time `gorm:"column:updated_at"`
 	}
@@ -76,6 +88,9 @@ func (r *CashClosingRepositoryImpl) GetCashClosingStats(restaurantID string, sta
 			SUM(total_profit) as total_profit,
 			SUM(order_count) as order_count,
 			CASE WHEN SUM(order_count) > 0 THEN SUM(total_sales) / SUM(order_count) ELSE 0 END as average_order_value,
+			SUM(total_tips) as total_tips,
+			SUM(total_discounts) as total_discounts,
+			SUM(total_taxes) as total_taxes,
 			MIN(created_at) as created_at,
 			MAX(updated_at) as updated_at
 		`).
@@ -99,6 +114,9 @@ func (r *CashClosingRepositoryImpl) GetCashClosingStats(restaurantID string, sta
 		TotalProfit:       result.TotalProfit,
 		OrderCount:        result.OrderCount,
 		AverageOrderValue: result.AverageOrderValue,
+		TotalTips:         result.TotalTips,
+		TotalDiscounts:    result.TotalDiscounts,
+		TotalTaxes:        result.TotalTaxes,
 		CreatedAt:         result.CreatedAt,
 		UpdatedAt:         result.UpdatedAt,
 	}, nil
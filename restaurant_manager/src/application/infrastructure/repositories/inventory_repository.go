@@ -34,9 +34,9 @@ func (repo *InventoryRepositoryImpl) CreateInventory(inventories []models.Invent
 	return inventoryIDs, nil
 }
 
-func (repo *InventoryRepositoryImpl) GetInventory(inventoryID string) (*models.Inventory, error) {
+func (repo *InventoryRepositoryImpl) GetInventory(restaurantID string, inventoryID string) (*models.Inventory, error) {
 	var inventory models.Inventory
-	err := repo.db.Preload("RawIngredient").First(&inventory, "inventory_id = ?", inventoryID).Error
+	err := repo.db.Preload("RawIngredient").First(&inventory, "inventory_id = ? AND restaurant_id = ?", inventoryID, restaurantID).Error
 	if err != nil {
 		return nil, err
 	}
@@ -52,7 +52,7 @@ func (repo *InventoryRepositoryImpl) GetInventoryByRestaurantID(restaurantID str
 func (repo *InventoryRepositoryImpl) UpdateInventory(inventories []models.Inventory) error {
 	for _, inventory := range inventories {
 		err := repo.db.Model(&models.Inventory{}).
-			Where("inventory_id = ?", inventory.InventoryID).
+			Where("inventory_id = ? AND restaurant_id = ?", inventory.InventoryID, inventory.RestaurantID).
 			Updates(inventory).Error
 		if err != nil {
 			return err
@@ -61,8 +61,8 @@ func (repo *InventoryRepositoryImpl) UpdateInventory(inventories []models.Invent
 	return nil
 }
 
-func (repo *InventoryRepositoryImpl) DeleteInventory(inventoryID string) error {
-	return repo.db.Delete(&models.Inventory{}, "inventory_id = ?", inventoryID).Error
+func (repo *InventoryRepositoryImpl) DeleteInventory(restaurantID string, inventoryID string) error {
+	return repo.db.Delete(&models.Inventory{}, "inventory_id = ? AND restaurant_id = ?", inventoryID, restaurantID).Error
 }
 
 func (repo *InventoryRepositoryImpl) GetInventoryByRawIngredientIDAndRestaurantID(rawIngredientID string, restaurantID string) (*models.Inventory, error) {
@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"restaurant_manager/src/domain/models"
+	"restaurant_manager/src/domain/repositories"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InvoiceRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewInvoiceRepository(db *gorm.DB) repositories.InvoiceRepository {
+	return &InvoiceRepositoryImpl{db: db}
+}
+
+func (repo *InvoiceRepositoryImpl) CreateResolution(resolution *models.InvoiceResolution) (string, error) {
+	result := repo.db.Clauses(clause.Returning{}).Omit("resolution_id").Create(resolution)
+	if result.Error != nil {
+		return "", result.Error
+	}
+	return resolution.ResolutionID, nil
+}
+
+func (repo *InvoiceRepositoryImpl) GetResolutions(restaurantID string) ([]models.InvoiceResolution, error) {
+	var resolutions []models.InvoiceResolution
+	err := repo.db.Where("restaurant_id = ?", restaurantID).Order("created_at").Find(&resolutions).Error
+	return resolutions, err
+}
+
+// LockResolution locks the resolution the restaurant numbers its invoices with
+// on the given day: the newest one covering the day that still has numbers
+// left, or else the newest exhausted one.
+func (repo *InvoiceRepositoryImpl) LockResolution(restaurantID string, day string) (*models.InvoiceResolution, error) {
+	var resolution models.InvoiceResolution
+	err := repo.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("restaurant_id = ? AND valid_from <= ? AND valid_to >= ?", restaurantID, day, day).
+		Order("next_number > range_to, created_at DESC").
+		First(&resolution).Error
+	if err != nil {
+		return nil, err
+	}
+	return &resolution, nil
+}
+
+func (repo *InvoiceRepositoryImpl) AdvanceResolution(resolutionID string, nextNumber int64) error {
+	return repo.db.Model(&models.InvoiceResolution{}).
+		Where("resolution_id = ?", resolutionID).
+		Update("next_number", nextNumber).Error
+}
+
+func (repo *InvoiceRepositoryImpl) CreateInvoice(invoice *models.Invoice) (string, error) {
+	result := repo.db.Clauses(clause.Returning{}).Omit("invoice_id").Create(invoice)
+	if result.Error != nil {
+		return "", result.Error
+	}
+	return invoice.InvoiceID, nil
+}
+
+func (repo *InvoiceRepositoryImpl) GetInvoiceByOrder(restaurantID string, orderID string) (*models.Invoice, error) {
+	var invoice models.Invoice
+	err := repo.db.First(&invoice, "order_id = ? AND restaurant_id = ?", orderID, restaurantID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// UpdateInvoiceTransmission saves the tax authority's answer to the invoice.
+func (repo *InvoiceRepositoryImpl) UpdateInvoiceTransmission(invoice *models.Invoice) error {
+	return repo.db.Model(&models.Invoice{}).
+		Where("invoice_id = ?", invoice.InvoiceID).
+		Select("status", "tracking_id", "status_message", "transmitted_at").
+		Updates(invoice).Error
+}
+
+func (repo *InvoiceRepositoryImpl) WithTransaction(fn func(txRepo repositories.InvoiceRepository) error) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := &InvoiceRepositoryImpl{db: tx}
+		return fn(txRepo)
+	})
+}
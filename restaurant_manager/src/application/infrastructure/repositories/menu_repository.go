@@ -24,31 +24,109 @@ func (repo *MenuRepositoryImpl) AddMenuItem(menuItem *models.MenuItem) (string,
 	return menuItem.MenuItemID, nil
 }
 
-func (repo *MenuRepositoryImpl) DeleteMenuItem(menuItemID string) error {
-	return repo.db.Delete(&models.MenuItem{}, "menu_item_id = ?", menuItemID).Error
+func (repo *MenuRepositoryImpl) DeleteMenuItem(restaurantID string, menuItemID string) error {
+	return repo.db.Delete(&models.MenuItem{}, "menu_item_id = ? AND restaurant_id = ?", menuItemID, restaurantID).Error
 }
 
 func (repo *MenuRepositoryImpl) UpdateMenuItem(menuItem *models.MenuItem) error {
 	return repo.db.Model(&models.MenuItem{}).
-		Where("menu_item_id = ?", menuItem.MenuItemID).
+		Where("menu_item_id = ? AND restaurant_id = ?", menuItem.MenuItemID, menuItem.RestaurantID).
+		Omit("ModifierGroups", "ComboSlots").
 		Updates(menuItem).Error
 }
 
+// SetMenuItemAvailable puts the menu item on or takes it off the menu, leaving
+// the rest of it as it is.
+func (repo *MenuRepositoryImpl) SetMenuItemAvailable(restaurantID string, menuItemID string, available bool) error {
+	return repo.db.Model(&models.MenuItem{}).
+		Where("menu_item_id = ? AND restaurant_id = ?", menuItemID, restaurantID).
+		Update("available", available).Error
+}
+
 func (repo *MenuRepositoryImpl) GetMenuItemsByRestaurantID(restaurantID string) ([]models.MenuItem, error) {
 	var items []models.MenuItem
-	err := repo.db.Preload("Ingredients").Preload("Ingredients.RawIngredient").Where("restaurant_id = ?", restaurantID).Find(&items).Error
+	err := repo.withChoices(repo.db.Preload("Ingredients").Preload("Ingredients.RawIngredient")).
+		Where("restaurant_id = ?", restaurantID).Find(&items).Error
 	return items, err
 }
 
-func (repo *MenuRepositoryImpl) GetMenuItemByID(menuItemID string) (*models.MenuItem, error) {
+func (repo *MenuRepositoryImpl) GetMenuItemByID(restaurantID string, menuItemID string) (*models.MenuItem, error) {
 	var item models.MenuItem
-	err := repo.db.Preload("Ingredients").Preload("Ingredients.RawIngredient").Where("menu_item_id = ?", menuItemID).First(&item).Error
+	err := repo.withChoices(repo.db.Preload("Ingredients").Preload("Ingredients.RawIngredient")).
+		Where("menu_item_id = ? AND restaurant_id = ?", menuItemID, restaurantID).First(&item).Error
 	if err != nil {
 		return nil, err
 	}
 	return &item, nil
 }
 
+// withChoices preloads the modifier groups of the menu items, with their
+// modifiers and recipe deltas, and their combo slots, in menu order.
+func (repo *MenuRepositoryImpl) withChoices(query *gorm.DB) *gorm.DB {
+	return query.
+		Preload("ModifierGroups", func(db *gorm.DB) *gorm.DB { return db.Order("position, name") }).
+		Preload("ModifierGroups.Modifiers", func(db *gorm.DB) *gorm.DB { return db.Order("position, name") }).
+		Preload("ModifierGroups.Modifiers.Ingredients").
+		Preload("ComboSlots", func(db *gorm.DB) *gorm.DB { return db.Order("position, name") }).
+		Preload("ComboSlots.MenuItem")
+}
+
+// ReplaceComboSlots makes the menu item a combo of the given slots, or a plain
+// menu item when there are none.
+func (repo *MenuRepositoryImpl) ReplaceComboSlots(comboItemID string, slots []models.ComboSlot) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.ComboSlot{}, "combo_item_id = ?", comboItemID).Error; err != nil {
+			return err
+		}
+		for i := range slots {
+			slots[i].ComboItemID = comboItemID
+			if err := tx.Clauses(clause.Returning{}).Omit("slot_id", "MenuItem").Create(&slots[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CreateModifierGroup inserts the group with its modifiers and their recipe
+// deltas.
+func (repo *MenuRepositoryImpl) CreateModifierGroup(group *models.ModifierGroup) (string, error) {
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Returning{}).Omit("modifier_group_id", "Modifiers").Create(group).Error; err != nil {
+			return err
+		}
+		for i := range group.Modifiers {
+			modifier := &group.Modifiers[i]
+			modifier.ModifierGroupID = group.ModifierGroupID
+			if err := tx.Clauses(clause.Returning{}).Omit("modifier_id", "Ingredients").Create(modifier).Error; err != nil {
+				return err
+			}
+			for j := range modifier.Ingredients {
+				modifier.Ingredients[j].ModifierID = modifier.ModifierID
+				if err := tx.Clauses(clause.Returning{}).Omit("modifier_ingredient_id").Create(&modifier.Ingredients[j]).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return group.ModifierGroupID, nil
+}
+
+func (repo *MenuRepositoryImpl) DeleteModifierGroup(menuItemID string, modifierGroupID string) error {
+	result := repo.db.Delete(&models.ModifierGroup{}, "modifier_group_id = ? AND menu_item_id = ?", modifierGroupID, menuItemID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
 func (repo *MenuRepositoryImpl) WithTransaction(fn func(txRepo repositories.MenuRepository) error) error {
 	return repo.db.Transaction(func(tx *gorm.DB) error {
 		return fn(repo)
@@ -3,6 +3,7 @@ package repositories
 import (
 	"restaurant_manager/src/domain/models"
 	"restaurant_manager/src/domain/repositories"
+	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -24,20 +25,33 @@ func (repo *OrderRepositoryImpl) CreateOrder(order *models.Order) (string, error
 	return order.OrderID, nil
 }
 
-func (repo *OrderRepositoryImpl) DeleteOrder(orderID string) error {
-	return repo.db.Delete(&models.Order{}, "order_id = ?", orderID).Error
+func (repo *OrderRepositoryImpl) DeleteOrder(restaurantID string, orderID string) error {
+	return repo.db.Delete(&models.Order{}, "order_id = ? AND restaurant_id = ?", orderID, restaurantID).Error
 }
 
 func (repo *OrderRepositoryImpl) UpdateOrder(order *models.Order) error {
-	return repo.db.Model(&models.Order{}).Where("order_id = ?", order.OrderID).Updates(order).Error
+	return repo.db.Model(&models.Order{}).
+		Where("order_id = ? AND restaurant_id = ?", order.OrderID, order.RestaurantID).
+		Omit(clause.Associations).
+		Updates(order).Error
 }
 
-func (repo *OrderRepositoryImpl) GetOrder(orderID string) (*models.Order, error) {
+// UpdateOrderTotals saves the order's price breakdown, zero amounts included.
+func (repo *OrderRepositoryImpl) UpdateOrderTotals(order *models.Order) error {
+	return repo.db.Model(&models.Order{}).
+		Where("order_id = ? AND restaurant_id = ?", order.OrderID, order.RestaurantID).
+		Select("subtotal", "discount_total", "tax_total", "service_charge", "total_price").
+		Updates(order).Error
+}
+
+func (repo *OrderRepositoryImpl) GetOrder(restaurantID string, orderID string) (*models.Order, error) {
 	var orders models.Order
 	err := repo.db.Model(&models.Order{}).
-		Preload("OrderItems").Preload("OrderItems.MenuItem").
+		Preload("OrderItems").Preload("OrderItems.MenuItem").Preload("OrderItems.Modifiers").
+		Preload("OrderItems.Components.MenuItem").
+		Preload("Discounts").Preload("Coupons").
 		Preload("Table").
-		Where("order_id = ?", orderID).
+		Where("order_id = ? AND restaurant_id = ?", orderID, restaurantID).
 		First(&orders).Error
 
 	if err != nil {
@@ -61,6 +75,10 @@ func (repo *OrderRepositoryImpl) GetOrderByRestaurantID(restaurantID string, sta
 	query := repo.db.Model(&models.Order{}).
 		Preload("OrderItems").
 		Preload("OrderItems.MenuItem").
+		Preload("OrderItems.Modifiers").
+		Preload("OrderItems.Components.MenuItem").
+		Preload("Discounts").
+		Preload("Coupons").
 		Preload("Table").
 		Where("restaurant_id = ? AND status = ?", restaurantID, status)
 
@@ -83,39 +101,184 @@ func (repo *OrderRepositoryImpl) GetOrderByRestaurantID(restaurantID string, sta
 	return orders, nil
 }
 
+// AddOrderItem inserts the item together with the modifiers and, for combos,
+// the components chosen for it.
 func (repo *OrderRepositoryImpl) AddOrderItem(orderItem *models.OrderItem) (string, error) {
-	result := repo.db.Create(orderItem)
+	result := repo.db.Clauses(clause.Returning{}).Omit("order_item_id", clause.Associations).Create(orderItem)
 	if result.Error != nil {
 		return "", result.Error
 	}
-	return orderItem.OrderID, nil
+	for i := range orderItem.Modifiers {
+		orderItem.Modifiers[i].OrderItemID = orderItem.OrderItemID
+		err := repo.db.Clauses(clause.Returning{}).Omit("order_item_modifier_id", clause.Associations).Create(&orderItem.Modifiers[i]).Error
+		if err != nil {
+			return "", err
+		}
+	}
+	for i := range orderItem.Components {
+		orderItem.Components[i].OrderItemID = orderItem.OrderItemID
+		err := repo.db.Clauses(clause.Returning{}).Omit("order_item_component_id", clause.Associations).Create(&orderItem.Components[i]).Error
+		if err != nil {
+			return "", err
+		}
+	}
+	return orderItem.OrderItemID, nil
 }
 
 func (repo *OrderRepositoryImpl) UpdateOrderItem(orderItem *models.OrderItem) error {
 	return repo.db.Model(&models.OrderItem{}).
-		Where("order_id = ? AND menu_item_id = ? AND observation = ?", orderItem.OrderID, orderItem.MenuItemID, orderItem.Observation).
+		Where("order_item_id = ?", orderItem.OrderItemID).
+		Omit(clause.Associations).
+		Updates(orderItem).Error
+}
+
+// GetTaxTotals sums the taxes charged on the items of the orders paid between
+// startDate and endDate, per tax category and rate.
+func (repo *OrderRepositoryImpl) GetTaxTotals(restaurantID string, startDate time.Time, endDate time.Time) ([]models.TaxLine, error) {
+	var lines []models.TaxLine
+	err := repo.db.Raw(`
+		SELECT oi.tax_category, oi.tax_rate, SUM(oi.tax_base) AS base, SUM(oi.tax_amount) AS amount
+		FROM servu.order_items oi
+		JOIN servu.orders o ON o.order_id = oi.order_id
+		WHERE o.restaurant_id = ? AND o.status = ? AND oi.status <> ? AND oi.tax_category <> ?
+			AND o.created_at >= ? AND o.created_at < ?
+		GROUP BY oi.tax_category, oi.tax_rate
+		ORDER BY oi.tax_category, oi.tax_rate
+	`, restaurantID, models.Paid, models.Cancelled, models.TaxNone, startDate, endDate).Scan(&lines).Error
+	return lines, err
+}
+
+// UpdateOrderItemTaxes saves the tax worked out on each item line.
+func (repo *OrderRepositoryImpl) UpdateOrderItemTaxes(items []models.OrderItem) error {
+	for _, item := range items {
+		err := repo.db.Model(&models.OrderItem{}).
+			Where("order_item_id = ?", item.OrderItemID).
+			Select("tax_base", "tax_amount").
+			Updates(&item).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClaimTicketedUnits records that the item's units up to the given quantity
+// were sent to the kitchen. It reports false, recording nothing, when another
+// ticket sent them first.
+func (repo *OrderRepositoryImpl) ClaimTicketedUnits(orderItemID string, ticketed int, quantity int) (bool, error) {
+	result := repo.db.Model(&models.OrderItem{}).
+		Where("order_item_id = ? AND ticketed_quantity = ?", orderItemID, ticketed).
+		Update("ticketed_quantity", quantity)
+	return result.RowsAffected == 1, result.Error
+}
+
+// UpdateOrderItemQuantity saves the item's quantity and the units of it
+// already ticketed, zeros included.
+func (repo *OrderRepositoryImpl) UpdateOrderItemQuantity(orderItem *models.OrderItem) error {
+	return repo.db.Model(&models.OrderItem{}).
+		Where("order_item_id = ?", orderItem.OrderItemID).
+		Select("quantity", "ticketed_quantity").
 		Updates(orderItem).Error
 }
 
+// MoveOrderItem hands the item, with its modifiers and components, to another order.
+func (repo *OrderRepositoryImpl) MoveOrderItem(orderItemID string, orderID string) error {
+	return repo.db.Model(&models.OrderItem{}).
+		Where("order_item_id = ?", orderItemID).
+		Update("order_id", orderID).Error
+}
+
+// MoveOrderCoupons hands the coupons redeemed on an order to another, except
+// those the other order already redeemed.
+func (repo *OrderRepositoryImpl) MoveOrderCoupons(fromOrderID string, toOrderID string) error {
+	return repo.db.Exec(`
+		UPDATE servu.order_coupons SET order_id = ?
+		WHERE order_id = ? AND promotion_id NOT IN (SELECT promotion_id FROM servu.order_coupons WHERE order_id = ?)
+	`, toOrderID, fromOrderID, toOrderID).Error
+}
+
 func (repo *OrderRepositoryImpl) DeleteOrderItem(orderID string, menuItemID string) error {
 	return repo.db.Delete(&models.OrderItem{}, "order_id = ? AND menu_item_id = ?", orderID, menuItemID).Error
 }
 
-func (repo *OrderRepositoryImpl) GetOrderItems(orderID string) ([]models.OrderItem, error) {
+func (repo *OrderRepositoryImpl) GetOrderItems(restaurantID string, orderID string) ([]models.OrderItem, error) {
 	var items []models.OrderItem
-	err := repo.db.Where("order_id = ?", orderID).Find(&items).Error
+	err := repo.db.Preload("Modifiers").Preload("Components").
+		Where("order_id = ? AND order_id IN (?)", orderID, repo.restaurantOrders(restaurantID)).
+		Find(&items).Error
 	return items, err
 }
 
-func (repo *OrderRepositoryImpl) GetOrderItem(orderID string, menuItemID string, observation string) (*models.OrderItem, error) {
+func (repo *OrderRepositoryImpl) GetOrderItem(restaurantID string, orderID string, menuItemID string, observation string) (*models.OrderItem, error) {
+	var item models.OrderItem
+	err := repo.db.Preload("Modifiers.Modifier.Ingredients").Preload("Components.MenuItem.Ingredients").
+		Where("order_id = ? AND menu_item_id = ? AND observation = ?", orderID, menuItemID, observation).
+		Where("order_id IN (?)", repo.restaurantOrders(restaurantID)).
+		First(&item).Error
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (repo *OrderRepositoryImpl) GetOrderItemByID(restaurantID string, orderID string, orderItemID string) (*models.OrderItem, error) {
 	var item models.OrderItem
-	err := repo.db.Where("order_id = ? AND menu_item_id = ? AND observation = ?", orderID, menuItemID, observation).First(&item).Error
+	err := repo.db.Preload("Modifiers.Modifier.Ingredients").Preload("Components.MenuItem.Ingredients").
+		Where("order_item_id = ? AND order_id = ?", orderItemID, orderID).
+		Where("order_id IN (?)", repo.restaurantOrders(restaurantID)).
+		First(&item).Error
 	if err != nil {
 		return nil, err
 	}
 	return &item, nil
 }
 
+// restaurantOrders selects the IDs of the orders belonging to a restaurant, used
+// to scope order items, which do not carry a restaurant of their own.
+// LockOrders locks the restaurant's orders with the given IDs until the
+// transaction ends and returns the IDs it found. Orders are locked in ID
+// order, so transactions locking the same orders cannot deadlock.
+func (repo *OrderRepositoryImpl) LockOrders(restaurantID string, orderIDs []string) ([]string, error) {
+	var locked []string
+	err := repo.db.Model(&models.Order{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("restaurant_id = ? AND order_id IN ?", restaurantID, orderIDs).
+		Order("order_id").
+		Pluck("order_id", &locked).Error
+	return locked, err
+}
+
+// HasOpenOrders reports whether any order at the table is still open.
+func (repo *OrderRepositoryImpl) HasOpenOrders(restaurantID string, tableID string) (bool, error) {
+	var count int64
+	err := repo.db.Model(&models.Order{}).
+		Where("restaurant_id = ? AND table_id = ? AND status NOT IN ?", restaurantID, tableID, []models.OrderStatus{models.Paid, models.Cancelled}).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// LockTable loads the restaurant's table and holds a row lock on it until the
+// transaction ends, so two orders cannot take the same table at once.
+func (repo *OrderRepositoryImpl) LockTable(restaurantID string, tableID string) (*models.Table, error) {
+	var table models.Table
+	err := repo.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&table, "table_id = ? AND restaurant_id = ?", tableID, restaurantID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &table, nil
+}
+
+func (repo *OrderRepositoryImpl) UpdateTableStatus(restaurantID string, tableID string, status models.TableStatus) error {
+	return repo.db.Model(&models.Table{}).
+		Where("table_id = ? AND restaurant_id = ?", tableID, restaurantID).
+		Update("status", status).Error
+}
+
+func (repo *OrderRepositoryImpl) restaurantOrders(restaurantID string) *gorm.DB {
+	return repo.db.Model(&models.Order{}).Select("order_id").Where("restaurant_id = ?", restaurantID)
+}
+
 func (repo *OrderRepositoryImpl) WithTransaction(fn func(txRepo repositories.OrderRepository) error) error {
 	return repo.db.Transaction(func(tx *gorm.DB) error {
 		txRepo := &OrderRepositoryImpl{db: tx}
@@ -123,6 +286,24 @@ func (repo *OrderRepositoryImpl) WithTransaction(fn func(txRepo repositories.Ord
 	})
 }
 
+// Payments returns the payments repository on the same connection, so payments
+// are read and taken within the order's transaction.
+func (repo *OrderRepositoryImpl) Payments() repositories.PaymentRepository {
+	return &PaymentRepositoryImpl{db: repo.db}
+}
+
+// Inventory returns the inventory repository on the same connection, so the
+// stock an order uses moves within the order's transaction.
+func (repo *OrderRepositoryImpl) Inventory() repositories.InventoryRepository {
+	return &InventoryRepositoryImpl{db: repo.db}
+}
+
+// Menu returns the menu repository on the same connection, so menu items sold
+// out by an order leave the menu within the order's transaction.
+func (repo *OrderRepositoryImpl) Menu() repositories.MenuRepository {
+	return &MenuRepositoryImpl{db: repo.db}
+}
+
 func (repo *OrderRepositoryImpl) AddVoidOrderItem(voidOrderItem *models.VoidOrderItem) error {
 	return repo.db.Clauses(clause.Returning{}).Omit("void_order_item_id").Create(voidOrderItem).Error
 }
@@ -135,17 +316,109 @@ func (repo *OrderRepositoryImpl) GetVoidOrderItems(restaurantID string) ([]model
 	return items, err
 }
 
-func (repo *OrderRepositoryImpl) DeleteVoidOrderItem(voidOrderItemID string) error {
-	return repo.db.Delete(&models.VoidOrderItem{}, "void_order_item_id = ?", voidOrderItemID).Error
+func (repo *OrderRepositoryImpl) DeleteVoidOrderItem(restaurantID string, voidOrderItemID string) error {
+	return repo.db.Delete(&models.VoidOrderItem{}, "void_order_item_id = ? AND restaurant_id = ?", voidOrderItemID, restaurantID).Error
 }
 
-func (repo *OrderRepositoryImpl) GetVoidOrderItemByID(voidOrderItemID string) (*models.VoidOrderItem, error) {
+func (repo *OrderRepositoryImpl) GetVoidOrderItemByID(restaurantID string, voidOrderItemID string) (*models.VoidOrderItem, error) {
 	var item models.VoidOrderItem
 	err := repo.db.Preload("MenuItem").
-		Where("void_order_item_id = ?", voidOrderItemID).
+		Where("void_order_item_id = ? AND restaurant_id = ?", voidOrderItemID, restaurantID).
 		First(&item).Error
 	if err != nil {
 		return nil, err
 	}
 	return &item, nil
 }
+
+// LockVoidOrderItem loads the restaurant's void item and holds a row lock on it
+// until the transaction ends, so the same void cannot be recovered twice.
+func (repo *OrderRepositoryImpl) LockVoidOrderItem(restaurantID string, voidOrderItemID string) (*models.VoidOrderItem, error) {
+	var item models.VoidOrderItem
+	err := repo.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&item, "void_order_item_id = ? AND restaurant_id = ?", voidOrderItemID, restaurantID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (repo *OrderRepositoryImpl) AddOrderStatusChange(change *models.OrderStatusChange) error {
+	return repo.db.Clauses(clause.Returning{}).Omit("history_id").Create(change).Error
+}
+
+func (repo *OrderRepositoryImpl) GetOrderStatusHistory(restaurantID string, orderID string) ([]models.OrderStatusChange, error) {
+	var history []models.OrderStatusChange
+	err := repo.db.Where("order_id = ? AND restaurant_id = ?", orderID, restaurantID).
+		Order("changed_at").
+		Find(&history).Error
+	return history, err
+}
+
+// ReplaceOrderDiscounts swaps the order's discount lines for the given ones.
+func (repo *OrderRepositoryImpl) ReplaceOrderDiscounts(orderID string, discounts []models.OrderDiscount) error {
+	if err := repo.db.Delete(&models.OrderDiscount{}, "order_id = ?", orderID).Error; err != nil {
+		return err
+	}
+	for i := range discounts {
+		discounts[i].OrderID = orderID
+		err := repo.db.Clauses(clause.Returning{}).Omit("order_discount_id").Create(&discounts[i]).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RedeemCoupon counts a use of the coupon's promotion and records it on the
+// order. It reports false, recording nothing, when the promotion already
+// reached its usage limit.
+func (repo *OrderRepositoryImpl) RedeemCoupon(coupon *models.OrderCoupon) (bool, error) {
+	result := repo.db.Model(&models.Promotion{}).
+		Where("promotion_id = ? AND (usage_limit IS NULL OR times_used < usage_limit)", coupon.PromotionID).
+		Update("times_used", gorm.Expr("times_used + 1"))
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	err := repo.db.Clauses(clause.Returning{}).Omit("order_coupon_id").Create(coupon).Error
+	return err == nil, err
+}
+
+// performanceBuckets maps each supported grouping to the expression naming its
+// bucket. Orders are stamped in UTC and bucketed in the time zone bound to the
+// expression.
+var performanceBuckets = map[string]string{
+	models.PerformanceByHour: "to_char(created_at AT TIME ZONE 'UTC' AT TIME ZONE ?, 'HH24')",
+	models.PerformanceByDay:  "to_char(created_at AT TIME ZONE 'UTC' AT TIME ZONE ?, 'YYYY-MM-DD')",
+}
+
+// GetKitchenPerformance aggregates the stage durations of the restaurant's
+// orders created between the two times, bucketed by hour or day in the given
+// time zone.
+func (repo *OrderRepositoryImpl) GetKitchenPerformance(restaurantID string, groupBy string, timeZone string, startDate time.Time, endDate time.Time) ([]models.KitchenPerformance, error) {
+	bucket, ok := performanceBuckets[groupBy]
+	if !ok {
+		return nil, gorm.ErrInvalidData
+	}
+
+	// Zero durations belong to stages the order never went through
+	var performance []models.KitchenPerformance
+	err := repo.db.Model(&models.Order{}).
+		Select(bucket+` AS bucket,
+			COUNT(*) AS orders,
+			COALESCE(AVG(NULLIF(time_to_prepare_seconds, 0)), 0) AS avg_prepare,
+			COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY NULLIF(time_to_prepare_seconds, 0)), 0) AS p50_prepare,
+			COALESCE(percentile_cont(0.9) WITHIN GROUP (ORDER BY NULLIF(time_to_prepare_seconds, 0)), 0) AS p90_prepare,
+			COALESCE(AVG(NULLIF(time_to_deliver_seconds, 0)), 0) AS avg_deliver,
+			COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY NULLIF(time_to_deliver_seconds, 0)), 0) AS p50_deliver,
+			COALESCE(percentile_cont(0.9) WITHIN GROUP (ORDER BY NULLIF(time_to_deliver_seconds, 0)), 0) AS p90_deliver,
+			COALESCE(AVG(NULLIF(time_to_pay_seconds, 0)), 0) AS avg_pay,
+			COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY NULLIF(time_to_pay_seconds, 0)), 0) AS p50_pay,
+			COALESCE(percentile_cont(0.9) WITHIN GROUP (ORDER BY NULLIF(time_to_pay_seconds, 0)), 0) AS p90_pay`, timeZone).
+		Where("restaurant_id = ? AND created_at >= ? AND created_at < ?", restaurantID, startDate, endDate).
+		Where("status <> ?", models.Cancelled).
+		Group("bucket").
+		Order("bucket").
+		Scan(&performance).Error
+	return performance, err
+}
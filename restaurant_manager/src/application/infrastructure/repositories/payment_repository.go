@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"restaurant_manager/src/domain/models"
+	"restaurant_manager/src/domain/repositories"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PaymentRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewPaymentRepository(db *gorm.DB) repositories.PaymentRepository {
+	return &PaymentRepositoryImpl{db: db}
+}
+
+// CreatePayment inserts the payment together with its allocations.
+func (repo *PaymentRepositoryImpl) CreatePayment(payment *models.Payment) (string, error) {
+	result := repo.db.Clauses(clause.Returning{}).Omit("payment_id", clause.Associations).Create(payment)
+	if result.Error != nil {
+		return "", result.Error
+	}
+	for i := range payment.Allocations {
+		payment.Allocations[i].PaymentID = payment.PaymentID
+		err := repo.db.Clauses(clause.Returning{}).Omit("payment_allocation_id").Create(&payment.Allocations[i]).Error
+		if err != nil {
+			return "", err
+		}
+	}
+	return payment.PaymentID, nil
+}
+
+func (repo *PaymentRepositoryImpl) GetPayments(restaurantID string, orderID string) ([]models.Payment, error) {
+	var payments []models.Payment
+	err := repo.db.Preload("Allocations").
+		Where("restaurant_id = ? AND order_id = ?", restaurantID, orderID).
+		Order("created_at").
+		Find(&payments).Error
+	return payments, err
+}
+
+// GetPaymentMethodTotals sums the completed payments taken between startDate
+// and endDate by payment method.
+func (repo *PaymentRepositoryImpl) GetPaymentMethodTotals(restaurantID string, startDate time.Time, endDate time.Time) ([]models.PaymentMethodTotal, error) {
+	var totals []models.PaymentMethodTotal
+	err := repo.db.Model(&models.Payment{}).
+		Select("payment_method AS method, SUM(amount) AS amount, SUM(tip) AS tips, COUNT(*) AS count").
+		Where("restaurant_id = ? AND status = ?", restaurantID, models.PaymentCompleted).
+		Where("created_at >= ? AND created_at < ?", startDate, endDate).
+		Group("payment_method").
+		Order("payment_method").
+		Scan(&totals).Error
+	return totals, err
+}
+
+// GetTipShares sums the tips taken between startDate and endDate by the waiter
+// who served the order, falling back to whoever took the payment for orders
+// placed by customers, and by the shift of theirs the payment fell in.
+func (repo *PaymentRepositoryImpl) GetTipShares(restaurantID string, startDate time.Time, endDate time.Time) ([]models.TipShare, error) {
+	var shares []models.TipShare
+	err := repo.db.Raw(`
+		SELECT u.user_id, u.name, s.shift_id, s.clock_in, s.clock_out,
+			COUNT(p.payment_id) AS payments, SUM(p.tip) AS tips
+		FROM servu.payments p
+		JOIN servu.orders o ON o.order_id = p.order_id
+		LEFT JOIN servu.users u ON u.user_id = COALESCE(o.waiter_id, p.created_by)
+		LEFT JOIN servu.shifts s ON s.user_id = u.user_id AND s.restaurant_id = p.restaurant_id
+			AND p.created_at >= s.clock_in AND (s.clock_out IS NULL OR p.created_at < s.clock_out)
+		WHERE p.restaurant_id = ? AND p.status = ? AND p.tip > 0
+			AND p.created_at >= ? AND p.created_at < ?
+		GROUP BY u.user_id, u.name, s.shift_id, s.clock_in, s.clock_out
+		ORDER BY u.name, s.clock_in`,
+		restaurantID, models.PaymentCompleted, startDate, endDate).
+		Scan(&shares).Error
+	return shares, err
+}
+
+// LockOrder loads the order with its items and holds a row lock on it until
+// the transaction ends, so concurrent payments see each other's allocations.
+func (repo *PaymentRepositoryImpl) LockOrder(restaurantID string, orderID string) (*models.Order, error) {
+	var order models.Order
+	err := repo.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("order_id = ? AND restaurant_id = ?", orderID, restaurantID).
+		First(&order).Error
+	if err != nil {
+		return nil, err
+	}
+	err = repo.db.Preload("MenuItem").Where("order_id = ?", orderID).Order("created_at").Find(&order.OrderItems).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (repo *PaymentRepositoryImpl) CreateServiceChargeRule(rule *models.ServiceChargeRule) (string, error) {
+	result := repo.db.Clauses(clause.Returning{}).Omit("rule_id").Create(rule)
+	if result.Error != nil {
+		return "", result.Error
+	}
+	return rule.RuleID, nil
+}
+
+func (repo *PaymentRepositoryImpl) GetServiceChargeRule(restaurantID string, ruleID string) (*models.ServiceChargeRule, error) {
+	var rule models.ServiceChargeRule
+	err := repo.db.First(&rule, "rule_id = ? AND restaurant_id = ?", ruleID, restaurantID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (repo *PaymentRepositoryImpl) GetServiceChargeRules(restaurantID string) ([]models.ServiceChargeRule, error) {
+	var rules []models.ServiceChargeRule
+	err := repo.db.Where("restaurant_id = ?", restaurantID).Order("minimum_order_total, created_at").Find(&rules).Error
+	return rules, err
+}
+
+// UpdateServiceChargeRule saves every field of the rule, so it can be switched
+// off.
+func (repo *PaymentRepositoryImpl) UpdateServiceChargeRule(rule *models.ServiceChargeRule) error {
+	return repo.db.Model(&models.ServiceChargeRule{}).
+		Where("rule_id = ? AND restaurant_id = ?", rule.RuleID, rule.RestaurantID).
+		Select("name", "percentage", "minimum_order_total", "active").
+		Updates(rule).Error
+}
+
+func (repo *PaymentRepositoryImpl) DeleteServiceChargeRule(restaurantID string, ruleID string) error {
+	return repo.db.Delete(&models.ServiceChargeRule{}, "rule_id = ? AND restaurant_id = ?", ruleID, restaurantID).Error
+}
+
+func (repo *PaymentRepositoryImpl) WithTransaction(fn func(txRepo repositories.PaymentRepository) error) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := &PaymentRepositoryImpl{db: tx}
+		return fn(txRepo)
+	})
+}
@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"restaurant_manager/src/domain/models"
+	"restaurant_manager/src/domain/repositories"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PromotionRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewPromotionRepository(db *gorm.DB) repositories.PromotionRepository {
+	return &PromotionRepositoryImpl{db: db}
+}
+
+func (repo *PromotionRepositoryImpl) CreatePromotion(promotion *models.Promotion) (string, error) {
+	result := repo.db.Clauses(clause.Returning{}).Omit("promotion_id").Create(promotion)
+	if result.Error != nil {
+		return "", result.Error
+	}
+	return promotion.PromotionID, nil
+}
+
+func (repo *PromotionRepositoryImpl) GetPromotion(restaurantID string, promotionID string) (*models.Promotion, error) {
+	var promotion models.Promotion
+	err := repo.db.First(&promotion, "promotion_id = ? AND restaurant_id = ?", promotionID, restaurantID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+func (repo *PromotionRepositoryImpl) GetPromotions(restaurantID string) ([]models.Promotion, error) {
+	var promotions []models.Promotion
+	err := repo.db.Where("restaurant_id = ?", restaurantID).Order("created_at").Find(&promotions).Error
+	return promotions, err
+}
+
+func (repo *PromotionRepositoryImpl) GetActivePromotions(restaurantID string) ([]models.Promotion, error) {
+	var promotions []models.Promotion
+	err := repo.db.Where("restaurant_id = ? AND active", restaurantID).Order("created_at").Find(&promotions).Error
+	return promotions, err
+}
+
+// GetPromotionByCode finds a coupon promotion; codes are not case sensitive.
+func (repo *PromotionRepositoryImpl) GetPromotionByCode(restaurantID string, code string) (*models.Promotion, error) {
+	var promotion models.Promotion
+	err := repo.db.First(&promotion, "restaurant_id = ? AND UPPER(coupon_code) = UPPER(?)", restaurantID, code).Error
+	if err != nil {
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+// UpdatePromotion saves every editable field, so optional limits can be
+// cleared and the promotion switched off. Usage counts are left alone.
+func (repo *PromotionRepositoryImpl) UpdatePromotion(promotion *models.Promotion) error {
+	return repo.db.Model(&models.Promotion{}).
+		Where("promotion_id = ? AND restaurant_id = ?", promotion.PromotionID, promotion.RestaurantID).
+		Select("name", "kind", "scope", "value", "menu_item_id", "category", "buy_quantity", "free_quantity",
+			"minimum_order_total", "starts_at", "ends_at", "happy_hour_start", "happy_hour_end",
+			"coupon_code", "usage_limit", "active").
+		Updates(promotion).Error
+}
+
+func (repo *PromotionRepositoryImpl) DeletePromotion(restaurantID string, promotionID string) error {
+	return repo.db.Delete(&models.Promotion{}, "promotion_id = ? AND restaurant_id = ?", promotionID, restaurantID).Error
+}
@@ -42,7 +42,7 @@ func (r *RawIngredientsRepository) UpdateMany(ingredients []models.RawIngredient
 	return nil
 }
 
-func (r *RawIngredientsRepository) Delete(id string) error {
-	result := r.db.Where("id = ?", id).Delete(&models.RawIngredient{})
+func (r *RawIngredientsRepository) Delete(restaurantID string, id string) error {
+	result := r.db.Where("raw_ingredient_id = ? AND restaurant_id = ?", id, restaurantID).Delete(&models.RawIngredient{})
 	return result.Error
 }
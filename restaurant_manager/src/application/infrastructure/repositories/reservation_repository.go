@@ -0,0 +1,224 @@
+package repositories
+
+import (
+	"restaurant_manager/src/domain/models"
+	"restaurant_manager/src/domain/repositories"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReservationRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewReservationRepository(db *gorm.DB) repositories.ReservationRepository {
+	return &ReservationRepositoryImpl{db: db}
+}
+
+// CreateReservation inserts the reservation and the tables assigned to it.
+func (repo *ReservationRepositoryImpl) CreateReservation(reservation *models.Reservation) (string, error) {
+	result := repo.db.Clauses(clause.Returning{}).Omit("reservation_id", clause.Associations).Create(reservation)
+	if result.Error != nil {
+		return "", result.Error
+	}
+	if err := repo.insertTables(reservation); err != nil {
+		return "", err
+	}
+	return reservation.ReservationID, nil
+}
+
+func (repo *ReservationRepositoryImpl) GetReservation(restaurantID string, reservationID string) (*models.Reservation, error) {
+	var reservation models.Reservation
+	err := repo.db.Preload("Tables.Table").
+		First(&reservation, "reservation_id = ? AND restaurant_id = ?", reservationID, restaurantID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// GetReservations returns the restaurant's reservations starting between the
+// two times, earliest first.
+func (repo *ReservationRepositoryImpl) GetReservations(restaurantID string, startDate time.Time, endDate time.Time) ([]models.Reservation, error) {
+	var reservations []models.Reservation
+	err := repo.db.Preload("Tables.Table").
+		Where("restaurant_id = ? AND starts_at >= ? AND starts_at < ?", restaurantID, startDate, endDate).
+		Order("starts_at, created_at").
+		Find(&reservations).Error
+	return reservations, err
+}
+
+// UpdateReservation writes the party's details and time and replaces the
+// tables assigned to the reservation.
+func (repo *ReservationRepositoryImpl) UpdateReservation(reservation *models.Reservation) error {
+	err := repo.db.Model(&models.Reservation{}).
+		Where("reservation_id = ? AND restaurant_id = ?", reservation.ReservationID, reservation.RestaurantID).
+		Select("guest_name", "phone", "party_size", "starts_at", "duration_minutes", "notes").
+		Updates(reservation).Error
+	if err != nil {
+		return err
+	}
+	err = repo.db.Delete(&models.ReservationTable{}, "reservation_id = ?", reservation.ReservationID).Error
+	if err != nil {
+		return err
+	}
+	return repo.insertTables(reservation)
+}
+
+func (repo *ReservationRepositoryImpl) insertTables(reservation *models.Reservation) error {
+	if len(reservation.Tables) == 0 {
+		return nil
+	}
+	for i := range reservation.Tables {
+		reservation.Tables[i].ReservationID = reservation.ReservationID
+	}
+	return repo.db.Omit(clause.Associations).Create(&reservation.Tables).Error
+}
+
+func (repo *ReservationRepositoryImpl) UpdateReservationStatus(reservation *models.Reservation) error {
+	return repo.db.Model(&models.Reservation{}).
+		Where("reservation_id = ? AND restaurant_id = ?", reservation.ReservationID, reservation.RestaurantID).
+		Select("status", "seated_at", "cancelled_at").
+		Updates(reservation).Error
+}
+
+// LockTables locks the restaurant's tables with the given IDs until the
+// transaction ends and returns the IDs it found, so bookings of the same
+// tables are checked for conflicts one at a time. Tables are locked in ID
+// order, so transactions locking the same tables cannot deadlock.
+func (repo *ReservationRepositoryImpl) LockTables(restaurantID string, tableIDs []string) ([]string, error) {
+	var locked []string
+	err := repo.db.Model(&models.Table{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("restaurant_id = ? AND table_id IN ?", restaurantID, tableIDs).
+		Order("table_id").
+		Pluck("table_id", &locked).Error
+	return locked, err
+}
+
+func (repo *ReservationRepositoryImpl) UpdateTableStatus(restaurantID string, tableID string, status models.TableStatus) error {
+	return repo.db.Model(&models.Table{}).
+		Where("table_id = ? AND restaurant_id = ?", tableID, restaurantID).
+		Update("status", status).Error
+}
+
+// GetConflictingReservations returns the booked and seated reservations, other
+// than excludeID, holding any of the tables at some moment between the two
+// times.
+func (repo *ReservationRepositoryImpl) GetConflictingReservations(restaurantID string, tableIDs []string, startsAt time.Time, endsAt time.Time, excludeID string) ([]models.Reservation, error) {
+	var reservations []models.Reservation
+	query := repo.db.Preload("Tables.Table").
+		Where("restaurant_id = ? AND status IN ?", restaurantID, []models.ReservationStatus{models.ReservationBooked, models.ReservationSeated}).
+		Where("starts_at < ? AND starts_at + duration_minutes * INTERVAL '1 minute' > ?", endsAt, startsAt).
+		Where("reservation_id IN (?)", repo.db.Model(&models.ReservationTable{}).
+			Select("reservation_id").
+			Where("table_id IN ?", tableIDs))
+	if excludeID != "" {
+		query = query.Where("reservation_id <> ?", excludeID)
+	}
+	err := query.Order("starts_at").Find(&reservations).Error
+	return reservations, err
+}
+
+// SyncTableStatuses brings table statuses in line with the reservations:
+// booked reservations whose party has not arrived by noShowBefore become
+// no-shows, available tables of booked reservations starting by holdUntil are
+// held as reserved, and reserved tables no booked reservation holds any more
+// are released. An empty restaurantID syncs every restaurant.
+func (repo *ReservationRepositoryImpl) SyncTableStatuses(restaurantID string, holdUntil time.Time, noShowBefore time.Time) error {
+	scoped := func(query *gorm.DB) *gorm.DB {
+		if restaurantID != "" {
+			return query.Where("restaurant_id = ?", restaurantID)
+		}
+		return query
+	}
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		err := scoped(tx.Model(&models.Reservation{})).
+			Where("status = ? AND starts_at <= ?", models.ReservationBooked, noShowBefore).
+			Update("status", models.ReservationNoShow).Error
+		if err != nil {
+			return err
+		}
+
+		upcoming := func() *gorm.DB {
+			return tx.Table("servu.reservation_tables rt").
+				Select("1").
+				Joins("JOIN servu.reservations r ON r.reservation_id = rt.reservation_id").
+				Where("rt.table_id = tables.table_id AND r.status = ? AND r.starts_at <= ?", models.ReservationBooked, holdUntil)
+		}
+		err = scoped(tx.Model(&models.Table{})).
+			Where("status = ? AND NOT EXISTS (?)", models.TableStatusReserved, upcoming()).
+			Update("status", models.TableStatusAvailable).Error
+		if err != nil {
+			return err
+		}
+		return scoped(tx.Model(&models.Table{})).
+			Where("status = ? AND EXISTS (?)", models.TableStatusAvailable, upcoming()).
+			Update("status", models.TableStatusReserved).Error
+	})
+}
+
+func (repo *ReservationRepositoryImpl) CreateWaitlistEntry(entry *models.WaitlistEntry) (string, error) {
+	result := repo.db.Clauses(clause.Returning{}).Omit("entry_id").Create(entry)
+	if result.Error != nil {
+		return "", result.Error
+	}
+	return entry.EntryID, nil
+}
+
+func (repo *ReservationRepositoryImpl) GetWaitlistEntry(restaurantID string, entryID string) (*models.WaitlistEntry, error) {
+	var entry models.WaitlistEntry
+	err := repo.db.First(&entry, "entry_id = ? AND restaurant_id = ?", entryID, restaurantID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// GetWaitlist returns the parties still waiting, in the order they joined.
+func (repo *ReservationRepositoryImpl) GetWaitlist(restaurantID string) ([]models.WaitlistEntry, error) {
+	var entries []models.WaitlistEntry
+	err := repo.db.Where("restaurant_id = ? AND status = ?", restaurantID, models.WaitlistWaiting).
+		Order("created_at, entry_id").
+		Find(&entries).Error
+	return entries, err
+}
+
+func (repo *ReservationRepositoryImpl) UpdateWaitlistEntryStatus(entry *models.WaitlistEntry) error {
+	return repo.db.Model(&models.WaitlistEntry{}).
+		Where("entry_id = ? AND restaurant_id = ?", entry.EntryID, entry.RestaurantID).
+		Select("status", "table_id", "seated_at", "left_at").
+		Updates(entry).Error
+}
+
+// GetAverageTableTurn returns how long, on average, the restaurant's orders
+// paid since the given time stayed open. It is zero when there are none.
+func (repo *ReservationRepositoryImpl) GetAverageTableTurn(restaurantID string, since time.Time) (time.Duration, error) {
+	var seconds float64
+	err := repo.db.Model(&models.Order{}).
+		Select("COALESCE(AVG(EXTRACT(EPOCH FROM paid_at - created_at)), 0)").
+		Where("restaurant_id = ? AND status = ? AND paid_at >= ?", restaurantID, models.Paid, since).
+		Scan(&seconds).Error
+	return time.Duration(seconds * float64(time.Second)), err
+}
+
+// GetTableOccupancy returns, for each table with open orders, when the oldest
+// of them was taken.
+func (repo *ReservationRepositoryImpl) GetTableOccupancy(restaurantID string) ([]models.TableOccupancy, error) {
+	var occupancy []models.TableOccupancy
+	err := repo.db.Model(&models.Order{}).
+		Select("table_id, MIN(created_at) AS since").
+		Where("restaurant_id = ? AND status NOT IN ?", restaurantID, []models.OrderStatus{models.Paid, models.Cancelled}).
+		Group("table_id").
+		Scan(&occupancy).Error
+	return occupancy, err
+}
+
+func (repo *ReservationRepositoryImpl) WithTransaction(fn func(txRepo repositories.ReservationRepository) error) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := &ReservationRepositoryImpl{db: tx}
+		return fn(txRepo)
+	})
+}
@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"restaurant_manager/src/domain/models"
+	"restaurant_manager/src/domain/repositories"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ShiftRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewShiftRepository(db *gorm.DB) repositories.ShiftRepository {
+	return &ShiftRepositoryImpl{db: db}
+}
+
+func (r *ShiftRepositoryImpl) CreateShift(shift *models.Shift) (string, error) {
+	result := r.db.Clauses(clause.Returning{}).Omit("shift_id", "User").Create(shift)
+	if result.Error != nil {
+		return "", result.Error
+	}
+	return shift.ShiftID, nil
+}
+
+func (r *ShiftRepositoryImpl) GetOpenShift(userID string) (*models.Shift, error) {
+	var shift models.Shift
+	err := r.db.Where("user_id = ? AND clock_out IS NULL", userID).First(&shift).Error
+	if err != nil {
+		return nil, err
+	}
+	return &shift, nil
+}
+
+func (r *ShiftRepositoryImpl) CloseShift(shiftID string, clockOut time.Time) error {
+	return r.db.Model(&models.Shift{}).
+		Where("shift_id = ? AND clock_out IS NULL", shiftID).
+		Update("clock_out", clockOut).Error
+}
+
+// HasOverlappingShift reports whether the user has a shift overlapping the
+// given interval. A nil clockOut stands for a shift that is still open.
+func (r *ShiftRepositoryImpl) HasOverlappingShift(userID string, clockIn time.Time, clockOut *time.Time) (bool, error) {
+	query := r.db.Model(&models.Shift{}).
+		Where("user_id = ?", userID).
+		Where("clock_out IS NULL OR clock_out > ?", clockIn)
+	if clockOut != nil {
+		query = query.Where("clock_in < ?", *clockOut)
+	}
+
+	var count int64
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
+// GetShifts returns the shifts of the restaurant overlapping the date range,
+// optionally only those of one user.
+func (r *ShiftRepositoryImpl) GetShifts(restaurantID string, userID string, startDate, endDate time.Time) ([]models.Shift, error) {
+	query := r.db.Preload("User").
+		Where("restaurant_id = ?", restaurantID).
+		Where("clock_in < ? AND (clock_out IS NULL OR clock_out > ?)", endDate, startDate)
+	if userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var shifts []models.Shift
+	err := query.Order("clock_in").Find(&shifts).Error
+	return shifts, err
+}
@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"restaurant_manager/src/domain/models"
+	"restaurant_manager/src/domain/repositories"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StationRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewStationRepository(db *gorm.DB) repositories.StationRepository {
+	return &StationRepositoryImpl{db: db}
+}
+
+func (repo *StationRepositoryImpl) CreateStation(station *models.Station) (string, error) {
+	result := repo.db.Clauses(clause.Returning{}).Omit("station_id").Create(station)
+	if result.Error != nil {
+		return "", result.Error
+	}
+	return station.StationID, nil
+}
+
+func (repo *StationRepositoryImpl) GetStation(restaurantID string, stationID string) (*models.Station, error) {
+	var station models.Station
+	err := repo.db.First(&station, "station_id = ? AND restaurant_id = ?", stationID, restaurantID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &station, nil
+}
+
+func (repo *StationRepositoryImpl) GetStations(restaurantID string) ([]models.Station, error) {
+	var stations []models.Station
+	err := repo.db.Where("restaurant_id = ?", restaurantID).Order("created_at, station_id").Find(&stations).Error
+	return stations, err
+}
+
+// GetDefaultStation returns the oldest station of the kind, which receives the
+// items of its categories that have no station of their own.
+func (repo *StationRepositoryImpl) GetDefaultStation(restaurantID string, kind string) (*models.Station, error) {
+	var station models.Station
+	err := repo.db.Where("restaurant_id = ? AND kind = ?", restaurantID, kind).
+		Order("created_at, station_id").
+		First(&station).Error
+	if err != nil {
+		return nil, err
+	}
+	return &station, nil
+}
+
+func (repo *StationRepositoryImpl) UpdateStation(station *models.Station) error {
+	return repo.db.Model(&models.Station{}).
+		Where("station_id = ? AND restaurant_id = ?", station.StationID, station.RestaurantID).
+		Updates(station).Error
+}
+
+func (repo *StationRepositoryImpl) DeleteStation(restaurantID string, stationID string) error {
+	return repo.db.Delete(&models.Station{}, "station_id = ? AND restaurant_id = ?", stationID, restaurantID).Error
+}
+
+// GetStationItems returns the items in the given status routed to the station,
+// in the order they reached the kitchen. Items of a held course count from the
+// moment the course was fired.
+func (repo *StationRepositoryImpl) GetStationItems(restaurantID string, stationID string, categories []string, status models.OrderStatus) ([]models.OrderItem, error) {
+	var items []models.OrderItem
+	err := repo.stationItems(restaurantID, stationID, categories).
+		Where("order_items.status = ?", status).
+		Order("COALESCE(order_items.fired_at, order_items.created_at)").
+		Find(&items).Error
+	return items, err
+}
+
+// GetLastBumpedItem returns the item the station marked prepared most recently.
+func (repo *StationRepositoryImpl) GetLastBumpedItem(restaurantID string, stationID string, categories []string) (*models.OrderItem, error) {
+	var item models.OrderItem
+	err := repo.stationItems(restaurantID, stationID, categories).
+		Where("order_items.status = ?", models.Prepared).
+		Order("order_items.prepared_at DESC").
+		First(&item).Error
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// stationItems selects the restaurant's order items routed to a station: those
+// whose menu item names the station and, when categories are given, those of
+// these categories whose menu item names no station.
+func (repo *StationRepositoryImpl) stationItems(restaurantID string, stationID string, categories []string) *gorm.DB {
+	query := repo.db.Model(&models.OrderItem{}).
+		Select("order_items.*").
+		Preload("MenuItem").
+		Preload("Modifiers").
+		Preload("Components.MenuItem").
+		Joins("JOIN servu.menu_items mi ON mi.menu_item_id = order_items.menu_item_id").
+		Joins("JOIN servu.orders o ON o.order_id = order_items.order_id").
+		Where("o.restaurant_id = ?", restaurantID)
+	if len(categories) > 0 {
+		return query.Where("(mi.station_id = ? OR (mi.station_id IS NULL AND mi.category IN ?))", stationID, categories)
+	}
+	return query.Where("mi.station_id = ?", stationID)
+}
@@ -24,19 +24,19 @@ func (repo *TableRepositoryImpl) CreateTable(table *models.Table) (string, error
 	return table.TableID, nil
 }
 
-func (repo *TableRepositoryImpl) DeleteTable(tableID string) error {
-	return repo.db.Delete(&models.Table{}, "table_id = ?", tableID).Error
+func (repo *TableRepositoryImpl) DeleteTable(restaurantID string, tableID string) error {
+	return repo.db.Delete(&models.Table{}, "table_id = ? AND restaurant_id = ?", tableID, restaurantID).Error
 }
 
 func (repo *TableRepositoryImpl) UpdateTable(table *models.Table) error {
 	return repo.db.Model(&models.Table{}).
-		Where("table_id = ?", table.TableID).
+		Where("table_id = ? AND restaurant_id = ?", table.TableID, table.RestaurantID).
 		Updates(table).Error
 }
 
-func (repo *TableRepositoryImpl) GetTable(tableID string) (*models.Table, error) {
+func (repo *TableRepositoryImpl) GetTable(restaurantID string, tableID string) (*models.Table, error) {
 	var table models.Table
-	err := repo.db.First(&table, "table_id = ?", tableID).Error
+	err := repo.db.First(&table, "table_id = ? AND restaurant_id = ?", tableID, restaurantID).Error
 	if err != nil {
 		return nil, err
 	}
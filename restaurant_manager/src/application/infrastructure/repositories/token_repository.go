@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"restaurant_manager/src/domain/models"
+	"restaurant_manager/src/domain/repositories"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TokenRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewTokenRepository(db *gorm.DB) repositories.TokenRepository {
+	return &TokenRepositoryImpl{db: db}
+}
+
+func (repo *TokenRepositoryImpl) CreateRefreshToken(token *models.RefreshToken) (string, error) {
+	result := repo.db.Clauses(clause.Returning{}).Omit("token_id").Create(token)
+	if result.Error != nil {
+		return "", result.Error
+	}
+	return token.TokenID, nil
+}
+
+func (repo *TokenRepositoryImpl) GetRefreshTokenByHash(tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := repo.db.Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (repo *TokenRepositoryImpl) RevokeRefreshToken(tokenID string, replacedBy *string) error {
+	return repo.db.Model(&models.RefreshToken{}).
+		Where("token_id = ? AND revoked_at IS NULL", tokenID).
+		Updates(map[string]interface{}{
+			"revoked_at":  time.Now().UTC(),
+			"replaced_by": replacedBy,
+		}).Error
+}
+
+func (repo *TokenRepositoryImpl) RevokeUserRefreshTokens(userID string) error {
+	return repo.db.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now().UTC()).Error
+}
+
+func (repo *TokenRepositoryImpl) GetRefreshTokensCreatedAfter(userID string, createdAfter time.Time) ([]models.RefreshToken, error) {
+	var tokens []models.RefreshToken
+	err := repo.db.Where("user_id = ? AND created_at > ?", userID, createdAfter).Find(&tokens).Error
+	return tokens, err
+}
+
+func (repo *TokenRepositoryImpl) CreatePinSession(session *models.PinSession) error {
+	return repo.db.Omit("created_at").Create(session).Error
+}
+
+// GetActivePinSessions returns the user's PIN sessions still valid at the
+// given time.
+func (repo *TokenRepositoryImpl) GetActivePinSessions(userID string, at time.Time) ([]models.PinSession, error) {
+	var sessions []models.PinSession
+	err := repo.db.Where("user_id = ? AND expires_at > ?", userID, at).Find(&sessions).Error
+	return sessions, err
+}
+
+func (repo *TokenRepositoryImpl) AddRevokedToken(token *models.RevokedToken) error {
+	return repo.db.Clauses(clause.OnConflict{DoNothing: true}).Create(token).Error
+}
+
+func (repo *TokenRepositoryImpl) GetRevokedTokens(after time.Time) ([]models.RevokedToken, error) {
+	var tokens []models.RevokedToken
+	err := repo.db.Where("expires_at > ?", after).Find(&tokens).Error
+	return tokens, err
+}
+
+// FindRevokedToken returns the revocation of the access token, or nil when it
+// was not revoked.
+func (repo *TokenRepositoryImpl) FindRevokedToken(tokenID string) (*models.RevokedToken, error) {
+	var tokens []models.RevokedToken
+	if err := repo.db.Where("token_id = ?", tokenID).Limit(1).Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	return &tokens[0], nil
+}
+
+func (repo *TokenRepositoryImpl) CreateUserToken(token *models.UserToken) (string, error) {
+	result := repo.db.Clauses(clause.Returning{}).Omit("token_id").Create(token)
+	if result.Error != nil {
+		return "", result.Error
+	}
+	return token.TokenID, nil
+}
+
+func (repo *TokenRepositoryImpl) GetUserTokenByHash(tokenHash string, purpose string) (*models.UserToken, error) {
+	var token models.UserToken
+	err := repo.db.Where("token_hash = ? AND purpose = ?", tokenHash, purpose).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// UseUserToken marks the token as used and reports whether it was still unused,
+// so a token can only be redeemed once even under concurrent requests.
+func (repo *TokenRepositoryImpl) UseUserToken(tokenID string) (bool, error) {
+	result := repo.db.Model(&models.UserToken{}).
+		Where("token_id = ? AND used_at IS NULL", tokenID).
+		Update("used_at", time.Now().UTC())
+	return result.RowsAffected == 1, result.Error
+}
+
+func (repo *TokenRepositoryImpl) InvalidateUserTokens(userID string, purpose string) error {
+	return repo.db.Model(&models.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", time.Now().UTC()).Error
+}
+
+func (repo *TokenRepositoryImpl) WithTransaction(fn func(txRepo repositories.TokenRepository) error) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&TokenRepositoryImpl{db: tx})
+	})
+}
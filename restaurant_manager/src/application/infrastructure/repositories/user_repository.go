@@ -5,8 +5,8 @@ import (
 	"fmt"
 	"restaurant_manager/src/domain/models"
 	"restaurant_manager/src/domain/repositories"
+	"time"
 
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
@@ -19,15 +19,8 @@ func NewUserRepository(db *gorm.DB) repositories.UserRepository {
 	return &UserRepositoryImpl{db: db}
 }
 
-// CreateUser stores a new user with a hashed password
+// CreateUser stores a new user; the password must already be hashed
 func (repo *UserRepositoryImpl) CreateUser(user *models.User) (string, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.PasswordHash), bcrypt.DefaultCost)
-	if err != nil {
-		return "", fmt.Errorf("failed to hash password: %w", err)
-	}
-
-	user.PasswordHash = string(hashedPassword)
-	user.Role = "admin"
 	result := repo.db.Clauses(clause.Returning{}).Omit("user_id").Create(user)
 	if result.Error != nil {
 		return "", result.Error
@@ -80,3 +73,50 @@ func (repo *UserRepositoryImpl) GetUserById(userID string) (*models.User, error)
 	}
 	return &user, nil
 }
+
+func (repo *UserRepositoryImpl) UpdatePassword(userID string, passwordHash string) error {
+	result := repo.db.Model(&models.User{}).
+		Where("user_id = ?", userID).
+		Update("password_hash", passwordHash)
+	return result.Error
+}
+
+func (repo *UserRepositoryImpl) MarkEmailVerified(userID string) error {
+	result := repo.db.Model(&models.User{}).
+		Where("user_id = ? AND email_verified_at IS NULL", userID).
+		Update("email_verified_at", time.Now().UTC())
+	return result.Error
+}
+
+func (repo *UserRepositoryImpl) UpdatePin(userID string, pinHash string) error {
+	result := repo.db.Model(&models.User{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]interface{}{
+			"pin_hash":            pinHash,
+			"pin_failed_attempts": 0,
+			"pin_locked_until":    nil,
+		})
+	return result.Error
+}
+
+// RecordPinFailure counts a failed PIN attempt and locks the PIN until
+// lockedUntil once maxAttempts consecutive failures are reached.
+func (repo *UserRepositoryImpl) RecordPinFailure(userID string, maxAttempts int, lockedUntil time.Time) error {
+	result := repo.db.Model(&models.User{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]interface{}{
+			"pin_failed_attempts": gorm.Expr("pin_failed_attempts + 1"),
+			"pin_locked_until":    gorm.Expr("CASE WHEN pin_failed_attempts + 1 >= ? THEN ? ELSE pin_locked_until END", maxAttempts, lockedUntil),
+		})
+	return result.Error
+}
+
+func (repo *UserRepositoryImpl) ResetPinFailures(userID string) error {
+	result := repo.db.Model(&models.User{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]interface{}{
+			"pin_failed_attempts": 0,
+			"pin_locked_until":    nil,
+		})
+	return result.Error
+}
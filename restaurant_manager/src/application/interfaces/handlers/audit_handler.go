@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"restaurant_manager/src/application/interfaces/handlers/dto"
+	"restaurant_manager/src/application/services"
+	"restaurant_manager/src/domain/models"
+	"strconv"
+	"time"
+)
+
+type AuditHandler struct {
+	service *services.AuditService
+}
+
+func NewAuditHandler(service *services.AuditService) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+// GetAuditEvents handles GET /restaurants/{restaurant_id}/audit
+func (h *AuditHandler) GetAuditEvents(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	filter := models.AuditFilter{
+		RestaurantID: restaurantID,
+		EntityType:   query.Get("entity_type"),
+		EntityID:     query.Get("entity_id"),
+		ActorID:      query.Get("actor_id"),
+		Action:       query.Get("action"),
+	}
+
+	if value := query.Get("start_date"); value != "" {
+		startDate, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			http.Error(w, "Invalid start_date format", http.StatusBadRequest)
+			return
+		}
+		filter.StartDate = &startDate
+	}
+	if value := query.Get("end_date"); value != "" {
+		endDate, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			http.Error(w, "Invalid end_date format", http.StatusBadRequest)
+			return
+		}
+		// end_date is inclusive
+		endDate = endDate.AddDate(0, 0, 1)
+		filter.EndDate = &endDate
+	}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = limit
+	}
+	if value := query.Get("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Invalid offset", http.StatusBadRequest)
+			return
+		}
+		filter.Offset = offset
+	}
+
+	events, err := h.service.GetAuditEvents(filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.FromAuditEvents(events))
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"restaurant_manager/src/application/interfaces/handlers/dto"
 	"restaurant_manager/src/application/services"
@@ -23,12 +24,6 @@ func NewCashClosingHandler(service *services.CashClosingService) *CashClosingHan
 
 // CreateCashClosing handles POST /cash-closings
 func (h *CashClosingHandler) CreateCashClosing(w http.ResponseWriter, r *http.Request) {
-	owner := utils.TokenVerification(r, w)
-	if owner == "" {
-		http.Error(w, "Invalid token", http.StatusUnauthorized)
-		return
-	}
-
 	var request dto.CashClosingRequest
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
@@ -63,6 +58,9 @@ func (h *CashClosingHandler) CreateCashClosing(w http.ResponseWriter, r *http.Re
 		TotalProfit:       request.TotalProfit,
 		OrderCount:        request.OrderCount,
 		AverageOrderValue: request.AverageOrderValue,
+		TotalTips:         request.TotalTips,
+		TotalDiscounts:    request.TotalDiscounts,
+		TotalTaxes:        request.TotalTaxes,
 	}
 
 	if err := h.service.CreateCashClosing(cashClosing); err != nil {
@@ -84,6 +82,9 @@ func (h *CashClosingHandler) CreateCashClosing(w http.ResponseWriter, r *http.Re
 		TotalProfit:       cashClosing.TotalProfit,
 		OrderCount:        cashClosing.OrderCount,
 		AverageOrderValue: cashClosing.AverageOrderValue,
+		TotalTips:         cashClosing.TotalTips,
+		TotalDiscounts:    cashClosing.TotalDiscounts,
+		TotalTaxes:        cashClosing.TotalTaxes,
 		CreatedAt:         cashClosing.CreatedAt,
 		UpdatedAt:         cashClosing.UpdatedAt,
 	}
@@ -95,8 +96,6 @@ func (h *CashClosingHandler) CreateCashClosing(w http.ResponseWriter, r *http.Re
 
 // GetCashClosingData handles GET /cash-closings/data
 func (h *CashClosingHandler) GetCashClosingData(w http.ResponseWriter, r *http.Request) {
-	_ = utils.TokenVerification(r, w)
-
 	restaurantID := r.URL.Query().Get("restaurant_id")
 	if restaurantID == "" {
 		http.Error(w, "restaurant_id is required", http.StatusBadRequest)
@@ -131,11 +130,23 @@ func (h *CashClosingHandler) GetCashClosingData(w http.ResponseWriter, r *http.R
 		TotalProfit:       cashClosing.TotalProfit,
 		OrderCount:        cashClosing.OrderCount,
 		AverageOrderValue: cashClosing.AverageOrderValue,
+		TotalTips:         cashClosing.TotalTips,
+		TotalDiscounts:    cashClosing.TotalDiscounts,
+		TotalTaxes:        cashClosing.TotalTaxes,
 		CashInRegister:    0,                      // Will be filled by form
 		CashWithdrawn:     0,                      // Will be filled by form
 		Notes:             "",                     // Will be filled by form
 		TopSellingItems:   []dto.TopSellingItem{}, // TODO: Calculate from orders
-		PaymentMethods:    []dto.PaymentMethod{},  // TODO: Calculate from payments
+		PaymentMethods:    []dto.PaymentMethod{},
+		Taxes:             dto.FromTaxLines(cashClosing.Taxes),
+	}
+	for _, total := range cashClosing.PaymentMethods {
+		response.PaymentMethods = append(response.PaymentMethods, dto.PaymentMethod{
+			Method: string(total.Method),
+			Amount: total.Amount,
+			Tips:   total.Tips,
+			Count:  total.Count,
+		})
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -144,8 +155,6 @@ func (h *CashClosingHandler) GetCashClosingData(w http.ResponseWriter, r *http.R
 
 // GetCashClosingHistory handles GET /cash-closings/history
 func (h *CashClosingHandler) GetCashClosingHistory(w http.ResponseWriter, r *http.Request) {
-	_ = utils.TokenVerification(r, w)
-
 	restaurantID := r.URL.Query().Get("restaurant_id")
 	if restaurantID == "" {
 		http.Error(w, "restaurant_id is required", http.StatusBadRequest)
@@ -200,6 +209,9 @@ func (h *CashClosingHandler) GetCashClosingHistory(w http.ResponseWriter, r *htt
 			TotalProfit:       cc.TotalProfit,
 			OrderCount:        cc.OrderCount,
 			AverageOrderValue: cc.AverageOrderValue,
+			TotalTips:         cc.TotalTips,
+			TotalDiscounts:    cc.TotalDiscounts,
+			TotalTaxes:        cc.TotalTaxes,
 			CreatedAt:         cc.CreatedAt,
 			UpdatedAt:         cc.UpdatedAt,
 		}
@@ -212,12 +224,6 @@ func (h *CashClosingHandler) GetCashClosingHistory(w http.ResponseWriter, r *htt
 
 // UpdateCashClosing handles PUT /cash-closings/{id}
 func (h *CashClosingHandler) UpdateCashClosing(w http.ResponseWriter, r *http.Request) {
-	owner := utils.TokenVerification(r, w)
-	if owner == "" {
-		http.Error(w, "Invalid token", http.StatusUnauthorized)
-		return
-	}
-
 	vars := mux.Vars(r)
 	cashClosingID := vars["id"]
 	if cashClosingID == "" {
@@ -260,8 +266,11 @@ func (h *CashClosingHandler) UpdateCashClosing(w http.ResponseWriter, r *http.Re
 	existingCashClosing.TotalProfit = request.TotalProfit
 	existingCashClosing.OrderCount = request.OrderCount
 	existingCashClosing.AverageOrderValue = request.AverageOrderValue
+	existingCashClosing.TotalTips = request.TotalTips
+	existingCashClosing.TotalDiscounts = request.TotalDiscounts
+	existingCashClosing.TotalTaxes = request.TotalTaxes
 
-	if err := h.service.UpdateCashClosing(existingCashClosing); err != nil {
+	if err := h.service.UpdateCashClosing(utils.GetAuthContext(r).UserID, existingCashClosing); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -271,12 +280,6 @@ func (h *CashClosingHandler) UpdateCashClosing(w http.ResponseWriter, r *http.Re
 
 // DeleteCashClosing handles DELETE /cash-closings/{id}
 func (h *CashClosingHandler) DeleteCashClosing(w http.ResponseWriter, r *http.Request) {
-	owner := utils.TokenVerification(r, w)
-	if owner == "" {
-		http.Error(w, "Invalid token", http.StatusUnauthorized)
-		return
-	}
-
 	vars := mux.Vars(r)
 	cashClosingID := vars["id"]
 	if cashClosingID == "" {
@@ -284,7 +287,17 @@ func (h *CashClosingHandler) DeleteCashClosing(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	if err := h.service.DeleteCashClosing(cashClosingID); err != nil {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.service.DeleteCashClosing(utils.GetAuthContext(r).UserID, restaurantID, cashClosingID)
+	if errors.Is(err, services.ErrCashClosingNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -294,8 +307,6 @@ func (h *CashClosingHandler) DeleteCashClosing(w http.ResponseWriter, r *http.Re
 
 // GetCashClosingStats handles GET /cash-closings/stats
 func (h *CashClosingHandler) GetCashClosingStats(w http.ResponseWriter, r *http.Request) {
-	_ = utils.TokenVerification(r, w)
-
 	restaurantID := r.URL.Query().Get("restaurant_id")
 	if restaurantID == "" {
 		http.Error(w, "restaurant_id is required", http.StatusBadRequest)
@@ -341,6 +352,10 @@ func (h *CashClosingHandler) GetCashClosingStats(w http.ResponseWriter, r *http.
 		TotalProfit:       stats.TotalProfit,
 		OrderCount:        stats.OrderCount,
 		AverageOrderValue: stats.AverageOrderValue,
+		TotalTips:         stats.TotalTips,
+		TotalDiscounts:    stats.TotalDiscounts,
+		TotalTaxes:        stats.TotalTaxes,
+		Taxes:             dto.FromTaxLines(stats.Taxes),
 		CreatedAt:         stats.CreatedAt,
 		UpdatedAt:         stats.UpdatedAt,
 	}
@@ -0,0 +1,43 @@
+package dto
+
+import (
+	"encoding/json"
+	"restaurant_manager/src/domain/models"
+	"time"
+)
+
+type AuditEventResponse struct {
+	EventID    string          `json:"event_id"`
+	ActorID    *string         `json:"actor_id"`
+	ActorName  string          `json:"actor_name,omitempty"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	Action     string          `json:"action"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+func FromAuditEvents(events []models.AuditEvent) []AuditEventResponse {
+	responses := make([]AuditEventResponse, len(events))
+	for i, event := range events {
+		responses[i] = AuditEventResponse{
+			EventID:    event.EventID,
+			ActorID:    event.ActorID,
+			EntityType: event.EntityType,
+			EntityID:   event.EntityID,
+			Action:     event.Action,
+			CreatedAt:  event.CreatedAt,
+		}
+		if event.Actor != nil {
+			responses[i].ActorName = event.Actor.Name
+		}
+		if event.Before != nil {
+			responses[i].Before = json.RawMessage(*event.Before)
+		}
+		if event.After != nil {
+			responses[i].After = json.RawMessage(*event.After)
+		}
+	}
+	return responses
+}
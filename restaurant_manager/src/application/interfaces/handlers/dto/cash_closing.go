@@ -13,23 +13,30 @@ type CashClosingRequest struct {
 	TotalProfit       float64 `json:"total_profit"`
 	OrderCount        int     `json:"order_count"`
 	AverageOrderValue float64 `json:"average_order_value"`
+	TotalTips         float64 `json:"total_tips"`
+	TotalDiscounts    float64 `json:"total_discounts"`
+	TotalTaxes        float64 `json:"total_taxes"`
 }
 
 type CashClosingResponse struct {
-	CashClosingID     string    `json:"cash_closing_id"`
-	RestaurantID      string    `json:"restaurant_id"`
-	ClosingDate       string    `json:"closing_date"`
-	CashInRegister    float64   `json:"cash_in_register"`
-	CashWithdrawn     float64   `json:"cash_withdrawn"`
-	Notes             string    `json:"notes"`
-	TotalSales        float64   `json:"total_sales"`
-	TotalRevenue      float64   `json:"total_revenue"`
-	TotalCosts        float64   `json:"total_costs"`
-	TotalProfit       float64   `json:"total_profit"`
-	OrderCount        int       `json:"order_count"`
-	AverageOrderValue float64   `json:"average_order_value"`
-	CreatedAt         time.Time `json:"created_at"`
-	UpdatedAt         time.Time `json:"updated_at"`
+	CashClosingID     string       `json:"cash_closing_id"`
+	RestaurantID      string       `json:"restaurant_id"`
+	ClosingDate       string       `json:"closing_date"`
+	CashInRegister    float64      `json:"cash_in_register"`
+	CashWithdrawn     float64      `json:"cash_withdrawn"`
+	Notes             string       `json:"notes"`
+	TotalSales        float64      `json:"total_sales"`
+	TotalRevenue      float64      `json:"total_revenue"`
+	TotalCosts        float64      `json:"total_costs"`
+	TotalProfit       float64      `json:"total_profit"`
+	OrderCount        int          `json:"order_count"`
+	AverageOrderValue float64      `json:"average_order_value"`
+	TotalTips         float64      `json:"total_tips"`
+	TotalDiscounts    float64      `json:"total_discounts"`
+	TotalTaxes        float64      `json:"total_taxes"`
+	Taxes             []TaxLineDTO `json:"taxes,omitempty"`
+	CreatedAt         time.Time    `json:"created_at"`
+	UpdatedAt         time.Time    `json:"updated_at"`
 }
 
 type CashClosingData struct {
@@ -40,8 +47,12 @@ type CashClosingData struct {
 	TotalProfit       float64          `json:"total_profit"`
 	OrderCount        int              `json:"order_count"`
 	AverageOrderValue float64          `json:"average_order_value"`
+	TotalTips         float64          `json:"total_tips"`
+	TotalDiscounts    float64          `json:"total_discounts"`
+	TotalTaxes        float64          `json:"total_taxes"`
 	TopSellingItems   []TopSellingItem `json:"top_selling_items"`
 	PaymentMethods    []PaymentMethod  `json:"payment_methods"`
+	Taxes             []TaxLineDTO     `json:"taxes"`
 	CashInRegister    float64          `json:"cash_in_register"`
 	CashWithdrawn     float64          `json:"cash_withdrawn"`
 	Notes             string           `json:"notes"`
@@ -56,5 +67,6 @@ type TopSellingItem struct {
 type PaymentMethod struct {
 	Method string  `json:"method"`
 	Amount float64 `json:"amount"`
+	Tips   float64 `json:"tips"`
 	Count  int     `json:"count"`
 }
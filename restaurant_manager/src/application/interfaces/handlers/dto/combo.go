@@ -0,0 +1,75 @@
+package dto
+
+import "restaurant_manager/src/domain/models"
+
+// ComboSlotDTO is one part of a combo: a fixed menu_item_id or a choice among
+// the menu items of a category.
+type ComboSlotDTO struct {
+	ID         string  `json:"slot_id,omitempty"`
+	Name       string  `json:"name"`
+	Quantity   int     `json:"quantity"`
+	MenuItemID *string `json:"menu_item_id,omitempty"`
+	MenuItem   string  `json:"menu_item,omitempty"`
+	Category   *string `json:"category,omitempty"`
+	Position   int     `json:"position"`
+}
+
+// OrderItemComponentDTO is a menu item a combo is made of. Requests list the
+// picks for the choice slots; responses list every component.
+type OrderItemComponentDTO struct {
+	SlotID     string `json:"slot_id"`
+	MenuItemID string `json:"menu_item_id"`
+	Name       string `json:"name,omitempty"`
+	Quantity   int    `json:"quantity"`
+}
+
+func (slot ComboSlotDTO) ToModel() models.ComboSlot {
+	model := models.ComboSlot{
+		Name:       slot.Name,
+		Quantity:   slot.Quantity,
+		MenuItemID: slot.MenuItemID,
+		Position:   slot.Position,
+	}
+	if slot.Category != nil {
+		category := models.Category(*slot.Category)
+		model.Category = &category
+	}
+	return model
+}
+
+func FromComboSlots(slots []models.ComboSlot) []ComboSlotDTO {
+	dtos := make([]ComboSlotDTO, len(slots))
+	for i, slot := range slots {
+		dtos[i] = ComboSlotDTO{
+			ID:         slot.SlotID,
+			Name:       slot.Name,
+			Quantity:   slot.Quantity,
+			MenuItemID: slot.MenuItemID,
+			Position:   slot.Position,
+		}
+		if slot.MenuItem != nil {
+			dtos[i].MenuItem = slot.MenuItem.Name
+		}
+		if slot.Category != nil {
+			category := string(*slot.Category)
+			dtos[i].Category = &category
+		}
+	}
+	return dtos
+}
+
+func fromOrderItemComponents(components []models.OrderItemComponent) []OrderItemComponentDTO {
+	if len(components) == 0 {
+		return nil
+	}
+	dtos := make([]OrderItemComponentDTO, len(components))
+	for i, component := range components {
+		dtos[i] = OrderItemComponentDTO{
+			SlotID:     safeString(component.SlotID),
+			MenuItemID: component.MenuItemID,
+			Name:       component.MenuItem.Name,
+			Quantity:   component.Quantity,
+		}
+	}
+	return dtos
+}
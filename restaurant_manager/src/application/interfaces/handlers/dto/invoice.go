@@ -0,0 +1,110 @@
+package dto
+
+import (
+	"fmt"
+	"restaurant_manager/src/domain/models"
+	"time"
+)
+
+// InvoiceRequest names the user an order is invoiced to. Customers are always
+// invoiced themselves.
+type InvoiceRequest struct {
+	BuyerID string `json:"buyer_id"`
+}
+
+type InvoiceDTO struct {
+	InvoiceID     string     `json:"invoice_id"`
+	OrderID       string     `json:"order_id"`
+	Number        string     `json:"number"`
+	Cufe          string     `json:"cufe"`
+	BuyerName     string     `json:"buyer_name"`
+	BuyerNit      string     `json:"buyer_nit"`
+	Subtotal      float64    `json:"subtotal"`
+	TaxTotal      float64    `json:"tax_total"`
+	Total         float64    `json:"total"`
+	Status        string     `json:"status"`
+	TrackingID    string     `json:"tracking_id,omitempty"`
+	StatusMessage string     `json:"status_message,omitempty"`
+	IssuedAt      time.Time  `json:"issued_at"`
+	TransmittedAt *time.Time `json:"transmitted_at,omitempty"`
+}
+
+// InvoiceResolutionDTO is a numbering resolution. Its dates are days written
+// as 2006-01-02; the technical key is accepted but never shown.
+type InvoiceResolutionDTO struct {
+	ResolutionID     string `json:"resolution_id,omitempty"`
+	ResolutionNumber string `json:"resolution_number"`
+	Prefix           string `json:"prefix"`
+	RangeFrom        int64  `json:"range_from"`
+	RangeTo          int64  `json:"range_to"`
+	NextNumber       int64  `json:"next_number,omitempty"`
+	ValidFrom        string `json:"valid_from"`
+	ValidTo          string `json:"valid_to"`
+	TechnicalKey     string `json:"technical_key,omitempty"`
+	Environment      string `json:"environment"`
+}
+
+func FromInvoice(invoice models.Invoice) InvoiceDTO {
+	return InvoiceDTO{
+		InvoiceID:     invoice.InvoiceID,
+		OrderID:       invoice.OrderID,
+		Number:        invoice.Number,
+		Cufe:          invoice.Cufe,
+		BuyerName:     invoice.BuyerName,
+		BuyerNit:      invoice.BuyerNit,
+		Subtotal:      invoice.Subtotal,
+		TaxTotal:      invoice.TaxTotal,
+		Total:         invoice.Total,
+		Status:        string(invoice.Status),
+		TrackingID:    safeString(invoice.TrackingID),
+		StatusMessage: safeString(invoice.StatusMessage),
+		IssuedAt:      invoice.IssuedAt,
+		TransmittedAt: invoice.TransmittedAt,
+	}
+}
+
+// ToModel builds the resolution for a restaurant; resolutions are for testing
+// unless stated.
+func (resolution InvoiceResolutionDTO) ToModel(restaurantID string) (models.InvoiceResolution, error) {
+	validFrom, err := time.Parse(time.DateOnly, resolution.ValidFrom)
+	if err != nil {
+		return models.InvoiceResolution{}, fmt.Errorf("valid_from must look like 2006-01-02")
+	}
+	validTo, err := time.Parse(time.DateOnly, resolution.ValidTo)
+	if err != nil {
+		return models.InvoiceResolution{}, fmt.Errorf("valid_to must look like 2006-01-02")
+	}
+	model := models.InvoiceResolution{
+		RestaurantID:     restaurantID,
+		ResolutionNumber: resolution.ResolutionNumber,
+		Prefix:           resolution.Prefix,
+		RangeFrom:        resolution.RangeFrom,
+		RangeTo:          resolution.RangeTo,
+		ValidFrom:        validFrom,
+		ValidTo:          validTo,
+		TechnicalKey:     resolution.TechnicalKey,
+		Environment:      models.InvoiceEnvironment(resolution.Environment),
+	}
+	if model.Environment == "" {
+		model.Environment = models.InvoiceTesting
+	}
+	return model, nil
+}
+
+func FromInvoiceResolutions(resolutions []models.InvoiceResolution) []InvoiceResolutionDTO {
+	dtos := make([]InvoiceResolutionDTO, len(resolutions))
+	for i, resolution := range resolutions {
+		dtos[i] = InvoiceResolutionDTO{
+			ResolutionID:     resolution.ResolutionID,
+			ResolutionNumber: resolution.ResolutionNumber,
+			Prefix:           resolution.Prefix,
+			RangeFrom:        resolution.RangeFrom,
+			RangeTo:          resolution.RangeTo,
+			NextNumber:       resolution.NextNumber,
+			ValidFrom:        resolution.ValidFrom.Format(time.DateOnly),
+			ValidTo:          resolution.ValidTo.Format(time.DateOnly),
+			Environment:      string(resolution.Environment),
+		}
+	}
+	return dtos
+}
@@ -10,8 +10,13 @@ type MenuItemResponse struct {
 	Available   bool                `json:"available"`
 	ImageURL    string              `json:"image_url"`
 	Category    string              `json:"category"`
+	StationID   *string             `json:"station_id"`
+	TaxCategory string              `json:"tax_category,omitempty"`
 	SideDishes  int                 `json:"side_dishes"`
 	Ingredients []IngredientSummary `json:"ingredients"`
+
+	ModifierGroups []ModifierGroupDTO `json:"modifier_groups"`
+	ComboSlots     []ComboSlotDTO     `json:"combo_slots,omitempty"`
 }
 
 type IngredientSummary struct {
@@ -44,7 +49,12 @@ func FromMenuItem(menu models.MenuItem) MenuItemResponse {
 		ImageURL:    menu.ImageURL,
 		SideDishes:  menu.SideDishes,
 		Category:    string(menu.Category),
+		StationID:   menu.StationID,
+		TaxCategory: safeTaxCategory(menu.TaxCategory),
 		Ingredients: fromIngredients(menu.Ingredients),
+
+		ModifierGroups: FromModifierGroups(menu.ModifierGroups),
+		ComboSlots:     FromComboSlots(menu.ComboSlots),
 	}
 }
 
@@ -66,3 +76,10 @@ func fromIngredients(ingredients []models.Ingredient) []IngredientSummary {
 	}
 	return result
 }
+
+func safeTaxCategory(category *models.TaxCategory) string {
+	if category == nil {
+		return ""
+	}
+	return string(*category)
+}
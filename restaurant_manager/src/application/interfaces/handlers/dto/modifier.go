@@ -0,0 +1,122 @@
+package dto
+
+import "restaurant_manager/src/domain/models"
+
+type ModifierGroupDTO struct {
+	ID            string        `json:"modifier_group_id,omitempty"`
+	Name          string        `json:"name"`
+	Required      bool          `json:"required"`
+	MinSelections int           `json:"min_selections"`
+	MaxSelections int           `json:"max_selections"`
+	Position      int           `json:"position"`
+	Modifiers     []ModifierDTO `json:"modifiers"`
+}
+
+type ModifierDTO struct {
+	ID          string                  `json:"modifier_id,omitempty"`
+	Name        string                  `json:"name"`
+	PriceDelta  float64                 `json:"price_delta"`
+	Position    int                     `json:"position"`
+	Ingredients []ModifierIngredientDTO `json:"ingredients,omitempty"`
+}
+
+// ModifierIngredientDTO is a recipe delta; negative amounts remove the ingredient.
+type ModifierIngredientDTO struct {
+	RawIngredientID string  `json:"raw_ingredient_id"`
+	Amount          float64 `json:"amount"`
+	Unit            string  `json:"unit"`
+}
+
+// OrderItemModifierDTO is a modifier chosen for an order item. Requests only
+// need the modifier_id; name and price_delta are filled in by the server.
+type OrderItemModifierDTO struct {
+	ModifierID string  `json:"modifier_id"`
+	Name       string  `json:"name,omitempty"`
+	PriceDelta float64 `json:"price_delta,omitempty"`
+}
+
+func (group ModifierGroupDTO) ToModel(menuItemID string) *models.ModifierGroup {
+	modifiers := make([]models.Modifier, len(group.Modifiers))
+	for i, modifier := range group.Modifiers {
+		ingredients := make([]models.ModifierIngredient, len(modifier.Ingredients))
+		for j, ingredient := range modifier.Ingredients {
+			ingredients[j] = models.ModifierIngredient{
+				RawIngredientID: ingredient.RawIngredientID,
+				Amount:          ingredient.Amount,
+				Unit:            ingredient.Unit,
+			}
+		}
+		modifiers[i] = models.Modifier{
+			Name:        modifier.Name,
+			PriceDelta:  modifier.PriceDelta,
+			Position:    modifier.Position,
+			Ingredients: ingredients,
+		}
+	}
+	return &models.ModifierGroup{
+		MenuItemID:    menuItemID,
+		Name:          group.Name,
+		Required:      group.Required,
+		MinSelections: group.MinSelections,
+		MaxSelections: group.MaxSelections,
+		Position:      group.Position,
+		Modifiers:     modifiers,
+	}
+}
+
+func FromModifierGroups(groups []models.ModifierGroup) []ModifierGroupDTO {
+	dtos := make([]ModifierGroupDTO, len(groups))
+	for i, group := range groups {
+		modifiers := make([]ModifierDTO, len(group.Modifiers))
+		for j, modifier := range group.Modifiers {
+			ingredients := make([]ModifierIngredientDTO, len(modifier.Ingredients))
+			for k, ingredient := range modifier.Ingredients {
+				ingredients[k] = ModifierIngredientDTO{
+					RawIngredientID: ingredient.RawIngredientID,
+					Amount:          ingredient.Amount,
+					Unit:            ingredient.Unit,
+				}
+			}
+			modifiers[j] = ModifierDTO{
+				ID:          modifier.ModifierID,
+				Name:        modifier.Name,
+				PriceDelta:  modifier.PriceDelta,
+				Position:    modifier.Position,
+				Ingredients: ingredients,
+			}
+		}
+		dtos[i] = ModifierGroupDTO{
+			ID:            group.ModifierGroupID,
+			Name:          group.Name,
+			Required:      group.Required,
+			MinSelections: group.MinSelections,
+			MaxSelections: group.MaxSelections,
+			Position:      group.Position,
+			Modifiers:     modifiers,
+		}
+	}
+	return dtos
+}
+
+func fromOrderItemModifiers(modifiers []models.OrderItemModifier) []OrderItemModifierDTO {
+	if len(modifiers) == 0 {
+		return nil
+	}
+	dtos := make([]OrderItemModifierDTO, len(modifiers))
+	for i, modifier := range modifiers {
+		dtos[i] = OrderItemModifierDTO{
+			ModifierID: safeString(modifier.ModifierID),
+			Name:       modifier.Name,
+			PriceDelta: modifier.PriceDelta,
+		}
+	}
+	return dtos
+}
+
+func modifierNames(modifiers []models.OrderItemModifier) []string {
+	names := make([]string, len(modifiers))
+	for i, modifier := range modifiers {
+		names[i] = modifier.Name
+	}
+	return names
+}
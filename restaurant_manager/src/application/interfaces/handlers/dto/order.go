@@ -1,40 +1,160 @@
 package dto
 
 import (
+	"math"
 	"restaurant_manager/src/domain/models"
 	"time"
 )
 
 type OrderDTO struct {
-	OrderID       string         `json:"order_id"`
-	TableID       string         `json:"table_id"`
-	Table         int            `json:"table"`
-	RestaurantID  string         `json:"restaurant_id"`
-	Items         []OrderItemDTO `json:"items"`
-	Status        string         `json:"status"`
-	TotalPrice    float64        `json:"total_price"`
-	TimeToPrepare float64        `json:"time_to_prepare"`
-	TimeToDeliver float64        `json:"time_to_deliver"`
-	TimeToPay     float64        `json:"time_to_pay"`
-	CreatedAt     time.Time      `json:"created_at"`
+	OrderID       string             `json:"order_id"`
+	TableID       string             `json:"table_id"`
+	Table         int                `json:"table"`
+	RestaurantID  string             `json:"restaurant_id"`
+	Items         []OrderItemDTO     `json:"items"`
+	Status        string             `json:"status"`
+	TotalPrice    float64            `json:"total_price"`
+	Breakdown     *PriceBreakdownDTO `json:"breakdown,omitempty"`
+	Discounts     []OrderDiscountDTO `json:"discounts,omitempty"`
+	TimeToPrepare float64            `json:"time_to_prepare"`
+	TimeToDeliver float64            `json:"time_to_deliver"`
+	TimeToPay     float64            `json:"time_to_pay"`
+	WaiterID      string             `json:"waiter_id,omitempty"`
+	CreatedAt     time.Time          `json:"created_at"`
+	PreparedAt    *time.Time         `json:"prepared_at,omitempty"`
+	DeliveredAt   *time.Time         `json:"delivered_at,omitempty"`
+	PaidAt        *time.Time         `json:"paid_at,omitempty"`
+	CancelledAt   *time.Time         `json:"cancelled_at,omitempty"`
+	MergedInto    string             `json:"merged_into,omitempty"`
+}
+
+// PriceBreakdownDTO shows how the server priced an order. The service charge
+// is only suggested and is not part of the total.
+type PriceBreakdownDTO struct {
+	Subtotal      float64      `json:"subtotal"`
+	Discounts     float64      `json:"discounts"`
+	Taxes         float64      `json:"taxes"`
+	ServiceCharge float64      `json:"service_charge"`
+	Total         float64      `json:"total"`
+	TaxLines      []TaxLineDTO `json:"tax_lines,omitempty"`
+}
+
+// TaxLineDTO is the tax charged at one rate.
+type TaxLineDTO struct {
+	Tax      string  `json:"tax"`
+	Category string  `json:"category"`
+	Rate     float64 `json:"rate"`
+	Base     float64 `json:"base"`
+	Amount   float64 `json:"amount"`
 }
 
 type OrderItemDTO struct {
-	MenuItemID      string     `json:"menu_item_id"`
-	Name            string     `json:"name"`
-	Quantity        int        `json:"quantity"`
-	Price           float64    `json:"price"`
-	Status          string     `json:"status"`
-	Observation     string     `json:"observation"`
-	Image           string     `json:"image"`
-	CreatedAt       *time.Time `json:"created_at,omitempty"`
-	VoidOrderItemID string     `json:"void_order_item_id,omitempty"`
+	OrderItemID     string                  `json:"order_item_id,omitempty"`
+	MenuItemID      string                  `json:"menu_item_id"`
+	Name            string                  `json:"name"`
+	Quantity        int                     `json:"quantity"`
+	Price           float64                 `json:"price"`
+	Status          string                  `json:"status"`
+	Observation     string                  `json:"observation"`
+	Modifiers       []OrderItemModifierDTO  `json:"modifiers,omitempty"`
+	Components      []OrderItemComponentDTO `json:"components,omitempty"`
+	Image           string                  `json:"image"`
+	Course          int                     `json:"course,omitempty"`
+	Seat            *int                    `json:"seat,omitempty"`
+	TaxCategory     string                  `json:"tax_category,omitempty"`
+	TaxRate         float64                 `json:"tax_rate,omitempty"`
+	TaxAmount       float64                 `json:"tax_amount,omitempty"`
+	Hold            bool                    `json:"hold,omitempty"`
+	CreatedAt       *time.Time              `json:"created_at,omitempty"`
+	FiredAt         *time.Time              `json:"fired_at,omitempty"`
+	PreparedAt      *time.Time              `json:"prepared_at,omitempty"`
+	DeliveredAt     *time.Time              `json:"delivered_at,omitempty"`
+	CompletedAt     *time.Time              `json:"completed_at,omitempty"`
+	CancelledAt     *time.Time              `json:"cancelled_at,omitempty"`
+	VoidOrderItemID string                  `json:"void_order_item_id,omitempty"`
+}
+
+// DurationStatsDTO summarises a stage duration in seconds.
+type DurationStatsDTO struct {
+	Avg float64 `json:"avg"`
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+}
+
+type KitchenPerformanceDTO struct {
+	Bucket  string           `json:"bucket"`
+	Orders  int              `json:"orders"`
+	Prepare DurationStatsDTO `json:"prepare"`
+	Deliver DurationStatsDTO `json:"deliver"`
+	Pay     DurationStatsDTO `json:"pay"`
 }
 
 type RecoverVoidOrderItemDTO struct {
 	TargetOrderID string `json:"target_order_id"`
 }
 
+type TransferOrderRequest struct {
+	TableID string `json:"table_id"`
+}
+
+// MergeOrdersRequest names the orders whose items move into the target order.
+type MergeOrdersRequest struct {
+	TargetOrderID string   `json:"target_order_id"`
+	OrderIDs      []string `json:"order_ids"`
+}
+
+// MoveOrderItemsRequest names the items, and how many units of each, to move
+// to the target order. Items without a quantity move whole.
+type MoveOrderItemsRequest struct {
+	TargetOrderID string              `json:"target_order_id"`
+	Items         []MovedOrderItemDTO `json:"items"`
+}
+
+type MovedOrderItemDTO struct {
+	OrderItemID string `json:"order_item_id"`
+	Quantity    int    `json:"quantity,omitempty"`
+}
+
+// Moves converts the request's items to the moves the order service takes.
+func (r MoveOrderItemsRequest) Moves() []models.OrderItemMove {
+	moves := make([]models.OrderItemMove, len(r.Items))
+	for i, item := range r.Items {
+		moves[i] = models.OrderItemMove{OrderItemID: item.OrderItemID, Quantity: item.Quantity}
+	}
+	return moves
+}
+
+// InitialStatus is the status a new item enters the order in: held items wait
+// for their course to be fired.
+func (item OrderItemDTO) InitialStatus() models.OrderStatus {
+	if item.Hold {
+		return models.Held
+	}
+	return models.Pending
+}
+
+// ChosenModifiers returns the modifiers requested for the item.
+func (item OrderItemDTO) ChosenModifiers() []models.OrderItemModifier {
+	modifiers := make([]models.OrderItemModifier, len(item.Modifiers))
+	for i := range item.Modifiers {
+		modifiers[i] = models.OrderItemModifier{ModifierID: &item.Modifiers[i].ModifierID}
+	}
+	return modifiers
+}
+
+// ChosenComponents returns the picks requested for the choice slots of a combo.
+func (item OrderItemDTO) ChosenComponents() []models.OrderItemComponent {
+	components := make([]models.OrderItemComponent, len(item.Components))
+	for i, component := range item.Components {
+		components[i] = models.OrderItemComponent{
+			SlotID:     &item.Components[i].SlotID,
+			MenuItemID: component.MenuItemID,
+			Quantity:   component.Quantity,
+		}
+	}
+	return components
+}
+
 func safeString(s *string) string {
 	if s == nil {
 		return ""
@@ -53,26 +173,73 @@ func FromOrders(orders []models.Order) []OrderDTO {
 			Status:        string(order.Status),
 			TotalPrice:    order.TotalPrice,
 			Items:         FromOrderItems(order.OrderItems),
+			Breakdown:     FromOrderTotals(order.Totals()),
+			Discounts:     FromOrderDiscounts(order.Discounts),
 			CreatedAt:     order.CreatedAt,
 			TimeToPrepare: order.TimeToPrepare,
 			TimeToDeliver: order.TimeToDeliver,
 			TimeToPay:     order.TimeToPay,
+			WaiterID:      safeString(order.WaiterID),
+			PreparedAt:    order.PreparedAt,
+			DeliveredAt:   order.DeliveredAt,
+			PaidAt:        order.PaidAt,
+			CancelledAt:   order.CancelledAt,
+			MergedInto:    safeString(order.MergedInto),
 		}
 	}
 	return orderDTOs
 }
 
+func FromOrderTotals(totals models.OrderTotals) *PriceBreakdownDTO {
+	return &PriceBreakdownDTO{
+		Subtotal:      totals.Subtotal,
+		Discounts:     totals.Discounts,
+		Taxes:         totals.Taxes,
+		ServiceCharge: totals.ServiceCharge,
+		Total:         totals.Total,
+		TaxLines:      FromTaxLines(totals.TaxLines),
+	}
+}
+
+func FromTaxLines(lines []models.TaxLine) []TaxLineDTO {
+	dtos := make([]TaxLineDTO, len(lines))
+	for i, line := range lines {
+		dtos[i] = TaxLineDTO{
+			Tax:      line.Category.Tax(),
+			Category: string(line.Category),
+			Rate:     line.Rate,
+			Base:     line.Base,
+			Amount:   line.Amount,
+		}
+	}
+	return dtos
+}
+
 func FromOrderItems(orderItems []models.OrderItem) []OrderItemDTO {
 	orderItemDTOs := make([]OrderItemDTO, len(orderItems))
 	for i, orderItem := range orderItems {
 		orderItemDTOs[i] = OrderItemDTO{
+			OrderItemID: orderItem.OrderItemID,
 			MenuItemID:  orderItem.MenuItemID,
 			Name:        orderItem.MenuItem.Name,
 			Quantity:    orderItem.Quantity,
 			Price:       orderItem.Price,
 			Status:      string(orderItem.Status),
 			Observation: safeString(orderItem.Observation),
+			Modifiers:   fromOrderItemModifiers(orderItem.Modifiers),
+			Components:  fromOrderItemComponents(orderItem.Components),
 			Image:       orderItem.MenuItem.ImageURL,
+			Course:      orderItem.Course,
+			Seat:        orderItem.Seat,
+			TaxCategory: string(orderItem.TaxCategory),
+			TaxRate:     orderItem.TaxRate,
+			TaxAmount:   orderItem.TaxAmount,
+			Hold:        orderItem.Status == models.Held,
+			FiredAt:     orderItem.FiredAt,
+			PreparedAt:  orderItem.PreparedAt,
+			DeliveredAt: orderItem.DeliveredAt,
+			CompletedAt: orderItem.CompletedAt,
+			CancelledAt: orderItem.CancelledAt,
 		}
 	}
 	return orderItemDTOs
@@ -95,3 +262,25 @@ func FromVoidOrderItems(voidOrderItems []models.VoidOrderItem) []OrderItemDTO {
 	}
 	return orderItemDTOs
 }
+
+func FromKitchenPerformance(performance []models.KitchenPerformance) []KitchenPerformanceDTO {
+	dtos := make([]KitchenPerformanceDTO, len(performance))
+	for i, bucket := range performance {
+		dtos[i] = KitchenPerformanceDTO{
+			Bucket:  bucket.Bucket,
+			Orders:  bucket.Orders,
+			Prepare: durationStats(bucket.AvgPrepare, bucket.P50Prepare, bucket.P90Prepare),
+			Deliver: durationStats(bucket.AvgDeliver, bucket.P50Deliver, bucket.P90Deliver),
+			Pay:     durationStats(bucket.AvgPay, bucket.P50Pay, bucket.P90Pay),
+		}
+	}
+	return dtos
+}
+
+func durationStats(avg, p50, p90 float64) DurationStatsDTO {
+	return DurationStatsDTO{
+		Avg: math.Round(avg*100) / 100,
+		P50: math.Round(p50*100) / 100,
+		P90: math.Round(p90*100) / 100,
+	}
+}
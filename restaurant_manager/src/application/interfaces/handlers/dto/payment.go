@@ -0,0 +1,259 @@
+package dto
+
+import (
+	"restaurant_manager/src/domain/models"
+	"sort"
+	"time"
+)
+
+// PaymentRequest takes a payment towards an order. It may cover the unpaid
+// items of a seat or the listed item units; the amount then defaults to what
+// they cost. The tip comes on top; ServiceCharge accepts the suggested service
+// charge as the tip when none is given.
+type PaymentRequest struct {
+	Amount        float64                `json:"amount"`
+	Tip           float64                `json:"tip,omitempty"`
+	ServiceCharge bool                   `json:"service_charge,omitempty"`
+	Method        string                 `json:"method"`
+	TransactionID string                 `json:"transaction_id,omitempty"`
+	Seat          *int                   `json:"seat,omitempty"`
+	Items         []PaymentAllocationDTO `json:"items,omitempty"`
+}
+
+type PaymentAllocationDTO struct {
+	OrderItemID string  `json:"order_item_id"`
+	Quantity    int     `json:"quantity"`
+	Amount      float64 `json:"amount,omitempty"`
+}
+
+type PaymentDTO struct {
+	PaymentID     string                 `json:"payment_id"`
+	OrderID       string                 `json:"order_id"`
+	Amount        float64                `json:"amount"`
+	Tip           float64                `json:"tip"`
+	Status        string                 `json:"status"`
+	Method        string                 `json:"method"`
+	TransactionID string                 `json:"transaction_id,omitempty"`
+	CreatedBy     string                 `json:"created_by,omitempty"`
+	CreatedAt     time.Time              `json:"created_at"`
+	Items         []PaymentAllocationDTO `json:"items,omitempty"`
+}
+
+// PaymentResultDTO is the payment just taken and the order's balance after it.
+type PaymentResultDTO struct {
+	Payment PaymentDTO `json:"payment"`
+	Balance BalanceDTO `json:"balance"`
+}
+
+// BalanceDTO shows what an order costs, what has been paid and what remains,
+// per item and per seat. Split holds the even shares of the outstanding amount
+// when a split was requested.
+type BalanceDTO struct {
+	OrderID       string            `json:"order_id"`
+	Total         float64           `json:"total"`
+	Paid          float64           `json:"paid"`
+	Outstanding   float64           `json:"outstanding"`
+	Settled       bool              `json:"settled"`
+	Tips          float64           `json:"tips"`
+	ServiceCharge *ServiceChargeDTO `json:"service_charge,omitempty"`
+	Items         []ItemBalanceDTO  `json:"items"`
+	Seats         []SeatBalanceDTO  `json:"seats,omitempty"`
+	Split         []float64         `json:"split,omitempty"`
+	Payments      []PaymentDTO      `json:"payments,omitempty"`
+}
+
+type ItemBalanceDTO struct {
+	OrderItemID    string  `json:"order_item_id"`
+	MenuItemID     string  `json:"menu_item_id"`
+	Name           string  `json:"name"`
+	Seat           *int    `json:"seat,omitempty"`
+	Quantity       int     `json:"quantity"`
+	PaidQuantity   int     `json:"paid_quantity"`
+	UnpaidQuantity int     `json:"unpaid_quantity"`
+	Price          float64 `json:"price"`
+	Discount       float64 `json:"discount,omitempty"`
+	Outstanding    float64 `json:"outstanding"`
+}
+
+// ServiceChargeDTO is the service charge suggested on an order's total.
+type ServiceChargeDTO struct {
+	RuleID     string  `json:"rule_id"`
+	Name       string  `json:"name"`
+	Percentage float64 `json:"percentage"`
+	Suggested  float64 `json:"suggested"`
+}
+
+type ServiceChargeRuleDTO struct {
+	RuleID            string  `json:"rule_id,omitempty"`
+	Name              string  `json:"name"`
+	Percentage        float64 `json:"percentage"`
+	MinimumOrderTotal float64 `json:"minimum_order_total"`
+	Active            *bool   `json:"active,omitempty"`
+}
+
+// TipShareDTO is the tips a waiter collected, per shift worked.
+type TipShareDTO struct {
+	UserID   string        `json:"user_id,omitempty"`
+	Name     string        `json:"name,omitempty"`
+	Payments int           `json:"payments"`
+	Tips     float64       `json:"tips"`
+	Shifts   []TipShiftDTO `json:"shifts"`
+}
+
+type TipShiftDTO struct {
+	ShiftID  string     `json:"shift_id,omitempty"`
+	ClockIn  *time.Time `json:"clock_in,omitempty"`
+	ClockOut *time.Time `json:"clock_out,omitempty"`
+	Payments int        `json:"payments"`
+	Tips     float64    `json:"tips"`
+}
+
+type SeatBalanceDTO struct {
+	Seat        int     `json:"seat"`
+	Outstanding float64 `json:"outstanding"`
+}
+
+// ToModel builds the payment the request describes for an order.
+func (request PaymentRequest) ToModel(restaurantID string, orderID string) models.Payment {
+	payment := models.Payment{
+		OrderID:       orderID,
+		RestaurantID:  restaurantID,
+		Amount:        request.Amount,
+		PaymentMethod: models.PaymentMethod(request.Method),
+	}
+	payment.Tip = request.Tip
+	if request.TransactionID != "" {
+		payment.TransactionID = &request.TransactionID
+	}
+	for _, item := range request.Items {
+		payment.Allocations = append(payment.Allocations, models.PaymentAllocation{
+			OrderItemID: item.OrderItemID,
+			Quantity:    item.Quantity,
+		})
+	}
+	return payment
+}
+
+func FromPayment(payment models.Payment) PaymentDTO {
+	paymentDTO := PaymentDTO{
+		PaymentID:     payment.PaymentID,
+		OrderID:       payment.OrderID,
+		Amount:        payment.Amount,
+		Tip:           payment.Tip,
+		Status:        string(payment.Status),
+		Method:        string(payment.PaymentMethod),
+		TransactionID: safeString(payment.TransactionID),
+		CreatedBy:     safeString(payment.CreatedBy),
+		CreatedAt:     payment.CreatedAt,
+	}
+	for _, allocation := range payment.Allocations {
+		paymentDTO.Items = append(paymentDTO.Items, PaymentAllocationDTO{
+			OrderItemID: allocation.OrderItemID,
+			Quantity:    allocation.Quantity,
+			Amount:      allocation.Amount,
+		})
+	}
+	return paymentDTO
+}
+
+func FromPayments(payments []models.Payment) []PaymentDTO {
+	paymentDTOs := make([]PaymentDTO, len(payments))
+	for i, payment := range payments {
+		paymentDTOs[i] = FromPayment(payment)
+	}
+	return paymentDTOs
+}
+
+func FromOrderBalance(orderID string, balance models.OrderBalance) BalanceDTO {
+	balanceDTO := BalanceDTO{
+		OrderID:     orderID,
+		Total:       balance.Total,
+		Paid:        balance.Paid,
+		Outstanding: balance.Outstanding,
+		Settled:     balance.Settled(),
+		Tips:        balance.Tips,
+		Items:       make([]ItemBalanceDTO, len(balance.Items)),
+	}
+	if rule := balance.ServiceCharge; rule != nil {
+		balanceDTO.ServiceCharge = &ServiceChargeDTO{
+			RuleID:     rule.RuleID,
+			Name:       rule.Name,
+			Percentage: rule.Percentage,
+			Suggested:  balance.SuggestedServiceCharge(),
+		}
+	}
+	seats := make(map[int]float64)
+	for i, item := range balance.Items {
+		balanceDTO.Items[i] = ItemBalanceDTO{
+			OrderItemID:    item.Item.OrderItemID,
+			MenuItemID:     item.Item.MenuItemID,
+			Name:           item.Item.MenuItem.Name,
+			Seat:           item.Item.Seat,
+			Quantity:       item.Item.Quantity,
+			PaidQuantity:   item.PaidQuantity,
+			UnpaidQuantity: item.UnpaidQuantity(),
+			Price:          item.Item.Price,
+			Discount:       item.Discount,
+			Outstanding:    item.Outstanding,
+		}
+		if item.Item.Seat != nil {
+			seats[*item.Item.Seat] += item.Outstanding
+		}
+	}
+	for seat, outstanding := range seats {
+		balanceDTO.Seats = append(balanceDTO.Seats, SeatBalanceDTO{Seat: seat, Outstanding: models.RoundMoney(outstanding)})
+	}
+	sort.Slice(balanceDTO.Seats, func(i, j int) bool { return balanceDTO.Seats[i].Seat < balanceDTO.Seats[j].Seat })
+	return balanceDTO
+}
+
+// ToModel builds the rule for a restaurant; rules are active unless stated.
+func (rule ServiceChargeRuleDTO) ToModel(restaurantID string) models.ServiceChargeRule {
+	return models.ServiceChargeRule{
+		RuleID:            rule.RuleID,
+		RestaurantID:      restaurantID,
+		Name:              rule.Name,
+		Percentage:        rule.Percentage,
+		MinimumOrderTotal: rule.MinimumOrderTotal,
+		Active:            rule.Active == nil || *rule.Active,
+	}
+}
+
+func FromServiceChargeRules(rules []models.ServiceChargeRule) []ServiceChargeRuleDTO {
+	dtos := make([]ServiceChargeRuleDTO, len(rules))
+	for i, rule := range rules {
+		dtos[i] = ServiceChargeRuleDTO{
+			RuleID:            rule.RuleID,
+			Name:              rule.Name,
+			Percentage:        rule.Percentage,
+			MinimumOrderTotal: rule.MinimumOrderTotal,
+			Active:            &rules[i].Active,
+		}
+	}
+	return dtos
+}
+
+// FromTipShares groups the tips of each waiter's shifts under the waiter.
+func FromTipShares(shares []models.TipShare) []TipShareDTO {
+	var dtos []TipShareDTO
+	index := make(map[string]int)
+	for _, share := range shares {
+		userID := safeString(share.UserID)
+		i, ok := index[userID]
+		if !ok {
+			i = len(dtos)
+			index[userID] = i
+			dtos = append(dtos, TipShareDTO{UserID: userID, Name: safeString(share.Name), Shifts: []TipShiftDTO{}})
+		}
+		dtos[i].Payments += share.Payments
+		dtos[i].Tips = models.RoundMoney(dtos[i].Tips + share.Tips)
+		dtos[i].Shifts = append(dtos[i].Shifts, TipShiftDTO{
+			ShiftID:  safeString(share.ShiftID),
+			ClockIn:  share.ClockIn,
+			ClockOut: share.ClockOut,
+			Payments: share.Payments,
+			Tips:     share.Tips,
+		})
+	}
+	return dtos
+}
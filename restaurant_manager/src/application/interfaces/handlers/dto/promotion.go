@@ -0,0 +1,113 @@
+package dto
+
+import (
+	"restaurant_manager/src/domain/models"
+	"time"
+)
+
+type PromotionDTO struct {
+	PromotionID       string     `json:"promotion_id,omitempty"`
+	Name              string     `json:"name"`
+	Kind              string     `json:"kind"`
+	Scope             string     `json:"scope"`
+	Value             float64    `json:"value,omitempty"`
+	MenuItemID        *string    `json:"menu_item_id,omitempty"`
+	Category          *string    `json:"category,omitempty"`
+	BuyQuantity       *int       `json:"buy_quantity,omitempty"`
+	FreeQuantity      *int       `json:"free_quantity,omitempty"`
+	MinimumOrderTotal float64    `json:"minimum_order_total"`
+	StartsAt          *time.Time `json:"starts_at,omitempty"`
+	EndsAt            *time.Time `json:"ends_at,omitempty"`
+	HappyHourStart    *string    `json:"happy_hour_start,omitempty"`
+	HappyHourEnd      *string    `json:"happy_hour_end,omitempty"`
+	CouponCode        *string    `json:"coupon_code,omitempty"`
+	UsageLimit        *int       `json:"usage_limit,omitempty"`
+	TimesUsed         int        `json:"times_used"`
+	Active            *bool      `json:"active,omitempty"`
+}
+
+// CouponRequest redeems a coupon code on an order.
+type CouponRequest struct {
+	Code string `json:"code"`
+}
+
+type OrderDiscountDTO struct {
+	OrderDiscountID string  `json:"order_discount_id"`
+	OrderItemID     string  `json:"order_item_id,omitempty"`
+	PromotionID     string  `json:"promotion_id,omitempty"`
+	Name            string  `json:"name"`
+	Amount          float64 `json:"amount"`
+}
+
+// ToModel builds the promotion for a restaurant; promotions are active unless
+// stated.
+func (promotion PromotionDTO) ToModel(restaurantID string) models.Promotion {
+	model := models.Promotion{
+		PromotionID:       promotion.PromotionID,
+		RestaurantID:      restaurantID,
+		Name:              promotion.Name,
+		Kind:              models.PromotionKind(promotion.Kind),
+		Scope:             models.PromotionScope(promotion.Scope),
+		Value:             promotion.Value,
+		MenuItemID:        promotion.MenuItemID,
+		BuyQuantity:       promotion.BuyQuantity,
+		FreeQuantity:      promotion.FreeQuantity,
+		MinimumOrderTotal: promotion.MinimumOrderTotal,
+		StartsAt:          promotion.StartsAt,
+		EndsAt:            promotion.EndsAt,
+		HappyHourStart:    promotion.HappyHourStart,
+		HappyHourEnd:      promotion.HappyHourEnd,
+		CouponCode:        promotion.CouponCode,
+		UsageLimit:        promotion.UsageLimit,
+		Active:            promotion.Active == nil || *promotion.Active,
+	}
+	if promotion.Category != nil {
+		category := models.Category(*promotion.Category)
+		model.Category = &category
+	}
+	return model
+}
+
+func FromPromotions(promotions []models.Promotion) []PromotionDTO {
+	dtos := make([]PromotionDTO, len(promotions))
+	for i, promotion := range promotions {
+		dtos[i] = PromotionDTO{
+			PromotionID:       promotion.PromotionID,
+			Name:              promotion.Name,
+			Kind:              string(promotion.Kind),
+			Scope:             string(promotion.Scope),
+			Value:             promotion.Value,
+			MenuItemID:        promotion.MenuItemID,
+			BuyQuantity:       promotion.BuyQuantity,
+			FreeQuantity:      promotion.FreeQuantity,
+			MinimumOrderTotal: promotion.MinimumOrderTotal,
+			StartsAt:          promotion.StartsAt,
+			EndsAt:            promotion.EndsAt,
+			HappyHourStart:    promotion.HappyHourStart,
+			HappyHourEnd:      promotion.HappyHourEnd,
+			CouponCode:        promotion.CouponCode,
+			UsageLimit:        promotion.UsageLimit,
+			TimesUsed:         promotion.TimesUsed,
+			Active:            &promotions[i].Active,
+		}
+		if promotion.Category != nil {
+			category := string(*promotion.Category)
+			dtos[i].Category = &category
+		}
+	}
+	return dtos
+}
+
+func FromOrderDiscounts(discounts []models.OrderDiscount) []OrderDiscountDTO {
+	dtos := make([]OrderDiscountDTO, len(discounts))
+	for i, discount := range discounts {
+		dtos[i] = OrderDiscountDTO{
+			OrderDiscountID: discount.OrderDiscountID,
+			OrderItemID:     safeString(discount.OrderItemID),
+			PromotionID:     safeString(discount.PromotionID),
+			Name:            discount.Name,
+			Amount:          discount.Amount,
+		}
+	}
+	return dtos
+}
@@ -0,0 +1,170 @@
+package dto
+
+import (
+	"restaurant_manager/src/domain/models"
+	"time"
+)
+
+type ReservationRequest struct {
+	GuestName       string    `json:"guest_name"`
+	Phone           string    `json:"phone"`
+	PartySize       int       `json:"party_size"`
+	StartsAt        time.Time `json:"starts_at"`
+	DurationMinutes int       `json:"duration_minutes"`
+	TableIDs        []string  `json:"table_ids"`
+	Notes           *string   `json:"notes,omitempty"`
+}
+
+// ReservationStatusRequest moves a reservation to seated, no_show or cancelled.
+type ReservationStatusRequest struct {
+	Status string `json:"status"`
+}
+
+type ReservationTableDTO struct {
+	TableID     string `json:"table_id"`
+	TableNumber int    `json:"table_number"`
+	Status      string `json:"status"`
+}
+
+type ReservationDTO struct {
+	ReservationID   string                `json:"reservation_id"`
+	GuestName       string                `json:"guest_name"`
+	Phone           string                `json:"phone"`
+	PartySize       int                   `json:"party_size"`
+	StartsAt        time.Time             `json:"starts_at"`
+	EndsAt          time.Time             `json:"ends_at"`
+	DurationMinutes int                   `json:"duration_minutes"`
+	Status          string                `json:"status"`
+	Tables          []ReservationTableDTO `json:"tables"`
+	Notes           *string               `json:"notes,omitempty"`
+	SeatedAt        *time.Time            `json:"seated_at,omitempty"`
+	CancelledAt     *time.Time            `json:"cancelled_at,omitempty"`
+	CreatedAt       time.Time             `json:"created_at"`
+}
+
+// WaitlistRequest adds a walk-in party to the waitlist. Parties are quoted a
+// wait worked out from the tables unless QuotedWaitMinutes is given.
+type WaitlistRequest struct {
+	GuestName         string `json:"guest_name"`
+	Phone             string `json:"phone"`
+	PartySize         int    `json:"party_size"`
+	QuotedWaitMinutes *int   `json:"quoted_wait_minutes,omitempty"`
+}
+
+// WaitlistStatusRequest takes a party off the waitlist, seated at TableID or
+// left.
+type WaitlistStatusRequest struct {
+	Status  string `json:"status"`
+	TableID string `json:"table_id,omitempty"`
+}
+
+type WaitlistEntryDTO struct {
+	EntryID           string     `json:"entry_id"`
+	GuestName         string     `json:"guest_name"`
+	Phone             string     `json:"phone"`
+	PartySize         int        `json:"party_size"`
+	Status            string     `json:"status"`
+	Position          int        `json:"position,omitempty"`
+	QuotedWaitMinutes int        `json:"quoted_wait_minutes"`
+	WaitedMinutes     int        `json:"waited_minutes"`
+	TableID           *string    `json:"table_id,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	SeatedAt          *time.Time `json:"seated_at,omitempty"`
+	LeftAt            *time.Time `json:"left_at,omitempty"`
+}
+
+func (request ReservationRequest) ToModel(restaurantID string, reservationID string) models.Reservation {
+	tables := make([]models.ReservationTable, len(request.TableIDs))
+	for i, tableID := range request.TableIDs {
+		tables[i] = models.ReservationTable{ReservationID: reservationID, TableID: tableID}
+	}
+	return models.Reservation{
+		ReservationID:   reservationID,
+		RestaurantID:    restaurantID,
+		GuestName:       request.GuestName,
+		Phone:           request.Phone,
+		PartySize:       request.PartySize,
+		StartsAt:        request.StartsAt,
+		DurationMinutes: request.DurationMinutes,
+		Notes:           request.Notes,
+		Tables:          tables,
+	}
+}
+
+func FromReservation(reservation models.Reservation) ReservationDTO {
+	tables := make([]ReservationTableDTO, len(reservation.Tables))
+	for i, table := range reservation.Tables {
+		tables[i] = ReservationTableDTO{
+			TableID:     table.TableID,
+			TableNumber: table.Table.TableNumber,
+			Status:      string(table.Table.Status),
+		}
+	}
+	return ReservationDTO{
+		ReservationID:   reservation.ReservationID,
+		GuestName:       reservation.GuestName,
+		Phone:           reservation.Phone,
+		PartySize:       reservation.PartySize,
+		StartsAt:        reservation.StartsAt,
+		EndsAt:          reservation.EndsAt(),
+		DurationMinutes: reservation.DurationMinutes,
+		Status:          string(reservation.Status),
+		Tables:          tables,
+		Notes:           reservation.Notes,
+		SeatedAt:        reservation.SeatedAt,
+		CancelledAt:     reservation.CancelledAt,
+		CreatedAt:       reservation.CreatedAt,
+	}
+}
+
+func FromReservations(reservations []models.Reservation) []ReservationDTO {
+	dtos := make([]ReservationDTO, len(reservations))
+	for i, reservation := range reservations {
+		dtos[i] = FromReservation(reservation)
+	}
+	return dtos
+}
+
+func (request WaitlistRequest) ToModel(restaurantID string) models.WaitlistEntry {
+	return models.WaitlistEntry{
+		RestaurantID: restaurantID,
+		GuestName:    request.GuestName,
+		Phone:        request.Phone,
+		PartySize:    request.PartySize,
+	}
+}
+
+// FromWaitlistEntry describes a party on the waitlist at the given place in
+// line, or off it when position is zero, as of now.
+func FromWaitlistEntry(entry models.WaitlistEntry, position int, now time.Time) WaitlistEntryDTO {
+	until := now
+	if entry.SeatedAt != nil {
+		until = *entry.SeatedAt
+	} else if entry.LeftAt != nil {
+		until = *entry.LeftAt
+	}
+	return WaitlistEntryDTO{
+		EntryID:           entry.EntryID,
+		GuestName:         entry.GuestName,
+		Phone:             entry.Phone,
+		PartySize:         entry.PartySize,
+		Status:            string(entry.Status),
+		Position:          position,
+		QuotedWaitMinutes: entry.QuotedWaitMinutes,
+		WaitedMinutes:     int(max(until.Sub(entry.CreatedAt), 0) / time.Minute),
+		TableID:           entry.TableID,
+		CreatedAt:         entry.CreatedAt,
+		SeatedAt:          entry.SeatedAt,
+		LeftAt:            entry.LeftAt,
+	}
+}
+
+// FromWaitlist describes the parties waiting, numbered in the order they
+// joined the line.
+func FromWaitlist(entries []models.WaitlistEntry, now time.Time) []WaitlistEntryDTO {
+	dtos := make([]WaitlistEntryDTO, len(entries))
+	for i, entry := range entries {
+		dtos[i] = FromWaitlistEntry(entry, i+1, now)
+	}
+	return dtos
+}
@@ -0,0 +1,65 @@
+package dto
+
+import (
+	"math"
+	"restaurant_manager/src/domain/models"
+	"time"
+)
+
+type ShiftRequest struct {
+	UserID       string     `json:"user_id"`
+	RestaurantID string     `json:"restaurant_id"`
+	ClockIn      time.Time  `json:"clock_in"`
+	ClockOut     *time.Time `json:"clock_out"`
+	Notes        string     `json:"notes"`
+}
+
+type ShiftResponse struct {
+	ShiftID      string     `json:"shift_id"`
+	UserID       string     `json:"user_id"`
+	Name         string     `json:"name,omitempty"`
+	RestaurantID string     `json:"restaurant_id"`
+	ClockIn      time.Time  `json:"clock_in"`
+	ClockOut     *time.Time `json:"clock_out,omitempty"`
+	Notes        string     `json:"notes,omitempty"`
+}
+
+type ShiftHoursResponse struct {
+	UserID string  `json:"user_id"`
+	Name   string  `json:"name"`
+	Shifts int     `json:"shifts"`
+	Hours  float64 `json:"hours"`
+}
+
+func FromShift(shift models.Shift) ShiftResponse {
+	return ShiftResponse{
+		ShiftID:      shift.ShiftID,
+		UserID:       shift.UserID,
+		Name:         shift.User.Name,
+		RestaurantID: shift.RestaurantID,
+		ClockIn:      shift.ClockIn,
+		ClockOut:     shift.ClockOut,
+		Notes:        shift.Notes,
+	}
+}
+
+func FromShifts(shifts []models.Shift) []ShiftResponse {
+	responses := make([]ShiftResponse, len(shifts))
+	for i, shift := range shifts {
+		responses[i] = FromShift(shift)
+	}
+	return responses
+}
+
+func FromShiftHours(report []models.ShiftHours) []ShiftHoursResponse {
+	responses := make([]ShiftHoursResponse, len(report))
+	for i, hours := range report {
+		responses[i] = ShiftHoursResponse{
+			UserID: hours.UserID,
+			Name:   hours.Name,
+			Shifts: hours.Shifts,
+			Hours:  math.Round(hours.Hours*100) / 100,
+		}
+	}
+	return responses
+}
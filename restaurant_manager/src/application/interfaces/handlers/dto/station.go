@@ -0,0 +1,62 @@
+package dto
+
+import (
+	"restaurant_manager/src/domain/models"
+	"time"
+)
+
+type StationRequest struct {
+	RestaurantID string `json:"restaurant_id"`
+	Name         string `json:"name"`
+	Kind         string `json:"kind"`
+}
+
+// StationItemRequest names an order item on a station's queue.
+type StationItemRequest struct {
+	OrderID     string `json:"order_id"`
+	OrderItemID string `json:"order_item_id"`
+	MenuItemID  string `json:"menu_item_id"`
+	Observation string `json:"observation"`
+}
+
+type StationQueueItem struct {
+	OrderID     string                  `json:"order_id"`
+	OrderItemID string                  `json:"order_item_id"`
+	MenuItemID  string                  `json:"menu_item_id"`
+	Name        string                  `json:"name"`
+	Quantity    int                     `json:"quantity"`
+	Observation string                  `json:"observation"`
+	Modifiers   []string                `json:"modifiers,omitempty"`
+	Components  []OrderItemComponentDTO `json:"components,omitempty"`
+	Status      string                  `json:"status"`
+	Course      int                     `json:"course"`
+	CreatedAt   time.Time               `json:"created_at"`
+	FiredAt     *time.Time              `json:"fired_at,omitempty"`
+	PreparedAt  *time.Time              `json:"prepared_at,omitempty"`
+}
+
+func FromStationItem(item models.OrderItem) StationQueueItem {
+	return StationQueueItem{
+		OrderID:     item.OrderID,
+		OrderItemID: item.OrderItemID,
+		MenuItemID:  item.MenuItemID,
+		Name:        item.MenuItem.Name,
+		Quantity:    item.Quantity,
+		Observation: safeString(item.Observation),
+		Modifiers:   modifierNames(item.Modifiers),
+		Components:  fromOrderItemComponents(item.Components),
+		Status:      string(item.Status),
+		Course:      item.Course,
+		CreatedAt:   item.CreatedAt,
+		FiredAt:     item.FiredAt,
+		PreparedAt:  item.PreparedAt,
+	}
+}
+
+func FromStationItems(items []models.OrderItem) []StationQueueItem {
+	queue := make([]StationQueueItem, len(items))
+	for i, item := range items {
+		queue[i] = FromStationItem(item)
+	}
+	return queue
+}
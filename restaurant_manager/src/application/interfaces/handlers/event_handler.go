@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"restaurant_manager/src/application/services"
+	"restaurant_manager/src/application/utils"
+	"restaurant_manager/src/domain/models"
+	"strconv"
+	"time"
+)
+
+// heartbeatInterval keeps idle connections from being closed by proxies.
+const heartbeatInterval = 15 * time.Second
+
+type EventHandler struct {
+	hub *services.EventHub
+}
+
+func NewEventHandler(hub *services.EventHub) *EventHandler {
+	return &EventHandler{hub: hub}
+}
+
+// StreamEvents handles GET /restaurants/{restaurant_id}/events as a
+// Server-Sent Events stream. Clients resuming after a disconnect send the
+// Last-Event-ID header, or the last_event_id query parameter, to replay the
+// events they missed.
+func (h *EventHandler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+	var since uint64
+	if lastEventID != "" {
+		var err error
+		if since, err = strconv.ParseUint(lastEventID, 10, 64); err != nil {
+			http.Error(w, "Invalid last event ID", http.StatusBadRequest)
+			return
+		}
+	}
+
+	role := utils.GetAuthContext(r).Role
+	replay, events, unsubscribe := h.hub.Subscribe(restaurantID, since)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	for _, event := range replay {
+		writeEvent(w, role, event)
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				// Dropped for falling behind; the client reconnects and replays
+				return
+			}
+			if writeEvent(w, role, event) {
+				flusher.Flush()
+			}
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// writeEvent writes the event in SSE format when the role may see it.
+func writeEvent(w http.ResponseWriter, role string, event models.OrderEvent) bool {
+	if !event.VisibleTo(role) {
+		return false
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return false
+	}
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return true
+}
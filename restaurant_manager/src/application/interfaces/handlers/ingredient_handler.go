@@ -5,7 +5,6 @@ import (
 	"net/http"
 	"restaurant_manager/src/application/interfaces/handlers/dto"
 	"restaurant_manager/src/application/services"
-	"restaurant_manager/src/application/utils"
 )
 
 type IngredientHandler struct {
@@ -17,9 +16,6 @@ func NewIngredientHandler(service *services.IngredientsService) *IngredientHandl
 }
 
 func (h *IngredientHandler) GetIngredientsByRestaurantID(w http.ResponseWriter, r *http.Request) {
-	// Verify token
-	_ = utils.TokenVerification(r, w)
-
 	// Get restaurant_id from query params
 	restaurantID := r.URL.Query().Get("restaurant_id")
 	if restaurantID == "" {
@@ -19,12 +19,6 @@ func NewInventoryHandler(service *services.InventoryService) *InventoryHandler {
 }
 
 func (h *InventoryHandler) CreateInventory(w http.ResponseWriter, r *http.Request) {
-	owner := utils.TokenVerification(r, w)
-	if owner == "" {
-		http.Error(w, "Invalid token", http.StatusUnauthorized)
-		return
-	}
-
 	restaurantID := r.URL.Query().Get("restaurant_id")
 	if restaurantID == "" {
 		http.Error(w, "Restaurant ID is required", http.StatusBadRequest)
@@ -52,8 +46,12 @@ func (h *InventoryHandler) CreateInventory(w http.ResponseWriter, r *http.Reques
 }
 
 func (h *InventoryHandler) GetInventory(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
 	inventoryID := mux.Vars(r)["inventory_id"]
-	inventory, err := h.service.GetInventory(inventoryID)
+	inventory, err := h.service.GetInventory(restaurantID, inventoryID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
@@ -72,9 +70,8 @@ func (h *InventoryHandler) GetInventoryByRestaurantID(w http.ResponseWriter, r *
 }
 
 func (h *InventoryHandler) UpdateInventory(w http.ResponseWriter, r *http.Request) {
-	owner := utils.TokenVerification(r, w)
-	if owner == "" {
-		http.Error(w, "Invalid token", http.StatusUnauthorized)
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
 		return
 	}
 
@@ -84,7 +81,11 @@ func (h *InventoryHandler) UpdateInventory(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	err := h.service.UpdateInventory(inventories)
+	for i := range inventories {
+		inventories[i].RestaurantID = restaurantID
+	}
+
+	err := h.service.UpdateInventory(utils.GetAuthContext(r).UserID, inventories)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -93,8 +94,12 @@ func (h *InventoryHandler) UpdateInventory(w http.ResponseWriter, r *http.Reques
 }
 
 func (h *InventoryHandler) DeleteInventory(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
 	inventoryID := mux.Vars(r)["inventory_id"]
-	err := h.service.DeleteInventory(inventoryID)
+	err := h.service.DeleteInventory(restaurantID, inventoryID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
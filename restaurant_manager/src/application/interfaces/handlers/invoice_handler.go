@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"restaurant_manager/src/application/interfaces/handlers/dto"
+	"restaurant_manager/src/application/services"
+
+	"github.com/gorilla/mux"
+)
+
+type InvoiceHandler struct {
+	service *services.InvoiceService
+}
+
+func NewInvoiceHandler(service *services.InvoiceService) *InvoiceHandler {
+	return &InvoiceHandler{service: service}
+}
+
+// IssueInvoice handles POST /orders/{order_id}/invoice. It answers 201 when
+// the invoice is issued and 200 when the order had already been invoiced.
+func (h *InvoiceHandler) IssueInvoice(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	var request dto.InvoiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	// Customers invoice their own orders to themselves
+	customerID := orderCustomer(r)
+	if customerID != "" {
+		request.BuyerID = customerID
+	}
+
+	invoice, created, err := h.service.IssueInvoice(customerID, restaurantID, mux.Vars(r)["order_id"], request.BuyerID)
+	if err != nil {
+		writeInvoiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if created {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(dto.FromInvoice(*invoice))
+}
+
+// DownloadInvoice handles GET /orders/{order_id}/invoice, sending the UBL
+// document as issued.
+func (h *InvoiceHandler) DownloadInvoice(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	invoice, err := h.service.GetInvoice(orderCustomer(r), restaurantID, mux.Vars(r)["order_id"])
+	if err != nil {
+		writeInvoiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+invoice.Number+`.xml"`)
+	io.WriteString(w, invoice.Document)
+}
+
+// CreateInvoiceResolution handles POST /restaurants/{restaurant_id}/invoice-resolutions
+func (h *InvoiceHandler) CreateInvoiceResolution(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	var request dto.InvoiceResolutionDTO
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	resolution, err := request.ToModel(restaurantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resolutionID, err := h.service.CreateResolution(&resolution)
+	if err != nil {
+		writeInvoiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"resolution_id": resolutionID})
+}
+
+// GetInvoiceResolutions handles GET /restaurants/{restaurant_id}/invoice-resolutions
+func (h *InvoiceHandler) GetInvoiceResolutions(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	resolutions, err := h.service.GetResolutions(restaurantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.FromInvoiceResolutions(resolutions))
+}
+
+func writeInvoiceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidInvoiceResolution), errors.Is(err, services.ErrInvalidInvoiceBuyer):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrOrderNotFound), errors.Is(err, services.ErrInvoiceNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrOrderNotInvoiceable), errors.Is(err, services.ErrNoInvoiceResolution),
+		errors.Is(err, services.ErrInvoiceRangeExhausted):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
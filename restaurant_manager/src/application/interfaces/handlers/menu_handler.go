@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"restaurant_manager/src/application/interfaces/handlers/dto"
@@ -23,7 +24,7 @@ func NewMenuHandler(service *services.MenuService) *MenuHandler {
 
 // Add a menu item
 func (h *MenuHandler) AddMenuItem(w http.ResponseWriter, r *http.Request) {
-	owner := utils.TokenVerification(r, w)
+	owner := utils.GetAuthContext(r).UserID
 	restaurantID := mux.Vars(r)["restaurant_id"]
 	err := r.ParseMultipartForm(10 << 20)
 	if err != nil {
@@ -90,16 +91,22 @@ func (h *MenuHandler) AddMenuItem(w http.ResponseWriter, r *http.Request) {
 		Category:     models.Category(category),
 		Ingredients:  ingredients,
 	}
+	if stationID := r.FormValue("station_id"); stationID != "" {
+		menuItem.StationID = &stationID
+	}
+	if taxCategory := r.FormValue("tax_category"); taxCategory != "" {
+		category := models.TaxCategory(taxCategory)
+		menuItem.TaxCategory = &category
+	}
 	menuItemID, err := h.service.AddMenuItem(&menuItem)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeMenuError(w, err)
 		return
 	}
 	json.NewEncoder(w).Encode(map[string]string{"menu_item_id": menuItemID})
 }
 
 func (h *MenuHandler) GetAllMenuItems(w http.ResponseWriter, r *http.Request) {
-	_ = utils.TokenVerification(r, w)
 	restaurantID := mux.Vars(r)["restaurant_id"]
 	menus, err := h.service.GetMenuItemsByRestaurantID(restaurantID)
 	if err != nil {
@@ -112,11 +119,17 @@ func (h *MenuHandler) GetAllMenuItems(w http.ResponseWriter, r *http.Request) {
 
 // Update a menu item
 func (h *MenuHandler) UpdateMenuItem(w http.ResponseWriter, r *http.Request) {
+	restaurantID := mux.Vars(r)["restaurant_id"]
 	menuItemId := mux.Vars(r)["menu_item_id"]
 	var menuItem models.MenuItem
 	json.NewDecoder(r.Body).Decode(&menuItem)
 	menuItem.MenuItemID = menuItemId
-	err := h.service.UpdateMenuItem(&menuItem)
+	menuItem.RestaurantID = restaurantID
+	err := h.service.UpdateMenuItem(utils.GetAuthContext(r).UserID, &menuItem)
+	if errors.Is(err, services.ErrInvalidTaxCategory) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
@@ -124,10 +137,83 @@ func (h *MenuHandler) UpdateMenuItem(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *MenuHandler) DeleteMenuItem(w http.ResponseWriter, r *http.Request) {
+	restaurantID := mux.Vars(r)["restaurant_id"]
 	menuItemId := mux.Vars(r)["menu_item_id"]
-	err := h.service.DeleteMenuItem(menuItemId)
+	err := h.service.DeleteMenuItem(restaurantID, menuItemId)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// CreateModifierGroup handles POST /menus/{restaurant_id}/items/{menu_item_id}/modifier-groups
+func (h *MenuHandler) CreateModifierGroup(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	var request dto.ModifierGroupDTO
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	groupID, err := h.service.CreateModifierGroup(vars["restaurant_id"], request.ToModel(vars["menu_item_id"]))
+	if err != nil {
+		writeMenuError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"modifier_group_id": groupID})
+}
+
+// GetModifierGroups handles GET /menus/{restaurant_id}/items/{menu_item_id}/modifier-groups
+func (h *MenuHandler) GetModifierGroups(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	groups, err := h.service.GetModifierGroups(vars["restaurant_id"], vars["menu_item_id"])
+	if err != nil {
+		writeMenuError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.FromModifierGroups(groups))
+}
+
+// DeleteModifierGroup handles DELETE /menus/{restaurant_id}/items/{menu_item_id}/modifier-groups/{modifier_group_id}
+func (h *MenuHandler) DeleteModifierGroup(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	err := h.service.DeleteModifierGroup(vars["restaurant_id"], vars["menu_item_id"], vars["modifier_group_id"])
+	if err != nil {
+		writeMenuError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ReplaceComboSlots handles PUT /menus/{restaurant_id}/items/{menu_item_id}/combo-slots
+func (h *MenuHandler) ReplaceComboSlots(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	var request []dto.ComboSlotDTO
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	slots := make([]models.ComboSlot, len(request))
+	for i, slot := range request {
+		slots[i] = slot.ToModel()
+	}
+	if err := h.service.ReplaceComboSlots(vars["restaurant_id"], vars["menu_item_id"], slots); err != nil {
+		writeMenuError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeMenuError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrMenuItemNotFound), errors.Is(err, services.ErrModifierGroupNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrInvalidModifierGroup), errors.Is(err, services.ErrInvalidComboSlots),
+		errors.Is(err, services.ErrInvalidTaxCategory):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -2,59 +2,86 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"restaurant_manager/src/application/interfaces/handlers/dto"
 	"restaurant_manager/src/application/services"
+	"restaurant_manager/src/application/utils"
 	"restaurant_manager/src/domain/models"
+	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 )
 
 type OrderHandler struct {
-	service *services.OrderService
+	service       *services.OrderService
+	tenantService *services.TenantService
 }
 
-func NewOrderHandler(service *services.OrderService) *OrderHandler {
-	return &OrderHandler{service}
+func NewOrderHandler(service *services.OrderService, tenantService *services.TenantService) *OrderHandler {
+	return &OrderHandler{service, tenantService}
 }
 
 func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
-	var orderDto *dto.OrderDTO
-	json.NewDecoder(r.Body).Decode(&orderDto)
+	var orderDto dto.OrderDTO
+	if err := json.NewDecoder(r.Body).Decode(&orderDto); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	restaurantID, ok := resolveRestaurant(w, r, h.tenantService, orderDto.RestaurantID)
+	if !ok {
+		return
+	}
 	order := models.Order{
 		TableID:      orderDto.TableID,
-		RestaurantID: orderDto.RestaurantID,
+		RestaurantID: restaurantID,
 		Status:       models.OrderStatus(orderDto.Status),
-		TotalPrice:   orderDto.TotalPrice,
+	}
+	// Record the staff member taking the order, or the customer placing it
+	customerID := orderCustomer(r)
+	if customerID != "" {
+		order.CustomerID = &customerID
+	} else {
+		auth := utils.GetAuthContext(r)
+		order.WaiterID = &auth.UserID
 	}
 	orderID, err := h.service.CreateOrder(&order)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
 	for _, item := range orderDto.Items {
 		orderItem := models.OrderItem{
 			OrderID:     orderID,
 			MenuItemID:  item.MenuItemID,
 			Quantity:    item.Quantity,
-			Status:      "pending",
+			Status:      item.InitialStatus(),
 			Price:       item.Price,
 			Observation: &item.Observation,
+			Course:      item.Course,
+			Seat:        item.Seat,
+			Modifiers:   item.ChosenModifiers(),
+			Components:  item.ChosenComponents(),
 		}
-		_, err := h.service.AddOrderItem(&orderItem)
+		_, err := h.service.AddOrderItem(utils.GetAuthContext(r).UserID, customerID, restaurantID, &orderItem)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeOrderError(w, err)
 			return
 		}
 	}
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
-		return
-	}
 	json.NewEncoder(w).Encode(map[string]string{"order_id": orderID})
 }
 
 func (h *OrderHandler) DeleteOrder(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
 	var orderID string
 	vars := mux.Vars(r)
 	orderID = vars["orders_id"]
-	err := h.service.DeleteOrder(orderID)
+	err := h.service.DeleteOrder(restaurantID, orderID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -63,27 +90,23 @@ func (h *OrderHandler) DeleteOrder(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *OrderHandler) UpdateOrder(w http.ResponseWriter, r *http.Request) {
-	var orderDto *dto.OrderDTO
-	json.NewDecoder(r.Body).Decode(&orderDto)
+	var orderDto dto.OrderDTO
+	if err := json.NewDecoder(r.Body).Decode(&orderDto); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	restaurantID, ok := resolveRestaurant(w, r, h.tenantService, orderDto.RestaurantID)
+	if !ok {
+		return
+	}
 	order := models.Order{
 		OrderID:      orderDto.OrderID,
-		TableID:      orderDto.TableID,
-		RestaurantID: orderDto.RestaurantID,
+		RestaurantID: restaurantID,
 		Status:       models.OrderStatus(orderDto.Status),
-		TotalPrice:   orderDto.TotalPrice,
-	}
-	if orderDto.TimeToPrepare != 0 {
-		order.TimeToPrepare = orderDto.TimeToPrepare
-	}
-	if orderDto.TimeToDeliver != 0 {
-		order.TimeToDeliver = orderDto.TimeToDeliver
-	}
-	if orderDto.TimeToPay != 0 {
-		order.TimeToPay = orderDto.TimeToPay
 	}
 	err := h.service.UpdateOrder(&order)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeOrderError(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -91,10 +114,14 @@ func (h *OrderHandler) UpdateOrder(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *OrderHandler) GetOrder(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
 	var orderID string
 	vars := mux.Vars(r)
 	orderID = vars["orders_id"]
-	order, err := h.service.GetOrder(orderID)
+	order, err := h.service.GetOrder(orderCustomer(r), restaurantID, orderID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
@@ -103,8 +130,11 @@ func (h *OrderHandler) GetOrder(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *OrderHandler) GetOrderByRestaurantID(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
 	queryParams := r.URL.Query()
-	restaurantID := queryParams.Get("restaurant_id")
 	status := queryParams.Get("status")
 	tableID := queryParams.Get("table_id")
 	startDate := queryParams.Get("start_date")
@@ -119,19 +149,32 @@ func (h *OrderHandler) GetOrderByRestaurantID(w http.ResponseWriter, r *http.Req
 }
 
 func (h *OrderHandler) AddOrderItem(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
 	var orderItemsID []string
 	orderID := mux.Vars(r)["order_id"]
 	var orderItem []dto.OrderItemDTO
-	json.NewDecoder(r.Body).Decode(&orderItem)
+	if err := json.NewDecoder(r.Body).Decode(&orderItem); err != nil || len(orderItem) == 0 {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
 	for _, item := range orderItem {
 		orderItemModel := models.OrderItem{
-			OrderID:    orderID,
-			MenuItemID: item.MenuItemID,
-			Quantity:   item.Quantity,
+			OrderID:     orderID,
+			MenuItemID:  item.MenuItemID,
+			Quantity:    item.Quantity,
+			Status:      item.InitialStatus(),
+			Observation: &item.Observation,
+			Course:      item.Course,
+			Seat:        item.Seat,
+			Modifiers:   item.ChosenModifiers(),
+			Components:  item.ChosenComponents(),
 		}
-		orderItemID, err := h.service.AddOrderItem(&orderItemModel)
+		orderItemID, err := h.service.AddOrderItem(utils.GetAuthContext(r).UserID, orderCustomer(r), restaurantID, &orderItemModel)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeOrderError(w, err)
 			return
 		}
 		orderItemsID = append(orderItemsID, orderItemID)
@@ -141,9 +184,13 @@ func (h *OrderHandler) AddOrderItem(w http.ResponseWriter, r *http.Request) {
 
 // Update an order item
 func (h *OrderHandler) UpdateOrderItem(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
 	orderID := mux.Vars(r)["order_id"]
-	menuItemID := mux.Vars(r)["menu_item_id"]
 	var body struct {
+		OrderItemID string `json:"order_item_id"`
 		Observation string `json:"observation"`
 		Status      string `json:"status"`
 	}
@@ -151,27 +198,33 @@ func (h *OrderHandler) UpdateOrderItem(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
-	err := h.service.UpdateOrderItem(orderID, menuItemID, body.Observation, body.Status)
+	ref := orderItemRef(r, body.OrderItemID, body.Observation)
+	err := h.service.UpdateOrderItem(restaurantID, orderID, ref, body.Status)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeOrderError(w, err)
+		return
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
 // Delete an order item
 func (h *OrderHandler) DeleteOrderItem(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
 	orderID := mux.Vars(r)["order_id"]
-	menuItemID := mux.Vars(r)["menu_item_id"]
 	var body struct {
+		OrderItemID string `json:"order_item_id"`
 		Observation string `json:"observation"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
-	err := h.service.DeleteOrderItem(orderID, menuItemID, body.Observation)
+	err := h.service.DeleteOrderItem(restaurantID, orderID, orderItemRef(r, body.OrderItemID, body.Observation))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeOrderError(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -179,10 +232,14 @@ func (h *OrderHandler) DeleteOrderItem(w http.ResponseWriter, r *http.Request) {
 
 // Get all items for an order
 func (h *OrderHandler) GetOrderItems(w http.ResponseWriter, r *http.Request) {
-	orderID := mux.Vars(r)["orders_id"]
-	items, err := h.service.GetOrderItems(orderID)
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	orderID := mux.Vars(r)["order_id"]
+	items, err := h.service.GetOrderItems(orderCustomer(r), restaurantID, orderID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeOrderError(w, err)
 		return
 	}
 	json.NewEncoder(w).Encode(items)
@@ -190,10 +247,10 @@ func (h *OrderHandler) GetOrderItems(w http.ResponseWriter, r *http.Request) {
 
 func (h *OrderHandler) CreateVoidOrderItem(w http.ResponseWriter, r *http.Request) {
 	orderID := mux.Vars(r)["order_id"]
-	menuItemID := mux.Vars(r)["menu_item_id"]
 
 	var body struct {
 		RestaurantID string `json:"restaurantId"`
+		OrderItemID  string `json:"order_item_id"`
 		Observation  string `json:"observation"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
@@ -201,9 +258,15 @@ func (h *OrderHandler) CreateVoidOrderItem(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	err := h.service.CreateVoidOrderItem(orderID, menuItemID, body.RestaurantID, body.Observation)
+	restaurantID, ok := resolveRestaurant(w, r, h.tenantService, body.RestaurantID)
+	if !ok {
+		return
+	}
+
+	ref := orderItemRef(r, body.OrderItemID, body.Observation)
+	err := h.service.CreateVoidOrderItem(utils.GetAuthContext(r).UserID, restaurantID, orderID, ref)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeOrderError(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -221,6 +284,10 @@ func (h *OrderHandler) GetVoidOrderItems(w http.ResponseWriter, r *http.Request)
 }
 
 func (h *OrderHandler) RecoverVoidOrderItem(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
 	voidOrderItemID := mux.Vars(r)["void_order_item_id"]
 
 	var recoveryDTO dto.RecoverVoidOrderItemDTO
@@ -229,11 +296,184 @@ func (h *OrderHandler) RecoverVoidOrderItem(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	err := h.service.RecoverVoidOrderItem(voidOrderItemID, recoveryDTO.TargetOrderID)
+	err := h.service.RecoverVoidOrderItem(utils.GetAuthContext(r).UserID, restaurantID, voidOrderItemID, recoveryDTO.TargetOrderID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeOrderError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// FireCourse handles POST /orders/{order_id}/courses/{course}/fire
+func (h *OrderHandler) FireCourse(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	vars := mux.Vars(r)
+	course, err := strconv.Atoi(vars["course"])
+	if err != nil || course < 1 {
+		http.Error(w, "course must be a positive number", http.StatusBadRequest)
+		return
+	}
+	fired, err := h.service.FireCourse(restaurantID, vars["order_id"], course)
+	if err != nil {
+		writeOrderError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.FromOrderItems(fired))
+}
+
+// RedeemCoupon handles POST /orders/{order_id}/coupons and returns the order's
+// discount lines once the coupon applies.
+func (h *OrderHandler) RedeemCoupon(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	var request dto.CouponRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || strings.TrimSpace(request.Code) == "" {
+		http.Error(w, "code is required", http.StatusBadRequest)
+		return
+	}
+	discounts, err := h.service.RedeemCoupon(orderCustomer(r), restaurantID, mux.Vars(r)["order_id"], request.Code)
+	if err != nil {
+		writeOrderError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.FromOrderDiscounts(discounts))
+}
+
+// GetOrderStatusHistory handles GET /orders/{orders_id}/history
+func (h *OrderHandler) GetOrderStatusHistory(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	history, err := h.service.GetOrderStatusHistory(restaurantID, mux.Vars(r)["orders_id"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// GetKitchenPerformance handles GET /restaurants/{restaurant_id}/kitchen-performance
+func (h *OrderHandler) GetKitchenPerformance(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	groupBy := r.URL.Query().Get("group_by")
+	if groupBy == "" {
+		groupBy = models.PerformanceByHour
+	}
+	if groupBy != models.PerformanceByHour && groupBy != models.PerformanceByDay {
+		http.Error(w, "group_by must be hour or day", http.StatusBadRequest)
+		return
+	}
+	startDate, endDate, ok := parseDateRange(w, r)
+	if !ok {
+		return
+	}
+
+	performance, err := h.service.GetKitchenPerformance(restaurantID, groupBy, startDate, endDate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.FromKitchenPerformance(performance))
+}
+
+// orderItemRef names the order item of the {menu_item_id} route. Clients send
+// the order_item_id; older ones still send the item's observation instead.
+func orderItemRef(r *http.Request, orderItemID string, observation string) models.OrderItemRef {
+	return models.OrderItemRef{
+		OrderItemID: orderItemID,
+		MenuItemID:  mux.Vars(r)["menu_item_id"],
+		Observation: observation,
+	}
+}
+
+// TransferOrder handles POST /orders/{order_id}/transfer, moving the order to
+// another table.
+func (h *OrderHandler) TransferOrder(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	var request dto.TransferOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.TableID == "" {
+		http.Error(w, "table_id is required", http.StatusBadRequest)
+		return
+	}
+	order, err := h.service.TransferOrder(restaurantID, mux.Vars(r)["order_id"], request.TableID)
+	if err != nil {
+		writeOrderError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(dto.FromOrders([]models.Order{*order})[0])
+}
+
+// MergeOrders handles POST /orders/merge, answering with the order the others
+// were merged into.
+func (h *OrderHandler) MergeOrders(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	var request dto.MergeOrdersRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.TargetOrderID == "" {
+		http.Error(w, "target_order_id and order_ids are required", http.StatusBadRequest)
+		return
+	}
+	order, err := h.service.MergeOrders(restaurantID, request.TargetOrderID, request.OrderIDs)
+	if err != nil {
+		writeOrderError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(dto.FromOrders([]models.Order{*order})[0])
+}
+
+// MoveOrderItems handles POST /orders/{order_id}/items/move, answering with
+// the order the items were moved to.
+func (h *OrderHandler) MoveOrderItems(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	var request dto.MoveOrderItemsRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.TargetOrderID == "" {
+		http.Error(w, "target_order_id and items are required", http.StatusBadRequest)
+		return
+	}
+	order, err := h.service.MoveOrderItems(restaurantID, mux.Vars(r)["order_id"], request.TargetOrderID, request.Moves())
+	if err != nil {
+		writeOrderError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(dto.FromOrders([]models.Order{*order})[0])
+}
+
+func writeOrderError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidStatusTransition), errors.Is(err, services.ErrCouponExhausted),
+		errors.Is(err, services.ErrCouponAlreadyRedeemed), errors.Is(err, services.ErrTableOccupied),
+		errors.Is(err, services.ErrOrderHasPayments):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, services.ErrOrderNotFound), errors.Is(err, services.ErrOrderItemNotFound),
+		errors.Is(err, services.ErrNothingToFire), errors.Is(err, services.ErrCouponNotFound),
+		errors.Is(err, services.ErrTableNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrInvalidModifiers), errors.Is(err, services.ErrInvalidComponents),
+		errors.Is(err, services.ErrInvalidOrderMove):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
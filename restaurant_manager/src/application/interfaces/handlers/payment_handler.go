@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"restaurant_manager/src/application/interfaces/handlers/dto"
+	"restaurant_manager/src/application/services"
+	"restaurant_manager/src/application/utils"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type PaymentHandler struct {
+	service *services.PaymentService
+}
+
+func NewPaymentHandler(service *services.PaymentService) *PaymentHandler {
+	return &PaymentHandler{service: service}
+}
+
+// RecordPayment handles POST /orders/{order_id}/payments
+func (h *PaymentHandler) RecordPayment(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	var request dto.PaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	orderID := mux.Vars(r)["order_id"]
+	payment := request.ToModel(restaurantID, orderID)
+	if userID := utils.GetAuthContext(r).UserID; userID != "" {
+		payment.CreatedBy = &userID
+	}
+	balance, err := h.service.RecordPayment(&payment, request.Seat, request.ServiceCharge)
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(dto.PaymentResultDTO{
+		Payment: dto.FromPayment(payment),
+		Balance: dto.FromOrderBalance(orderID, *balance),
+	})
+}
+
+// GetBalance handles GET /orders/{order_id}/balance. The split query parameter
+// divides the outstanding amount into that many even shares.
+func (h *PaymentHandler) GetBalance(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	ways := 0
+	if split := r.URL.Query().Get("split"); split != "" {
+		var err error
+		ways, err = strconv.Atoi(split)
+		if err != nil || ways < 1 {
+			http.Error(w, "split must be a positive number", http.StatusBadRequest)
+			return
+		}
+	}
+
+	orderID := mux.Vars(r)["order_id"]
+	balance, payments, err := h.service.GetBalance(restaurantID, orderID)
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+	balanceDTO := dto.FromOrderBalance(orderID, *balance)
+	balanceDTO.Split = balance.SplitEvenly(ways)
+	balanceDTO.Payments = dto.FromPayments(payments)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(balanceDTO)
+}
+
+// GetTipShares handles GET /restaurants/{restaurant_id}/tips
+func (h *PaymentHandler) GetTipShares(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	startDate, endDate, ok := parseDateRange(w, r)
+	if !ok {
+		return
+	}
+
+	shares, err := h.service.GetTipShares(restaurantID, startDate, endDate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.FromTipShares(shares))
+}
+
+// CreateServiceChargeRule handles POST /restaurants/{restaurant_id}/service-charge-rules
+func (h *PaymentHandler) CreateServiceChargeRule(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	var request dto.ServiceChargeRuleDTO
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	rule := request.ToModel(restaurantID)
+	ruleID, err := h.service.CreateServiceChargeRule(&rule)
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"rule_id": ruleID})
+}
+
+// GetServiceChargeRules handles GET /restaurants/{restaurant_id}/service-charge-rules
+func (h *PaymentHandler) GetServiceChargeRules(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	rules, err := h.service.GetServiceChargeRules(restaurantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.FromServiceChargeRules(rules))
+}
+
+// UpdateServiceChargeRule handles PUT /restaurants/{restaurant_id}/service-charge-rules/{rule_id}
+func (h *PaymentHandler) UpdateServiceChargeRule(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	var request dto.ServiceChargeRuleDTO
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	request.RuleID = mux.Vars(r)["rule_id"]
+	rule := request.ToModel(restaurantID)
+	if err := h.service.UpdateServiceChargeRule(&rule); err != nil {
+		writePaymentError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteServiceChargeRule handles DELETE /restaurants/{restaurant_id}/service-charge-rules/{rule_id}
+func (h *PaymentHandler) DeleteServiceChargeRule(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteServiceChargeRule(restaurantID, mux.Vars(r)["rule_id"]); err != nil {
+		writePaymentError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writePaymentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidPayment), errors.Is(err, services.ErrInvalidServiceChargeRule):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrOrderNotFound), errors.Is(err, services.ErrOrderItemNotFound),
+		errors.Is(err, services.ErrServiceChargeRuleNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrPaymentExceedsBalance), errors.Is(err, services.ErrInvalidStatusTransition):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"restaurant_manager/src/application/interfaces/handlers/dto"
+	"restaurant_manager/src/application/services"
+
+	"github.com/gorilla/mux"
+)
+
+type PromotionHandler struct {
+	service *services.PromotionService
+}
+
+func NewPromotionHandler(service *services.PromotionService) *PromotionHandler {
+	return &PromotionHandler{service: service}
+}
+
+// CreatePromotion handles POST /restaurants/{restaurant_id}/promotions
+func (h *PromotionHandler) CreatePromotion(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	var request dto.PromotionDTO
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	promotion := request.ToModel(restaurantID)
+	promotionID, err := h.service.CreatePromotion(&promotion)
+	if err != nil {
+		writePromotionError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"promotion_id": promotionID})
+}
+
+// GetPromotions handles GET /restaurants/{restaurant_id}/promotions
+func (h *PromotionHandler) GetPromotions(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	promotions, err := h.service.GetPromotions(restaurantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.FromPromotions(promotions))
+}
+
+// UpdatePromotion handles PUT /restaurants/{restaurant_id}/promotions/{promotion_id}
+func (h *PromotionHandler) UpdatePromotion(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	var request dto.PromotionDTO
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	request.PromotionID = mux.Vars(r)["promotion_id"]
+	promotion := request.ToModel(restaurantID)
+	if err := h.service.UpdatePromotion(&promotion); err != nil {
+		writePromotionError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeletePromotion handles DELETE /restaurants/{restaurant_id}/promotions/{promotion_id}
+func (h *PromotionHandler) DeletePromotion(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	if err := h.service.DeletePromotion(restaurantID, mux.Vars(r)["promotion_id"]); err != nil {
+		writePromotionError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writePromotionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidPromotion):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrPromotionNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
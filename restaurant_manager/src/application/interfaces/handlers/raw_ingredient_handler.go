@@ -112,7 +112,7 @@ func (h *RawIngredientsHandler) DeleteRawIngredients(w http.ResponseWriter, r *h
 		http.Error(w, "raw_ingredient_id is required", http.StatusBadRequest)
 		return
 	}
-	if err := h.service.DeleteRawIngredient(id); err != nil {
+	if err := h.service.DeleteRawIngredient(restaurantID, id); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"restaurant_manager/src/application/services"
+	"restaurant_manager/src/domain/models"
+
+	"github.com/gorilla/mux"
+)
+
+type ReceiptHandler struct {
+	service *services.ReceiptService
+}
+
+func NewReceiptHandler(service *services.ReceiptService) *ReceiptHandler {
+	return &ReceiptHandler{service: service}
+}
+
+// GetReceipt handles GET /orders/{order_id}/receipt?format=pdf|escpos|text,
+// sending the order's bill as plain text unless another format is asked for.
+func (h *ReceiptHandler) GetReceipt(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	format := ticketFormat(r)
+	receipt, err := h.service.GetReceipt(orderCustomer(r), restaurantID, mux.Vars(r)["order_id"], format)
+	if err != nil {
+		writeReceiptError(w, err)
+		return
+	}
+	writeTickets(w, format, "receipt-"+mux.Vars(r)["order_id"], receipt)
+}
+
+// PrintKitchenTickets handles POST /orders/{order_id}/kitchen-tickets, sending
+// a ticket for each station, or only the one named by station_id, with the
+// items it has not been sent yet. It answers 204 when there is nothing new.
+func (h *ReceiptHandler) PrintKitchenTickets(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	format := ticketFormat(r)
+	tickets, count, err := h.service.PrintKitchenTickets(restaurantID, mux.Vars(r)["order_id"], r.URL.Query().Get("station_id"), format)
+	if err != nil {
+		writeReceiptError(w, err)
+		return
+	}
+	if count == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeTickets(w, format, "kitchen-"+mux.Vars(r)["order_id"], tickets)
+}
+
+// ticketFormat reads the format tickets are asked for in, text by default.
+func ticketFormat(r *http.Request) models.TicketFormat {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return models.TicketFormat(format)
+	}
+	return models.TicketText
+}
+
+func writeTickets(w http.ResponseWriter, format models.TicketFormat, name string, tickets []byte) {
+	w.Header().Set("Content-Type", format.ContentType())
+	switch format {
+	case models.TicketPDF:
+		w.Header().Set("Content-Disposition", `inline; filename="`+name+`.pdf"`)
+	case models.TicketEscPos:
+		w.Header().Set("Content-Disposition", `attachment; filename="`+name+`.bin"`)
+	}
+	w.Write(tickets)
+}
+
+func writeReceiptError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidTicketFormat):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrOrderNotFound), errors.Is(err, services.ErrStationNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
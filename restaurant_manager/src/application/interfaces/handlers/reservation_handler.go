@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"restaurant_manager/src/application/interfaces/handlers/dto"
+	"restaurant_manager/src/application/services"
+	"restaurant_manager/src/application/utils"
+	"restaurant_manager/src/domain/models"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type ReservationHandler struct {
+	service *services.ReservationService
+}
+
+func NewReservationHandler(service *services.ReservationService) *ReservationHandler {
+	return &ReservationHandler{service: service}
+}
+
+// CreateReservation handles POST /restaurants/{restaurant_id}/reservations
+func (h *ReservationHandler) CreateReservation(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	var request dto.ReservationRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	reservation := request.ToModel(restaurantID, "")
+	reservationID, err := h.service.CreateReservation(&reservation)
+	if err != nil {
+		writeReservationError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"reservation_id": reservationID})
+}
+
+// GetReservations handles GET /restaurants/{restaurant_id}/reservations
+// Query params: date (YYYY-MM-DD, defaults to today in the restaurant's time zone)
+func (h *ReservationHandler) GetReservations(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	date := utils.GetCurrentUTCTime()
+	if dateStr := r.URL.Query().Get("date"); dateStr != "" {
+		parsed, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			http.Error(w, "Invalid date format", http.StatusBadRequest)
+			return
+		}
+		date = parsed
+	}
+
+	reservations, err := h.service.GetReservations(restaurantID, date)
+	if err != nil {
+		writeReservationError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.FromReservations(reservations))
+}
+
+// UpdateReservation handles PUT /restaurants/{restaurant_id}/reservations/{reservation_id}
+func (h *ReservationHandler) UpdateReservation(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	var request dto.ReservationRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	reservation := request.ToModel(restaurantID, mux.Vars(r)["reservation_id"])
+	if err := h.service.UpdateReservation(&reservation); err != nil {
+		writeReservationError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UpdateReservationStatus handles PUT /restaurants/{restaurant_id}/reservations/{reservation_id}/status
+func (h *ReservationHandler) UpdateReservationStatus(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	var request dto.ReservationStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	reservation, err := h.service.UpdateReservationStatus(restaurantID, mux.Vars(r)["reservation_id"], models.ReservationStatus(request.Status))
+	if err != nil {
+		writeReservationError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.FromReservation(*reservation))
+}
+
+// AddToWaitlist handles POST /restaurants/{restaurant_id}/waitlist
+func (h *ReservationHandler) AddToWaitlist(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	var request dto.WaitlistRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	entry := request.ToModel(restaurantID)
+	position, err := h.service.AddToWaitlist(&entry, request.QuotedWaitMinutes)
+	if err != nil {
+		writeReservationError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(dto.FromWaitlistEntry(entry, position, utils.GetCurrentUTCTime()))
+}
+
+// GetWaitlist handles GET /restaurants/{restaurant_id}/waitlist
+func (h *ReservationHandler) GetWaitlist(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	entries, err := h.service.GetWaitlist(restaurantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.FromWaitlist(entries, utils.GetCurrentUTCTime()))
+}
+
+// UpdateWaitlistEntryStatus handles PUT /restaurants/{restaurant_id}/waitlist/{entry_id}/status
+func (h *ReservationHandler) UpdateWaitlistEntryStatus(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	var request dto.WaitlistStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	entry, err := h.service.UpdateWaitlistEntryStatus(restaurantID, mux.Vars(r)["entry_id"], models.WaitlistStatus(request.Status), request.TableID)
+	if err != nil {
+		writeReservationError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.FromWaitlistEntry(*entry, 0, utils.GetCurrentUTCTime()))
+}
+
+func writeReservationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidReservation), errors.Is(err, services.ErrInvalidWaitlistEntry):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrReservationNotFound), errors.Is(err, services.ErrWaitlistEntryNotFound),
+		errors.Is(err, services.ErrTableNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrReservationConflict), errors.Is(err, services.ErrReservationClosed),
+		errors.Is(err, services.ErrInvalidStatusTransition), errors.Is(err, services.ErrTableOccupied):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
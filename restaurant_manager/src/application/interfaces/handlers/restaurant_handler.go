@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"restaurant_manager/src/application/services"
 	"restaurant_manager/src/application/utils"
 	"restaurant_manager/src/domain/models"
+	"strconv"
 
 	"github.com/gorilla/mux"
 )
@@ -21,11 +23,7 @@ func NewRestaurantHandler(service *services.RestaurantService) *RestaurantHandle
 // Create a new restaurant
 func (h *RestaurantHandler) CreateRestaurant(w http.ResponseWriter, r *http.Request) {
 
-	owner := utils.TokenVerification(r, w)
-	if owner == "" {
-		http.Error(w, "Invalid token", http.StatusUnauthorized)
-		return
-	}
+	owner := utils.GetAuthContext(r).UserID
 	err := r.ParseMultipartForm(10 << 20)
 	if err != nil {
 		http.Error(w, "Unable to parse form", http.StatusBadRequest)
@@ -53,10 +51,34 @@ func (h *RestaurantHandler) CreateRestaurant(w http.ResponseWriter, r *http.Requ
 		Description: description,
 		ImageURL:    imageURL,
 		OwnerID:     owner,
+		TimeZone:    r.FormValue("time_zone"),
+		TaxRegime:   models.TaxRegime(r.FormValue("tax_regime")),
+	}
+	if included, err := strconv.ParseBool(r.FormValue("prices_include_tax")); err == nil {
+		restaurant.PricesIncludeTax = &included
+	}
+	if nit := r.FormValue("nit_number"); nit != "" {
+		restaurant.NitNumber = &nit
+	}
+	if legalName := r.FormValue("legal_name"); legalName != "" {
+		restaurant.LegalName = &legalName
+	}
+	if header := r.FormValue("receipt_header"); header != "" {
+		restaurant.ReceiptHeader = &header
+	}
+	if footer := r.FormValue("receipt_footer"); footer != "" {
+		restaurant.ReceiptFooter = &footer
+	}
+	if width, err := strconv.Atoi(r.FormValue("paper_width")); err == nil {
+		restaurant.PaperWidth = models.PaperWidth(width)
 	}
 
 	// Call the service to save the restaurant in the database
 	restaurantID, err := h.service.CreateRestaurant(&restaurant)
+	if errors.Is(err, services.ErrInvalidTimeZone) || errors.Is(err, services.ErrInvalidTaxRegime) || errors.Is(err, services.ErrInvalidPaper) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -78,7 +100,7 @@ func (h *RestaurantHandler) GetRestaurant(w http.ResponseWriter, r *http.Request
 }
 
 func (h *RestaurantHandler) GetAllRestaurant(w http.ResponseWriter, r *http.Request) {
-	ownerID := utils.TokenVerification(r, w)
+	ownerID := utils.GetAuthContext(r).UserID
 	restaurants, err := h.service.GetAllRestaurant(ownerID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
@@ -94,6 +116,10 @@ func (h *RestaurantHandler) UpdateRestaurant(w http.ResponseWriter, r *http.Requ
 	json.NewDecoder(r.Body).Decode(&restaurant)
 	restaurant.RestaurantID = restaurantID
 	err := h.service.UpdateRestaurant(&restaurant)
+	if errors.Is(err, services.ErrInvalidTimeZone) || errors.Is(err, services.ErrInvalidTaxRegime) || errors.Is(err, services.ErrInvalidPaper) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
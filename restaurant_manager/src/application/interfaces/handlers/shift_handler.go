@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"restaurant_manager/src/application/interfaces/handlers/dto"
+	"restaurant_manager/src/application/services"
+	"restaurant_manager/src/application/utils"
+	"restaurant_manager/src/domain/models"
+	"time"
+)
+
+type ShiftHandler struct {
+	service       *services.ShiftService
+	tenantService *services.TenantService
+}
+
+func NewShiftHandler(service *services.ShiftService, tenantService *services.TenantService) *ShiftHandler {
+	return &ShiftHandler{service: service, tenantService: tenantService}
+}
+
+// ClockIn handles POST /shifts/clock-in
+func (h *ShiftHandler) ClockIn(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+
+	shift, err := h.service.ClockIn(restaurantID, utils.GetAuthContext(r).UserID)
+	if err != nil {
+		writeShiftError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(dto.FromShift(*shift))
+}
+
+// ClockOut handles POST /shifts/clock-out
+func (h *ShiftHandler) ClockOut(w http.ResponseWriter, r *http.Request) {
+	shift, err := h.service.ClockOut(utils.GetAuthContext(r).UserID)
+	if err != nil {
+		writeShiftError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.FromShift(*shift))
+}
+
+// CreateShift handles POST /shifts
+func (h *ShiftHandler) CreateShift(w http.ResponseWriter, r *http.Request) {
+	var request dto.ShiftRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	restaurantID, ok := resolveRestaurant(w, r, h.tenantService, request.RestaurantID)
+	if !ok {
+		return
+	}
+
+	shift := &models.Shift{
+		UserID:       request.UserID,
+		RestaurantID: restaurantID,
+		ClockIn:      request.ClockIn.UTC(),
+		Notes:        request.Notes,
+	}
+	if request.ClockOut != nil {
+		clockOut := request.ClockOut.UTC()
+		shift.ClockOut = &clockOut
+	}
+
+	if _, err := h.service.CreateShift(shift); err != nil {
+		writeShiftError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(dto.FromShift(*shift))
+}
+
+// GetShifts handles GET /shifts
+func (h *ShiftHandler) GetShifts(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	startDate, endDate, ok := parseDateRange(w, r)
+	if !ok {
+		return
+	}
+
+	shifts, err := h.service.GetShifts(restaurantID, r.URL.Query().Get("user_id"), startDate, endDate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.FromShifts(shifts))
+}
+
+// GetHoursReport handles GET /shifts/report
+func (h *ShiftHandler) GetHoursReport(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	startDate, endDate, ok := parseDateRange(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.service.GetHoursReport(restaurantID, startDate, endDate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.FromShiftHours(report))
+}
+
+// parseDateRange reads the start_date and end_date query parameters. Both days
+// are included; the range defaults to the last 7 days.
+func parseDateRange(w http.ResponseWriter, r *http.Request) (time.Time, time.Time, bool) {
+	today := utils.GetCurrentUTCTime().Truncate(24 * time.Hour)
+	startDate := today.AddDate(0, 0, -6)
+	endDate := today
+
+	var err error
+	if value := r.URL.Query().Get("start_date"); value != "" {
+		if startDate, err = time.Parse("2006-01-02", value); err != nil {
+			http.Error(w, "Invalid start_date format", http.StatusBadRequest)
+			return time.Time{}, time.Time{}, false
+		}
+	}
+	if value := r.URL.Query().Get("end_date"); value != "" {
+		if endDate, err = time.Parse("2006-01-02", value); err != nil {
+			http.Error(w, "Invalid end_date format", http.StatusBadRequest)
+			return time.Time{}, time.Time{}, false
+		}
+	}
+	if endDate.Before(startDate) {
+		http.Error(w, "end_date must not be before start_date", http.StatusBadRequest)
+		return time.Time{}, time.Time{}, false
+	}
+	return startDate, endDate.AddDate(0, 0, 1), true
+}
+
+func writeShiftError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrAlreadyClockedIn),
+		errors.Is(err, services.ErrNotClockedIn),
+		errors.Is(err, services.ErrShiftOverlap):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, services.ErrInvalidShift):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrEmployeeNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
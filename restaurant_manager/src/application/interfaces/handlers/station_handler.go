@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"restaurant_manager/src/application/interfaces/handlers/dto"
+	"restaurant_manager/src/application/services"
+	"restaurant_manager/src/domain/models"
+
+	"github.com/gorilla/mux"
+)
+
+type StationHandler struct {
+	service       *services.StationService
+	tenantService *services.TenantService
+}
+
+func NewStationHandler(service *services.StationService, tenantService *services.TenantService) *StationHandler {
+	return &StationHandler{service: service, tenantService: tenantService}
+}
+
+// CreateStation handles POST /stations
+func (h *StationHandler) CreateStation(w http.ResponseWriter, r *http.Request) {
+	var request dto.StationRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	restaurantID, ok := resolveRestaurant(w, r, h.tenantService, request.RestaurantID)
+	if !ok {
+		return
+	}
+
+	station := &models.Station{RestaurantID: restaurantID, Name: request.Name, Kind: request.Kind}
+	stationID, err := h.service.CreateStation(station)
+	if err != nil {
+		writeStationError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"station_id": stationID})
+}
+
+// GetStations handles GET /stations
+func (h *StationHandler) GetStations(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	stations, err := h.service.GetStations(restaurantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stations)
+}
+
+// UpdateStation handles PUT /stations/{station_id}
+func (h *StationHandler) UpdateStation(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	var request dto.StationRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	station := &models.Station{
+		StationID:    mux.Vars(r)["station_id"],
+		RestaurantID: restaurantID,
+		Name:         request.Name,
+		Kind:         request.Kind,
+	}
+	if err := h.service.UpdateStation(station); err != nil {
+		writeStationError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteStation handles DELETE /stations/{station_id}
+func (h *StationHandler) DeleteStation(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteStation(restaurantID, mux.Vars(r)["station_id"]); err != nil {
+		writeStationError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetStationQueue handles GET /stations/{station_id}/queue
+func (h *StationHandler) GetStationQueue(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	queue, err := h.service.GetStationQueue(restaurantID, mux.Vars(r)["station_id"])
+	if err != nil {
+		writeStationError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.FromStationItems(queue))
+}
+
+// BumpItem handles POST /stations/{station_id}/bump
+func (h *StationHandler) BumpItem(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	var request dto.StationItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	ref := models.OrderItemRef{OrderItemID: request.OrderItemID, MenuItemID: request.MenuItemID, Observation: request.Observation}
+	err := h.service.BumpItem(restaurantID, mux.Vars(r)["station_id"], request.OrderID, ref)
+	if err != nil {
+		writeStationError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RecallItem handles POST /stations/{station_id}/recall
+func (h *StationHandler) RecallItem(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
+	item, err := h.service.RecallItem(restaurantID, mux.Vars(r)["station_id"])
+	if err != nil {
+		writeStationError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.FromStationItem(*item))
+}
+
+func writeStationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidStation):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrStationNotFound),
+		errors.Is(err, services.ErrItemNotAtStation),
+		errors.Is(err, services.ErrNothingToRecall):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrInvalidStatusTransition):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -10,17 +10,23 @@ import (
 )
 
 type TableHandler struct {
-	service *services.TableService
+	service       *services.TableService
+	tenantService *services.TenantService
 }
 
-func NewTableHandler(service *services.TableService) *TableHandler {
-	return &TableHandler{service: service}
+func NewTableHandler(service *services.TableService, tenantService *services.TenantService) *TableHandler {
+	return &TableHandler{service: service, tenantService: tenantService}
 }
 
 // Create a new table
 func (h *TableHandler) CreateTable(w http.ResponseWriter, r *http.Request) {
 	var table models.Table
 	json.NewDecoder(r.Body).Decode(&table)
+	restaurantID, ok := resolveRestaurant(w, r, h.tenantService, table.RestaurantID)
+	if !ok {
+		return
+	}
+	table.RestaurantID = restaurantID
 	tableID, err := h.service.CreateTable(&table)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -31,8 +37,12 @@ func (h *TableHandler) CreateTable(w http.ResponseWriter, r *http.Request) {
 
 // Get table details
 func (h *TableHandler) GetTable(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
 	tableID := mux.Vars(r)["table_id"]
-	table, err := h.service.GetTable(tableID)
+	table, err := h.service.GetTable(restaurantID, tableID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
@@ -55,6 +65,11 @@ func (h *TableHandler) GetTablesByRestaurantId(w http.ResponseWriter, r *http.Re
 func (h *TableHandler) UpdateTable(w http.ResponseWriter, r *http.Request) {
 	var table models.Table
 	json.NewDecoder(r.Body).Decode(&table)
+	restaurantID, ok := resolveRestaurant(w, r, h.tenantService, table.RestaurantID)
+	if !ok {
+		return
+	}
+	table.RestaurantID = restaurantID
 	err := h.service.UpdateTable(&table)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -64,8 +79,12 @@ func (h *TableHandler) UpdateTable(w http.ResponseWriter, r *http.Request) {
 
 // Delete table
 func (h *TableHandler) DeleteTable(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := restaurantScope(w, r)
+	if !ok {
+		return
+	}
 	tableID := mux.Vars(r)["table_id"]
-	err := h.service.DeleteTable(tableID)
+	err := h.service.DeleteTable(restaurantID, tableID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
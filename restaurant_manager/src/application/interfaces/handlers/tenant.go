@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"restaurant_manager/src/application/services"
+	"restaurant_manager/src/application/utils"
+	"restaurant_manager/src/domain/models"
+)
+
+// restaurantScope returns the restaurant the request is scoped to. Admins own
+// several restaurants, so on routes that only carry an entity ID they must name
+// the restaurant with the restaurant_id query parameter.
+func restaurantScope(w http.ResponseWriter, r *http.Request) (string, bool) {
+	restaurantID := utils.GetAuthContext(r).RestaurantID
+	if restaurantID == "" {
+		http.Error(w, services.ErrRestaurantRequired.Error(), http.StatusBadRequest)
+		return "", false
+	}
+	return restaurantID, true
+}
+
+// orderCustomer returns the customer whose own orders the request is limited
+// to, or an empty string when staff make it.
+func orderCustomer(r *http.Request) string {
+	if auth := utils.GetAuthContext(r); auth.Role == models.RoleCustomer {
+		return auth.UserID
+	}
+	return ""
+}
+
+// resolveRestaurant scopes the request to a restaurant named in the request
+// body, rejecting restaurants the caller may not act on.
+func resolveRestaurant(w http.ResponseWriter, r *http.Request, tenantService *services.TenantService, restaurantID string) (string, bool) {
+	restaurantID, err := tenantService.ResolveRestaurant(utils.GetAuthContext(r), restaurantID)
+	if errors.Is(err, services.ErrRestaurantForbidden) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return "", false
+	}
+	if err != nil || restaurantID == "" {
+		http.Error(w, services.ErrRestaurantRequired.Error(), http.StatusBadRequest)
+		return "", false
+	}
+	return restaurantID, true
+}
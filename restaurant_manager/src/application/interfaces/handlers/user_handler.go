@@ -2,21 +2,25 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"restaurant_manager/src/application/interfaces/handlers/dto"
 	"restaurant_manager/src/application/services"
 	"restaurant_manager/src/application/utils"
 	"restaurant_manager/src/domain/models"
 
+	"github.com/gorilla/mux"
 	"github.com/rs/zerolog/log"
 )
 
 type UserHandler struct {
-	service *services.UserService
+	service       *services.UserService
+	tenantService *services.TenantService
+	tokenService  *services.TokenService
 }
 
-func NewUserHandler(service *services.UserService) *UserHandler {
-	return &UserHandler{service: service}
+func NewUserHandler(service *services.UserService, tenantService *services.TenantService, tokenService *services.TokenService) *UserHandler {
+	return &UserHandler{service: service, tenantService: tenantService, tokenService: tokenService}
 }
 
 // writeJSONResponse writes a JSON response with the given status code
@@ -34,6 +38,20 @@ func (h *UserHandler) writeErrorResponse(w http.ResponseWriter, status int, mess
 	h.writeJSONResponse(w, status, map[string]string{"error": message})
 }
 
+// canManageUser reports whether the caller may modify the given user: either
+// themselves or a member of a restaurant they are allowed to act on.
+func (h *UserHandler) canManageUser(r *http.Request, user *models.User) bool {
+	auth := utils.GetAuthContext(r)
+	if user.UserID == auth.UserID {
+		return true
+	}
+	if user.RestaurantId == nil {
+		return false
+	}
+	_, err := h.tenantService.ResolveRestaurant(auth, *user.RestaurantId)
+	return err == nil
+}
+
 // RegisterUser handles user registration
 func (h *UserHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 	var user models.User
@@ -53,6 +71,35 @@ func (h *UserHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 	h.writeJSONResponse(w, http.StatusCreated, map[string]string{"user_id": userID})
 }
 
+// CreateStaffUser lets an admin add a waiter or kitchen account to the
+// restaurant the request is scoped to
+func (h *UserHandler) CreateStaffUser(w http.ResponseWriter, r *http.Request) {
+	var user models.User
+	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+		log.Error().Err(err).Msg("Failed to decode request body")
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	restaurantID := ""
+	if user.RestaurantId != nil {
+		restaurantID = *user.RestaurantId
+	}
+	restaurantID, ok := resolveRestaurant(w, r, h.tenantService, restaurantID)
+	if !ok {
+		return
+	}
+
+	userID, err := h.service.CreateStaffUser(&user, restaurantID)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create staff user")
+		h.writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusCreated, map[string]string{"user_id": userID})
+}
+
 // LoginUser handles user authentication
 func (h *UserHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 	var credentials struct {
@@ -73,7 +120,7 @@ func (h *UserHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := utils.GenerateJWT(user.UserID)
+	tokens, err := h.tokenService.IssueTokens(user)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to generate JWT")
 		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to generate token")
@@ -81,8 +128,10 @@ func (h *UserHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	response := map[string]interface{}{
-		"token": token,
-		"role":  user.Role,
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"role":          user.Role,
 	}
 
 	if user.Role != "admin" && user.RestaurantId != nil {
@@ -92,13 +141,203 @@ func (h *UserHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 	h.writeJSONResponse(w, http.StatusOK, response)
 }
 
-// GetUsersByRestaurantID handles retrieving users by restaurant ID
-func (h *UserHandler) GetUsersByRestaurantID(w http.ResponseWriter, r *http.Request) {
-	owner := utils.TokenVerification(r, w)
-	if owner == "" {
-		h.writeErrorResponse(w, http.StatusUnauthorized, "Invalid token")
+// RefreshToken exchanges a refresh token for a new access and refresh token pair
+func (h *UserHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		h.writeErrorResponse(w, http.StatusBadRequest, "refresh_token is required")
+		return
+	}
+
+	tokens, err := h.tokenService.Refresh(req.RefreshToken)
+	if errors.Is(err, services.ErrInvalidRefreshToken) {
+		h.writeErrorResponse(w, http.StatusUnauthorized, "Invalid or expired refresh token")
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to refresh token")
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to generate token")
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+	})
+}
+
+// Logout revokes the caller's access token and the given refresh token
+func (h *UserHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	if err := h.tokenService.Logout(utils.GetAuthContext(r), req.RefreshToken); err != nil {
+		log.Error().Err(err).Msg("Failed to logout")
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to logout")
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, map[string]string{
+		"message": "Logged out successfully",
+	})
+}
+
+// ForgotPassword mails a password reset link. It answers the same way whether
+// or not the email is registered.
+func (h *UserHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.service.RequestPasswordReset(req.Email); err != nil {
+		log.Error().Err(err).Msg("Failed to request password reset")
+		h.writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, map[string]string{
+		"message": "If the email is registered, a reset link has been sent",
+	})
+}
+
+// ResetPassword sets a new password using a token from a reset link
+func (h *UserHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	userID, err := h.service.ResetPassword(req.Token, req.Password)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to reset password")
+		h.writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Sessions opened with the old password must not outlive it
+	if err := h.tokenService.RevokeUser(userID); err != nil {
+		log.Error().Err(err).Msg("Failed to revoke user tokens")
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, map[string]string{
+		"message": "Password reset successfully",
+	})
+}
+
+// VerifyEmail confirms the user's email address using a token from a verification link
+func (h *UserHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.VerifyEmail(r.URL.Query().Get("token")); err != nil {
+		log.Error().Err(err).Msg("Failed to verify email")
+		h.writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, map[string]string{
+		"message": "Email verified successfully",
+	})
+}
+
+// SetPin sets the quick-login PIN of a staff member
+func (h *UserHandler) SetPin(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Pin string `json:"pin"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	user, err := h.service.GetUserById(mux.Vars(r)["user_id"])
+	if err != nil || !h.canManageUser(r, user) {
+		h.writeErrorResponse(w, http.StatusNotFound, "User not found")
+		return
+	}
+
+	if err := h.service.SetPin(user, req.Pin); err != nil {
+		log.Error().Err(err).Msg("Failed to set pin")
+		h.writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, map[string]string{
+		"message": "Pin updated successfully",
+	})
+}
+
+// PinLogin authenticates a staff member on a shared terminal of the restaurant
+func (h *UserHandler) PinLogin(w http.ResponseWriter, r *http.Request) {
+	restaurantID := mux.Vars(r)["restaurant_id"]
+	var req struct {
+		UserID string `json:"user_id"`
+		Pin    string `json:"pin"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
+
+	user, err := h.service.PinLogin(restaurantID, req.UserID, req.Pin)
+	if errors.Is(err, services.ErrPinLocked) {
+		h.writeErrorResponse(w, http.StatusTooManyRequests, err.Error())
+		return
+	}
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusUnauthorized, "Invalid credentials")
+		return
+	}
+
+	tokens, err := h.tokenService.IssuePinSession(user, restaurantID)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to generate JWT")
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to generate token")
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"token":         tokens.AccessToken,
+		"expires_in":    tokens.ExpiresIn,
+		"user_id":       user.UserID,
+		"name":          user.Name,
+		"role":          user.Role,
+		"restaurant_id": restaurantID,
+	})
+}
+
+// GetJWKS serves the public keys access tokens can be verified with
+func (h *UserHandler) GetJWKS(w http.ResponseWriter, r *http.Request) {
+	jwks, err := utils.JWKS()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to encode JWKS")
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to encode keys")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	w.Write(jwks)
+}
+
+// GetUsersByRestaurantID handles retrieving users by restaurant ID
+func (h *UserHandler) GetUsersByRestaurantID(w http.ResponseWriter, r *http.Request) {
 	restaurantID := r.URL.Query().Get("restaurantId")
 	role := r.URL.Query().Get("role")
 	if restaurantID == "" {
@@ -135,29 +374,40 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get existing user to preserve optional fields if not provided
-	existingUser, err := h.service.GetUserByEmail(req.Email)
-	if err != nil {
+	existingUser, err := h.service.GetUserById(userID)
+	if err != nil || !h.canManageUser(r, existingUser) {
 		log.Error().Err(err).Msg("User not found")
 		h.writeErrorResponse(w, http.StatusNotFound, "User not found")
 		return
 	}
 
 	user := &models.User{
-		UserID:    userID,
-		Name:      req.Name,
-		Email:     req.Email,
-		Phone:     req.Phone,
-		Role:      existingUser.Role,
-		IdNumber:  existingUser.IdNumber,
-		NitNumber: existingUser.NitNumber,
+		UserID:       userID,
+		Name:         req.Name,
+		Email:        req.Email,
+		Phone:        req.Phone,
+		PasswordHash: req.PasswordHash,
+		Role:         existingUser.Role,
+		IdNumber:     existingUser.IdNumber,
+		NitNumber:    existingUser.NitNumber,
 	}
 
-	if err := h.service.UpdateUser(user); err != nil {
+	if err := h.service.UpdateUser(user); errors.Is(err, services.ErrPasswordPolicy) {
+		h.writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	} else if err != nil {
 		log.Error().Err(err).Msg("Failed to update user")
 		h.writeErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
+	// Sessions opened with the old password must not outlive it
+	if req.PasswordHash != "" {
+		if err := h.tokenService.RevokeUser(userID); err != nil {
+			log.Error().Err(err).Msg("Failed to revoke user tokens")
+		}
+	}
+
 	h.writeJSONResponse(w, http.StatusOK, map[string]string{
 		"message": "User updated successfully",
 	})
@@ -170,13 +420,19 @@ func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err := h.service.GetUserById(userID)
-	if err != nil {
+	existingUser, err := h.service.GetUserById(userID)
+	if err != nil || !h.canManageUser(r, existingUser) {
 		log.Error().Err(err).Msg("User not found")
 		h.writeErrorResponse(w, http.StatusNotFound, "User not found")
 		return
 	}
 
+	if err := h.tokenService.RevokeUser(userID); err != nil {
+		log.Error().Err(err).Msg("Failed to revoke user tokens")
+		h.writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
 	if err := h.service.DeleteUser(userID); err != nil {
 		log.Error().Err(err).Msg("Failed to delete user")
 		h.writeErrorResponse(w, http.StatusInternalServerError, err.Error())
@@ -0,0 +1,80 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"restaurant_manager/src/application/services"
+	"restaurant_manager/src/application/utils"
+
+	"github.com/gorilla/mux"
+)
+
+type AuthMiddleware struct {
+	tenantService *services.TenantService
+}
+
+func NewAuthMiddleware(tenantService *services.TenantService) *AuthMiddleware {
+	return &AuthMiddleware{tenantService: tenantService}
+}
+
+// Authorize verifies the bearer token and rejects the request
+// unless the caller has one of the given roles. Routes without roles are public.
+// When the request names a restaurant, the caller must be allowed to act on it
+// and the request is scoped to that restaurant.
+func (m *AuthMiddleware) Authorize(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(roles) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Role and restaurant come from the token claims, so no lookup is needed
+			auth, err := utils.VerifyRequestToken(r)
+			if err != nil {
+				writeAuthError(w, http.StatusUnauthorized, "Invalid or expired token")
+				return
+			}
+
+			if !hasRole(auth.Role, roles) {
+				writeAuthError(w, http.StatusForbidden, "Insufficient permissions")
+				return
+			}
+
+			restaurantID, err := m.tenantService.ResolveRestaurant(auth, requestedRestaurantID(r))
+			if err != nil {
+				writeAuthError(w, http.StatusForbidden, "Access to this restaurant is not allowed")
+				return
+			}
+			auth.RestaurantID = restaurantID
+
+			next.ServeHTTP(w, r.WithContext(utils.WithAuthContext(r.Context(), auth)))
+		})
+	}
+}
+
+// requestedRestaurantID returns the restaurant named in the path or query string.
+func requestedRestaurantID(r *http.Request) string {
+	if restaurantID := mux.Vars(r)["restaurant_id"]; restaurantID != "" {
+		return restaurantID
+	}
+	if restaurantID := r.URL.Query().Get("restaurant_id"); restaurantID != "" {
+		return restaurantID
+	}
+	return r.URL.Query().Get("restaurantId")
+}
+
+func hasRole(role string, roles []string) bool {
+	for _, allowed := range roles {
+		if role == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func writeAuthError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
@@ -5,6 +5,7 @@ import (
 	"log"
 	"net/http"
 	"restaurant_manager/src/application/interfaces/handlers"
+	"restaurant_manager/src/domain/models"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -32,7 +33,7 @@ func accessControlMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET,POST,OPTIONS,PUT,DELETE")
-		w.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization, Last-Event-ID")
 
 		if r.Method == "OPTIONS" {
 			return
@@ -42,7 +43,27 @@ func accessControlMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-func SetupRoutes(userHandler *handlers.UserHandler,
+// route describes an endpoint and the roles allowed to call it. A route
+// without roles is public.
+type route struct {
+	path    string
+	method  string
+	handler http.HandlerFunc
+	roles   []string
+}
+
+var (
+	public       []string
+	adminOnly    = []string{models.RoleAdmin}
+	staff        = []string{models.RoleAdmin, models.RoleWaiter}
+	kitchenStaff = []string{models.RoleAdmin, models.RoleWaiter, models.RoleKitchen}
+	ordering     = []string{models.RoleAdmin, models.RoleWaiter, models.RoleCustomer}
+	crew         = []string{models.RoleWaiter, models.RoleKitchen}
+	anyRole      = []string{models.RoleAdmin, models.RoleWaiter, models.RoleCustomer, models.RoleKitchen}
+)
+
+func SetupRoutes(authMiddleware *AuthMiddleware,
+	userHandler *handlers.UserHandler,
 	restaurantHandler *handlers.RestaurantHandler,
 	menuHandler *handlers.MenuHandler,
 	orderHandler *handlers.OrderHandler,
@@ -50,7 +71,16 @@ func SetupRoutes(userHandler *handlers.UserHandler,
 	inventoryHandler *handlers.InventoryHandler,
 	ingredientHandler *handlers.IngredientHandler,
 	rawIngredientsHandler *handlers.RawIngredientsHandler,
-	cashClosingHandler *handlers.CashClosingHandler) *mux.Router {
+	cashClosingHandler *handlers.CashClosingHandler,
+	shiftHandler *handlers.ShiftHandler,
+	auditHandler *handlers.AuditHandler,
+	eventHandler *handlers.EventHandler,
+	stationHandler *handlers.StationHandler,
+	paymentHandler *handlers.PaymentHandler,
+	promotionHandler *handlers.PromotionHandler,
+	invoiceHandler *handlers.InvoiceHandler,
+	receiptHandler *handlers.ReceiptHandler,
+	reservationHandler *handlers.ReservationHandler) *mux.Router {
 
 	r := mux.NewRouter()
 
@@ -64,51 +94,125 @@ func SetupRoutes(userHandler *handlers.UserHandler,
 		json.NewEncoder(w).Encode(map[string]string{"status": "healthy"})
 	}).Methods("GET")
 
-	r.HandleFunc("/register", userHandler.RegisterUser).Methods("POST", "OPTIONS")
-	r.HandleFunc("/login", userHandler.LoginUser).Methods("POST", "OPTIONS")
-	r.HandleFunc("/users", userHandler.GetUsersByRestaurantID).Methods("GET", "OPTIONS")
-	r.HandleFunc("/users", userHandler.UpdateUser).Methods("PUT", "OPTIONS")
-	r.HandleFunc("/users", userHandler.DeleteUser).Methods("DELETE", "OPTIONS")
-	r.HandleFunc("/restaurants", restaurantHandler.CreateRestaurant).Methods("POST", "OPTIONS")
-	r.HandleFunc("/restaurants", restaurantHandler.GetAllRestaurant).Methods("GET", "OPTIONS")
-	r.HandleFunc("/restaurants/{restaurant_id}", restaurantHandler.UpdateRestaurant).Methods("PUT", "OPTIONS")
-	r.HandleFunc("/restaurants/{restaurant_id}", restaurantHandler.DeleteRestaurant).Methods("DELETE", "OPTIONS")
-	r.HandleFunc("/menus/{restaurant_id}/items", menuHandler.AddMenuItem).Methods("POST", "OPTIONS")
-	r.HandleFunc("/menus/{restaurant_id}/items", menuHandler.GetAllMenuItems).Methods("GET", "OPTIONS")
-	r.HandleFunc("/menus/{restaurant_id}/items/{menu_item_id}", menuHandler.UpdateMenuItem).Methods("PUT", "OPTIONS")
-	r.HandleFunc("/menus/{restaurant_id}/items/{menu_item_id}", menuHandler.DeleteMenuItem).Methods("DELETE", "OPTIONS")
-	r.HandleFunc("/orders", orderHandler.CreateOrder).Methods("POST", "OPTIONS")
-	r.HandleFunc("/orders", orderHandler.UpdateOrder).Methods("PUT", "OPTIONS")
-	r.HandleFunc("/orders", orderHandler.GetOrderByRestaurantID).Methods("GET", "OPTIONS")
-	r.HandleFunc("/orders/{orders_id}", orderHandler.GetOrder).Methods("GET", "OPTIONS")
-	r.HandleFunc("/orders/{orders_id}", orderHandler.DeleteOrder).Methods("DELETE", "OPTIONS")
-	r.HandleFunc("/orders/{order_id}/items", orderHandler.AddOrderItem).Methods("POST", "OPTIONS")
-	r.HandleFunc("/orders/{order_id}/items/{menu_item_id}", orderHandler.UpdateOrderItem).Methods("PUT", "OPTIONS")
-	r.HandleFunc("/orders/{order_id}/items/{menu_item_id}", orderHandler.DeleteOrderItem).Methods("DELETE", "OPTIONS")
-	r.HandleFunc("/orders/{order_id}/items", orderHandler.GetOrderItems).Methods("GET", "OPTIONS")
-	r.HandleFunc("/orders/{order_id}/items/{menu_item_id}/void", orderHandler.CreateVoidOrderItem).Methods("POST", "OPTIONS")
-	r.HandleFunc("/restaurants/{restaurant_id}/order-items/void", orderHandler.GetVoidOrderItems).Methods("GET", "OPTIONS")
-	r.HandleFunc("/void-order-items/{void_order_item_id}/recover", orderHandler.RecoverVoidOrderItem).Methods("POST", "OPTIONS")
-	r.HandleFunc("/tables", tableHandler.CreateTable).Methods("POST", "OPTIONS")
-	r.HandleFunc("/tables/{table_id}", tableHandler.GetTable).Methods("GET", "OPTIONS")
-	r.HandleFunc("/tables", tableHandler.GetTablesByRestaurantId).Methods("GET", "OPTIONS")
-	r.HandleFunc("/tables/{table_id}", tableHandler.UpdateTable).Methods("PUT", "OPTIONS")
-	r.HandleFunc("/tables/{table_id}", tableHandler.DeleteTable).Methods("DELETE", "OPTIONS")
-	r.HandleFunc("/inventory", inventoryHandler.CreateInventory).Methods("POST", "OPTIONS")
-	r.HandleFunc("/inventory", inventoryHandler.GetInventoryByRestaurantID).Methods("GET", "OPTIONS")
-	r.HandleFunc("/inventory", inventoryHandler.UpdateInventory).Methods("PUT", "OPTIONS")
-	r.HandleFunc("/ingredients", ingredientHandler.GetIngredientsByRestaurantID).Methods("GET", "OPTIONS")
-	r.HandleFunc("/raw-ingredients", rawIngredientsHandler.GetByCategory).Methods("GET", "OPTIONS")
-	r.HandleFunc("/raw-ingredients/upload", rawIngredientsHandler.UploadRawIngredientsCSV).Methods("POST", "OPTIONS")
-	r.HandleFunc("/raw-ingredients", rawIngredientsHandler.UpdateRawIngredients).Methods("PUT", "OPTIONS")
-	r.HandleFunc("/raw-ingredients/{raw_ingredient_id}", rawIngredientsHandler.DeleteRawIngredients).Methods("DELETE", "OPTIONS")
-
-	// Cash Closing routes
-	r.HandleFunc("/cash-closings", cashClosingHandler.CreateCashClosing).Methods("POST", "OPTIONS")
-	r.HandleFunc("/cash-closings/data", cashClosingHandler.GetCashClosingData).Methods("GET", "OPTIONS")
-	r.HandleFunc("/cash-closings/history", cashClosingHandler.GetCashClosingHistory).Methods("GET", "OPTIONS")
-	r.HandleFunc("/cash-closings/{id}", cashClosingHandler.UpdateCashClosing).Methods("PUT", "OPTIONS")
-	r.HandleFunc("/cash-closings/{id}", cashClosingHandler.DeleteCashClosing).Methods("DELETE", "OPTIONS")
-	r.HandleFunc("/cash-closings/stats", cashClosingHandler.GetCashClosingStats).Methods("GET", "OPTIONS")
+	routes := []route{
+		{"/register", "POST", userHandler.RegisterUser, public},
+		{"/login", "POST", userHandler.LoginUser, public},
+		{"/token/refresh", "POST", userHandler.RefreshToken, public},
+		{"/logout", "POST", userHandler.Logout, anyRole},
+		{"/.well-known/jwks.json", "GET", userHandler.GetJWKS, public},
+		{"/password/forgot", "POST", userHandler.ForgotPassword, public},
+		{"/password/reset", "POST", userHandler.ResetPassword, public},
+		{"/verify-email", "GET", userHandler.VerifyEmail, public},
+		{"/users", "GET", userHandler.GetUsersByRestaurantID, adminOnly},
+		{"/users", "POST", userHandler.CreateStaffUser, adminOnly},
+		{"/users", "PUT", userHandler.UpdateUser, adminOnly},
+		{"/users", "DELETE", userHandler.DeleteUser, adminOnly},
+		{"/users/{user_id}/pin", "PUT", userHandler.SetPin, adminOnly},
+		{"/restaurants/{restaurant_id}/pin-login", "POST", userHandler.PinLogin, public},
+		{"/restaurants", "POST", restaurantHandler.CreateRestaurant, adminOnly},
+		{"/restaurants", "GET", restaurantHandler.GetAllRestaurant, adminOnly},
+		{"/restaurants/{restaurant_id}", "PUT", restaurantHandler.UpdateRestaurant, adminOnly},
+		{"/restaurants/{restaurant_id}", "DELETE", restaurantHandler.DeleteRestaurant, adminOnly},
+		{"/menus/{restaurant_id}/items", "POST", menuHandler.AddMenuItem, adminOnly},
+		{"/menus/{restaurant_id}/items", "GET", menuHandler.GetAllMenuItems, anyRole},
+		{"/menus/{restaurant_id}/items/{menu_item_id}", "PUT", menuHandler.UpdateMenuItem, adminOnly},
+		{"/menus/{restaurant_id}/items/{menu_item_id}", "DELETE", menuHandler.DeleteMenuItem, adminOnly},
+		{"/menus/{restaurant_id}/items/{menu_item_id}/modifier-groups", "POST", menuHandler.CreateModifierGroup, adminOnly},
+		{"/menus/{restaurant_id}/items/{menu_item_id}/modifier-groups", "GET", menuHandler.GetModifierGroups, anyRole},
+		{"/menus/{restaurant_id}/items/{menu_item_id}/modifier-groups/{modifier_group_id}", "DELETE", menuHandler.DeleteModifierGroup, adminOnly},
+		{"/menus/{restaurant_id}/items/{menu_item_id}/combo-slots", "PUT", menuHandler.ReplaceComboSlots, adminOnly},
+		{"/orders", "POST", orderHandler.CreateOrder, ordering},
+		{"/orders", "PUT", orderHandler.UpdateOrder, kitchenStaff},
+		{"/orders", "GET", orderHandler.GetOrderByRestaurantID, kitchenStaff},
+		{"/orders/{orders_id}", "GET", orderHandler.GetOrder, anyRole},
+		{"/orders/{orders_id}", "DELETE", orderHandler.DeleteOrder, adminOnly},
+		{"/orders/{orders_id}/history", "GET", orderHandler.GetOrderStatusHistory, kitchenStaff},
+		{"/orders/{order_id}/items", "POST", orderHandler.AddOrderItem, ordering},
+		{"/orders/{order_id}/items/{menu_item_id}", "PUT", orderHandler.UpdateOrderItem, kitchenStaff},
+		{"/orders/{order_id}/items/{menu_item_id}", "DELETE", orderHandler.DeleteOrderItem, staff},
+		{"/orders/{order_id}/items", "GET", orderHandler.GetOrderItems, anyRole},
+		{"/orders/{order_id}/courses/{course}/fire", "POST", orderHandler.FireCourse, staff},
+		{"/orders/{order_id}/transfer", "POST", orderHandler.TransferOrder, staff},
+		{"/orders/merge", "POST", orderHandler.MergeOrders, staff},
+		{"/orders/{order_id}/items/move", "POST", orderHandler.MoveOrderItems, staff},
+		{"/orders/{order_id}/items/{menu_item_id}/void", "POST", orderHandler.CreateVoidOrderItem, staff},
+		{"/orders/{order_id}/payments", "POST", paymentHandler.RecordPayment, staff},
+		{"/orders/{order_id}/balance", "GET", paymentHandler.GetBalance, staff},
+		{"/orders/{order_id}/coupons", "POST", orderHandler.RedeemCoupon, ordering},
+		{"/orders/{order_id}/invoice", "POST", invoiceHandler.IssueInvoice, ordering},
+		{"/orders/{order_id}/invoice", "GET", invoiceHandler.DownloadInvoice, ordering},
+		{"/orders/{order_id}/receipt", "GET", receiptHandler.GetReceipt, ordering},
+		{"/orders/{order_id}/kitchen-tickets", "POST", receiptHandler.PrintKitchenTickets, kitchenStaff},
+		{"/restaurants/{restaurant_id}/kitchen-performance", "GET", orderHandler.GetKitchenPerformance, adminOnly},
+		{"/restaurants/{restaurant_id}/service-charge-rules", "POST", paymentHandler.CreateServiceChargeRule, adminOnly},
+		{"/restaurants/{restaurant_id}/service-charge-rules", "GET", paymentHandler.GetServiceChargeRules, staff},
+		{"/restaurants/{restaurant_id}/service-charge-rules/{rule_id}", "PUT", paymentHandler.UpdateServiceChargeRule, adminOnly},
+		{"/restaurants/{restaurant_id}/service-charge-rules/{rule_id}", "DELETE", paymentHandler.DeleteServiceChargeRule, adminOnly},
+		{"/restaurants/{restaurant_id}/tips", "GET", paymentHandler.GetTipShares, adminOnly},
+		{"/restaurants/{restaurant_id}/promotions", "POST", promotionHandler.CreatePromotion, adminOnly},
+		{"/restaurants/{restaurant_id}/promotions", "GET", promotionHandler.GetPromotions, staff},
+		{"/restaurants/{restaurant_id}/promotions/{promotion_id}", "PUT", promotionHandler.UpdatePromotion, adminOnly},
+		{"/restaurants/{restaurant_id}/promotions/{promotion_id}", "DELETE", promotionHandler.DeletePromotion, adminOnly},
+		{"/restaurants/{restaurant_id}/invoice-resolutions", "POST", invoiceHandler.CreateInvoiceResolution, adminOnly},
+		{"/restaurants/{restaurant_id}/invoice-resolutions", "GET", invoiceHandler.GetInvoiceResolutions, adminOnly},
+		{"/restaurants/{restaurant_id}/order-items/void", "GET", orderHandler.GetVoidOrderItems, staff},
+		{"/void-order-items/{void_order_item_id}/recover", "POST", orderHandler.RecoverVoidOrderItem, staff},
+		{"/tables", "POST", tableHandler.CreateTable, adminOnly},
+		{"/tables/{table_id}", "GET", tableHandler.GetTable, kitchenStaff},
+		{"/tables", "GET", tableHandler.GetTablesByRestaurantId, staff},
+		{"/tables/{table_id}", "PUT", tableHandler.UpdateTable, staff},
+		{"/tables/{table_id}", "DELETE", tableHandler.DeleteTable, adminOnly},
+		{"/inventory", "POST", inventoryHandler.CreateInventory, adminOnly},
+		{"/inventory", "GET", inventoryHandler.GetInventoryByRestaurantID, adminOnly},
+		{"/inventory", "PUT", inventoryHandler.UpdateInventory, adminOnly},
+		{"/ingredients", "GET", ingredientHandler.GetIngredientsByRestaurantID, adminOnly},
+		{"/raw-ingredients", "GET", rawIngredientsHandler.GetByCategory, adminOnly},
+		{"/raw-ingredients/upload", "POST", rawIngredientsHandler.UploadRawIngredientsCSV, adminOnly},
+		{"/raw-ingredients", "PUT", rawIngredientsHandler.UpdateRawIngredients, adminOnly},
+		{"/raw-ingredients/{raw_ingredient_id}", "DELETE", rawIngredientsHandler.DeleteRawIngredients, adminOnly},
+
+		// Cash Closing routes
+		{"/cash-closings", "POST", cashClosingHandler.CreateCashClosing, adminOnly},
+		{"/cash-closings/data", "GET", cashClosingHandler.GetCashClosingData, adminOnly},
+		{"/cash-closings/history", "GET", cashClosingHandler.GetCashClosingHistory, adminOnly},
+		{"/cash-closings/{id}", "PUT", cashClosingHandler.UpdateCashClosing, adminOnly},
+		{"/cash-closings/{id}", "DELETE", cashClosingHandler.DeleteCashClosing, adminOnly},
+		{"/cash-closings/stats", "GET", cashClosingHandler.GetCashClosingStats, adminOnly},
+
+		// Shift routes
+		{"/shifts/clock-in", "POST", shiftHandler.ClockIn, crew},
+		{"/shifts/clock-out", "POST", shiftHandler.ClockOut, crew},
+		{"/shifts", "POST", shiftHandler.CreateShift, adminOnly},
+		{"/shifts", "GET", shiftHandler.GetShifts, adminOnly},
+		{"/shifts/report", "GET", shiftHandler.GetHoursReport, adminOnly},
+
+		// Audit routes
+		{"/restaurants/{restaurant_id}/audit", "GET", auditHandler.GetAuditEvents, adminOnly},
+
+		// Live order events
+		{"/restaurants/{restaurant_id}/events", "GET", eventHandler.StreamEvents, kitchenStaff},
+
+		// Kitchen station routes
+		{"/stations", "POST", stationHandler.CreateStation, adminOnly},
+		{"/stations", "GET", stationHandler.GetStations, kitchenStaff},
+		{"/stations/{station_id}", "PUT", stationHandler.UpdateStation, adminOnly},
+		{"/stations/{station_id}", "DELETE", stationHandler.DeleteStation, adminOnly},
+		{"/stations/{station_id}/queue", "GET", stationHandler.GetStationQueue, kitchenStaff},
+		{"/stations/{station_id}/bump", "POST", stationHandler.BumpItem, kitchenStaff},
+		{"/stations/{station_id}/recall", "POST", stationHandler.RecallItem, kitchenStaff},
+
+		// Reservation and waitlist routes
+		{"/restaurants/{restaurant_id}/reservations", "POST", reservationHandler.CreateReservation, staff},
+		{"/restaurants/{restaurant_id}/reservations", "GET", reservationHandler.GetReservations, staff},
+		{"/restaurants/{restaurant_id}/reservations/{reservation_id}", "PUT", reservationHandler.UpdateReservation, staff},
+		{"/restaurants/{restaurant_id}/reservations/{reservation_id}/status", "PUT", reservationHandler.UpdateReservationStatus, staff},
+		{"/restaurants/{restaurant_id}/waitlist", "POST", reservationHandler.AddToWaitlist, staff},
+		{"/restaurants/{restaurant_id}/waitlist", "GET", reservationHandler.GetWaitlist, staff},
+		{"/restaurants/{restaurant_id}/waitlist/{entry_id}/status", "PUT", reservationHandler.UpdateWaitlistEntryStatus, staff},
+	}
+
+	for _, rt := range routes {
+		r.Handle(rt.path, authMiddleware.Authorize(rt.roles...)(rt.handler)).Methods(rt.method, "OPTIONS")
+	}
 	return r
 }
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"encoding/json"
+	"reflect"
+	"restaurant_manager/src/domain/models"
+	"restaurant_manager/src/domain/repositories"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 500
+)
+
+// Fields that change on every write and would only add noise to a diff.
+var ignoredAuditFields = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+}
+
+type AuditService struct {
+	repo repositories.AuditRepository
+}
+
+func NewAuditService(repo repositories.AuditRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// Record appends an audit event. before and after are snapshots of the entity,
+// nil when it did not exist, and only the fields that differ are stored. An
+// empty actorID records an action taken by the system. Failures are logged
+// rather than returned so auditing never fails an action that already happened.
+func (s *AuditService) Record(actorID string, restaurantID string, entityType string, entityID string, action string, before interface{}, after interface{}) {
+	beforeDiff, afterDiff, err := diffSnapshots(before, after)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to diff audit snapshots")
+		return
+	}
+	if action == models.AuditActionUpdate && beforeDiff == nil && afterDiff == nil {
+		return
+	}
+
+	event := &models.AuditEvent{
+		RestaurantID: restaurantID,
+		EntityType:   entityType,
+		EntityID:     entityID,
+		Action:       action,
+		Before:       beforeDiff,
+		After:        afterDiff,
+	}
+	if actorID != "" {
+		event.ActorID = &actorID
+	}
+	if err := s.repo.CreateAuditEvent(event); err != nil {
+		log.Error().Err(err).
+			Str("entity_type", entityType).
+			Str("entity_id", entityID).
+			Str("action", action).
+			Msg("Failed to record audit event")
+	}
+}
+
+func (s *AuditService) GetAuditEvents(filter models.AuditFilter) ([]models.AuditEvent, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditLimit
+	}
+	if filter.Limit > maxAuditLimit {
+		filter.Limit = maxAuditLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	return s.repo.GetAuditEvents(filter)
+}
+
+// diffSnapshots returns the JSON of the fields that differ between before and
+// after, as seen by each side.
+func diffSnapshots(before interface{}, after interface{}) (*string, *string, error) {
+	beforeFields, err := toAuditFields(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	afterFields, err := toAuditFields(after)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	beforeDiff := map[string]interface{}{}
+	afterDiff := map[string]interface{}{}
+	for key, value := range beforeFields {
+		if other, ok := afterFields[key]; !ok || !reflect.DeepEqual(value, other) {
+			beforeDiff[key] = value
+		}
+	}
+	for key, value := range afterFields {
+		if other, ok := beforeFields[key]; !ok || !reflect.DeepEqual(value, other) {
+			afterDiff[key] = value
+		}
+	}
+
+	beforeJSON, err := marshalAuditFields(beforeDiff)
+	if err != nil {
+		return nil, nil, err
+	}
+	afterJSON, err := marshalAuditFields(afterDiff)
+	if err != nil {
+		return nil, nil, err
+	}
+	return beforeJSON, afterJSON, nil
+}
+
+func toAuditFields(snapshot interface{}) (map[string]interface{}, error) {
+	if snapshot == nil || reflect.ValueOf(snapshot).Kind() == reflect.Ptr && reflect.ValueOf(snapshot).IsNil() {
+		return map[string]interface{}{}, nil
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for key := range ignoredAuditFields {
+		delete(fields, key)
+	}
+	return fields, nil
+}
+
+func marshalAuditFields(fields map[string]interface{}) (*string, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	value := string(data)
+	return &value, nil
+}
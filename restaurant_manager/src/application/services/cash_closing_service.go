@@ -1,6 +1,7 @@
 package services
 
 import (
+	"errors"
 	"restaurant_manager/src/domain/models"
 	"restaurant_manager/src/domain/repositories"
 	"time"
@@ -8,21 +9,29 @@ import (
 	"github.com/google/uuid"
 )
 
+var ErrCashClosingNotFound = errors.New("cash closing not found")
+
 type CashClosingService struct {
 	cashClosingRepo repositories.CashClosingRepository
 	orderRepo       repositories.OrderRepository
 	menuRepo        repositories.MenuRepository
+	paymentRepo     repositories.PaymentRepository
+	auditService    *AuditService
 }
 
 func NewCashClosingService(
 	cashClosingRepo repositories.CashClosingRepository,
 	orderRepo repositories.OrderRepository,
 	menuRepo repositories.MenuRepository,
+	paymentRepo repositories.PaymentRepository,
+	auditService *AuditService,
 ) *CashClosingService {
 	return &CashClosingService{
 		cashClosingRepo: cashClosingRepo,
 		orderRepo:       orderRepo,
 		menuRepo:        menuRepo,
+		paymentRepo:     paymentRepo,
+		auditService:    auditService,
 	}
 }
 
@@ -48,24 +57,59 @@ func (s *CashClosingService) GetCashClosingHistory(restaurantID string, startDat
 	return s.cashClosingRepo.GetCashClosingHistory(restaurantID, startDate, endDate)
 }
 
-func (s *CashClosingService) UpdateCashClosing(cashClosing *models.CashClosing) error {
+func (s *CashClosingService) UpdateCashClosing(actorID string, cashClosing *models.CashClosing) error {
+	before, err := s.cashClosingRepo.GetCashClosingByID(cashClosing.RestaurantID, cashClosing.CashClosingID)
+	if err != nil {
+		return ErrCashClosingNotFound
+	}
+
 	cashClosing.UpdatedAt = time.Now()
-	return s.cashClosingRepo.UpdateCashClosing(cashClosing)
+	if err := s.cashClosingRepo.UpdateCashClosing(cashClosing); err != nil {
+		return err
+	}
+
+	s.auditService.Record(actorID, cashClosing.RestaurantID, models.AuditEntityCashClosing, cashClosing.CashClosingID, models.AuditActionUpdate, before, cashClosing)
+	return nil
 }
 
-func (s *CashClosingService) DeleteCashClosing(cashClosingID string) error {
-	return s.cashClosingRepo.DeleteCashClosing(cashClosingID)
+func (s *CashClosingService) DeleteCashClosing(actorID string, restaurantID string, cashClosingID string) error {
+	before, err := s.cashClosingRepo.GetCashClosingByID(restaurantID, cashClosingID)
+	if err != nil {
+		return ErrCashClosingNotFound
+	}
+
+	if err := s.cashClosingRepo.DeleteCashClosing(restaurantID, cashClosingID); err != nil {
+		return err
+	}
+
+	s.auditService.Record(actorID, restaurantID, models.AuditEntityCashClosing, cashClosingID, models.AuditActionDelete, before, nil)
+	return nil
 }
 
+// GetCashClosingStats sums the cash closings between startDate and endDate,
+// both days included, and breaks down the taxes charged on the orders paid in
+// that period by rate.
 func (s *CashClosingService) GetCashClosingStats(restaurantID string, startDate, endDate time.Time) (*models.CashClosing, error) {
-	return s.cashClosingRepo.GetCashClosingStats(restaurantID, startDate, endDate)
+	stats, err := s.cashClosingRepo.GetCashClosingStats(restaurantID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	stats.Taxes, err = s.orderRepo.GetTaxTotals(restaurantID, startDate, endDate.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
 }
 
-// CalculateCashClosingData calculates the financial data for a specific date
+// CalculateCashClosingData calculates the financial data for a specific date.
+// Tips belong to the staff, so they are reported on their own and never count
+// as sales or revenue. Order totals are already net of their discounts, which
+// are reported on their own, and include their taxes, which are also broken
+// down by rate.
 func (s *CashClosingService) CalculateCashClosingData(restaurantID string, date time.Time) (*models.CashClosing, error) {
 	// Get paid orders for the date
 	tomorrow := date.AddDate(0, 0, 1)
-	orders, err := s.orderRepo.GetOrderByRestaurantID(restaurantID, "paid", "", date.Format("2006-01-02"), tomorrow.Format("2006-01-02"))
+	orders, err := s.orderRepo.GetOrderByRestaurantID(restaurantID, string(models.Paid), "", date.Format("2006-01-02"), tomorrow.Format("2006-01-02"))
 	if err != nil {
 		return nil, err
 	}
@@ -81,13 +125,15 @@ func (s *CashClosingService) CalculateCashClosingData(restaurantID string, date
 	}
 
 	// Calculate totals
-	var totalSales, totalRevenue, totalCosts float64
+	var totalSales, totalRevenue, totalCosts, totalDiscounts, totalTaxes float64
 	var orderCount int
 
 	for _, order := range filteredOrders {
 		totalSales += order.TotalPrice
 		totalRevenue += order.TotalPrice
 		orderCount++
+		totalDiscounts += order.DiscountTotal
+		totalTaxes += order.TaxTotal
 
 		// Calculate costs for this order
 		orderCosts, err := s.calculateOrderCosts(order)
@@ -97,6 +143,22 @@ func (s *CashClosingService) CalculateCashClosingData(restaurantID string, date
 		totalCosts += orderCosts
 	}
 
+	// Break down the payments taken that day
+	paymentMethods, err := s.paymentRepo.GetPaymentMethodTotals(restaurantID, date, tomorrow)
+	if err != nil {
+		return nil, err
+	}
+	var totalTips float64
+	for _, total := range paymentMethods {
+		totalTips += total.Tips
+	}
+
+	taxes, err := s.orderRepo.GetTaxTotals(restaurantID, date, tomorrow)
+	if err != nil {
+		return nil, err
+	}
+
+	totalDiscounts = models.RoundMoney(totalDiscounts)
 	totalProfit := totalRevenue - totalCosts
 	averageOrderValue := 0.0
 	if orderCount > 0 {
@@ -112,6 +174,11 @@ func (s *CashClosingService) CalculateCashClosingData(restaurantID string, date
 		TotalProfit:       totalProfit,
 		OrderCount:        orderCount,
 		AverageOrderValue: averageOrderValue,
+		TotalTips:         models.RoundMoney(totalTips),
+		TotalDiscounts:    totalDiscounts,
+		TotalTaxes:        models.RoundMoney(totalTaxes),
+		PaymentMethods:    paymentMethods,
+		Taxes:             taxes,
 	}, nil
 }
 
@@ -121,7 +188,7 @@ func (s *CashClosingService) calculateOrderCosts(order models.Order) (float64, e
 
 	for _, orderItem := range order.OrderItems {
 		// Get menu item with ingredients
-		menuItem, err := s.menuRepo.GetMenuItemByID(orderItem.MenuItemID)
+		menuItem, err := s.menuRepo.GetMenuItemByID(order.RestaurantID, orderItem.MenuItemID)
 		if err != nil {
 			continue // Skip if menu item not found
 		}
@@ -132,6 +199,17 @@ func (s *CashClosingService) calculateOrderCosts(order models.Order) (float64, e
 			itemCost += ingredient.Price // Price already includes the amount
 		}
 
+		// Combos cost what their components cost
+		for _, component := range orderItem.Components {
+			componentItem, err := s.menuRepo.GetMenuItemByID(order.RestaurantID, component.MenuItemID)
+			if err != nil {
+				continue
+			}
+			for _, ingredient := range componentItem.Ingredients {
+				itemCost += ingredient.Price * float64(component.Quantity)
+			}
+		}
+
 		// Multiply by quantity
 		totalCosts += itemCost * float64(orderItem.Quantity)
 	}
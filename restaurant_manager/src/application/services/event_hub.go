@@ -0,0 +1,104 @@
+package services
+
+import (
+	"restaurant_manager/src/application/utils"
+	"restaurant_manager/src/domain/models"
+	"sync"
+	"time"
+)
+
+const (
+	// eventHistorySize is how many events each restaurant keeps for replay.
+	eventHistorySize = 500
+	// subscriberBuffer is how far a subscriber may fall behind before it is
+	// dropped; it can reconnect and replay what it missed.
+	subscriberBuffer = 64
+)
+
+// EventHub is an in-process publish/subscribe hub with one topic per
+// restaurant. Every topic keeps its latest events so a subscriber that
+// reconnects can replay the ones it missed.
+type EventHub struct {
+	mu     sync.Mutex
+	nextID uint64
+	topics map[string]*eventTopic
+}
+
+type eventTopic struct {
+	history     []models.OrderEvent
+	subscribers map[chan models.OrderEvent]struct{}
+}
+
+func NewEventHub() *EventHub {
+	// Starting from the clock keeps IDs growing across restarts, so an old
+	// last event ID never hides newer events.
+	return &EventHub{
+		nextID: uint64(time.Now().UnixMicro()),
+		topics: make(map[string]*eventTopic),
+	}
+}
+
+// Publish assigns the event an ID and delivers it to the restaurant's subscribers.
+func (h *EventHub) Publish(event models.OrderEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.nextID++
+	event.ID = h.nextID
+	event.CreatedAt = utils.GetCurrentUTCTime()
+
+	topic := h.topic(event.RestaurantID)
+	topic.history = append(topic.history, event)
+	if len(topic.history) > eventHistorySize {
+		topic.history = topic.history[len(topic.history)-eventHistorySize:]
+	}
+
+	for subscriber := range topic.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+			delete(topic.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+}
+
+// Subscribe returns the events published after lastEventID that are still
+// kept, a channel with the events that follow and a function that ends the
+// subscription. A zero lastEventID skips the replay.
+func (h *EventHub) Subscribe(restaurantID string, lastEventID uint64) ([]models.OrderEvent, <-chan models.OrderEvent, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	topic := h.topic(restaurantID)
+	var replay []models.OrderEvent
+	if lastEventID > 0 {
+		for _, event := range topic.history {
+			if event.ID > lastEventID {
+				replay = append(replay, event)
+			}
+		}
+	}
+
+	subscriber := make(chan models.OrderEvent, subscriberBuffer)
+	topic.subscribers[subscriber] = struct{}{}
+
+	unsubscribe := func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := topic.subscribers[subscriber]; ok {
+			delete(topic.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+	return replay, subscriber, unsubscribe
+}
+
+func (h *EventHub) topic(restaurantID string) *eventTopic {
+	topic, ok := h.topics[restaurantID]
+	if !ok {
+		topic = &eventTopic{subscribers: make(map[chan models.OrderEvent]struct{})}
+		h.topics[restaurantID] = topic
+	}
+	return topic
+}
@@ -6,43 +6,68 @@ import (
 )
 
 type InventoryService struct {
-	repo        repositories.InventoryRepository
-	menuService *MenuService
+	repo         repositories.InventoryRepository
+	menuService  *MenuService
+	auditService *AuditService
 }
 
-func NewInventoryService(repo repositories.InventoryRepository, menuService *MenuService) *InventoryService {
-	return &InventoryService{repo: repo, menuService: menuService}
+func NewInventoryService(repo repositories.InventoryRepository, menuService *MenuService, auditService *AuditService) *InventoryService {
+	return &InventoryService{repo: repo, menuService: menuService, auditService: auditService}
 }
 
 func (s *InventoryService) CreateInventory(inventories []models.Inventory) ([]string, error) {
 	return s.repo.CreateInventory(inventories)
 }
 
-func (s *InventoryService) GetInventory(inventoryID string) (*models.Inventory, error) {
-	return s.repo.GetInventory(inventoryID)
+func (s *InventoryService) GetInventory(restaurantID string, inventoryID string) (*models.Inventory, error) {
+	return s.repo.GetInventory(restaurantID, inventoryID)
 }
 
 func (s *InventoryService) GetInventoryByRestaurantID(restaurantID string) ([]models.Inventory, error) {
 	return s.repo.GetInventoryByRestaurantID(restaurantID)
 }
 
-func (s *InventoryService) UpdateInventory(inventories []models.Inventory) error {
-	return s.repo.UpdateInventory(inventories)
+// UpdateInventory applies manual inventory edits and audits each of them.
+// Stock movements caused by orders go straight to the repository instead.
+func (s *InventoryService) UpdateInventory(actorID string, inventories []models.Inventory) error {
+	before := make([]*models.Inventory, len(inventories))
+	for i, inventory := range inventories {
+		before[i], _ = s.repo.GetInventory(inventory.RestaurantID, inventory.InventoryID)
+	}
+
+	if err := s.repo.UpdateInventory(inventories); err != nil {
+		return err
+	}
+
+	for i, inventory := range inventories {
+		if before[i] == nil {
+			continue
+		}
+		after, err := s.repo.GetInventory(inventory.RestaurantID, inventory.InventoryID)
+		if err != nil {
+			continue
+		}
+		s.auditService.Record(actorID, inventory.RestaurantID, models.AuditEntityInventory, inventory.InventoryID, models.AuditActionUpdate, before[i], after)
+	}
+	return nil
 }
 
-func (s *InventoryService) DeleteInventory(inventoryID string) error {
-	return s.repo.DeleteInventory(inventoryID)
+func (s *InventoryService) DeleteInventory(restaurantID string, inventoryID string) error {
+	return s.repo.DeleteInventory(restaurantID, inventoryID)
 }
 
 func (s *InventoryService) GetInventoryByRawIngredientIDAndRestaurantID(rawIngredientID string, restaurantID string) (*models.Inventory, error) {
 	return s.repo.GetInventoryByRawIngredientIDAndRestaurantID(rawIngredientID, restaurantID)
 }
 
-func (s *InventoryService) DeductInventoryForMenuItem(menuItem *models.MenuItem, quantity int) (bool, error) {
+// DeductInventoryForMenuItem takes the stock used by quantity units of the menu
+// item and reports whether an ingredient ran out. Orders pass their own
+// inventory repository so the stock moves within their transaction.
+func (s *InventoryService) DeductInventoryForMenuItem(repo repositories.InventoryRepository, menuItem *models.MenuItem, quantity int) (bool, error) {
 	inventories := []models.Inventory{}
 	zeroInventory := false
 	for _, item := range menuItem.Ingredients {
-		inventory, err := s.GetInventoryByRawIngredientIDAndRestaurantID(item.RawIngredientID, menuItem.RestaurantID)
+		inventory, err := repo.GetInventoryByRawIngredientIDAndRestaurantID(item.RawIngredientID, menuItem.RestaurantID)
 		if err != nil {
 			return false, err
 		}
@@ -56,17 +81,19 @@ func (s *InventoryService) DeductInventoryForMenuItem(menuItem *models.MenuItem,
 		}
 		inventories = append(inventories, *inventory)
 	}
-	err := s.UpdateInventory(inventories)
+	err := repo.UpdateInventory(inventories)
 	if err != nil {
 		return false, err
 	}
 	return zeroInventory, nil
 }
 
-func (s *InventoryService) AddInventoryForMenuItem(menuItem *models.MenuItem, quantity int) error {
+// AddInventoryForMenuItem puts back the stock used by quantity units of the
+// menu item, through the given repository like DeductInventoryForMenuItem.
+func (s *InventoryService) AddInventoryForMenuItem(repo repositories.InventoryRepository, menuItem *models.MenuItem, quantity int) error {
 	inventories := []models.Inventory{}
 	for _, item := range menuItem.Ingredients {
-		inventory, err := s.GetInventoryByRawIngredientIDAndRestaurantID(item.RawIngredientID, menuItem.RestaurantID)
+		inventory, err := repo.GetInventoryByRawIngredientIDAndRestaurantID(item.RawIngredientID, menuItem.RestaurantID)
 		if err != nil {
 			return err
 		}
@@ -74,5 +101,5 @@ func (s *InventoryService) AddInventoryForMenuItem(menuItem *models.MenuItem, qu
 		inventory.Quantity += amountToAdd
 		inventories = append(inventories, *inventory)
 	}
-	return s.UpdateInventory(inventories)
+	return repo.UpdateInventory(inventories)
 }
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"restaurant_manager/src/application/utils"
+	"restaurant_manager/src/domain/models"
+	"restaurant_manager/src/domain/ports"
+	"restaurant_manager/src/domain/repositories"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrInvalidInvoiceResolution = errors.New("invalid invoice resolution")
+	ErrNoInvoiceResolution      = errors.New("no invoice resolution covers today")
+	ErrInvoiceRangeExhausted    = errors.New("invoice resolution has no numbers left")
+	ErrInvalidInvoiceBuyer      = errors.New("invoice buyer must be the order's customer or a member of the restaurant, with a NIT")
+	ErrOrderNotInvoiceable      = errors.New("order cannot be invoiced")
+	ErrInvoiceNotFound          = errors.New("invoice not found")
+)
+
+type InvoiceService struct {
+	repo           repositories.InvoiceRepository
+	orderRepo      repositories.OrderRepository
+	restaurantRepo repositories.RestaurantRepository
+	userRepo       repositories.UserRepository
+	transmitter    ports.InvoiceTransmitter
+}
+
+func NewInvoiceService(repo repositories.InvoiceRepository, orderRepo repositories.OrderRepository, restaurantRepo repositories.RestaurantRepository, userRepo repositories.UserRepository, transmitter ports.InvoiceTransmitter) *InvoiceService {
+	return &InvoiceService{repo: repo, orderRepo: orderRepo, restaurantRepo: restaurantRepo, userRepo: userRepo, transmitter: transmitter}
+}
+
+// CreateResolution registers a numbering resolution; its first invoice takes
+// the first number of its range.
+func (s *InvoiceService) CreateResolution(resolution *models.InvoiceResolution) (string, error) {
+	if err := resolution.Validate(); err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidInvoiceResolution, err)
+	}
+	resolution.NextNumber = resolution.RangeFrom
+	return s.repo.CreateResolution(resolution)
+}
+
+func (s *InvoiceService) GetResolutions(restaurantID string) ([]models.InvoiceResolution, error) {
+	return s.repo.GetResolutions(restaurantID)
+}
+
+// IssueInvoice invoices a paid order to a buyer with a NIT who may buy it (see
+// canBuy), numbering it with the next number of the resolution covering the
+// day, and transmits it. An order is invoiced once: asking again returns its
+// invoice, retrying the transmission if the tax authority has not answered
+// yet. The returned flag tells whether the invoice was issued by this call. A
+// customerID limits it to the orders that customer placed.
+func (s *InvoiceService) IssueInvoice(customerID string, restaurantID string, orderID string, buyerID string) (*models.Invoice, bool, error) {
+	order, err := s.orderRepo.GetOrder(restaurantID, orderID)
+	if err != nil {
+		return nil, false, ErrOrderNotFound
+	}
+	if err := checkCustomer(order, customerID); err != nil {
+		return nil, false, err
+	}
+	if invoice, err := s.repo.GetInvoiceByOrder(restaurantID, orderID); err == nil {
+		s.retransmit(invoice)
+		return invoice, false, nil
+	}
+
+	if order.Status != models.Paid {
+		return nil, false, fmt.Errorf("%w: only paid orders are invoiced, order is %s", ErrOrderNotInvoiceable, order.Status)
+	}
+	restaurant, err := s.restaurantRepo.GetRestaurant(restaurantID)
+	if err != nil {
+		return nil, false, err
+	}
+	seller := restaurant.InvoiceParty()
+	if strings.TrimSpace(seller.Nit) == "" {
+		return nil, false, fmt.Errorf("%w: the restaurant has no NIT", ErrOrderNotInvoiceable)
+	}
+	buyer, err := s.userRepo.GetUserById(buyerID)
+	if err != nil || !canBuy(buyer, order, restaurant) || buyer.NitNumber == nil || strings.TrimSpace(*buyer.NitNumber) == "" {
+		return nil, false, ErrInvalidInvoiceBuyer
+	}
+
+	issuedAt := utils.GetCurrentUTCTime().In(restaurant.Location())
+	var invoice models.Invoice
+	created := false
+	err = s.repo.WithTransaction(func(txRepo repositories.InvoiceRepository) error {
+		resolution, err := txRepo.LockResolution(restaurantID, issuedAt.Format(time.DateOnly))
+		if err != nil {
+			return ErrNoInvoiceResolution
+		}
+		// The lock serialises numbering, so an invoice issued meanwhile is seen here
+		if existing, err := txRepo.GetInvoiceByOrder(restaurantID, orderID); err == nil {
+			invoice = *existing
+			return nil
+		}
+		if resolution.Exhausted() {
+			return fmt.Errorf("%w: resolution %s ends at %d", ErrInvoiceRangeExhausted, resolution.ResolutionNumber, resolution.RangeTo)
+		}
+
+		invoice, err = models.NewInvoice(order, seller, models.InvoiceParty{Name: buyer.Name, Nit: *buyer.NitNumber}, *resolution, issuedAt)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrOrderNotInvoiceable, err)
+		}
+		invoice.BuyerID = &buyer.UserID
+		if _, err := txRepo.CreateInvoice(&invoice); err != nil {
+			return err
+		}
+		created = true
+		return txRepo.AdvanceResolution(resolution.ResolutionID, resolution.NextNumber+1)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	s.retransmit(&invoice)
+	return &invoice, created, nil
+}
+
+// canBuy reports whether the order may be invoiced to the user: the customer
+// who placed it, or the owner or staff of its restaurant.
+func canBuy(user *models.User, order *models.Order, restaurant *models.Restaurant) bool {
+	switch {
+	case order.CustomerID != nil && *order.CustomerID == user.UserID:
+		return true
+	case restaurant.OwnerID == user.UserID:
+		return true
+	default:
+		return user.RestaurantId != nil && *user.RestaurantId == restaurant.RestaurantID
+	}
+}
+
+// GetInvoice returns the order's invoice. A customerID limits it to the
+// customer's own orders.
+func (s *InvoiceService) GetInvoice(customerID string, restaurantID string, orderID string) (*models.Invoice, error) {
+	order, err := s.orderRepo.GetOrder(restaurantID, orderID)
+	if err != nil {
+		return nil, ErrInvoiceNotFound
+	}
+	if err := checkCustomer(order, customerID); err != nil {
+		return nil, ErrInvoiceNotFound
+	}
+	invoice, err := s.repo.GetInvoiceByOrder(restaurantID, orderID)
+	if err != nil {
+		return nil, ErrInvoiceNotFound
+	}
+	return invoice, nil
+}
+
+// retransmit sends a pending invoice to the tax authority and saves its
+// answer. Invoices that cannot be sent stay pending until asked for again.
+func (s *InvoiceService) retransmit(invoice *models.Invoice) {
+	if invoice.Status != models.InvoicePending {
+		return
+	}
+	answer, err := s.transmitter.Transmit(*invoice)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to transmit invoice %s", invoice.Number)
+		return
+	}
+	now := utils.GetCurrentUTCTime()
+	invoice.Status = answer.Status
+	invoice.TrackingID = &answer.TrackingID
+	invoice.StatusMessage = &answer.Message
+	invoice.TransmittedAt = &now
+	if err := s.repo.UpdateInvoiceTransmission(invoice); err != nil {
+		log.Error().Err(err).Msgf("Failed to save the transmission of invoice %s", invoice.Number)
+	}
+}
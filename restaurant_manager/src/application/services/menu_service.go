@@ -1,23 +1,40 @@
 package services
 
 import (
+	"errors"
+	"fmt"
 	"mime/multipart"
 	"restaurant_manager/src/domain/models"
 	"restaurant_manager/src/domain/ports"
 	"restaurant_manager/src/domain/repositories"
+	"strings"
+)
+
+var (
+	ErrMenuItemNotFound      = errors.New("menu item not found")
+	ErrModifierGroupNotFound = errors.New("modifier group not found")
+	ErrInvalidModifierGroup  = errors.New("invalid modifier group")
+	ErrInvalidModifiers      = errors.New("invalid modifier selection")
+	ErrInvalidComboSlots     = errors.New("invalid combo slots")
+	ErrInvalidComponents     = errors.New("invalid combo selection")
+	ErrInvalidTaxCategory    = errors.New("tax category must be none, inc_8, iva_19, iva_5 or iva_0")
 )
 
 type MenuService struct {
 	repo              repositories.MenuRepository
 	imageManager      ports.StorageImageManager
 	ingredientService *IngredientsService
+	auditService      *AuditService
 }
 
-func NewMenuService(repo repositories.MenuRepository, awsS3 ports.StorageImageManager, ingredientService *IngredientsService) *MenuService {
-	return &MenuService{repo: repo, imageManager: awsS3, ingredientService: ingredientService}
+func NewMenuService(repo repositories.MenuRepository, awsS3 ports.StorageImageManager, ingredientService *IngredientsService, auditService *AuditService) *MenuService {
+	return &MenuService{repo: repo, imageManager: awsS3, ingredientService: ingredientService, auditService: auditService}
 }
 
 func (s *MenuService) AddMenuItem(menuItem *models.MenuItem) (string, error) {
+	if menuItem.TaxCategory != nil && !menuItem.TaxCategory.IsValid() {
+		return "", ErrInvalidTaxCategory
+	}
 	menuItemID, err := s.repo.AddMenuItem(menuItem)
 	if err != nil {
 		return "", err
@@ -33,16 +50,23 @@ func (s *MenuService) AddMenuItem(menuItem *models.MenuItem) (string, error) {
 	return menuItemID, nil
 }
 
-func (s *MenuService) DeleteMenuItem(menuItemID string) error {
-	return s.repo.DeleteMenuItem(menuItemID)
+func (s *MenuService) DeleteMenuItem(restaurantID string, menuItemID string) error {
+	return s.repo.DeleteMenuItem(restaurantID, menuItemID)
 }
 
-func (s *MenuService) UpdateMenuItem(menuItem *models.MenuItem) error {
+// UpdateMenuItem replaces a menu item and its recipe. An empty actorID marks
+// changes made by the system, such as disabling an item that ran out of stock.
+func (s *MenuService) UpdateMenuItem(actorID string, menuItem *models.MenuItem) error {
+	if menuItem.TaxCategory != nil && !menuItem.TaxCategory.IsValid() {
+		return ErrInvalidTaxCategory
+	}
+	var before models.MenuItem
 	err := s.repo.WithTransaction(func(txRepo repositories.MenuRepository) error {
-		menuItemOld, err := s.repo.GetMenuItemByID(menuItem.MenuItemID)
+		menuItemOld, err := s.repo.GetMenuItemByID(menuItem.RestaurantID, menuItem.MenuItemID)
 		if err != nil {
 			return err
 		}
+		before = *menuItemOld
 		for i := range menuItemOld.Ingredients {
 			menuItemOld.Ingredients[i].MenuItemID = menuItem.MenuItemID
 		}
@@ -59,14 +83,210 @@ func (s *MenuService) UpdateMenuItem(menuItem *models.MenuItem) error {
 		}
 		return txRepo.UpdateMenuItem(menuItem)
 	})
-	return err
+	if err != nil {
+		return err
+	}
+
+	// Recipes are audited through the price and availability they produce, not
+	// ingredient by ingredient.
+	after := *menuItem
+	before.Ingredients, before.ModifierGroups = nil, nil
+	after.Ingredients, after.ModifierGroups = nil, nil
+	s.auditService.Record(actorID, menuItem.RestaurantID, models.AuditEntityMenuItem, menuItem.MenuItemID, models.AuditActionUpdate, before, after)
+	return nil
 }
 
 func (s *MenuService) GetMenuItemsByRestaurantID(restaurantID string) ([]models.MenuItem, error) {
 	return s.repo.GetMenuItemsByRestaurantID(restaurantID)
 }
-func (s *MenuService) GetMenuItemByID(menuItemID string) (*models.MenuItem, error) {
-	return s.repo.GetMenuItemByID(menuItemID)
+func (s *MenuService) GetMenuItemByID(restaurantID string, menuItemID string) (*models.MenuItem, error) {
+	return s.repo.GetMenuItemByID(restaurantID, menuItemID)
+}
+
+// CreateModifierGroup adds a group of choices to a menu item of the restaurant.
+func (s *MenuService) CreateModifierGroup(restaurantID string, group *models.ModifierGroup) (string, error) {
+	if _, err := s.repo.GetMenuItemByID(restaurantID, group.MenuItemID); err != nil {
+		return "", ErrMenuItemNotFound
+	}
+	if err := validateModifierGroup(group); err != nil {
+		return "", err
+	}
+	return s.repo.CreateModifierGroup(group)
+}
+
+// GetModifierGroups returns the groups of choices of a menu item in menu order.
+func (s *MenuService) GetModifierGroups(restaurantID string, menuItemID string) ([]models.ModifierGroup, error) {
+	menuItem, err := s.repo.GetMenuItemByID(restaurantID, menuItemID)
+	if err != nil {
+		return nil, ErrMenuItemNotFound
+	}
+	return menuItem.ModifierGroups, nil
+}
+
+// DeleteModifierGroup removes a group of choices. Order items keep the names
+// and prices of the modifiers chosen from it.
+func (s *MenuService) DeleteModifierGroup(restaurantID string, menuItemID string, modifierGroupID string) error {
+	if _, err := s.repo.GetMenuItemByID(restaurantID, menuItemID); err != nil {
+		return ErrMenuItemNotFound
+	}
+	if err := s.repo.DeleteModifierGroup(menuItemID, modifierGroupID); err != nil {
+		return ErrModifierGroupNotFound
+	}
+	return nil
+}
+
+// selectModifiers checks a selection of modifier IDs against the modifier
+// groups of the menu item and returns the chosen modifiers. Each group must
+// receive between its minimum and maximum number of choices.
+func selectModifiers(menuItem *models.MenuItem, modifierIDs []string) ([]models.Modifier, error) {
+	chosen := map[string]bool{}
+	for _, id := range modifierIDs {
+		if chosen[id] {
+			return nil, fmt.Errorf("%w: modifier %s chosen twice", ErrInvalidModifiers, id)
+		}
+		chosen[id] = true
+	}
+
+	var selected []models.Modifier
+	for _, group := range menuItem.ModifierGroups {
+		count := 0
+		for _, modifier := range group.Modifiers {
+			if chosen[modifier.ModifierID] {
+				selected = append(selected, modifier)
+				delete(chosen, modifier.ModifierID)
+				count++
+			}
+		}
+		if count < group.MinimumSelections() || count > group.MaxSelections {
+			return nil, fmt.Errorf("%w: %s takes %d to %d choices", ErrInvalidModifiers, group.Name, group.MinimumSelections(), group.MaxSelections)
+		}
+	}
+	for _, id := range modifierIDs {
+		if chosen[id] {
+			return nil, fmt.Errorf("%w: modifier %s is not offered with %s", ErrInvalidModifiers, id, menuItem.Name)
+		}
+	}
+	return selected, nil
+}
+
+// ReplaceComboSlots turns the menu item into a combo of the given slots. An
+// empty list makes it a plain menu item again.
+func (s *MenuService) ReplaceComboSlots(restaurantID string, comboItemID string, slots []models.ComboSlot) error {
+	if _, err := s.repo.GetMenuItemByID(restaurantID, comboItemID); err != nil {
+		return ErrMenuItemNotFound
+	}
+	for i := range slots {
+		slot := &slots[i]
+		if slot.Quantity == 0 {
+			slot.Quantity = 1
+		}
+		if strings.TrimSpace(slot.Name) == "" || slot.Quantity < 0 {
+			return fmt.Errorf("%w: every slot needs a name and a positive quantity", ErrInvalidComboSlots)
+		}
+		if (slot.MenuItemID == nil) == (slot.Category == nil) {
+			return fmt.Errorf("%w: slot %s needs either a menu item or a category", ErrInvalidComboSlots, slot.Name)
+		}
+		if slot.Category != nil && !slot.Category.IsValid() {
+			return fmt.Errorf("%w: unknown category %s", ErrInvalidComboSlots, *slot.Category)
+		}
+		if slot.MenuItemID != nil {
+			component, err := s.repo.GetMenuItemByID(restaurantID, *slot.MenuItemID)
+			if err != nil || component.MenuItemID == comboItemID || component.IsCombo() {
+				return fmt.Errorf("%w: slot %s must name another plain menu item of the restaurant", ErrInvalidComboSlots, slot.Name)
+			}
+		}
+	}
+	return s.repo.ReplaceComboSlots(comboItemID, slots)
+}
+
+// selectComponents checks the menu items picked for the choice slots of a
+// combo and returns every component of one combo, fixed slots included, with
+// its menu item loaded. Each choice slot must receive exactly its quantity of
+// available menu items of its category.
+func (s *MenuService) selectComponents(restaurantID string, combo *models.MenuItem, picks []models.OrderItemComponent) ([]models.OrderItemComponent, error) {
+	if !combo.IsCombo() {
+		if len(picks) > 0 {
+			return nil, fmt.Errorf("%w: %s is not a combo", ErrInvalidComponents, combo.Name)
+		}
+		return nil, nil
+	}
+
+	slots := map[string]models.ComboSlot{}
+	for _, slot := range combo.ComboSlots {
+		slots[slot.SlotID] = slot
+	}
+	picked := map[string]int{}
+	var components []models.OrderItemComponent
+	for _, pick := range picks {
+		if pick.SlotID == nil {
+			return nil, fmt.Errorf("%w: every pick needs a slot", ErrInvalidComponents)
+		}
+		slot, ok := slots[*pick.SlotID]
+		if !ok || !slot.IsChoice() {
+			return nil, fmt.Errorf("%w: %s has no choice slot %s", ErrInvalidComponents, combo.Name, *pick.SlotID)
+		}
+		if pick.Quantity == 0 {
+			pick.Quantity = 1
+		}
+		menuItem, err := s.repo.GetMenuItemByID(restaurantID, pick.MenuItemID)
+		if err != nil || pick.Quantity < 0 || !menuItem.Available || menuItem.IsCombo() || !slot.Offers(menuItem) {
+			return nil, fmt.Errorf("%w: %s cannot fill %s", ErrInvalidComponents, pick.MenuItemID, slot.Name)
+		}
+		picked[slot.SlotID] += pick.Quantity
+		components = append(components, models.OrderItemComponent{
+			SlotID:     pick.SlotID,
+			MenuItemID: menuItem.MenuItemID,
+			Quantity:   pick.Quantity,
+			MenuItem:   *menuItem,
+		})
+	}
+
+	for _, slot := range combo.ComboSlots {
+		if slot.IsChoice() {
+			if picked[slot.SlotID] != slot.Quantity {
+				return nil, fmt.Errorf("%w: %s takes %d choices", ErrInvalidComponents, slot.Name, slot.Quantity)
+			}
+			continue
+		}
+		menuItem, err := s.repo.GetMenuItemByID(restaurantID, *slot.MenuItemID)
+		if err != nil {
+			return nil, err
+		}
+		slotID := slot.SlotID
+		components = append(components, models.OrderItemComponent{
+			SlotID:     &slotID,
+			MenuItemID: menuItem.MenuItemID,
+			Quantity:   slot.Quantity,
+			MenuItem:   *menuItem,
+		})
+	}
+	return components, nil
+}
+
+func validateModifierGroup(group *models.ModifierGroup) error {
+	if strings.TrimSpace(group.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidModifierGroup)
+	}
+	if group.MaxSelections == 0 {
+		group.MaxSelections = 1
+	}
+	if group.MinSelections < 0 || group.MinimumSelections() > group.MaxSelections {
+		return fmt.Errorf("%w: selections must satisfy 0 <= min <= max", ErrInvalidModifierGroup)
+	}
+	if len(group.Modifiers) < group.MinimumSelections() {
+		return fmt.Errorf("%w: needs at least %d modifiers", ErrInvalidModifierGroup, group.MinimumSelections())
+	}
+	for _, modifier := range group.Modifiers {
+		if strings.TrimSpace(modifier.Name) == "" {
+			return fmt.Errorf("%w: every modifier needs a name", ErrInvalidModifierGroup)
+		}
+		for _, ingredient := range modifier.Ingredients {
+			if ingredient.RawIngredientID == "" || ingredient.Amount == 0 || !models.IsValidUnit(ingredient.Unit) {
+				return fmt.Errorf("%w: ingredient deltas need a raw ingredient, a non-zero amount and a valid unit", ErrInvalidModifierGroup)
+			}
+		}
+	}
+	return nil
 }
 
 func (s *MenuService) UploadFile(owner string, file multipart.File) (string, error) {
@@ -1,11 +1,25 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"restaurant_manager/src/application/utils"
 	"restaurant_manager/src/domain/models"
 	"restaurant_manager/src/domain/repositories"
+	"slices"
 	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidStatusTransition = errors.New("invalid status transition")
+	ErrOrderNotFound           = errors.New("order not found")
+	ErrOrderItemNotFound       = errors.New("order item not found")
+	ErrNothingToFire           = errors.New("course has no held items to fire")
+	ErrTableNotFound           = errors.New("table not found")
+	ErrTableOccupied           = errors.New("table is occupied by another order")
+	ErrOrderHasPayments        = errors.New("order has payments taken against its items")
+	ErrInvalidOrderMove        = errors.New("invalid order move")
 )
 
 type OrderService struct {
@@ -13,263 +27,679 @@ type OrderService struct {
 	tableService     *TableService
 	menuService      *MenuService
 	inventoryService *InventoryService
+	auditService     *AuditService
+	eventHub         *EventHub
+	promotionService *PromotionService
+	paymentRepo      repositories.PaymentRepository
+	restaurantRepo   repositories.RestaurantRepository
 }
 
-func NewOrderService(repo repositories.OrderRepository, tableService *TableService, menuService *MenuService, inventoryService *InventoryService) *OrderService {
-	return &OrderService{repo, tableService, menuService, inventoryService}
+func NewOrderService(repo repositories.OrderRepository, tableService *TableService, menuService *MenuService, inventoryService *InventoryService, auditService *AuditService, eventHub *EventHub, promotionService *PromotionService, paymentRepo repositories.PaymentRepository, restaurantRepo repositories.RestaurantRepository) *OrderService {
+	return &OrderService{repo, tableService, menuService, inventoryService, auditService, eventHub, promotionService, paymentRepo, restaurantRepo}
 }
 
+// CreateOrder opens an order with no items; its totals are priced as items
+// are added, whatever the caller sent.
 func (service *OrderService) CreateOrder(order *models.Order) (string, error) {
+	if order.Status == "" {
+		order.Status = models.Ordered
+	}
+	order.SetTotals(models.OrderTotals{})
+	if _, err := service.tableService.GetTable(order.RestaurantID, order.TableID); err != nil {
+		return "", fmt.Errorf("table not found")
+	}
 	orderId, err := service.repo.CreateOrder(order)
 	if err != nil {
 		return "", err
 	}
-	err = service.tableService.UpdateTableStatus(order.TableID, "occupied")
+	if err := recordStatusChange(service.repo, order, nil, utils.GetCurrentUTCTime()); err != nil {
+		_ = service.repo.DeleteOrder(order.RestaurantID, orderId)
+		return "", err
+	}
+	err = service.tableService.UpdateTableStatus(order.RestaurantID, order.TableID, "occupied")
 	if err != nil {
-		_ = service.repo.DeleteOrder(orderId)
+		_ = service.repo.DeleteOrder(order.RestaurantID, orderId)
 		return "", err
 	}
 
+	service.eventHub.Publish(models.OrderEvent{
+		Type:         models.OrderCreatedEvent,
+		RestaurantID: order.RestaurantID,
+		OrderID:      orderId,
+		TableID:      order.TableID,
+		Status:       order.Status,
+	})
 	return orderId, nil
 }
 
-func (service *OrderService) DeleteOrder(orderID string) error {
-	return service.repo.DeleteOrder(orderID)
+func (service *OrderService) DeleteOrder(restaurantID string, orderID string) error {
+	return service.repo.DeleteOrder(restaurantID, orderID)
 }
 
+// UpdateOrder saves the order and moves it to the requested status, carrying
+// its open items along. Moves the state machine does not allow are rejected
+// with ErrInvalidStatusTransition. The time spent in each stage is derived
+// from the transition timestamps, and the totals priced from the order's
+// items; neither is taken from the caller, nor is the table, which only
+// changes through TransferOrder. Orders are only marked paid once their
+// balance is settled.
 func (service *OrderService) UpdateOrder(order *models.Order) error {
-	return service.repo.WithTransaction(func(txRepo repositories.OrderRepository) error {
-		err := txRepo.UpdateOrder(order)
+	statusChanged := false
+	err := service.repo.WithTransaction(func(txRepo repositories.OrderRepository) error {
+		var err error
+		statusChanged, err = service.updateOrder(txRepo, order)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if statusChanged {
+		service.eventHub.Publish(orderStatusEvent(order))
+	}
+	return nil
+}
+
+// updateOrder does the work of UpdateOrder within the caller's transaction and
+// reports whether the order changed status. Publishing the change is left to
+// the caller, once the transaction has committed.
+func (service *OrderService) updateOrder(txRepo repositories.OrderRepository, order *models.Order) (bool, error) {
+	current, err := txRepo.GetOrder(order.RestaurantID, order.OrderID)
+	if err != nil {
+		return false, err
+	}
+	now := utils.GetCurrentUTCTime()
+	status := order.Status
+	order.Status = current.Status
+	order.CreatedAt = current.CreatedAt
+	order.PreparedAt = current.PreparedAt
+	order.DeliveredAt = current.DeliveredAt
+	order.TableID = current.TableID
+	order.TimeToPrepare, order.TimeToDeliver, order.TimeToPay = 0, 0, 0
+	order.SetTotals(models.OrderTotals{})
+	if status != "" && !order.TransitionTo(status, now) {
+		return false, invalidTransition(current.Status, status)
+	}
+	// Held courses have not reached the kitchen, so only cancelling may carry them along
+	if order.Status != current.Status && order.Status != models.Cancelled && current.HasHeldItems() {
+		return false, fmt.Errorf("%w: order has held items, fire their course first", ErrInvalidStatusTransition)
+	}
+	if order.Status == models.Paid && current.Status != models.Paid {
+		payments, err := txRepo.Payments().GetPayments(order.RestaurantID, order.OrderID)
 		if err != nil {
-			return err
+			return false, err
 		}
-		order, err = txRepo.GetOrder(order.OrderID)
-		if err != nil {
-			return err
+		if balance := models.NewOrderBalance(current, payments); !balance.Settled() {
+			return false, fmt.Errorf("%w: order has %.2f outstanding", ErrInvalidStatusTransition, balance.Outstanding)
 		}
-		for _, item := range order.OrderItems {
-			if item.Status != models.OrderStatus("cancelled") && item.Status != models.OrderStatus("completed") {
-				if order.Status == models.OrderStatus("paid") {
-					item.Status = models.OrderStatus("completed")
-				} else {
-					item.Status = models.OrderStatus(order.Status)
-				}
-				err = txRepo.UpdateOrderItem(&item)
-				if err != nil {
-					return err
-				}
-			}
+	}
+	if err := txRepo.UpdateOrder(order); err != nil {
+		return false, err
+	}
+	if err := service.updateTotals(txRepo, current); err != nil {
+		return false, err
+	}
+	order.SetTotals(current.Totals())
+	if order.Status == current.Status {
+		return false, nil
+	}
+	if err := recordStatusChange(txRepo, order, &current.Status, now); err != nil {
+		return false, err
+	}
+
+	itemStatus := order.Status
+	if itemStatus == models.Paid {
+		itemStatus = models.Completed
+	}
+	for _, item := range current.OrderItems {
+		if item.Status.IsFinal() {
+			continue
 		}
-		if order.Status == models.Paid {
-			err = service.tableService.UpdateTableStatus(order.TableID, string(models.TableStatusAvailable))
-			if err != nil {
-				return err
-			}
+		item.AdvanceTo(itemStatus, utils.GetCurrentUTCTime())
+		if err := txRepo.UpdateOrderItem(&item); err != nil {
+			return false, err
 		}
-		return nil
-	})
+	}
+	if order.Status == models.Paid {
+		if err := txRepo.UpdateTableStatus(order.RestaurantID, order.TableID, models.TableStatusAvailable); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
 }
 
-func (service *OrderService) GetOrder(orderID string) (*models.Order, error) {
-	return service.repo.GetOrder(orderID)
+// GetOrder returns the order. A customerID limits the lookup to the orders
+// that customer placed; staff pass none.
+func (service *OrderService) GetOrder(customerID string, restaurantID string, orderID string) (*models.Order, error) {
+	order, err := service.repo.GetOrder(restaurantID, orderID)
+	if err != nil {
+		return nil, ErrOrderNotFound
+	}
+	if err := checkCustomer(order, customerID); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// checkCustomer rejects requests limited to a customer's orders on orders the
+// customer did not place. They are reported as not found, so customers cannot
+// probe other orders by ID. An empty customerID is a staff request.
+func checkCustomer(order *models.Order, customerID string) error {
+	if customerID != "" && (order.CustomerID == nil || *order.CustomerID != customerID) {
+		return ErrOrderNotFound
+	}
+	return nil
 }
 
 func (service *OrderService) GetOrderByRestaurantID(restaurantID string, status string, tableID string, startDate string, endDate string) ([]models.Order, error) {
 	return service.repo.GetOrderByRestaurantID(restaurantID, status, tableID, startDate, endDate)
 }
 
-func (s *OrderService) AddOrderItem(orderItem *models.OrderItem) (string, error) {
-	var orderItemID string
-
+// AddOrderItem adds an item to the order, priced from the menu and the
+// modifiers chosen for it; combos are sold at their bundle price. The item is
+// taxed as the restaurant charges its menu item at the time it is ordered. An
+// item identical to one the order already holds, down to its modifiers and
+// seat, only raises that item's quantity. Combos are never merged since their
+// picks may differ. Sides sent for a dish on the order come with it, see
+// isIncludedSide. A customerID limits it to the customer's own orders.
+// Paid and cancelled orders take no more items. Menu items whose stock runs
+// out are taken off the menu on behalf of the actor.
+func (s *OrderService) AddOrderItem(actorID string, customerID string, restaurantID string, orderItem *models.OrderItem) (string, error) {
+	var soldOut []models.MenuItem
 	err := s.repo.WithTransaction(func(txRepo repositories.OrderRepository) error {
-		order, _ := s.repo.GetOrder(orderItem.OrderID)
-		if order != nil {
-			// Existing order: add or update order item
-			itemExists := false
-			for _, item := range order.OrderItems {
-				if item.MenuItemID == orderItem.MenuItemID && item.Status == models.OrderStatus("pending") && strings.EqualFold(*orderItem.Observation, *item.Observation) {
-					itemExists = true
-					orderItem.Quantity += item.Quantity
-					break
-				}
+		order, err := txRepo.GetOrder(restaurantID, orderItem.OrderID)
+		if err != nil {
+			return ErrOrderNotFound
+		}
+		if err := checkCustomer(order, customerID); err != nil {
+			return err
+		}
+		if order.Status.IsFinal() {
+			return fmt.Errorf("%w: order is %s", ErrInvalidStatusTransition, order.Status)
+		}
+		menuItem, err := s.menuService.GetMenuItemByID(restaurantID, orderItem.MenuItemID)
+		if err != nil {
+			return err
+		}
+		modifierIDs := make([]string, 0, len(orderItem.Modifiers))
+		for _, chosen := range orderItem.Modifiers {
+			if chosen.ModifierID != nil {
+				modifierIDs = append(modifierIDs, *chosen.ModifierID)
 			}
-			if itemExists {
-				return s.updateOrderItemQuantity(orderItem)
-			} else {
-				menuItem, err := s.menuService.GetMenuItemByID(orderItem.MenuItemID)
-				if err != nil {
-					return err
-				}
-				if strings.Contains(*orderItem.Observation, "Guarnición") {
-					orderItem.Price = 0
-				} else {
-					orderItem.Price = menuItem.Price
-				}
-				orderItem.Status = models.OrderStatus("pending")
-				id, err := s.repo.AddOrderItem(orderItem)
-				if err != nil {
-					return err
-				}
-				orderItemID = id
+		}
+		modifiers, err := selectModifiers(menuItem, modifierIDs)
+		if err != nil {
+			return err
+		}
+		components, err := s.menuService.selectComponents(restaurantID, menuItem, orderItem.Components)
+		if err != nil {
+			return err
+		}
+		restaurant, err := s.restaurantRepo.GetRestaurant(restaurantID)
+		if err != nil {
+			return err
+		}
+		orderItem.TaxCategory = restaurant.TaxFor(*menuItem)
+		orderItem.TaxRate = orderItem.TaxCategory.Rate()
+		orderItem.TaxIncluded = restaurant.TaxIncluded()
+		orderItem.Components = components
+		orderItem.Price = menuItem.Price
+		orderItem.Modifiers = make([]models.OrderItemModifier, len(modifiers))
+		for i, modifier := range modifiers {
+			orderItem.Price += modifier.PriceDelta
+			orderItem.Modifiers[i] = models.OrderItemModifier{
+				ModifierID: &modifiers[i].ModifierID,
+				Name:       modifier.Name,
+				PriceDelta: modifier.PriceDelta,
 			}
-			return s.handleInventoryAndMenu(orderItem.MenuItemID, orderItem.Quantity)
-		} else {
-			// New order: add order item (assume order is being created elsewhere)
+		}
+
+		if isIncludedSide(order, menuItem, orderItem) {
+			orderItem.Price = 0
+		}
 
-			id, err := s.repo.AddOrderItem(orderItem)
+		// Items go straight to the kitchen unless they are held for a later course
+		if orderItem.Status != models.Held {
+			orderItem.Status = models.Pending
+		}
+		if orderItem.Course < 1 {
+			orderItem.Course = 1
+		}
+		added := orderItem.Quantity
+		for _, item := range order.OrderItems {
+			if !menuItem.IsCombo() && item.MenuItemID == orderItem.MenuItemID && item.Status == orderItem.Status && item.Course == orderItem.Course &&
+				item.SameSeat(orderItem.Seat) && strings.EqualFold(*orderItem.Observation, *item.Observation) && item.SameModifiers(orderItem.Modifiers) &&
+				(item.Price == 0) == (orderItem.Price == 0) {
+				orderItem.OrderItemID = item.OrderItemID
+				orderItem.Price = item.Price
+				orderItem.TaxCategory, orderItem.TaxRate, orderItem.TaxIncluded = item.TaxCategory, item.TaxRate, item.TaxIncluded
+				orderItem.Quantity += item.Quantity
+				break
+			}
+		}
+		if orderItem.OrderItemID != "" {
+			err = txRepo.UpdateOrderItem(orderItem)
+		} else {
+			_, err = txRepo.AddOrderItem(orderItem)
+		}
+		if err != nil {
+			return err
+		}
+		sold, err := s.handleInventoryAndMenu(txRepo, menuItem, modifiers, added)
+		if err != nil {
+			return err
+		}
+		if sold {
+			soldOut = append(soldOut, *menuItem)
+		}
+		for i := range components {
+			sold, err := s.handleInventoryAndMenu(txRepo, &components[i].MenuItem, nil, components[i].Quantity*added)
 			if err != nil {
 				return err
 			}
-			orderItemID = id
-			return s.handleInventoryAndMenu(orderItem.MenuItemID, orderItem.Quantity)
+			if sold {
+				soldOut = append(soldOut, components[i].MenuItem)
+			}
 		}
+		return s.reprice(txRepo, restaurantID, orderItem.OrderID)
 	})
 	if err != nil {
 		return "", err
 	}
 
-	return orderItemID, nil
+	s.eventHub.Publish(itemEvent(models.ItemAddedEvent, restaurantID, orderItem))
+	s.recordSoldOut(actorID, soldOut)
+	return orderItem.OrderItemID, nil
 }
 
-// updateOrderItemQuantity updates the quantity of an existing order item
-func (s *OrderService) updateOrderItemQuantity(orderItem *models.OrderItem) error {
-	err := s.repo.UpdateOrderItem(orderItem)
-	if err != nil {
-		return err
+// includedSidePrefix starts the observation the menu page gives the sides chosen
+// with a dish, which it sends as items of their own.
+const includedSidePrefix = "Guarnición de "
+
+// isIncludedSide reports whether the item is a side that comes with a dish
+// already on the order and so is not charged. Each unit of the dish includes
+// as many sides as its menu item offers; sides beyond that are charged.
+func isIncludedSide(order *models.Order, menuItem *models.MenuItem, orderItem *models.OrderItem) bool {
+	if menuItem.Category != models.Side || orderItem.Observation == nil || !strings.HasPrefix(*orderItem.Observation, includedSidePrefix) {
+		return false
 	}
-	return nil
+	dish := strings.TrimPrefix(*orderItem.Observation, includedSidePrefix)
+	allowed, included := 0, orderItem.Quantity
+	for _, item := range order.OrderItems {
+		if item.Status == models.Cancelled {
+			continue
+		}
+		if item.MenuItem.Name == dish {
+			allowed += item.Quantity * item.MenuItem.SideDishes
+		}
+		if item.Price == 0 && item.Observation != nil && *item.Observation == *orderItem.Observation {
+			included += item.Quantity
+		}
+	}
+	return included <= allowed
 }
 
-func (s *OrderService) handleInventoryAndMenu(menuItemID string, quantity int) error {
-	menuItem, err := s.menuService.GetMenuItemByID(menuItemID)
+// reprice re-evaluates the promotions the order qualifies for, replaces its
+// discount lines and recomputes its totals, so both follow every change to
+// its items.
+func (s *OrderService) reprice(txRepo repositories.OrderRepository, restaurantID string, orderID string) error {
+	order, err := txRepo.GetOrder(restaurantID, orderID)
 	if err != nil {
 		return err
 	}
-	zeroInventory, err := s.inventoryService.DeductInventoryForMenuItem(menuItem, quantity)
+	discounts, err := s.promotionService.Evaluate(order)
 	if err != nil {
 		return err
 	}
-	shouldReturn, err1 := zeroInventoryCheck(zeroInventory, menuItem, err, s)
-	if shouldReturn {
-		return err1
+	if len(discounts) > 0 || len(order.Discounts) > 0 {
+		if err := txRepo.ReplaceOrderDiscounts(orderID, discounts); err != nil {
+			return err
+		}
+		order.Discounts = discounts
 	}
-	return nil
+	return s.updateTotals(txRepo, order)
 }
 
-func zeroInventoryCheck(zeroInventory bool, menuItem *models.MenuItem, err error, s *OrderService) (bool, error) {
-	if zeroInventory {
-		menuItem.Available = false
-		err = s.menuService.UpdateMenuItem(menuItem)
-		if err != nil {
-			return true, err
-		}
+// updateTotals prices the order from its items and discount lines and saves
+// the breakdown on it and the taxes on its item lines.
+func (s *OrderService) updateTotals(txRepo repositories.OrderRepository, order *models.Order) error {
+	rules, err := s.paymentRepo.GetServiceChargeRules(order.RestaurantID)
+	if err != nil {
+		return err
 	}
-	return false, nil
+	order.SetTotals(models.ComputeTotals(order, rules))
+	if err := txRepo.UpdateOrderItemTaxes(order.OrderItems); err != nil {
+		return err
+	}
+	return txRepo.UpdateOrderTotals(order)
 }
 
-func (s *OrderService) UpdateOrderItem(orderID string, menuItemID string, observation string, status string) error {
-	return s.repo.WithTransaction(func(txRepo repositories.OrderRepository) error {
-		orderItem, err := txRepo.GetOrderItem(orderID, menuItemID, observation)
+// RedeemCoupon applies a coupon code to an open order and returns the order's
+// discount lines with it. Each redemption counts towards the coupon's usage
+// limit; a code can only be redeemed once per order. A customerID limits it to
+// the customer's own orders.
+func (s *OrderService) RedeemCoupon(customerID string, restaurantID string, orderID string, code string) ([]models.OrderDiscount, error) {
+	promotion, err := s.promotionService.FindCoupon(restaurantID, code)
+	if err != nil {
+		return nil, err
+	}
+	var discounts []models.OrderDiscount
+	err = s.repo.WithTransaction(func(txRepo repositories.OrderRepository) error {
+		order, err := txRepo.GetOrder(restaurantID, orderID)
 		if err != nil {
+			return ErrOrderNotFound
+		}
+		if err := checkCustomer(order, customerID); err != nil {
 			return err
 		}
-		orderItem.Status = models.OrderStatus(status)
-		if err := txRepo.UpdateOrderItem(orderItem); err != nil {
+		if order.Status.IsFinal() {
+			return fmt.Errorf("%w: order is %s", ErrInvalidStatusTransition, order.Status)
+		}
+		for _, coupon := range order.Coupons {
+			if coupon.PromotionID == promotion.PromotionID {
+				return ErrCouponAlreadyRedeemed
+			}
+		}
+		redeemed, err := txRepo.RedeemCoupon(&models.OrderCoupon{
+			OrderID:     orderID,
+			PromotionID: promotion.PromotionID,
+			Code:        *promotion.CouponCode,
+			RedeemedAt:  utils.GetCurrentUTCTime(),
+		})
+		if err != nil {
 			return err
 		}
+		if !redeemed {
+			return ErrCouponExhausted
+		}
+		if err := s.reprice(txRepo, restaurantID, orderID); err != nil {
+			return err
+		}
+		order, err = txRepo.GetOrder(restaurantID, orderID)
+		if err != nil {
+			return err
+		}
+		discounts = order.Discounts
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+	return discounts, nil
 }
 
-func (s *OrderService) DeleteOrderItem(orderID string, menuItemID string, observation string) error {
-	return s.repo.WithTransaction(func(txRepo repositories.OrderRepository) error {
-		orderItem, err := txRepo.GetOrderItem(orderID, menuItemID, observation)
-		if err != nil {
+// handleInventoryAndMenu deducts the recipe of the item, adjusted by its
+// modifiers, and takes the menu item off the menu once an ingredient runs out,
+// reporting whether it did.
+func (s *OrderService) handleInventoryAndMenu(txRepo repositories.OrderRepository, menuItem *models.MenuItem, modifiers []models.Modifier, quantity int) (bool, error) {
+	recipe := menuItem.WithModifiers(modifiers)
+	zeroInventory, err := s.inventoryService.DeductInventoryForMenuItem(txRepo.Inventory(), &recipe, quantity)
+	if err != nil {
+		return false, err
+	}
+	if !zeroInventory || !menuItem.Available {
+		return false, nil
+	}
+	if err := txRepo.Menu().SetMenuItemAvailable(menuItem.RestaurantID, menuItem.MenuItemID, false); err != nil {
+		return false, err
+	}
+	menuItem.Available = false
+	return true, nil
+}
+
+// recordSoldOut audits the menu items the actor's order took off the menu.
+func (s *OrderService) recordSoldOut(actorID string, soldOut []models.MenuItem) {
+	for _, menuItem := range soldOut {
+		before, after := menuItem, menuItem
+		before.Available = true
+		before.Ingredients, before.ModifierGroups = nil, nil
+		after.Ingredients, after.ModifierGroups = nil, nil
+		s.auditService.Record(actorID, menuItem.RestaurantID, models.AuditEntityMenuItem, menuItem.MenuItemID, models.AuditActionUpdate, before, after)
+	}
+}
+
+// restoreInventory puts back the stock used by units of the order item,
+// following its modifiers and, for combos, each of its components.
+func (s *OrderService) restoreInventory(txRepo repositories.OrderRepository, restaurantID string, orderItem *models.OrderItem, units int) error {
+	menuItem, err := txRepo.Menu().GetMenuItemByID(restaurantID, orderItem.MenuItemID)
+	if err != nil {
+		return err
+	}
+	recipe := menuItem.WithModifiers(orderItem.ChosenModifiers())
+	if err := s.inventoryService.AddInventoryForMenuItem(txRepo.Inventory(), &recipe, units); err != nil {
+		return err
+	}
+	for i := range orderItem.Components {
+		component := &orderItem.Components[i]
+		if err := s.inventoryService.AddInventoryForMenuItem(txRepo.Inventory(), &component.MenuItem, component.Quantity*units); err != nil {
 			return err
 		}
+	}
+	return nil
+}
 
-		if orderItem.Quantity > 1 {
-			orderItem.Quantity--
-		} else {
-			orderItem.Status = models.OrderStatus("cancelled")
-		}
+// findOrderItem loads the order item a reference names.
+func findOrderItem(repo repositories.OrderRepository, restaurantID string, orderID string, ref models.OrderItemRef) (*models.OrderItem, error) {
+	var item *models.OrderItem
+	var err error
+	if ref.OrderItemID != "" {
+		item, err = repo.GetOrderItemByID(restaurantID, orderID, ref.OrderItemID)
+	} else {
+		item, err = repo.GetOrderItem(restaurantID, orderID, ref.MenuItemID, ref.Observation)
+	}
+	if err != nil || !item.Matches(ref) {
+		return nil, ErrOrderItemNotFound
+	}
+	return item, nil
+}
 
+func (s *OrderService) UpdateOrderItem(restaurantID string, orderID string, ref models.OrderItemRef, status string) error {
+	var orderItem *models.OrderItem
+	err := s.repo.WithTransaction(func(txRepo repositories.OrderRepository) error {
+		var err error
+		orderItem, err = findOrderItem(txRepo, restaurantID, orderID, ref)
+		if err != nil {
+			return err
+		}
+		if !orderItem.TransitionTo(models.OrderStatus(status), utils.GetCurrentUTCTime()) {
+			return invalidTransition(orderItem.Status, models.OrderStatus(status))
+		}
 		if err := txRepo.UpdateOrderItem(orderItem); err != nil {
 			return err
 		}
+		// A cancelled item is no longer charged
+		if orderItem.Status == models.Cancelled {
+			return s.reprice(txRepo, restaurantID, orderID)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
 
-		menuItem, err := s.menuService.GetMenuItemByID(orderItem.MenuItemID)
+	s.eventHub.Publish(itemEvent(models.ItemStatusChangedEvent, restaurantID, orderItem))
+	return nil
+}
+
+// FireCourse releases the held items of a course to the kitchen queue and
+// returns them.
+func (s *OrderService) FireCourse(restaurantID string, orderID string, course int) ([]models.OrderItem, error) {
+	var fired []models.OrderItem
+	err := s.repo.WithTransaction(func(txRepo repositories.OrderRepository) error {
+		order, err := txRepo.GetOrder(restaurantID, orderID)
 		if err != nil {
-			return err
+			return ErrOrderNotFound
 		}
-		if err := s.inventoryService.AddInventoryForMenuItem(menuItem, orderItem.Quantity); err != nil {
-			return err
+		if order.Status.IsFinal() {
+			return fmt.Errorf("%w: order is %s", ErrInvalidStatusTransition, order.Status)
 		}
+		now := utils.GetCurrentUTCTime()
+		for _, item := range order.OrderItems {
+			if item.Course != course || !item.Fire(now) {
+				continue
+			}
+			if err := txRepo.UpdateOrderItem(&item); err != nil {
+				return err
+			}
+			fired = append(fired, item)
+		}
+		if len(fired) == 0 {
+			return ErrNothingToFire
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range fired {
+		s.eventHub.Publish(itemEvent(models.ItemStatusChangedEvent, restaurantID, &fired[i]))
+	}
+	return fired, nil
+}
 
-		order, err := txRepo.GetOrder(orderID)
+// RecallOrderItem sends a prepared item back to the kitchen queue.
+func (s *OrderService) RecallOrderItem(restaurantID string, orderID string, ref models.OrderItemRef) error {
+	var orderItem *models.OrderItem
+	err := s.repo.WithTransaction(func(txRepo repositories.OrderRepository) error {
+		var err error
+		orderItem, err = findOrderItem(txRepo, restaurantID, orderID, ref)
 		if err != nil {
 			return err
 		}
-		shouldReturn, err := CancelOrder(order, txRepo, s)
-		if shouldReturn {
-			return err
+		if !orderItem.Recall() {
+			return invalidTransition(orderItem.Status, models.Pending)
 		}
-		return nil
+		return txRepo.UpdateOrderItem(orderItem)
 	})
+	if err != nil {
+		return err
+	}
+
+	s.eventHub.Publish(itemEvent(models.ItemStatusChangedEvent, restaurantID, orderItem))
+	return nil
+}
+
+// DeleteOrderItem takes a unit of the item off the order, cancelling the item
+// with its last unit. Items already completed or cancelled, and the items of
+// paid or cancelled orders, are left as they are.
+func (s *OrderService) DeleteOrderItem(restaurantID string, orderID string, ref models.OrderItemRef) error {
+	var orderItem *models.OrderItem
+	var order *models.Order
+	err := s.repo.WithTransaction(func(txRepo repositories.OrderRepository) error {
+		var err error
+		orderItem, order, err = s.removeOrderItemUnit(txRepo, restaurantID, orderID, ref)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.eventHub.Publish(itemEvent(models.ItemStatusChangedEvent, restaurantID, orderItem))
+	if order.Status == models.Cancelled {
+		s.eventHub.Publish(orderStatusEvent(order))
+	}
+	return nil
+}
+
+// removeOrderItemUnit does the work of DeleteOrderItem within the caller's
+// transaction and returns the item and the order as they were left.
+func (s *OrderService) removeOrderItemUnit(txRepo repositories.OrderRepository, restaurantID string, orderID string, ref models.OrderItemRef) (*models.OrderItem, *models.Order, error) {
+	order, err := txRepo.GetOrder(restaurantID, orderID)
+	if err != nil {
+		return nil, nil, ErrOrderNotFound
+	}
+	if order.Status.IsFinal() {
+		return nil, nil, fmt.Errorf("%w: order is %s", ErrInvalidStatusTransition, order.Status)
+	}
+	orderItem, err := findOrderItem(txRepo, restaurantID, orderID, ref)
+	if err != nil {
+		return nil, nil, err
+	}
+	if orderItem.Status.IsFinal() {
+		return nil, nil, fmt.Errorf("%w: item is %s", ErrInvalidStatusTransition, orderItem.Status)
+	}
+
+	if orderItem.Quantity > 1 {
+		orderItem.Quantity--
+	} else if !orderItem.TransitionTo(models.Cancelled, utils.GetCurrentUTCTime()) {
+		return nil, nil, invalidTransition(orderItem.Status, models.Cancelled)
+	}
+
+	if err := txRepo.UpdateOrderItem(orderItem); err != nil {
+		return nil, nil, err
+	}
+	// Either way a single unit leaves the order
+	if err := s.restoreInventory(txRepo, restaurantID, orderItem, 1); err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.reprice(txRepo, restaurantID, orderID); err != nil {
+		return nil, nil, err
+	}
+	order, err = txRepo.GetOrder(restaurantID, orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if shouldReturn, err := CancelOrder(order, txRepo, s); shouldReturn {
+		return nil, nil, err
+	}
+	return orderItem, order, nil
 }
 
 func CancelOrder(order *models.Order, txRepo repositories.OrderRepository, s *OrderService) (bool, error) {
 	orderCancelled := true
 	for _, item := range order.OrderItems {
-		if item.Status != models.OrderStatus("cancelled") {
+		if item.Status != models.Cancelled {
 			orderCancelled = false
 			break
 		}
 	}
 	if orderCancelled {
-		order.Status = models.OrderStatus("cancelled")
+		from := order.Status
+		now := utils.GetCurrentUTCTime()
+		if !order.TransitionTo(models.Cancelled, now) {
+			return true, invalidTransition(from, models.Cancelled)
+		}
 		if err := txRepo.UpdateOrder(order); err != nil {
 			return true, err
 		}
-		if err := s.tableService.UpdateTableStatus(order.TableID, string(models.TableStatusAvailable)); err != nil {
+		if err := recordStatusChange(txRepo, order, &from, now); err != nil {
+			return true, err
+		}
+		if err := txRepo.UpdateTableStatus(order.RestaurantID, order.TableID, models.TableStatusAvailable); err != nil {
 			return true, err
 		}
 	}
 	return false, nil
 }
 
-func (s *OrderService) GetOrderItems(orderID string) ([]models.OrderItem, error) {
-	return s.repo.GetOrderItems(orderID)
+// GetOrderItems returns the order's items, limited like GetOrder.
+func (s *OrderService) GetOrderItems(customerID string, restaurantID string, orderID string) ([]models.OrderItem, error) {
+	if _, err := s.GetOrder(customerID, restaurantID, orderID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetOrderItems(restaurantID, orderID)
 }
 
-func (s *OrderService) CreateVoidOrderItem(orderID string, menuItemID string, restaurantID string, observation string) error {
-	return s.repo.WithTransaction(func(txRepo repositories.OrderRepository) error {
-		orderItem, err := txRepo.GetOrderItem(orderID, menuItemID, observation)
+// CreateVoidOrderItem takes a unit of the item off the order, like
+// DeleteOrderItem, and keeps it as a void that can be recovered to another order.
+func (s *OrderService) CreateVoidOrderItem(actorID string, restaurantID string, orderID string, ref models.OrderItemRef) error {
+	var voidOrderItem *models.VoidOrderItem
+	var orderItem *models.OrderItem
+	var order *models.Order
+	err := s.repo.WithTransaction(func(txRepo repositories.OrderRepository) error {
+		var err error
+		orderItem, order, err = s.removeOrderItemUnit(txRepo, restaurantID, orderID, ref)
 		if err != nil {
 			return err
 		}
-		orderItem.Quantity = orderItem.Quantity - 1
-		if orderItem.Quantity == 0 {
-			err = s.DeleteOrderItem(orderID, menuItemID, observation)
-			if err != nil {
-				return err
-			}
-		} else {
-			err = txRepo.UpdateOrderItem(orderItem)
-			if err != nil {
-				return err
-			}
-			err = s.inventoryService.AddInventoryForMenuItem(&orderItem.MenuItem, 1)
-			if err != nil {
-				return err
-			}
-		}
-		voidOrderItem := &models.VoidOrderItem{
+		voidOrderItem = &models.VoidOrderItem{
 			RestaurantID: restaurantID,
-			MenuItemID:   menuItemID,
+			MenuItemID:   orderItem.MenuItemID,
 			Quantity:     1, // Always void 1 item at a time
 			Price:        orderItem.Price,
-			Observation:  observation,
+			Observation:  observation(orderItem),
 			VoidReason:   "void",
 			Status:       models.VoidOrderItemVoided,
 			CreatedAt:    utils.GetCurrentUTCTime(),
@@ -280,16 +710,44 @@ func (s *OrderService) CreateVoidOrderItem(orderID string, menuItemID string, re
 		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	s.eventHub.Publish(models.OrderEvent{
+		Type:         models.ItemVoidedEvent,
+		RestaurantID: restaurantID,
+		OrderID:      orderID,
+		OrderItemID:  orderItem.OrderItemID,
+		MenuItemID:   orderItem.MenuItemID,
+		Observation:  voidOrderItem.Observation,
+		Quantity:     voidOrderItem.Quantity,
+	})
+	if order.Status == models.Cancelled {
+		s.eventHub.Publish(orderStatusEvent(order))
+	}
+	s.auditService.Record(actorID, restaurantID, models.AuditEntityVoidOrderItem, voidOrderItem.VoidOrderItemID, models.AuditActionVoid, nil, map[string]interface{}{
+		"order_id":      orderID,
+		"order_item_id": orderItem.OrderItemID,
+		"menu_item_id":  orderItem.MenuItemID,
+		"quantity":      voidOrderItem.Quantity,
+		"price":         voidOrderItem.Price,
+		"observation":   voidOrderItem.Observation,
+	})
+	return nil
 }
 
 func (s *OrderService) GetVoidOrderItems(restaurantID string) ([]models.VoidOrderItem, error) {
 	return s.repo.GetVoidOrderItems(restaurantID)
 }
 
-func (s *OrderService) RecoverVoidOrderItem(voidOrderItemID string, targetOrderID string) error {
-	return s.repo.WithTransaction(func(txRepo repositories.OrderRepository) error {
+func (s *OrderService) RecoverVoidOrderItem(actorID string, restaurantID string, voidOrderItemID string, targetOrderID string) error {
+	var voidItem *models.VoidOrderItem
+	var matchingOrderItem *models.OrderItem
+	err := s.repo.WithTransaction(func(txRepo repositories.OrderRepository) error {
 		// Get the void order item
-		voidItem, err := s.repo.GetVoidOrderItemByID(voidOrderItemID)
+		var err error
+		voidItem, err = txRepo.LockVoidOrderItem(restaurantID, voidOrderItemID)
 		if err != nil {
 			return err
 		}
@@ -300,13 +758,15 @@ func (s *OrderService) RecoverVoidOrderItem(voidOrderItemID string, targetOrderI
 		}
 
 		// Get the target order to check if it has a matching item
-		targetOrder, err := txRepo.GetOrder(targetOrderID)
+		targetOrder, err := txRepo.GetOrder(restaurantID, targetOrderID)
 		if err != nil {
-			return fmt.Errorf("target order not found")
+			return fmt.Errorf("%w: target order not found", ErrOrderNotFound)
+		}
+		if targetOrder.Status.IsFinal() {
+			return fmt.Errorf("%w: target order is %s", ErrInvalidStatusTransition, targetOrder.Status)
 		}
 
 		// Find a matching order item in the target order
-		var matchingOrderItem *models.OrderItem
 		for i := range targetOrder.OrderItems {
 			item := &targetOrder.OrderItems[i]
 			if item.MenuItemID == voidItem.MenuItemID {
@@ -319,19 +779,369 @@ func (s *OrderService) RecoverVoidOrderItem(voidOrderItemID string, targetOrderI
 			return fmt.Errorf("target order does not contain a matching item for recovery")
 		}
 
-		// Update the quantity of the existing order item
-		matchingOrderItem.Status = models.OrderStatus("prepared")
+		// The recovered dish is already cooked, so the matching item skips the kitchen
+		matchingOrderItem.AdvanceTo(models.Prepared, utils.GetCurrentUTCTime())
 		err = txRepo.UpdateOrderItem(matchingOrderItem)
 		if err != nil {
 			return err
 		}
 
 		// Delete the void order item
-		err = txRepo.DeleteVoidOrderItem(voidOrderItemID)
+		err = txRepo.DeleteVoidOrderItem(restaurantID, voidOrderItemID)
 		if err != nil {
 			return err
 		}
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	s.eventHub.Publish(itemEvent(models.ItemStatusChangedEvent, restaurantID, matchingOrderItem))
+	s.auditService.Record(actorID, restaurantID, models.AuditEntityVoidOrderItem, voidOrderItemID, models.AuditActionRecover, map[string]interface{}{
+		"menu_item_id": voidItem.MenuItemID,
+		"quantity":     voidItem.Quantity,
+		"price":        voidItem.Price,
+		"observation":  voidItem.Observation,
+		"status":       voidItem.Status,
+	}, map[string]interface{}{
+		"target_order_id": targetOrderID,
+		"status":          models.VoidOrderItemRecovered,
+	})
+	return nil
+}
+
+// TransferOrder moves an open order to another table, which becomes occupied.
+// The table it leaves is freed unless another open order sits at it. Tables
+// already occupied are refused: their orders are merged instead.
+func (s *OrderService) TransferOrder(restaurantID string, orderID string, tableID string) (*models.Order, error) {
+	transferred := false
+	err := s.repo.WithTransaction(func(txRepo repositories.OrderRepository) error {
+		orders, err := lockOpenOrders(txRepo, restaurantID, orderID)
+		if err != nil {
+			return err
+		}
+		order := orders[0]
+		if order.TableID == tableID {
+			return nil
+		}
+		table, err := txRepo.LockTable(restaurantID, tableID)
+		if err != nil {
+			return ErrTableNotFound
+		}
+		if table.Status == models.TableStatusOccupied {
+			return ErrTableOccupied
+		}
+
+		from := order.TableID
+		order.TableID = tableID
+		if err := txRepo.UpdateOrder(order); err != nil {
+			return err
+		}
+		if err := txRepo.UpdateTableStatus(restaurantID, tableID, models.TableStatusOccupied); err != nil {
+			return err
+		}
+		transferred = true
+		return s.releaseTable(txRepo, restaurantID, from)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := s.repo.GetOrder(restaurantID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if transferred {
+		s.eventHub.Publish(models.OrderEvent{
+			Type:         models.OrderTransferredEvent,
+			RestaurantID: restaurantID,
+			OrderID:      orderID,
+			TableID:      order.TableID,
+			Status:       order.Status,
+		})
+	}
+	return order, nil
+}
+
+// MergeOrders moves the items and coupons of open orders into another open
+// order, as when two tables join. The merged orders are cancelled, pointing at
+// the order that took their items, and their tables freed unless another open
+// order sits at them. Orders with payments taken cannot be merged away, since
+// the payments are allocated to their items.
+func (s *OrderService) MergeOrders(restaurantID string, targetOrderID string, orderIDs []string) (*models.Order, error) {
+	if len(orderIDs) == 0 || slices.Contains(orderIDs, targetOrderID) || hasDuplicates(orderIDs) {
+		return nil, fmt.Errorf("%w: name the orders to merge, apart from the one they merge into", ErrInvalidOrderMove)
+	}
+	var merged []*models.Order
+	var moved []models.OrderItem
+	err := s.repo.WithTransaction(func(txRepo repositories.OrderRepository) error {
+		orders, err := lockOpenOrders(txRepo, restaurantID, append([]string{targetOrderID}, orderIDs...)...)
+		if err != nil {
+			return err
+		}
+		target := orders[0]
+		for _, order := range orders[1:] {
+			if err := s.requireUnpaid(order); err != nil {
+				return err
+			}
+			for _, item := range order.OrderItems {
+				if item.Status == models.Cancelled {
+					continue
+				}
+				if err := txRepo.MoveOrderItem(item.OrderItemID, target.OrderID); err != nil {
+					return err
+				}
+				item.OrderID = target.OrderID
+				moved = append(moved, item)
+			}
+			if err := txRepo.MoveOrderCoupons(order.OrderID, target.OrderID); err != nil {
+				return err
+			}
+			if err := s.closeMergedOrder(txRepo, order, target); err != nil {
+				return err
+			}
+			merged = append(merged, order)
+		}
+		return s.reprice(txRepo, restaurantID, target.OrderID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.publishMoves(restaurantID, moved, merged)
+	return s.repo.GetOrder(restaurantID, targetOrderID)
+}
+
+// MoveOrderItems moves units of an open order's items to another open order,
+// splitting an item when only some of its units move. An order left with no
+// items is closed as if merged into the other. Items of orders with payments
+// taken cannot be moved, since the payments are allocated to them.
+func (s *OrderService) MoveOrderItems(restaurantID string, orderID string, targetOrderID string, moves []models.OrderItemMove) (*models.Order, error) {
+	if len(moves) == 0 || orderID == targetOrderID {
+		return nil, fmt.Errorf("%w: name the items to move to another order", ErrInvalidOrderMove)
+	}
+	var moved []models.OrderItem
+	var merged []*models.Order
+	err := s.repo.WithTransaction(func(txRepo repositories.OrderRepository) error {
+		orders, err := lockOpenOrders(txRepo, restaurantID, orderID, targetOrderID)
+		if err != nil {
+			return err
+		}
+		source, target := orders[0], orders[1]
+		if err := s.requireUnpaid(source); err != nil {
+			return err
+		}
+
+		items := make(map[string]*models.OrderItem)
+		for i := range source.OrderItems {
+			if source.OrderItems[i].Status != models.Cancelled {
+				items[source.OrderItems[i].OrderItemID] = &source.OrderItems[i]
+			}
+		}
+		for _, move := range moves {
+			item, ok := items[move.OrderItemID]
+			if !ok {
+				return ErrOrderItemNotFound
+			}
+			quantity := move.Quantity
+			if quantity == 0 {
+				quantity = item.Quantity
+			}
+			if quantity < 0 || quantity > item.Quantity {
+				return fmt.Errorf("%w: item %s has %d units", ErrInvalidOrderMove, item.OrderItemID, item.Quantity)
+			}
+
+			if quantity == item.Quantity {
+				if err := txRepo.MoveOrderItem(item.OrderItemID, target.OrderID); err != nil {
+					return err
+				}
+				item.OrderID = target.OrderID
+				moved = append(moved, *item)
+				delete(items, item.OrderItemID)
+				continue
+			}
+			part := item.Split(quantity)
+			part.OrderID = target.OrderID
+			if _, err := txRepo.AddOrderItem(&part); err != nil {
+				return err
+			}
+			if err := txRepo.UpdateOrderItemQuantity(item); err != nil {
+				return err
+			}
+			moved = append(moved, part)
+		}
+
+		if len(items) == 0 {
+			if err := s.closeMergedOrder(txRepo, source, target); err != nil {
+				return err
+			}
+			merged = append(merged, source)
+		} else if err := s.reprice(txRepo, restaurantID, source.OrderID); err != nil {
+			return err
+		}
+		return s.reprice(txRepo, restaurantID, target.OrderID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.publishMoves(restaurantID, moved, merged)
+	return s.repo.GetOrder(restaurantID, targetOrderID)
+}
+
+// lockOpenOrders locks the orders for the rest of the transaction and loads
+// them in the order asked for. Orders that are paid or cancelled are refused.
+func lockOpenOrders(txRepo repositories.OrderRepository, restaurantID string, orderIDs ...string) ([]*models.Order, error) {
+	locked, err := txRepo.LockOrders(restaurantID, orderIDs)
+	if err != nil || len(locked) != len(orderIDs) {
+		return nil, ErrOrderNotFound
+	}
+	orders := make([]*models.Order, len(orderIDs))
+	for i, orderID := range orderIDs {
+		if orders[i], err = txRepo.GetOrder(restaurantID, orderID); err != nil {
+			return nil, ErrOrderNotFound
+		}
+		if orders[i].Status.IsFinal() {
+			return nil, fmt.Errorf("%w: order %s is %s", ErrInvalidStatusTransition, orderID, orders[i].Status)
+		}
+	}
+	return orders, nil
+}
+
+// requireUnpaid refuses orders that have payments taken against them.
+func (s *OrderService) requireUnpaid(order *models.Order) error {
+	payments, err := s.paymentRepo.GetPayments(order.RestaurantID, order.OrderID)
+	if err != nil {
+		return err
+	}
+	for _, payment := range payments {
+		if payment.Status == models.PaymentCompleted {
+			return fmt.Errorf("%w: order %s", ErrOrderHasPayments, order.OrderID)
+		}
+	}
+	return nil
+}
+
+// closeMergedOrder cancels an order whose items went to another, pointing it
+// at that order, and frees its table unless another open order sits at it.
+func (s *OrderService) closeMergedOrder(txRepo repositories.OrderRepository, order *models.Order, into *models.Order) error {
+	from := order.Status
+	now := utils.GetCurrentUTCTime()
+	if !order.TransitionTo(models.Cancelled, now) {
+		return invalidTransition(from, models.Cancelled)
+	}
+	order.MergedInto = &into.OrderID
+	if err := txRepo.UpdateOrder(order); err != nil {
+		return err
+	}
+	if err := recordStatusChange(txRepo, order, &from, now); err != nil {
+		return err
+	}
+	if err := s.reprice(txRepo, order.RestaurantID, order.OrderID); err != nil {
+		return err
+	}
+	if order.TableID == into.TableID {
+		return nil
+	}
+	return s.releaseTable(txRepo, order.RestaurantID, order.TableID)
+}
+
+// releaseTable frees the table once no open order is left at it.
+func (s *OrderService) releaseTable(txRepo repositories.OrderRepository, restaurantID string, tableID string) error {
+	open, err := txRepo.HasOpenOrders(restaurantID, tableID)
+	if err != nil || open {
+		return err
+	}
+	return txRepo.UpdateTableStatus(restaurantID, tableID, models.TableStatusAvailable)
+}
+
+// publishMoves tells staff which items changed orders and which orders were
+// merged away.
+func (s *OrderService) publishMoves(restaurantID string, moved []models.OrderItem, merged []*models.Order) {
+	for i := range moved {
+		s.eventHub.Publish(itemEvent(models.ItemMovedEvent, restaurantID, &moved[i]))
+	}
+	for _, order := range merged {
+		s.eventHub.Publish(models.OrderEvent{
+			Type:         models.OrderMergedEvent,
+			RestaurantID: restaurantID,
+			OrderID:      order.OrderID,
+			TableID:      order.TableID,
+			Status:       order.Status,
+			MergedInto:   *order.MergedInto,
+		})
+	}
+}
+
+func hasDuplicates(ids []string) bool {
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			return true
+		}
+		seen[id] = true
+	}
+	return false
+}
+
+// GetOrderStatusHistory returns the statuses an order went through, oldest first.
+func (s *OrderService) GetOrderStatusHistory(restaurantID string, orderID string) ([]models.OrderStatusChange, error) {
+	return s.repo.GetOrderStatusHistory(restaurantID, orderID)
+}
+
+// GetKitchenPerformance aggregates how long orders created between startDate
+// and endDate spent being prepared, delivered and paid, grouped by hour of the
+// day or by day in the restaurant's time zone.
+func (s *OrderService) GetKitchenPerformance(restaurantID string, groupBy string, startDate time.Time, endDate time.Time) ([]models.KitchenPerformance, error) {
+	restaurant, err := s.restaurantRepo.GetRestaurant(restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetKitchenPerformance(restaurantID, groupBy, restaurant.Location().String(), startDate, endDate)
+}
+
+func recordStatusChange(repo repositories.OrderRepository, order *models.Order, from *models.OrderStatus, at time.Time) error {
+	return repo.AddOrderStatusChange(&models.OrderStatusChange{
+		OrderID:      order.OrderID,
+		RestaurantID: order.RestaurantID,
+		FromStatus:   from,
+		ToStatus:     order.Status,
+		ChangedAt:    at,
+	})
+}
+
+func orderStatusEvent(order *models.Order) models.OrderEvent {
+	return models.OrderEvent{
+		Type:         models.OrderStatusChangedEvent,
+		RestaurantID: order.RestaurantID,
+		OrderID:      order.OrderID,
+		TableID:      order.TableID,
+		Status:       order.Status,
+	}
+}
+
+func itemEvent(eventType string, restaurantID string, item *models.OrderItem) models.OrderEvent {
+	return models.OrderEvent{
+		Type:         eventType,
+		RestaurantID: restaurantID,
+		OrderID:      item.OrderID,
+		OrderItemID:  item.OrderItemID,
+		MenuItemID:   item.MenuItemID,
+		Observation:  observation(item),
+		Quantity:     item.Quantity,
+		Status:       item.Status,
+	}
+}
+
+func observation(item *models.OrderItem) string {
+	if item.Observation == nil {
+		return ""
+	}
+	return *item.Observation
+}
+
+func invalidTransition(from models.OrderStatus, to models.OrderStatus) error {
+	return fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, from, to)
 }
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"restaurant_manager/src/application/utils"
+	"restaurant_manager/src/domain/models"
+	"restaurant_manager/src/domain/repositories"
+	"time"
+)
+
+var (
+	ErrInvalidPayment            = errors.New("invalid payment")
+	ErrPaymentExceedsBalance     = errors.New("payment exceeds the outstanding balance")
+	ErrServiceChargeRuleNotFound = errors.New("service charge rule not found")
+	ErrInvalidServiceChargeRule  = errors.New("service charge rule needs a name, a percentage between 0 and 100 and a non-negative minimum")
+)
+
+type PaymentService struct {
+	repo         repositories.PaymentRepository
+	orderService *OrderService
+}
+
+func NewPaymentService(repo repositories.PaymentRepository, orderService *OrderService) *PaymentService {
+	return &PaymentService{repo: repo, orderService: orderService}
+}
+
+// RecordPayment takes a payment towards an order and returns the order's
+// balance after it. The payment may allocate units of the order's items or,
+// when seat is given, every unpaid unit served to that seat; its amount then
+// defaults to what those units cost. A payment never exceeds what is
+// outstanding. Tips come on top of the amount; when the guest accepts the
+// suggested service charge and names no tip, the tip is the charge on the
+// amount paid.
+//
+// Once the order is settled and has been delivered it is marked paid, in the
+// same transaction as the payment, which completes its items and frees the
+// table. Orders settled before delivery are left for staff to close as before.
+func (s *PaymentService) RecordPayment(payment *models.Payment, seat *int, withServiceCharge bool) (*models.OrderBalance, error) {
+	var balance models.OrderBalance
+	var paid *models.Order
+	err := s.orderService.repo.WithTransaction(func(txOrders repositories.OrderRepository) error {
+		txRepo := txOrders.Payments()
+		order, err := txRepo.LockOrder(payment.RestaurantID, payment.OrderID)
+		if err != nil {
+			return ErrOrderNotFound
+		}
+		if order.Status.IsFinal() {
+			return fmt.Errorf("%w: order is %s", ErrInvalidStatusTransition, order.Status)
+		}
+		payments, err := txRepo.GetPayments(payment.RestaurantID, payment.OrderID)
+		if err != nil {
+			return err
+		}
+		rules, err := txRepo.GetServiceChargeRules(payment.RestaurantID)
+		if err != nil {
+			return err
+		}
+		balance = models.NewOrderBalance(order, payments)
+		balance.ServiceCharge = models.ServiceChargeFor(rules, balance.Total)
+
+		if err := allocatePayment(payment, balance, seat); err != nil {
+			return err
+		}
+		if payment.Amount > balance.Outstanding {
+			return fmt.Errorf("%w: %.2f outstanding", ErrPaymentExceedsBalance, balance.Outstanding)
+		}
+		if payment.Tip < 0 {
+			return fmt.Errorf("%w: tip cannot be negative", ErrInvalidPayment)
+		}
+		if withServiceCharge && payment.Tip == 0 && balance.ServiceCharge != nil {
+			payment.Tip = balance.ServiceCharge.On(payment.Amount)
+		}
+		payment.Tip = models.RoundMoney(payment.Tip)
+		payment.Status = models.PaymentCompleted
+		payment.CreatedAt = utils.GetCurrentUTCTime()
+		if _, err := txRepo.CreatePayment(payment); err != nil {
+			return err
+		}
+		serviceCharge := balance.ServiceCharge
+		balance = models.NewOrderBalance(order, append(payments, *payment))
+		balance.ServiceCharge = serviceCharge
+
+		if balance.Settled() && order.Status == models.Delivered {
+			paid = &models.Order{OrderID: order.OrderID, RestaurantID: order.RestaurantID, Status: models.Paid}
+			if _, err := s.orderService.updateOrder(txOrders, paid); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if paid != nil {
+		s.orderService.eventHub.Publish(orderStatusEvent(paid))
+	}
+	return &balance, nil
+}
+
+// allocatePayment validates the payment against the balance, resolving a seat
+// to its unpaid item units and pricing each allocation from its item, net of
+// the item's discount.
+func allocatePayment(payment *models.Payment, balance models.OrderBalance, seat *int) error {
+	if !payment.PaymentMethod.IsValid() {
+		return fmt.Errorf("%w: method must be cash, card or online", ErrInvalidPayment)
+	}
+	if seat != nil {
+		if len(payment.Allocations) > 0 {
+			return fmt.Errorf("%w: allocate either a seat or items", ErrInvalidPayment)
+		}
+		for _, item := range balance.Items {
+			if item.Item.Seat != nil && *item.Item.Seat == *seat && item.UnpaidQuantity() > 0 {
+				payment.Allocations = append(payment.Allocations, models.PaymentAllocation{
+					OrderItemID: item.Item.OrderItemID,
+					Quantity:    item.UnpaidQuantity(),
+				})
+			}
+		}
+		if len(payment.Allocations) == 0 {
+			return fmt.Errorf("%w: seat %d has nothing left to pay", ErrInvalidPayment, *seat)
+		}
+	}
+
+	allocated := 0.0
+	remaining := make(map[string]models.ItemBalance, len(balance.Items))
+	for _, item := range balance.Items {
+		remaining[item.Item.OrderItemID] = item
+	}
+	for i := range payment.Allocations {
+		allocation := &payment.Allocations[i]
+		item, ok := remaining[allocation.OrderItemID]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrOrderItemNotFound, allocation.OrderItemID)
+		}
+		if allocation.Quantity < 1 || allocation.Quantity > item.UnpaidQuantity() {
+			return fmt.Errorf("%w: %d unpaid units of item %s", ErrInvalidPayment, item.UnpaidQuantity(), allocation.OrderItemID)
+		}
+		allocation.Amount = item.CostOf(allocation.Quantity)
+		allocated += allocation.Amount
+		item.Outstanding = models.RoundMoney(item.Outstanding - allocation.Amount)
+		item.PaidQuantity += allocation.Quantity
+		remaining[allocation.OrderItemID] = item
+	}
+
+	allocated = models.RoundMoney(allocated)
+	if payment.Amount == 0 {
+		payment.Amount = allocated
+	}
+	payment.Amount = models.RoundMoney(payment.Amount)
+	if payment.Amount <= 0 {
+		return fmt.Errorf("%w: amount must be positive", ErrInvalidPayment)
+	}
+	if len(payment.Allocations) > 0 && payment.Amount != allocated {
+		return fmt.Errorf("%w: allocated items cost %.2f", ErrInvalidPayment, allocated)
+	}
+	return nil
+}
+
+// GetBalance returns the order's balance and the payments taken for it.
+func (s *PaymentService) GetBalance(restaurantID string, orderID string) (*models.OrderBalance, []models.Payment, error) {
+	order, err := s.orderService.GetOrder("", restaurantID, orderID)
+	if err != nil {
+		return nil, nil, ErrOrderNotFound
+	}
+	payments, err := s.repo.GetPayments(restaurantID, orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	rules, err := s.repo.GetServiceChargeRules(restaurantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	balance := models.NewOrderBalance(order, payments)
+	balance.ServiceCharge = models.ServiceChargeFor(rules, balance.Total)
+	return &balance, payments, nil
+}
+
+// GetTipShares returns the tips taken between startDate and endDate, both days
+// included, per waiter and shift.
+func (s *PaymentService) GetTipShares(restaurantID string, startDate time.Time, endDate time.Time) ([]models.TipShare, error) {
+	return s.repo.GetTipShares(restaurantID, startDate, endDate.AddDate(0, 0, 1))
+}
+
+func (s *PaymentService) CreateServiceChargeRule(rule *models.ServiceChargeRule) (string, error) {
+	if !rule.IsValid() {
+		return "", ErrInvalidServiceChargeRule
+	}
+	return s.repo.CreateServiceChargeRule(rule)
+}
+
+func (s *PaymentService) GetServiceChargeRules(restaurantID string) ([]models.ServiceChargeRule, error) {
+	return s.repo.GetServiceChargeRules(restaurantID)
+}
+
+func (s *PaymentService) UpdateServiceChargeRule(rule *models.ServiceChargeRule) error {
+	if !rule.IsValid() {
+		return ErrInvalidServiceChargeRule
+	}
+	if _, err := s.repo.GetServiceChargeRule(rule.RestaurantID, rule.RuleID); err != nil {
+		return ErrServiceChargeRuleNotFound
+	}
+	return s.repo.UpdateServiceChargeRule(rule)
+}
+
+func (s *PaymentService) DeleteServiceChargeRule(restaurantID string, ruleID string) error {
+	if _, err := s.repo.GetServiceChargeRule(restaurantID, ruleID); err != nil {
+		return ErrServiceChargeRuleNotFound
+	}
+	return s.repo.DeleteServiceChargeRule(restaurantID, ruleID)
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"restaurant_manager/src/domain/models"
+	"restaurant_manager/src/domain/repositories"
+	"strings"
+)
+
+var (
+	ErrInvalidPromotion      = errors.New("invalid promotion")
+	ErrPromotionNotFound     = errors.New("promotion not found")
+	ErrCouponNotFound        = errors.New("coupon not found")
+	ErrCouponExhausted       = errors.New("coupon reached its usage limit")
+	ErrCouponAlreadyRedeemed = errors.New("coupon already redeemed on this order")
+)
+
+type PromotionService struct {
+	repo           repositories.PromotionRepository
+	restaurantRepo repositories.RestaurantRepository
+}
+
+func NewPromotionService(repo repositories.PromotionRepository, restaurantRepo repositories.RestaurantRepository) *PromotionService {
+	return &PromotionService{repo: repo, restaurantRepo: restaurantRepo}
+}
+
+func (s *PromotionService) CreatePromotion(promotion *models.Promotion) (string, error) {
+	if err := validatePromotion(promotion); err != nil {
+		return "", err
+	}
+	return s.repo.CreatePromotion(promotion)
+}
+
+func (s *PromotionService) GetPromotions(restaurantID string) ([]models.Promotion, error) {
+	return s.repo.GetPromotions(restaurantID)
+}
+
+func (s *PromotionService) UpdatePromotion(promotion *models.Promotion) error {
+	if err := validatePromotion(promotion); err != nil {
+		return err
+	}
+	if _, err := s.repo.GetPromotion(promotion.RestaurantID, promotion.PromotionID); err != nil {
+		return ErrPromotionNotFound
+	}
+	return s.repo.UpdatePromotion(promotion)
+}
+
+func (s *PromotionService) DeletePromotion(restaurantID string, promotionID string) error {
+	if _, err := s.repo.GetPromotion(restaurantID, promotionID); err != nil {
+		return ErrPromotionNotFound
+	}
+	return s.repo.DeletePromotion(restaurantID, promotionID)
+}
+
+func validatePromotion(promotion *models.Promotion) error {
+	promotion.Normalize()
+	if promotion.CouponCode != nil {
+		code := strings.TrimSpace(*promotion.CouponCode)
+		promotion.CouponCode = &code
+	}
+	if err := promotion.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPromotion, err)
+	}
+	return nil
+}
+
+// FindCoupon returns the active promotion a coupon code unlocks.
+func (s *PromotionService) FindCoupon(restaurantID string, code string) (*models.Promotion, error) {
+	promotion, err := s.repo.GetPromotionByCode(restaurantID, strings.TrimSpace(code))
+	if err != nil || !promotion.Active {
+		return nil, ErrCouponNotFound
+	}
+	return promotion, nil
+}
+
+// Evaluate works out the discount lines the restaurant's active promotions
+// give the order, judging happy hours in the restaurant's time zone.
+func (s *PromotionService) Evaluate(order *models.Order) ([]models.OrderDiscount, error) {
+	promotions, err := s.repo.GetActivePromotions(order.RestaurantID)
+	if err != nil {
+		return nil, err
+	}
+	if len(promotions) == 0 {
+		return nil, nil
+	}
+	restaurant, err := s.restaurantRepo.GetRestaurant(order.RestaurantID)
+	if err != nil {
+		return nil, err
+	}
+	return models.ApplyPromotions(order, promotions, order.Coupons, restaurant.Location()), nil
+}
@@ -25,6 +25,6 @@ func (s *RawIngredientsService) UpdateRawIngredients(ingredients []models.RawIng
 	return s.repository.UpdateMany(ingredients, restaurantID)
 }
 
-func (s *RawIngredientsService) DeleteRawIngredient(id string) error {
-	return s.repository.Delete(id)
+func (s *RawIngredientsService) DeleteRawIngredient(restaurantID string, id string) error {
+	return s.repository.Delete(restaurantID, id)
 }
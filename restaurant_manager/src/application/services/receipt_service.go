@@ -0,0 +1,141 @@
+package services
+
+import (
+	"errors"
+	"restaurant_manager/src/application/utils"
+	"restaurant_manager/src/domain/models"
+	"restaurant_manager/src/domain/repositories"
+)
+
+var ErrInvalidTicketFormat = errors.New("format must be text, escpos or pdf")
+
+// kitchenStation receives the items of restaurants without a station for them.
+var kitchenStation = models.Station{Name: "Cocina"}
+
+type ReceiptService struct {
+	orderRepo      repositories.OrderRepository
+	paymentRepo    repositories.PaymentRepository
+	restaurantRepo repositories.RestaurantRepository
+	stationRepo    repositories.StationRepository
+}
+
+func NewReceiptService(orderRepo repositories.OrderRepository, paymentRepo repositories.PaymentRepository, restaurantRepo repositories.RestaurantRepository, stationRepo repositories.StationRepository) *ReceiptService {
+	return &ReceiptService{orderRepo: orderRepo, paymentRepo: paymentRepo, restaurantRepo: restaurantRepo, stationRepo: stationRepo}
+}
+
+// GetReceipt renders the order's bill, with the payments taken so far, on the
+// restaurant's paper. A customerID limits it to the customer's own orders.
+func (s *ReceiptService) GetReceipt(customerID string, restaurantID string, orderID string, format models.TicketFormat) ([]byte, error) {
+	if !format.IsValid() {
+		return nil, ErrInvalidTicketFormat
+	}
+	order, err := s.orderRepo.GetOrder(restaurantID, orderID)
+	if err != nil {
+		return nil, ErrOrderNotFound
+	}
+	if err := checkCustomer(order, customerID); err != nil {
+		return nil, err
+	}
+	payments, err := s.paymentRepo.GetPayments(restaurantID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	restaurant, err := s.restaurantRepo.GetRestaurant(restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	receipt := models.NewReceipt(order, payments, *restaurant, utils.GetCurrentUTCTime())
+	return models.RenderTickets(format, []models.Ticket{receipt}), nil
+}
+
+// PrintKitchenTickets renders a ticket for each station with the units of the
+// order's fired items it has not been sent yet, and records them as sent, so
+// every unit is ticketed once. An empty stationID prints every station's
+// ticket. It returns no tickets when there is nothing new to send.
+func (s *ReceiptService) PrintKitchenTickets(restaurantID string, orderID string, stationID string, format models.TicketFormat) ([]byte, int, error) {
+	if !format.IsValid() {
+		return nil, 0, ErrInvalidTicketFormat
+	}
+	order, err := s.orderRepo.GetOrder(restaurantID, orderID)
+	if err != nil {
+		return nil, 0, ErrOrderNotFound
+	}
+	restaurant, err := s.restaurantRepo.GetRestaurant(restaurantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	stations, err := s.stationRepo.GetStations(restaurantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if stationID != "" && !hasStation(stations, stationID) {
+		return nil, 0, ErrStationNotFound
+	}
+
+	routed := make(map[string][]models.OrderItem)
+	err = s.orderRepo.WithTransaction(func(txRepo repositories.OrderRepository) error {
+		for _, item := range order.OrderItems {
+			if item.Status == models.Held || item.Status == models.Cancelled || item.Quantity <= item.TicketedQuantity {
+				continue
+			}
+			station := routeToStation(stations, item.MenuItem)
+			if stationID != "" && station.StationID != stationID {
+				continue
+			}
+			claimed, err := txRepo.ClaimTicketedUnits(item.OrderItemID, item.TicketedQuantity, item.Quantity)
+			if err != nil {
+				return err
+			}
+			if !claimed {
+				continue
+			}
+			item.Quantity -= item.TicketedQuantity
+			routed[station.StationID] = append(routed[station.StationID], item)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	printedAt := utils.GetCurrentUTCTime()
+	var tickets []models.Ticket
+	for _, station := range append(stations, kitchenStation) {
+		if items := routed[station.StationID]; len(items) > 0 {
+			tickets = append(tickets, models.NewKitchenTicket(order, station, items, *restaurant, printedAt))
+		}
+	}
+	if len(tickets) == 0 {
+		return nil, 0, nil
+	}
+	return models.RenderTickets(format, tickets), len(tickets), nil
+}
+
+// routeToStation returns the station preparing the menu item: its own or the
+// oldest station of the kind its category goes to, as the stations' queues
+// route it. Stations are expected oldest first.
+func routeToStation(stations []models.Station, menuItem models.MenuItem) models.Station {
+	if menuItem.StationID != nil {
+		for _, station := range stations {
+			if station.StationID == *menuItem.StationID {
+				return station
+			}
+		}
+	}
+	kind := menuItem.Category.DefaultStationKind()
+	for _, station := range stations {
+		if station.Kind == kind {
+			return station
+		}
+	}
+	return kitchenStation
+}
+
+func hasStation(stations []models.Station, stationID string) bool {
+	for _, station := range stations {
+		if station.StationID == stationID {
+			return true
+		}
+	}
+	return false
+}
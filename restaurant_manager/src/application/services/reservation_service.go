@@ -0,0 +1,352 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"restaurant_manager/src/application/utils"
+	"restaurant_manager/src/domain/models"
+	"restaurant_manager/src/domain/repositories"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrInvalidReservation    = errors.New("invalid reservation")
+	ErrReservationNotFound   = errors.New("reservation not found")
+	ErrReservationConflict   = errors.New("table is already booked at that time")
+	ErrReservationClosed     = errors.New("only booked reservations can be changed")
+	ErrInvalidWaitlistEntry  = errors.New("invalid waitlist entry")
+	ErrWaitlistEntryNotFound = errors.New("waitlist entry not found")
+)
+
+// tableTurnWindow is how far back paid orders are looked at to learn how long
+// parties stay at their tables.
+const tableTurnWindow = 30 * 24 * time.Hour
+
+type ReservationService struct {
+	repo           repositories.ReservationRepository
+	tableService   *TableService
+	restaurantRepo repositories.RestaurantRepository
+}
+
+func NewReservationService(repo repositories.ReservationRepository, tableService *TableService, restaurantRepo repositories.RestaurantRepository) *ReservationService {
+	return &ReservationService{repo: repo, tableService: tableService, restaurantRepo: restaurantRepo}
+}
+
+// CreateReservation books the party, refusing tables another booking holds at
+// any moment of its stay.
+func (s *ReservationService) CreateReservation(reservation *models.Reservation) (string, error) {
+	if err := validateReservation(reservation); err != nil {
+		return "", err
+	}
+	var reservationID string
+	err := s.repo.WithTransaction(func(txRepo repositories.ReservationRepository) error {
+		if err := checkReservationConflicts(txRepo, reservation, ""); err != nil {
+			return err
+		}
+		var err error
+		reservationID, err = txRepo.CreateReservation(reservation)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return reservationID, s.SyncTableStatuses(reservation.RestaurantID)
+}
+
+// GetReservations returns the reservations starting on the date, a day in the
+// restaurant's time zone.
+func (s *ReservationService) GetReservations(restaurantID string, date time.Time) ([]models.Reservation, error) {
+	if err := s.SyncTableStatuses(restaurantID); err != nil {
+		return nil, err
+	}
+	restaurant, err := s.restaurantRepo.GetRestaurant(restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	loc := restaurant.Location()
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
+	return s.repo.GetReservations(restaurantID, day.UTC(), day.AddDate(0, 0, 1).UTC())
+}
+
+// UpdateReservation reschedules a booked reservation or changes its party or
+// tables, with the same conflict checks as a new booking.
+func (s *ReservationService) UpdateReservation(reservation *models.Reservation) error {
+	current, err := s.repo.GetReservation(reservation.RestaurantID, reservation.ReservationID)
+	if err != nil {
+		return ErrReservationNotFound
+	}
+	if current.Status != models.ReservationBooked {
+		return ErrReservationClosed
+	}
+	if err := validateReservation(reservation); err != nil {
+		return err
+	}
+	err = s.repo.WithTransaction(func(txRepo repositories.ReservationRepository) error {
+		if err := checkReservationConflicts(txRepo, reservation, reservation.ReservationID); err != nil {
+			return err
+		}
+		return txRepo.UpdateReservation(reservation)
+	})
+	if err != nil {
+		return err
+	}
+	return s.SyncTableStatuses(reservation.RestaurantID)
+}
+
+// UpdateReservationStatus seats the party, cancels the booking or marks it a
+// no-show. Seating occupies the reservation's tables; the others give them
+// back to walk-ins.
+func (s *ReservationService) UpdateReservationStatus(restaurantID string, reservationID string, status models.ReservationStatus) (*models.Reservation, error) {
+	if !status.IsValid() {
+		return nil, fmt.Errorf("%w: status must be booked, seated, no_show or cancelled", ErrInvalidReservation)
+	}
+	var reservation *models.Reservation
+	err := s.repo.WithTransaction(func(txRepo repositories.ReservationRepository) error {
+		var err error
+		reservation, err = txRepo.GetReservation(restaurantID, reservationID)
+		if err != nil {
+			return ErrReservationNotFound
+		}
+		now := utils.GetCurrentUTCTime()
+		if !reservation.TransitionTo(status, now) {
+			return ErrInvalidStatusTransition
+		}
+		if status == models.ReservationSeated {
+			if err := checkSeating(txRepo, reservation, now); err != nil {
+				return err
+			}
+		}
+		if err := txRepo.UpdateReservationStatus(reservation); err != nil {
+			return err
+		}
+		if status == models.ReservationSeated {
+			for i, table := range reservation.Tables {
+				if err := txRepo.UpdateTableStatus(restaurantID, table.TableID, models.TableStatusOccupied); err != nil {
+					return err
+				}
+				reservation.Tables[i].Table.Status = models.TableStatusOccupied
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reservation, s.SyncTableStatuses(restaurantID)
+}
+
+// checkSeating locks the reservation's tables and refuses to seat the party,
+// arriving now, at tables that are occupied, held for another booking or
+// booked by another reservation before the party would leave. Parties marked
+// no-shows that turn up late are checked the same way.
+func checkSeating(txRepo repositories.ReservationRepository, reservation *models.Reservation, now time.Time) error {
+	if len(reservation.Tables) == 0 {
+		return fmt.Errorf("%w: assign a table before seating the party", ErrInvalidReservation)
+	}
+	stay := *reservation
+	stay.StartsAt = now
+	if err := checkReservationConflicts(txRepo, &stay, reservation.ReservationID); err != nil {
+		return err
+	}
+	// The tables are locked now, so their statuses are read again
+	locked, err := txRepo.GetReservation(reservation.RestaurantID, reservation.ReservationID)
+	if err != nil {
+		return err
+	}
+	var reserved []string
+	for _, table := range locked.Tables {
+		switch table.Table.Status {
+		case models.TableStatusOccupied:
+			return fmt.Errorf("%w: table %d", ErrTableOccupied, table.Table.TableNumber)
+		case models.TableStatusReserved:
+			reserved = append(reserved, table.TableID)
+		}
+	}
+	if len(reserved) == 0 {
+		return nil
+	}
+	holds, err := txRepo.GetConflictingReservations(reservation.RestaurantID, reserved, now, now.Add(models.ReservationHoldWindow), reservation.ReservationID)
+	if err != nil {
+		return err
+	}
+	if len(holds) > 0 {
+		return fmt.Errorf("%w: the table is held for reservation %s", ErrReservationConflict, holds[0].ReservationID)
+	}
+	return nil
+}
+
+// SyncTableStatuses holds the tables of the restaurant's upcoming bookings as
+// reserved, marks parties that did not arrive in time as no-shows and releases
+// the tables no booking holds any more. An empty restaurantID syncs every
+// restaurant.
+func (s *ReservationService) SyncTableStatuses(restaurantID string) error {
+	now := utils.GetCurrentUTCTime()
+	return s.repo.SyncTableStatuses(restaurantID, now.Add(models.ReservationHoldWindow), now.Add(-models.ReservationGracePeriod))
+}
+
+// SyncTableStatusesEvery syncs the tables of every restaurant at the given
+// interval, so tables change status around their reservations without anyone
+// asking. It never returns.
+func (s *ReservationService) SyncTableStatusesEvery(interval time.Duration) {
+	for range time.Tick(interval) {
+		if err := s.SyncTableStatuses(""); err != nil {
+			log.Error().Err(err).Msg("Failed to sync table statuses with reservations")
+		}
+	}
+}
+
+func validateReservation(reservation *models.Reservation) error {
+	reservation.Normalize()
+	if err := reservation.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidReservation, err)
+	}
+	reservation.StartsAt = reservation.StartsAt.UTC()
+	if reservation.StartsAt.Add(models.ReservationGracePeriod).Before(utils.GetCurrentUTCTime()) {
+		return fmt.Errorf("%w: starts_at is in the past", ErrInvalidReservation)
+	}
+	return nil
+}
+
+// checkReservationConflicts locks the reservation's tables and rejects it if
+// another booking, other than excludeID, holds any of them during its stay.
+func checkReservationConflicts(txRepo repositories.ReservationRepository, reservation *models.Reservation, excludeID string) error {
+	tableIDs := reservation.TableIDs()
+	if len(tableIDs) == 0 {
+		return nil
+	}
+	locked, err := txRepo.LockTables(reservation.RestaurantID, tableIDs)
+	if err != nil {
+		return err
+	}
+	if len(locked) != len(tableIDs) {
+		return ErrTableNotFound
+	}
+	conflicts, err := txRepo.GetConflictingReservations(reservation.RestaurantID, tableIDs, reservation.StartsAt, reservation.EndsAt(), excludeID)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		conflict := conflicts[0]
+		return fmt.Errorf("%w: reservation %s holds it from %s to %s", ErrReservationConflict,
+			conflict.ReservationID, conflict.StartsAt.Format(time.RFC3339), conflict.EndsAt().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// AddToWaitlist puts a walk-in party at the end of the line and quotes it a
+// wait, unless staff quoted one themselves. It returns the party's place in
+// line.
+func (s *ReservationService) AddToWaitlist(entry *models.WaitlistEntry, quotedWait *int) (int, error) {
+	if quotedWait != nil {
+		entry.QuotedWaitMinutes = *quotedWait
+	}
+	if err := entry.Validate(); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidWaitlistEntry, err)
+	}
+	waiting, err := s.repo.GetWaitlist(entry.RestaurantID)
+	if err != nil {
+		return 0, err
+	}
+	if quotedWait == nil {
+		wait, err := s.quoteWait(entry.RestaurantID, len(waiting))
+		if err != nil {
+			return 0, err
+		}
+		entry.QuotedWaitMinutes = int(wait / time.Minute)
+	}
+	if _, err := s.repo.CreateWaitlistEntry(entry); err != nil {
+		return 0, err
+	}
+	return len(waiting) + 1, nil
+}
+
+// quoteWait estimates the wait of a party with ahead parties in front of it
+// from the free tables, how long the occupied ones have been busy and how
+// long parties have stayed at their tables over the last month. Reserved
+// tables are kept for their bookings.
+func (s *ReservationService) quoteWait(restaurantID string, ahead int) (time.Duration, error) {
+	now := utils.GetCurrentUTCTime()
+	turn, err := s.repo.GetAverageTableTurn(restaurantID, now.Add(-tableTurnWindow))
+	if err != nil {
+		return 0, err
+	}
+	if turn <= 0 {
+		turn = models.DefaultTableTurn
+	}
+	tables, err := s.tableService.GetTablesByRestaurantId(restaurantID)
+	if err != nil {
+		return 0, err
+	}
+	occupancy, err := s.repo.GetTableOccupancy(restaurantID)
+	if err != nil {
+		return 0, err
+	}
+	busySince := make(map[string]time.Time, len(occupancy))
+	for _, table := range occupancy {
+		busySince[table.TableID] = table.Since
+	}
+
+	free := 0
+	var busyFor []time.Duration
+	for _, table := range tables {
+		switch table.Status {
+		case models.TableStatusAvailable:
+			free++
+		case models.TableStatusOccupied:
+			since, ok := busySince[table.TableID]
+			if !ok {
+				since = now
+			}
+			busyFor = append(busyFor, models.RemainingTurn(since, now, turn))
+		}
+	}
+	return models.QuoteWait(free, busyFor, ahead, turn), nil
+}
+
+// GetWaitlist returns the parties still waiting, in the order they joined.
+func (s *ReservationService) GetWaitlist(restaurantID string) ([]models.WaitlistEntry, error) {
+	return s.repo.GetWaitlist(restaurantID)
+}
+
+// UpdateWaitlistEntryStatus takes a waiting party off the line, either seated,
+// occupying the table it was given if any, or gone.
+func (s *ReservationService) UpdateWaitlistEntryStatus(restaurantID string, entryID string, status models.WaitlistStatus, tableID string) (*models.WaitlistEntry, error) {
+	entry, err := s.repo.GetWaitlistEntry(restaurantID, entryID)
+	if err != nil {
+		return nil, ErrWaitlistEntryNotFound
+	}
+	if entry.Status != models.WaitlistWaiting {
+		return nil, ErrInvalidStatusTransition
+	}
+	now := utils.GetCurrentUTCTime()
+	switch status {
+	case models.WaitlistSeated:
+		if tableID != "" {
+			table, err := s.tableService.GetTable(restaurantID, tableID)
+			if err != nil {
+				return nil, ErrTableNotFound
+			}
+			if table.Status == models.TableStatusOccupied {
+				return nil, ErrTableOccupied
+			}
+			entry.TableID = &tableID
+		}
+		entry.SeatedAt = &now
+	case models.WaitlistLeft:
+		entry.LeftAt = &now
+	default:
+		return nil, fmt.Errorf("%w: status must be seated or left", ErrInvalidWaitlistEntry)
+	}
+	entry.Status = status
+	if err := s.repo.UpdateWaitlistEntryStatus(entry); err != nil {
+		return nil, err
+	}
+	if entry.TableID != nil {
+		if err := s.tableService.UpdateTableStatus(restaurantID, *entry.TableID, string(models.TableStatusOccupied)); err != nil {
+			return nil, err
+		}
+	}
+	return entry, nil
+}
@@ -1,10 +1,18 @@
 package services
 
 import (
+	"errors"
 	"mime/multipart"
 	"restaurant_manager/src/domain/models"
 	"restaurant_manager/src/domain/ports"
 	"restaurant_manager/src/domain/repositories"
+	"time"
+)
+
+var (
+	ErrInvalidTimeZone  = errors.New("time zone must be an IANA name such as America/Bogota")
+	ErrInvalidTaxRegime = errors.New("tax regime must be inc, iva or not_responsible")
+	ErrInvalidPaper     = errors.New("paper width must be 58 or 80")
 )
 
 type RestaurantService struct {
@@ -17,6 +25,9 @@ func NewRestaurantService(repo repositories.RestaurantRepository, awsS3 ports.St
 }
 
 func (s *RestaurantService) CreateRestaurant(restaurant *models.Restaurant) (string, error) {
+	if err := validateRestaurant(restaurant); err != nil {
+		return "", err
+	}
 	return s.repo.CreateRestaurant(restaurant)
 }
 
@@ -25,9 +36,29 @@ func (s *RestaurantService) GetRestaurant(restaurantID string) (*models.Restaura
 }
 
 func (s *RestaurantService) UpdateRestaurant(restaurant *models.Restaurant) error {
+	if err := validateRestaurant(restaurant); err != nil {
+		return err
+	}
 	return s.repo.UpdateRestaurant(restaurant)
 }
 
+// validateRestaurant checks the restaurant's time zone, tax regime and paper
+// width. Empty values leave the current ones alone.
+func validateRestaurant(restaurant *models.Restaurant) error {
+	if restaurant.TimeZone != "" {
+		if _, err := time.LoadLocation(restaurant.TimeZone); err != nil {
+			return ErrInvalidTimeZone
+		}
+	}
+	if restaurant.TaxRegime != "" && !restaurant.TaxRegime.IsValid() {
+		return ErrInvalidTaxRegime
+	}
+	if restaurant.PaperWidth != 0 && !restaurant.PaperWidth.IsValid() {
+		return ErrInvalidPaper
+	}
+	return nil
+}
+
 func (s *RestaurantService) DeleteRestaurant(restaurantID string) error {
 	return s.repo.DeleteRestaurant(restaurantID)
 }
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"errors"
+	"restaurant_manager/src/application/utils"
+	"restaurant_manager/src/domain/models"
+	"restaurant_manager/src/domain/repositories"
+	"sort"
+	"time"
+)
+
+var (
+	ErrAlreadyClockedIn = errors.New("already clocked in")
+	ErrNotClockedIn     = errors.New("not clocked in")
+	ErrShiftOverlap     = errors.New("shift overlaps another shift of the employee")
+	ErrInvalidShift     = errors.New("clock_out must be after clock_in")
+	ErrEmployeeNotFound = errors.New("employee not found")
+)
+
+type ShiftService struct {
+	repo     repositories.ShiftRepository
+	userRepo repositories.UserRepository
+}
+
+func NewShiftService(repo repositories.ShiftRepository, userRepo repositories.UserRepository) *ShiftService {
+	return &ShiftService{repo: repo, userRepo: userRepo}
+}
+
+// ClockIn opens a shift for the user starting now.
+func (s *ShiftService) ClockIn(restaurantID string, userID string) (*models.Shift, error) {
+	if _, err := s.repo.GetOpenShift(userID); err == nil {
+		return nil, ErrAlreadyClockedIn
+	}
+
+	shift := &models.Shift{
+		UserID:       userID,
+		RestaurantID: restaurantID,
+		ClockIn:      utils.GetCurrentUTCTime(),
+	}
+	if err := s.checkOverlap(shift); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.CreateShift(shift); err != nil {
+		return nil, err
+	}
+	return shift, nil
+}
+
+// ClockOut closes the user's open shift.
+func (s *ShiftService) ClockOut(userID string) (*models.Shift, error) {
+	shift, err := s.repo.GetOpenShift(userID)
+	if err != nil {
+		return nil, ErrNotClockedIn
+	}
+
+	clockOut := utils.GetCurrentUTCTime()
+	if err := s.repo.CloseShift(shift.ShiftID, clockOut); err != nil {
+		return nil, err
+	}
+	shift.ClockOut = &clockOut
+	return shift, nil
+}
+
+// CreateShift records a shift entered by an admin, e.g. a forgotten clock-in.
+func (s *ShiftService) CreateShift(shift *models.Shift) (string, error) {
+	if shift.ClockIn.IsZero() || (shift.ClockOut != nil && !shift.ClockOut.After(shift.ClockIn)) {
+		return "", ErrInvalidShift
+	}
+
+	user, err := s.userRepo.GetUserById(shift.UserID)
+	if err != nil || user.RestaurantId == nil || *user.RestaurantId != shift.RestaurantID {
+		return "", ErrEmployeeNotFound
+	}
+
+	if err := s.checkOverlap(shift); err != nil {
+		return "", err
+	}
+	return s.repo.CreateShift(shift)
+}
+
+func (s *ShiftService) checkOverlap(shift *models.Shift) error {
+	overlaps, err := s.repo.HasOverlappingShift(shift.UserID, shift.ClockIn, shift.ClockOut)
+	if err != nil {
+		return err
+	}
+	if overlaps {
+		return ErrShiftOverlap
+	}
+	return nil
+}
+
+func (s *ShiftService) GetShifts(restaurantID string, userID string, startDate, endDate time.Time) ([]models.Shift, error) {
+	return s.repo.GetShifts(restaurantID, userID, startDate, endDate)
+}
+
+// GetHoursReport sums the hours each employee worked between startDate and
+// endDate. Shifts crossing the range boundaries only count the part inside it,
+// and open shifts count up to now.
+func (s *ShiftService) GetHoursReport(restaurantID string, startDate, endDate time.Time) ([]models.ShiftHours, error) {
+	shifts, err := s.repo.GetShifts(restaurantID, "", startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	now := utils.GetCurrentUTCTime()
+	byUser := map[string]*models.ShiftHours{}
+	for _, shift := range shifts {
+		start := shift.ClockIn
+		if start.Before(startDate) {
+			start = startDate
+		}
+		end := now
+		if shift.ClockOut != nil {
+			end = *shift.ClockOut
+		}
+		if end.After(endDate) {
+			end = endDate
+		}
+
+		hours, ok := byUser[shift.UserID]
+		if !ok {
+			hours = &models.ShiftHours{UserID: shift.UserID, Name: shift.User.Name}
+			byUser[shift.UserID] = hours
+		}
+		hours.Shifts++
+		if end.After(start) {
+			hours.Hours += end.Sub(start).Hours()
+		}
+	}
+
+	report := make([]models.ShiftHours, 0, len(byUser))
+	for _, hours := range byUser {
+		report = append(report, *hours)
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].Name < report[j].Name })
+	return report, nil
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"errors"
+	"restaurant_manager/src/domain/models"
+	"restaurant_manager/src/domain/repositories"
+	"strings"
+)
+
+var (
+	ErrStationNotFound  = errors.New("station not found")
+	ErrInvalidStation   = errors.New("station needs a name and a kind of grill, cold, bar or dessert")
+	ErrItemNotAtStation = errors.New("order item is not waiting at this station")
+	ErrNothingToRecall  = errors.New("no bumped item to recall at this station")
+)
+
+type StationService struct {
+	repo         repositories.StationRepository
+	orderService *OrderService
+}
+
+func NewStationService(repo repositories.StationRepository, orderService *OrderService) *StationService {
+	return &StationService{repo: repo, orderService: orderService}
+}
+
+func (s *StationService) CreateStation(station *models.Station) (string, error) {
+	if strings.TrimSpace(station.Name) == "" || !models.IsStationKind(station.Kind) {
+		return "", ErrInvalidStation
+	}
+	return s.repo.CreateStation(station)
+}
+
+func (s *StationService) GetStations(restaurantID string) ([]models.Station, error) {
+	return s.repo.GetStations(restaurantID)
+}
+
+func (s *StationService) UpdateStation(station *models.Station) error {
+	if station.Kind != "" && !models.IsStationKind(station.Kind) {
+		return ErrInvalidStation
+	}
+	if _, err := s.repo.GetStation(station.RestaurantID, station.StationID); err != nil {
+		return ErrStationNotFound
+	}
+	return s.repo.UpdateStation(station)
+}
+
+func (s *StationService) DeleteStation(restaurantID string, stationID string) error {
+	return s.repo.DeleteStation(restaurantID, stationID)
+}
+
+// GetStationQueue returns the pending items routed to the station, oldest first.
+func (s *StationService) GetStationQueue(restaurantID string, stationID string) ([]models.OrderItem, error) {
+	station, categories, err := s.routing(restaurantID, stationID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetStationItems(restaurantID, station.StationID, categories, models.Pending)
+}
+
+// BumpItem marks a pending item of the station's queue as prepared.
+func (s *StationService) BumpItem(restaurantID string, stationID string, orderID string, ref models.OrderItemRef) error {
+	queue, err := s.GetStationQueue(restaurantID, stationID)
+	if err != nil {
+		return err
+	}
+	for _, item := range queue {
+		if item.OrderID == orderID && item.Matches(ref) {
+			return s.orderService.UpdateOrderItem(restaurantID, orderID, models.OrderItemRef{OrderItemID: item.OrderItemID}, string(models.Prepared))
+		}
+	}
+	return ErrItemNotAtStation
+}
+
+// RecallItem puts the item the station bumped last back in its queue.
+func (s *StationService) RecallItem(restaurantID string, stationID string) (*models.OrderItem, error) {
+	station, categories, err := s.routing(restaurantID, stationID)
+	if err != nil {
+		return nil, err
+	}
+	item, err := s.repo.GetLastBumpedItem(restaurantID, station.StationID, categories)
+	if err != nil {
+		return nil, ErrNothingToRecall
+	}
+	if err := s.orderService.RecallOrderItem(restaurantID, item.OrderID, models.OrderItemRef{OrderItemID: item.OrderItemID}); err != nil {
+		return nil, err
+	}
+	item.Status = models.Pending
+	return item, nil
+}
+
+// routing returns the station and, when it is the default station of its kind,
+// the categories it receives for menu items without a station of their own.
+func (s *StationService) routing(restaurantID string, stationID string) (*models.Station, []string, error) {
+	station, err := s.repo.GetStation(restaurantID, stationID)
+	if err != nil {
+		return nil, nil, ErrStationNotFound
+	}
+	defaultStation, err := s.repo.GetDefaultStation(restaurantID, station.Kind)
+	if err != nil || defaultStation.StationID != station.StationID {
+		return station, nil, nil
+	}
+	return station, models.StationCategories(station.Kind), nil
+}
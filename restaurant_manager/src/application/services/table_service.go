@@ -16,30 +16,31 @@ func NewTableService(repo repositories.TableRepository, QRTemplate string) *Tabl
 }
 
 func (s *TableService) CreateTable(table *models.Table) (string, error) {
-	table.QRCode = fmt.Sprintf(s.QRtemplate, table.RestaurantID, models.OrderStatus("ordered"), table.TableID)
+	table.QRCode = fmt.Sprintf(s.QRtemplate, table.RestaurantID, models.Ordered, table.TableID)
 	return s.repo.CreateTable(table)
 }
 
-func (s *TableService) GetTable(tableID string) (*models.Table, error) {
-	return s.repo.GetTable(tableID)
+func (s *TableService) GetTable(restaurantID string, tableID string) (*models.Table, error) {
+	return s.repo.GetTable(restaurantID, tableID)
 }
 
 func (s *TableService) UpdateTable(table *models.Table) error {
 	return s.repo.UpdateTable(table)
 }
 
-func (s *TableService) DeleteTable(tableID string) error {
-	return s.repo.DeleteTable(tableID)
+func (s *TableService) DeleteTable(restaurantID string, tableID string) error {
+	return s.repo.DeleteTable(restaurantID, tableID)
 }
 
 func (s *TableService) GetTablesByRestaurantId(restaurantID string) ([]models.Table, error) {
 	return s.repo.GetTablesByRestaurantId(restaurantID)
 }
 
-func (service *TableService) UpdateTableStatus(tableID string, status string) error {
+func (service *TableService) UpdateTableStatus(restaurantID string, tableID string, status string) error {
 	table := &models.Table{
-		TableID: tableID,
-		Status:  models.TableStatus(status),
+		TableID:      tableID,
+		RestaurantID: restaurantID,
+		Status:       models.TableStatus(status),
 	}
 	return service.repo.UpdateTable(table)
 }
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"errors"
+	"restaurant_manager/src/application/utils"
+	"restaurant_manager/src/domain/models"
+	"restaurant_manager/src/domain/repositories"
+)
+
+var (
+	ErrRestaurantForbidden = errors.New("restaurant does not belong to the caller")
+	ErrRestaurantRequired  = errors.New("restaurant_id is required")
+)
+
+type TenantService struct {
+	restaurantRepo repositories.RestaurantRepository
+}
+
+func NewTenantService(restaurantRepo repositories.RestaurantRepository) *TenantService {
+	return &TenantService{restaurantRepo: restaurantRepo}
+}
+
+// ResolveRestaurant checks that the caller may act on the requested restaurant
+// and returns the restaurant the request is scoped to. Admins may act on the
+// restaurants they own, staff only on the restaurant they belong to and
+// customers on whichever restaurant they are ordering from; within it,
+// customers only reach the orders they placed. When no restaurant is requested
+// the caller's own restaurant is used, under the same checks.
+func (s *TenantService) ResolveRestaurant(auth *utils.AuthContext, restaurantID string) (string, error) {
+	if restaurantID == "" {
+		if auth.RestaurantID == "" {
+			return "", nil
+		}
+		restaurantID = auth.RestaurantID
+	}
+
+	switch auth.Role {
+	case models.RoleAdmin:
+		restaurant, err := s.restaurantRepo.GetRestaurant(restaurantID)
+		if err != nil || restaurant.OwnerID != auth.UserID {
+			return "", ErrRestaurantForbidden
+		}
+	case models.RoleCustomer:
+		if _, err := s.restaurantRepo.GetRestaurant(restaurantID); err != nil {
+			return "", ErrRestaurantForbidden
+		}
+	default:
+		if restaurantID != auth.RestaurantID {
+			return "", ErrRestaurantForbidden
+		}
+	}
+	return restaurantID, nil
+}
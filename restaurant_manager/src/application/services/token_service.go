@@ -0,0 +1,270 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"restaurant_manager/src/application/utils"
+	"restaurant_manager/src/domain/models"
+	"restaurant_manager/src/domain/repositories"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+var ErrInvalidRefreshToken = errors.New("invalid refresh token")
+
+// TokenPair is a short-lived access token together with the refresh token that
+// can be exchanged for the next pair.
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64
+}
+
+// TokenService issues, rotates and revokes tokens. Revocations are stored in
+// the database, which every instance checks, and the ones already seen are
+// kept in memory so a revoked token is turned away without a query.
+type TokenService struct {
+	repo       repositories.TokenRepository
+	userRepo   repositories.UserRepository
+	refreshTTL time.Duration
+	pinTTL     time.Duration
+
+	mu      sync.RWMutex
+	revoked map[string]time.Time
+}
+
+func NewTokenService(repo repositories.TokenRepository, userRepo repositories.UserRepository, refreshTTL time.Duration, pinTTL time.Duration) *TokenService {
+	s := &TokenService{
+		repo:       repo,
+		userRepo:   userRepo,
+		refreshTTL: refreshTTL,
+		pinTTL:     pinTTL,
+		revoked:    map[string]time.Time{},
+	}
+	s.loadRevokedTokens()
+	return s
+}
+
+func (s *TokenService) loadRevokedTokens() {
+	tokens, err := s.repo.GetRevokedTokens(utils.GetCurrentUTCTime())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load revoked tokens")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, token := range tokens {
+		s.revoked[token.TokenID] = token.ExpiresAt
+	}
+}
+
+// IsRevoked implements utils.RevocationList. Tokens not known to be revoked are
+// looked up in the database, since another instance may have revoked them.
+// When the lookup fails the token is treated as revoked.
+func (s *TokenService) IsRevoked(tokenID string) bool {
+	s.mu.RLock()
+	_, ok := s.revoked[tokenID]
+	s.mu.RUnlock()
+	if ok {
+		return true
+	}
+
+	token, err := s.repo.FindRevokedToken(tokenID)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to check token revocation")
+		return true
+	}
+	if token == nil {
+		return false
+	}
+	s.remember(token.TokenID, token.ExpiresAt)
+	return true
+}
+
+// IssueTokens starts a new session for the user.
+func (s *TokenService) IssueTokens(user *models.User) (*TokenPair, error) {
+	pair, _, err := s.issueTokens(s.repo, user)
+	return pair, err
+}
+
+// IssuePinSession issues a short-lived access token, without a refresh token,
+// scoped to the restaurant the staff member logged in to with their PIN. The
+// token is recorded so RevokeUser can end the session.
+func (s *TokenService) IssuePinSession(user *models.User, restaurantID string) (*TokenPair, error) {
+	auth := &utils.AuthContext{UserID: user.UserID, Role: user.Role, RestaurantID: restaurantID}
+	accessToken, err := utils.GenerateJWTWithTTL(auth, s.pinTTL)
+	if err != nil {
+		return nil, err
+	}
+	err = s.repo.CreatePinSession(&models.PinSession{
+		TokenID:      accessToken.TokenID,
+		UserID:       user.UserID,
+		RestaurantID: restaurantID,
+		ExpiresAt:    accessToken.ExpiresAt.UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{
+		AccessToken: accessToken.Token,
+		ExpiresIn:   int64(s.pinTTL.Seconds()),
+	}, nil
+}
+
+func (s *TokenService) issueTokens(repo repositories.TokenRepository, user *models.User) (*TokenPair, string, error) {
+	auth := &utils.AuthContext{UserID: user.UserID, Role: user.Role}
+	if user.RestaurantId != nil {
+		auth.RestaurantID = *user.RestaurantId
+	}
+	accessToken, err := utils.GenerateJWT(auth)
+	if err != nil {
+		return nil, "", err
+	}
+
+	refreshToken, err := newRandomToken()
+	if err != nil {
+		return nil, "", err
+	}
+	tokenID, err := repo.CreateRefreshToken(&models.RefreshToken{
+		UserID:        user.UserID,
+		TokenHash:     hashToken(refreshToken),
+		AccessTokenID: accessToken.TokenID,
+		ExpiresAt:     utils.GetCurrentUTCTime().Add(s.refreshTTL),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken.Token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(utils.AccessTokenTTL().Seconds()),
+	}, tokenID, nil
+}
+
+// Refresh exchanges a refresh token for a new token pair. Each refresh token can
+// be used once; presenting one that was already rotated means it leaked, so
+// every session of its user is revoked.
+func (s *TokenService) Refresh(refreshToken string) (*TokenPair, error) {
+	stored, err := s.repo.GetRefreshTokenByHash(hashToken(refreshToken))
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	if stored.RevokedAt != nil {
+		if err := s.RevokeUser(stored.UserID); err != nil {
+			log.Error().Err(err).Msg("Failed to revoke sessions after refresh token reuse")
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+	if utils.GetCurrentUTCTime().After(stored.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// Reload the user so the new access token carries their current role and restaurant
+	user, err := s.userRepo.GetUserById(stored.UserID)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	var pair *TokenPair
+	err = s.repo.WithTransaction(func(txRepo repositories.TokenRepository) error {
+		newPair, newTokenID, err := s.issueTokens(txRepo, user)
+		if err != nil {
+			return err
+		}
+		pair = newPair
+		return txRepo.RevokeRefreshToken(stored.TokenID, &newTokenID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// Logout revokes the caller's access token and, when given, their refresh token.
+func (s *TokenService) Logout(auth *utils.AuthContext, refreshToken string) error {
+	if refreshToken != "" {
+		stored, err := s.repo.GetRefreshTokenByHash(hashToken(refreshToken))
+		if err == nil && stored.UserID == auth.UserID {
+			if err := s.repo.RevokeRefreshToken(stored.TokenID, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return s.revokeAccessToken(auth.TokenID, auth.UserID, auth.ExpiresAt)
+}
+
+// RevokeUser ends every session of the user: refresh tokens can no longer be
+// used and access tokens that may still be valid, PIN sessions included, are
+// revoked.
+func (s *TokenService) RevokeUser(userID string) error {
+	if err := s.repo.RevokeUserRefreshTokens(userID); err != nil {
+		return err
+	}
+	issuedAfter := utils.GetCurrentUTCTime().Add(-utils.AccessTokenTTL())
+	tokens, err := s.repo.GetRefreshTokensCreatedAfter(userID, issuedAfter)
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		if err := s.revokeAccessToken(token.AccessTokenID, userID, token.CreatedAt.Add(utils.AccessTokenTTL())); err != nil {
+			return err
+		}
+	}
+	sessions, err := s.repo.GetActivePinSessions(userID, utils.GetCurrentUTCTime())
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if err := s.revokeAccessToken(session.TokenID, userID, session.ExpiresAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *TokenService) revokeAccessToken(tokenID string, userID string, expiresAt time.Time) error {
+	err := s.repo.AddRevokedToken(&models.RevokedToken{
+		TokenID:   tokenID,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+		RevokedAt: utils.GetCurrentUTCTime(),
+	})
+	if err != nil {
+		return err
+	}
+	s.remember(tokenID, expiresAt)
+	return nil
+}
+
+// remember keeps a revoked token in memory until it expires, dropping the ones
+// that already have.
+func (s *TokenService) remember(tokenID string, expiresAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := utils.GetCurrentUTCTime()
+	for id, expiry := range s.revoked {
+		if now.After(expiry) {
+			delete(s.revoked, id)
+		}
+	}
+	s.revoked[tokenID] = expiresAt
+}
+
+func newRandomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
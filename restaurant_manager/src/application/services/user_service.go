@@ -3,19 +3,57 @@ package services
 import (
 	"errors"
 	"fmt"
+	"regexp"
+	"restaurant_manager/src/application/utils"
 	"restaurant_manager/src/domain/models"
+	"restaurant_manager/src/domain/ports"
 	"restaurant_manager/src/domain/repositories"
 	"strings"
+	"time"
+	"unicode"
 
+	"github.com/rs/zerolog/log"
 	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	passwordResetTTL     = time.Hour
+	emailVerificationTTL = 48 * time.Hour
+
+	minPasswordLength = 8
+	maxPasswordLength = 72
+
+	maxPinAttempts  = 5
+	pinLockDuration = 15 * time.Minute
+)
+
+var (
+	ErrInvalidUserToken = errors.New("invalid or expired token")
+	ErrPasswordPolicy   = errors.New("password must be 8 to 72 characters long and contain at least one letter and one digit")
+	ErrInvalidPin       = errors.New("pin must be 4 to 6 digits")
+	ErrPinLocked        = errors.New("too many failed attempts, try again later")
+)
+
+var pinPattern = regexp.MustCompile(`^[0-9]{4,6}$`)
+
 type UserService struct {
-	repo repositories.UserRepository
+	repo             repositories.UserRepository
+	tokenRepo        repositories.TokenRepository
+	mailSender       ports.MailSender
+	resetPasswordURL string
+	verifyEmailURL   string
 }
 
-func NewUserService(repo repositories.UserRepository) *UserService {
-	return &UserService{repo: repo}
+// NewUserService creates the service. resetPasswordURL and verifyEmailURL are
+// the links mailed to users, with %s standing for the token.
+func NewUserService(repo repositories.UserRepository, tokenRepo repositories.TokenRepository, mailSender ports.MailSender, resetPasswordURL string, verifyEmailURL string) *UserService {
+	return &UserService{
+		repo:             repo,
+		tokenRepo:        tokenRepo,
+		mailSender:       mailSender,
+		resetPasswordURL: resetPasswordURL,
+		verifyEmailURL:   verifyEmailURL,
+	}
 }
 
 // validateUser performs basic validation on user data
@@ -32,14 +70,68 @@ func (s *UserService) validateUser(user *models.User) error {
 	if strings.TrimSpace(user.Phone) == "" {
 		return errors.New("phone is required")
 	}
-	if user.Role != "admin" && user.Role != "waiter" && user.Role != "customer" {
+	switch user.Role {
+	case models.RoleAdmin, models.RoleWaiter, models.RoleCustomer, models.RoleKitchen:
+	default:
 		return errors.New("invalid role")
 	}
+	return validatePassword(user.PasswordHash)
+}
+
+// validatePassword enforces the password policy: 8 to 72 characters (bcrypt
+// ignores anything longer) with at least one letter and one digit.
+func validatePassword(password string) error {
+	if len(password) < minPasswordLength || len(password) > maxPasswordLength {
+		return ErrPasswordPolicy
+	}
+
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	if !hasLetter || !hasDigit {
+		return ErrPasswordPolicy
+	}
 	return nil
 }
 
+func hashPassword(password string) (string, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hashedPassword), nil
+}
+
 // RegisterUser creates a new user
 func (s *UserService) RegisterUser(user *models.User) (string, error) {
+	// Public registration only creates restaurant owners, and an owner joins a
+	// restaurant by creating it, never by naming one
+	user.Role = models.RoleAdmin
+	user.RestaurantId = nil
+	return s.createUser(user)
+}
+
+// CreateStaffUser creates a waiter or kitchen account for the given restaurant.
+func (s *UserService) CreateStaffUser(user *models.User, restaurantID string) (string, error) {
+	if user.Role != models.RoleWaiter && user.Role != models.RoleKitchen {
+		return "", errors.New("staff role must be waiter or kitchen")
+	}
+	user.RestaurantId = &restaurantID
+	return s.createUser(user)
+}
+
+// createUser validates and stores a new user, hashing their password and
+// sending the email verification.
+func (s *UserService) createUser(user *models.User) (string, error) {
+	if err := s.validateUser(user); err != nil {
+		return "", err
+	}
 
 	// Ensure email is unique
 	existingUser, _ := s.repo.GetUserByEmail(user.Email)
@@ -47,12 +139,24 @@ func (s *UserService) RegisterUser(user *models.User) (string, error) {
 		return "", errors.New("email already registered")
 	}
 
+	hashedPassword, err := hashPassword(user.PasswordHash)
+	if err != nil {
+		return "", err
+	}
+	user.PasswordHash = hashedPassword
+
 	// Create the user
 	userID, err := s.repo.CreateUser(user)
 	if err != nil {
 		return "", fmt.Errorf("failed to create user: %w", err)
 	}
 
+	// The account is usable right away, so a failed mail must not fail registration
+	user.UserID = userID
+	if err := s.SendEmailVerification(user); err != nil {
+		log.Error().Err(err).Msg("Failed to send email verification")
+	}
+
 	return userID, nil
 }
 
@@ -92,7 +196,18 @@ func (s *UserService) GetUsersByRestaurantIDAndRole(restaurantID string, role st
 	return users, nil
 }
 
+// UpdateUser updates the user, hashing the password when a new one is given.
 func (s *UserService) UpdateUser(user *models.User) error {
+	if user.PasswordHash != "" {
+		if err := validatePassword(user.PasswordHash); err != nil {
+			return err
+		}
+		hashedPassword, err := hashPassword(user.PasswordHash)
+		if err != nil {
+			return err
+		}
+		user.PasswordHash = hashedPassword
+	}
 	return s.repo.UpdateUser(user)
 }
 
@@ -114,3 +229,154 @@ func (s *UserService) GetUserById(userID string) (*models.User, error) {
 	}
 	return s.repo.GetUserById(userID)
 }
+
+// SendEmailVerification mails the user a link that confirms their email address.
+func (s *UserService) SendEmailVerification(user *models.User) error {
+	token, err := s.issueUserToken(user.UserID, models.TokenPurposeEmailVerification, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nThe link expires in 48 hours.\n",
+		user.Name, fmt.Sprintf(s.verifyEmailURL, token))
+	return s.mailSender.SendMail(user.Email, "Confirm your email address", body)
+}
+
+// VerifyEmail redeems an email verification token.
+func (s *UserService) VerifyEmail(token string) error {
+	userToken, err := s.redeemUserToken(token, models.TokenPurposeEmailVerification)
+	if err != nil {
+		return err
+	}
+	return s.repo.MarkEmailVerified(userToken.UserID)
+}
+
+// RequestPasswordReset mails a password reset link. Unknown emails are ignored
+// so callers cannot tell which addresses are registered.
+func (s *UserService) RequestPasswordReset(email string) error {
+	if strings.TrimSpace(email) == "" {
+		return errors.New("email is required")
+	}
+
+	user, err := s.repo.GetUserByEmail(email)
+	if err != nil {
+		return nil
+	}
+
+	// Only the most recent link stays valid
+	if err := s.tokenRepo.InvalidateUserTokens(user.UserID, models.TokenPurposePasswordReset); err != nil {
+		return err
+	}
+	token, err := s.issueUserToken(user.UserID, models.TokenPurposePasswordReset, passwordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Hi %s,\n\nWe received a request to reset your password. Open the link below to choose a new one:\n\n%s\n\nThe link expires in 1 hour. If you did not ask for this, you can ignore this email.\n",
+		user.Name, fmt.Sprintf(s.resetPasswordURL, token))
+	return s.mailSender.SendMail(user.Email, "Reset your password", body)
+}
+
+// ResetPassword redeems a password reset token and sets the new password. It
+// returns the ID of the user whose password changed.
+func (s *UserService) ResetPassword(token string, password string) (string, error) {
+	if err := validatePassword(password); err != nil {
+		return "", err
+	}
+
+	userToken, err := s.redeemUserToken(token, models.TokenPurposePasswordReset)
+	if err != nil {
+		return "", err
+	}
+
+	hashedPassword, err := hashPassword(password)
+	if err != nil {
+		return "", err
+	}
+	if err := s.repo.UpdatePassword(userToken.UserID, hashedPassword); err != nil {
+		return "", err
+	}
+	return userToken.UserID, nil
+}
+
+func (s *UserService) issueUserToken(userID string, purpose string, ttl time.Duration) (string, error) {
+	token, err := newRandomToken()
+	if err != nil {
+		return "", err
+	}
+	_, err = s.tokenRepo.CreateUserToken(&models.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashToken(token),
+		ExpiresAt: utils.GetCurrentUTCTime().Add(ttl),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to store token: %w", err)
+	}
+	return token, nil
+}
+
+func (s *UserService) redeemUserToken(token string, purpose string) (*models.UserToken, error) {
+	if strings.TrimSpace(token) == "" {
+		return nil, ErrInvalidUserToken
+	}
+
+	userToken, err := s.tokenRepo.GetUserTokenByHash(hashToken(token), purpose)
+	if err != nil || userToken.UsedAt != nil || utils.GetCurrentUTCTime().After(userToken.ExpiresAt) {
+		return nil, ErrInvalidUserToken
+	}
+
+	used, err := s.tokenRepo.UseUserToken(userToken.TokenID)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, ErrInvalidUserToken
+	}
+	return userToken, nil
+}
+
+// SetPin sets the PIN a staff member uses to log in on the shared terminals of
+// their restaurant.
+func (s *UserService) SetPin(user *models.User, pin string) error {
+	if !pinPattern.MatchString(pin) {
+		return ErrInvalidPin
+	}
+	if user.RestaurantId == nil {
+		return errors.New("only restaurant staff can have a pin")
+	}
+
+	pinHash, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash pin: %w", err)
+	}
+	return s.repo.UpdatePin(user.UserID, string(pinHash))
+}
+
+// PinLogin authenticates a staff member of the restaurant by PIN. The PIN is
+// locked for a while after repeated failures.
+func (s *UserService) PinLogin(restaurantID string, userID string, pin string) (*models.User, error) {
+	user, err := s.repo.GetUserById(userID)
+	if err != nil || user.PinHash == nil || user.RestaurantId == nil || *user.RestaurantId != restaurantID {
+		return nil, errors.New("invalid credentials")
+	}
+
+	now := utils.GetCurrentUTCTime()
+	if user.PinLockedUntil != nil && now.Before(*user.PinLockedUntil) {
+		return nil, ErrPinLocked
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(*user.PinHash), []byte(pin)); err != nil {
+		if err := s.repo.RecordPinFailure(user.UserID, maxPinAttempts, now.Add(pinLockDuration)); err != nil {
+			log.Error().Err(err).Msg("Failed to record pin failure")
+		}
+		return nil, errors.New("invalid credentials")
+	}
+
+	if user.PinFailedAttempts > 0 {
+		if err := s.repo.ResetPinFailures(user.UserID); err != nil {
+			log.Error().Err(err).Msg("Failed to reset pin failures")
+		}
+	}
+	return user, nil
+}
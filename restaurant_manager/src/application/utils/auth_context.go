@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// AuthContext holds the identity of the caller resolved by the authorization middleware
+type AuthContext struct {
+	UserID       string
+	Role         string
+	RestaurantID string
+	TokenID      string
+	ExpiresAt    time.Time
+}
+
+type authContextKey struct{}
+
+// WithAuthContext returns a copy of ctx carrying the given caller identity
+func WithAuthContext(ctx context.Context, auth *AuthContext) context.Context {
+	return context.WithValue(ctx, authContextKey{}, auth)
+}
+
+// GetAuthContext returns the caller identity stored in the request context, or
+// an empty AuthContext for public routes
+func GetAuthContext(r *http.Request) *AuthContext {
+	auth, ok := r.Context().Value(authContextKey{}).(*AuthContext)
+	if !ok || auth == nil {
+		return &AuthContext{}
+	}
+	return auth
+}
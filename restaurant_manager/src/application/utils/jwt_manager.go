@@ -1,8 +1,11 @@
 package utils
 
 import (
+	"crypto"
 	"crypto/rsa"
 	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
 	"encoding/pem"
 	"fmt"
 	"net/http"
@@ -12,98 +15,227 @@ import (
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/lestrrat-go/jwx/v3/jwa"
+	"github.com/lestrrat-go/jwx/v3/jwk"
+	"github.com/lestrrat-go/jwx/v3/jws"
 	"github.com/lestrrat-go/jwx/v3/jwt"
 	"github.com/rs/zerolog/log"
 )
 
-var privateKey *rsa.PrivateKey
-var publicKey *rsa.PublicKey
+// signingKey is the active private key. verificationKeys holds the public half
+// of every configured key, so tokens signed before a rotation stay valid.
+var signingKey jwk.Key
+var verificationKeys = jwk.NewSet()
 
-func SetJWT(cfg *config.Properties) {
+// SetJWT loads the configured keys. It fails when a key cannot be loaded or
+// none can sign tokens, leaving the keys in use untouched, since the service
+// cannot issue tokens without one.
+func SetJWT(cfg *config.Properties) error {
+	jwtConfig := cfg.RestaurantManager.JWT
 
-	privateKeyPath := cfg.RestaurantManager.JWT.PrivateKeyPath
-	publicKeyPath := cfg.RestaurantManager.JWT.PublicKeyPath
+	var activeKey jwk.Key
+	keys := jwk.NewSet()
+	for _, keyConfig := range jwtConfig.SigningKeys() {
+		publicKey, err := loadPublicKey(keyConfig.PublicKeyPath)
+		if err != nil {
+			return fmt.Errorf("failed to load public key %s: %w", keyConfig.PublicKeyPath, err)
+		}
+		kid := keyConfig.KeyID
+		if kid == "" {
+			// Without an explicit kid the key thumbprint keeps the ID stable across restarts
+			thumbprint, err := publicKey.Thumbprint(crypto.SHA256)
+			if err != nil {
+				return fmt.Errorf("failed to compute key thumbprint: %w", err)
+			}
+			kid = base64.RawURLEncoding.EncodeToString(thumbprint)
+		}
+		if err := setKeyHeaders(publicKey, kid); err != nil {
+			return fmt.Errorf("failed to set key headers: %w", err)
+		}
+		if err := keys.AddKey(publicKey); err != nil {
+			return fmt.Errorf("failed to add verification key %s: %w", kid, err)
+		}
 
-	// Fallback to default paths if not configured
-	if privateKeyPath == "" {
-		privateKeyPath = "resources/private.key"
+		// Sign with the key named by active_kid, or the first one with a private key
+		if keyConfig.PrivateKeyPath == "" || activeKey != nil ||
+			(jwtConfig.ActiveKeyID != "" && kid != jwtConfig.ActiveKeyID) {
+			continue
+		}
+		privateKey, err := loadPrivateKey(keyConfig.PrivateKeyPath)
+		if err != nil {
+			return fmt.Errorf("failed to load private key %s: %w", keyConfig.PrivateKeyPath, err)
+		}
+		if err := setKeyHeaders(privateKey, kid); err != nil {
+			return fmt.Errorf("failed to set key headers: %w", err)
+		}
+		activeKey = privateKey
 	}
-	if publicKeyPath == "" {
-		publicKeyPath = "resources/public.key"
+	if activeKey == nil && jwtConfig.ActiveKeyID != "" {
+		return fmt.Errorf("active signing key %s has no private key configured", jwtConfig.ActiveKeyID)
 	}
+	if activeKey == nil {
+		return fmt.Errorf("no JWT signing key configured")
+	}
+
+	signingKey = activeKey
+	verificationKeys = keys
+	accessTokenTTL = cfg.RestaurantManager.JWT.AccessTokenDuration()
+	return nil
+}
 
-	absPath, err := filepath.Abs(privateKeyPath)
+func readPEMBlock(path string, blockType string) (*pem.Block, error) {
+	absPath, err := filepath.Abs(path)
 	if err != nil {
-		log.Error().Msgf("Failed to access path: %v", absPath)
+		return nil, err
 	}
-	privKeyData, err := os.ReadFile(absPath)
+	data, err := os.ReadFile(absPath)
 	if err != nil {
-		log.Error().Msgf("Failed to read private key: %v", err)
+		return nil, err
 	}
-
-	block, _ := pem.Decode(privKeyData)
-	if block == nil || block.Type != "PRIVATE KEY" {
-		log.Error().Msg("Failed to decode PEM block containing private key")
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != blockType {
+		return nil, fmt.Errorf("failed to decode PEM block containing %s", strings.ToLower(blockType))
 	}
+	return block, nil
+}
 
+func loadPrivateKey(path string) (jwk.Key, error) {
+	block, err := readPEMBlock(path, "PRIVATE KEY")
+	if err != nil {
+		return nil, err
+	}
 	privKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
 	if err != nil {
-		log.Error().Msgf("Failed to parse private key: %v", err)
+		return nil, err
 	}
-	privateKey = privKey.(*rsa.PrivateKey)
+	rsaKey, ok := privKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is not an RSA key")
+	}
+	return jwk.Import(rsaKey)
+}
 
-	absPath, err = filepath.Abs(publicKeyPath)
+func loadPublicKey(path string) (jwk.Key, error) {
+	block, err := readPEMBlock(path, "PUBLIC KEY")
 	if err != nil {
-		log.Error().Msgf("Failed to access path: %v", absPath)
+		return nil, err
 	}
-	puKeyData, err := os.ReadFile(absPath)
+	pubKey, err := x509.ParsePKIXPublicKey(block.Bytes)
 	if err != nil {
-		log.Error().Msgf("Failed to read private key: %v", err)
+		return nil, err
 	}
-	blockpub, _ := pem.Decode(puKeyData)
-	if blockpub == nil || blockpub.Type != "PUBLIC KEY" {
-		log.Error().Msg("Failed to decode PEM block containing public key")
+	rsaKey, ok := pubKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key is not an RSA key")
 	}
-	pubKey, err := x509.ParsePKIXPublicKey(blockpub.Bytes)
-	if err != nil {
-		log.Error().Msgf("Failed to parse public key: %v", err)
+	return jwk.Import(rsaKey)
+}
+
+func setKeyHeaders(key jwk.Key, kid string) error {
+	if err := key.Set(jwk.KeyIDKey, kid); err != nil {
+		return err
+	}
+	if err := key.Set(jwk.AlgorithmKey, jwa.RS256()); err != nil {
+		return err
 	}
-	publicKey = pubKey.(*rsa.PublicKey)
+	return key.Set(jwk.KeyUsageKey, jwk.ForSignature)
+}
 
+// JWKS returns the public keys tokens are verified against, as a JSON Web Key Set.
+func JWKS() ([]byte, error) {
+	return json.Marshal(verificationKeys)
 }
 
-func GenerateJWT(userID string) (string, error) {
+// Custom claims embedded in access tokens so the caller's identity can be
+// resolved without hitting the database.
+const (
+	roleClaim         = "role"
+	restaurantIDClaim = "restaurant_id"
+)
+
+var accessTokenTTL = 15 * time.Minute
+
+// AccessTokenTTL returns how long issued access tokens stay valid.
+func AccessTokenTTL() time.Duration {
+	return accessTokenTTL
+}
+
+// RevocationList reports whether an access token was revoked before it expired.
+type RevocationList interface {
+	IsRevoked(tokenID string) bool
+}
+
+var revocationList RevocationList
+
+func SetRevocationList(list RevocationList) {
+	revocationList = list
+}
+
+// AccessToken is a signed access token together with its ID (jti) and expiry.
+type AccessToken struct {
+	Token     string
+	TokenID   string
+	ExpiresAt time.Time
+}
+
+func GenerateJWT(auth *AuthContext) (*AccessToken, error) {
+	return GenerateJWTWithTTL(auth, accessTokenTTL)
+}
+
+// GenerateJWTWithTTL issues an access token that expires after the given duration.
+func GenerateJWTWithTTL(auth *AuthContext, ttl time.Duration) (*AccessToken, error) {
+	now := time.Now()
+	tokenID := uuid.New().String()
+	expiresAt := now.Add(ttl)
 	token, err := jwt.NewBuilder().
-		Expiration(time.Now().Add(36 * time.Hour)).
-		IssuedAt(time.Now()).
-		Subject(userID).
+		JwtID(tokenID).
+		Expiration(expiresAt).
+		IssuedAt(now).
+		Subject(auth.UserID).
+		Claim(roleClaim, auth.Role).
+		Claim(restaurantIDClaim, auth.RestaurantID).
 		Build()
 
 	if err != nil {
 		log.Error().Msgf("Error: %v", err)
-		return "", err
+		return nil, err
 	}
-	signedToken, err := jwt.Sign(token, jwt.WithKey(jwa.RS256(), privateKey))
+	// The key carries its kid, which is copied into the token header
+	signedToken, err := jwt.Sign(token, jwt.WithKey(jwa.RS256(), signingKey))
 	if err != nil {
 		log.Err(err)
-		return "", err
+		return nil, err
 	}
 
-	return string(signedToken), nil
+	return &AccessToken{Token: string(signedToken), TokenID: tokenID, ExpiresAt: expiresAt}, nil
 }
 
-func verifyJWT(signedToken string) (string, error) {
-	tok, err := jwt.Parse([]byte(signedToken), jwt.WithKey(jwa.RS256(), publicKey))
+func verifyJWT(signedToken string) (*AuthContext, error) {
+	tok, err := jwt.Parse([]byte(signedToken), jwt.WithKeySet(verificationKeys, jws.WithUseDefault(true)))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	userID, ok := tok.Subject()
 	if !ok {
-		return "", fmt.Errorf("user ID (sub) not found in token")
+		return nil, fmt.Errorf("user ID (sub) not found in token")
+	}
+	tokenID, ok := tok.JwtID()
+	if !ok {
+		return nil, fmt.Errorf("token ID (jti) not found in token")
+	}
+	if revocationList != nil && revocationList.IsRevoked(tokenID) {
+		return nil, fmt.Errorf("token has been revoked")
 	}
 
-	return userID, nil
+	auth := &AuthContext{UserID: userID, TokenID: tokenID}
+	if err := tok.Get(roleClaim, &auth.Role); err != nil {
+		return nil, fmt.Errorf("role not found in token")
+	}
+	_ = tok.Get(restaurantIDClaim, &auth.RestaurantID)
+	auth.ExpiresAt, _ = tok.Expiration()
+
+	return auth, nil
 }
 
 func getBearerToken(r *http.Request) (string, error) {
@@ -120,16 +252,12 @@ func getBearerToken(r *http.Request) (string, error) {
 	return parts[1], nil
 }
 
-func TokenVerification(r *http.Request, w http.ResponseWriter) string {
+// VerifyRequestToken extracts the bearer token from the request and returns
+// the caller identity stored in its claims.
+func VerifyRequestToken(r *http.Request) (*AuthContext, error) {
 	tokenString, err := getBearerToken(r)
 	if err != nil {
-		http.Error(w, "Authorization header is missing or invalid", http.StatusUnauthorized)
-		return ""
-	}
-	owner, err := verifyJWT(tokenString)
-	if err != nil {
-		http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
-		return ""
+		return nil, err
 	}
-	return owner
+	return verifyJWT(tokenString)
 }
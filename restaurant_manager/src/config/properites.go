@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/rs/zerolog/log"
@@ -12,18 +13,87 @@ import (
 
 type Properties struct {
 	RestaurantManager struct {
-		Database   string    `yaml:"database"`
-		JWT        jwtConfig `yaml:"jwt"`
-		Aws        awsCreds  `yaml:"aws"`
-		QRTemplate string    `yaml:"qr_template"`
+		Database   string     `yaml:"database"`
+		JWT        jwtConfig  `yaml:"jwt"`
+		Aws        awsCreds   `yaml:"aws"`
+		QRTemplate string     `yaml:"qr_template"`
+		Mail       mailConfig `yaml:"mail"`
 	} `yaml:"restaurant_manager"`
 }
 
 type jwtConfig struct {
+	PrivateKeyPath  string   `yaml:"private_key_path"`
+	PublicKeyPath   string   `yaml:"public_key_path"`
+	ActiveKeyID     string   `yaml:"active_kid"`
+	Keys            []JWTKey `yaml:"keys"`
+	AccessTokenTTL  string   `yaml:"access_token_ttl"`
+	RefreshTokenTTL string   `yaml:"refresh_token_ttl"`
+	PinTokenTTL     string   `yaml:"pin_token_ttl"`
+}
+
+// JWTKey is a signing key pair identified by its kid. Keys without a private
+// key are only used to verify tokens signed before a rotation.
+type JWTKey struct {
+	KeyID          string `yaml:"kid"`
 	PrivateKeyPath string `yaml:"private_key_path"`
 	PublicKeyPath  string `yaml:"public_key_path"`
 }
 
+// SigningKeys returns the configured keys, falling back to the single
+// private_key_path/public_key_path pair when no key list is configured.
+func (c jwtConfig) SigningKeys() []JWTKey {
+	if len(c.Keys) > 0 {
+		return c.Keys
+	}
+
+	key := JWTKey{PrivateKeyPath: c.PrivateKeyPath, PublicKeyPath: c.PublicKeyPath}
+	if key.PrivateKeyPath == "" {
+		key.PrivateKeyPath = "resources/private.key"
+	}
+	if key.PublicKeyPath == "" {
+		key.PublicKeyPath = "resources/public.key"
+	}
+	return []JWTKey{key}
+}
+
+// AccessTokenDuration returns how long access tokens stay valid, 15 minutes by default.
+func (c jwtConfig) AccessTokenDuration() time.Duration {
+	return parseDuration(c.AccessTokenTTL, 15*time.Minute)
+}
+
+// RefreshTokenDuration returns how long refresh tokens stay valid, 30 days by default.
+func (c jwtConfig) RefreshTokenDuration() time.Duration {
+	return parseDuration(c.RefreshTokenTTL, 30*24*time.Hour)
+}
+
+// PinTokenDuration returns how long tokens issued by PIN login stay valid, 10 minutes by default.
+func (c jwtConfig) PinTokenDuration() time.Duration {
+	return parseDuration(c.PinTokenTTL, 10*time.Minute)
+}
+
+func parseDuration(value string, fallback time.Duration) time.Duration {
+	if value == "" {
+		return fallback
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Error().Msgf("Invalid duration %q, using %s", value, fallback)
+		return fallback
+	}
+	return duration
+}
+
+type mailConfig struct {
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+	// Link templates mailed to users; %s is replaced with the token
+	ResetPasswordURL string `yaml:"reset_password_url"`
+	VerifyEmailURL   string `yaml:"verify_email_url"`
+}
+
 type awsCreds struct {
 	Profile string `yaml:"profile"`
 	Region  string `yaml:"region"`
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+type AuditEvent struct {
+	EventID      string    `gorm:"primaryKey;column:event_id"`
+	RestaurantID string    `gorm:"column:restaurant_id"`
+	ActorID      *string   `gorm:"column:actor_id"`
+	EntityType   string    `gorm:"column:entity_type"`
+	EntityID     string    `gorm:"column:entity_id"`
+	Action       string    `gorm:"column:action"`
+	Before       *string   `gorm:"column:before;type:jsonb"`
+	After        *string   `gorm:"column:after;type:jsonb"`
+	CreatedAt    time.Time `gorm:"column:created_at;default:CURRENT_TIMESTAMP"`
+
+	// Relations
+	Actor *User `gorm:"foreignKey:ActorID;references:UserID"`
+}
+
+// AuditFilter narrows the audit events of a restaurant; empty fields match everything.
+type AuditFilter struct {
+	RestaurantID string
+	EntityType   string
+	EntityID     string
+	ActorID      string
+	Action       string
+	StartDate    *time.Time
+	EndDate      *time.Time
+	Limit        int
+	Offset       int
+}
+
+const (
+	AuditEntityVoidOrderItem = "void_order_item"
+	AuditEntityInventory     = "inventory"
+	AuditEntityMenuItem      = "menu_item"
+	AuditEntityCashClosing   = "cash_closing"
+)
+
+const (
+	AuditActionVoid    = "void"
+	AuditActionRecover = "recover"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+)
@@ -17,6 +17,16 @@ type CashClosing struct {
 	TotalProfit       float64   `json:"total_profit" gorm:"column:total_profit;type:decimal(10,2);not null;default:0"`
 	OrderCount        int       `json:"order_count" gorm:"column:order_count;type:int;not null;default:0"`
 	AverageOrderValue float64   `json:"average_order_value" gorm:"column:average_order_value;type:decimal(10,2);not null;default:0"`
+	TotalTips         float64   `json:"total_tips" gorm:"column:total_tips;type:decimal(10,2);not null;default:0"`
+	TotalDiscounts    float64   `json:"total_discounts" gorm:"column:total_discounts;type:decimal(10,2);not null;default:0"`
+	TotalTaxes        float64   `json:"total_taxes" gorm:"column:total_taxes;type:decimal(10,2);not null;default:0"`
 	CreatedAt         time.Time `json:"created_at" gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP"`
 	UpdatedAt         time.Time `json:"updated_at" gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP"`
+
+	// PaymentMethods breaks down the payments taken that day; it is calculated,
+	// not stored.
+	PaymentMethods []PaymentMethodTotal `json:"payment_methods,omitempty" gorm:"-"`
+	// Taxes breaks down the taxes charged on the paid orders by rate; it is
+	// calculated, not stored.
+	Taxes []TaxLine `json:"taxes,omitempty" gorm:"-"`
 }
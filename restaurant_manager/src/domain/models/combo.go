@@ -0,0 +1,38 @@
+package models
+
+// ComboSlot is one part of a combo menu item: either a fixed menu item or a
+// choice among the menu items of a category, taken Quantity times.
+type ComboSlot struct {
+	SlotID      string    `gorm:"primaryKey;column:slot_id" json:"slot_id"`
+	ComboItemID string    `gorm:"column:combo_item_id" json:"combo_item_id"`
+	Name        string    `gorm:"column:name" json:"name"`
+	Quantity    int       `gorm:"column:quantity" json:"quantity"`
+	MenuItemID  *string   `gorm:"column:menu_item_id" json:"menu_item_id"`
+	Category    *Category `gorm:"column:category" json:"category"`
+	Position    int       `gorm:"column:position" json:"position"`
+	MenuItem    *MenuItem `gorm:"foreignKey:MenuItemID;references:MenuItemID" json:"menu_item,omitempty"`
+}
+
+// IsChoice reports whether the guest picks the slot's menu items.
+func (s ComboSlot) IsChoice() bool {
+	return s.MenuItemID == nil
+}
+
+// Offers reports whether the menu item may fill the slot.
+func (s ComboSlot) Offers(menuItem *MenuItem) bool {
+	if s.IsChoice() {
+		return s.Category != nil && menuItem.Category == *s.Category
+	}
+	return *s.MenuItemID == menuItem.MenuItemID
+}
+
+// OrderItemComponent is a menu item an ordered combo was made of. Quantity
+// counts the components in one combo; the order item's quantity multiplies it.
+type OrderItemComponent struct {
+	OrderItemComponentID string   `gorm:"primaryKey;column:order_item_component_id"`
+	OrderItemID          string   `gorm:"column:order_item_id"`
+	SlotID               *string  `gorm:"column:slot_id"`
+	MenuItemID           string   `gorm:"column:menu_item_id"`
+	Quantity             int      `gorm:"column:quantity"`
+	MenuItem             MenuItem `gorm:"foreignKey:MenuItemID;references:MenuItemID"`
+}
@@ -0,0 +1,161 @@
+package models
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type InvoiceStatus string
+
+const (
+	InvoicePending  InvoiceStatus = "pending"
+	InvoiceAccepted InvoiceStatus = "accepted"
+	InvoiceRejected InvoiceStatus = "rejected"
+)
+
+type InvoiceEnvironment string
+
+const (
+	InvoiceProduction InvoiceEnvironment = "production"
+	InvoiceTesting    InvoiceEnvironment = "testing"
+)
+
+// Code is how the tax authority identifies the environment: 1 for
+// production, 2 for testing.
+func (e InvoiceEnvironment) Code() string {
+	if e == InvoiceProduction {
+		return "1"
+	}
+	return "2"
+}
+
+// InvoiceResolution is a numbering resolution granted to a restaurant: the
+// range of invoice numbers it may issue between two dates, and the technical
+// key its invoices are hashed with.
+type InvoiceResolution struct {
+	ResolutionID     string             `gorm:"primaryKey;column:resolution_id"`
+	RestaurantID     string             `gorm:"column:restaurant_id"`
+	ResolutionNumber string             `gorm:"column:resolution_number"`
+	Prefix           string             `gorm:"column:prefix"`
+	RangeFrom        int64              `gorm:"column:range_from"`
+	RangeTo          int64              `gorm:"column:range_to"`
+	NextNumber       int64              `gorm:"column:next_number"`
+	ValidFrom        time.Time          `gorm:"column:valid_from"`
+	ValidTo          time.Time          `gorm:"column:valid_to"`
+	TechnicalKey     string             `gorm:"column:technical_key"`
+	Environment      InvoiceEnvironment `gorm:"column:environment"`
+	CreatedAt        time.Time          `gorm:"column:created_at;default:CURRENT_TIMESTAMP"`
+}
+
+// Validate reports what, if anything, makes the resolution unusable.
+func (r InvoiceResolution) Validate() error {
+	if strings.TrimSpace(r.ResolutionNumber) == "" || strings.TrimSpace(r.TechnicalKey) == "" {
+		return fmt.Errorf("resolution_number and technical_key are required")
+	}
+	if len(r.Prefix) > 4 {
+		return fmt.Errorf("prefix is at most 4 characters")
+	}
+	if r.RangeFrom < 1 || r.RangeTo < r.RangeFrom {
+		return fmt.Errorf("range_from must be positive and not above range_to")
+	}
+	if r.ValidTo.Before(r.ValidFrom) {
+		return fmt.Errorf("valid_to cannot come before valid_from")
+	}
+	if r.Environment != InvoiceProduction && r.Environment != InvoiceTesting {
+		return fmt.Errorf("environment must be production or testing")
+	}
+	return nil
+}
+
+// ValidOn reports whether the resolution covers the given day.
+func (r InvoiceResolution) ValidOn(day time.Time) bool {
+	date := day.Format(time.DateOnly)
+	return date >= r.ValidFrom.Format(time.DateOnly) && date <= r.ValidTo.Format(time.DateOnly)
+}
+
+// Exhausted reports whether every number in the range has been issued.
+func (r InvoiceResolution) Exhausted() bool {
+	return r.NextNumber > r.RangeTo
+}
+
+// Invoice is the electronic invoice issued for an order. Document holds the
+// UBL 2.1 XML as issued; the tracking ID and status message are what the tax
+// authority answered when it was transmitted.
+type Invoice struct {
+	InvoiceID     string        `gorm:"primaryKey;column:invoice_id"`
+	RestaurantID  string        `gorm:"column:restaurant_id"`
+	OrderID       string        `gorm:"column:order_id"`
+	ResolutionID  string        `gorm:"column:resolution_id"`
+	Sequence      int64         `gorm:"column:sequence"`
+	Number        string        `gorm:"column:number"`
+	Cufe          string        `gorm:"column:cufe"`
+	BuyerID       *string       `gorm:"column:buyer_id"`
+	BuyerName     string        `gorm:"column:buyer_name"`
+	BuyerNit      string        `gorm:"column:buyer_nit"`
+	Subtotal      float64       `gorm:"column:subtotal"`
+	TaxTotal      float64       `gorm:"column:tax_total"`
+	Total         float64       `gorm:"column:total"`
+	Document      string        `gorm:"column:document"`
+	Status        InvoiceStatus `gorm:"column:status"`
+	TrackingID    *string       `gorm:"column:tracking_id"`
+	StatusMessage *string       `gorm:"column:status_message"`
+	IssuedAt      time.Time     `gorm:"column:issued_at"`
+	TransmittedAt *time.Time    `gorm:"column:transmitted_at"`
+}
+
+// InvoiceTransmission is the tax authority's answer to a transmitted invoice.
+type InvoiceTransmission struct {
+	Status     InvoiceStatus
+	TrackingID string
+	Message    string
+}
+
+// InvoiceParty is the seller or buyer named on an invoice.
+type InvoiceParty struct {
+	Name string
+	Nit  string
+}
+
+// InvoiceTaxSchemes are the codes the tax authority gives each tax.
+var InvoiceTaxSchemes = map[string]string{TaxIVA: "01", TaxINC: "04"}
+
+// NitParts splits a NIT written as "900123456-7" into its number and check
+// digit. Separators other than the dash are dropped.
+func NitParts(nit string) (number string, checkDigit string) {
+	number, checkDigit, _ = strings.Cut(strings.TrimSpace(nit), "-")
+	number = strings.NewReplacer(".", "", " ", "", ",", "").Replace(number)
+	return number, strings.TrimSpace(checkDigit)
+}
+
+// ComputeCufe works out the invoice's unique code (CUFE): the SHA-384 hash of
+// its number, issue time, amounts before tax, IVA, INC and ICA, total, the
+// seller's and buyer's NIT, the resolution's technical key and environment.
+func ComputeCufe(invoice Invoice, issuedAt time.Time, sellerNit string, taxes map[string]float64, resolution InvoiceResolution) string {
+	seller, _ := NitParts(sellerNit)
+	buyer, _ := NitParts(invoice.BuyerNit)
+	source := strings.Join([]string{
+		invoice.Number,
+		issuedAt.Format(time.DateOnly),
+		issuedAt.Format("15:04:05-07:00"),
+		formatAmount(invoice.Subtotal),
+		InvoiceTaxSchemes[TaxIVA], formatAmount(taxes[TaxIVA]),
+		InvoiceTaxSchemes[TaxINC], formatAmount(taxes[TaxINC]),
+		"03", formatAmount(0),
+		formatAmount(invoice.Total),
+		seller,
+		buyer,
+		resolution.TechnicalKey,
+		resolution.Environment.Code(),
+	}, "")
+	sum := sha512.Sum384([]byte(source))
+	return hex.EncodeToString(sum[:])
+}
+
+// formatAmount writes an amount with the two decimals invoices are hashed and
+// written with.
+func formatAmount(amount float64) string {
+	return fmt.Sprintf("%.2f", RoundMoney(amount))
+}
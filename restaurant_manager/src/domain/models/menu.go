@@ -10,8 +10,18 @@ type MenuItem struct {
 	SideDishes   int      `gorm:"column:side_dishes" json:"side_dishes"`
 	ImageURL     string   `gorm:"column:image_url" json:"image_url"`
 	Category     Category `gorm:"column:category" json:"category"`
+	StationID    *string  `gorm:"column:station_id" json:"station_id"`
+	// TaxCategory overrides the category the restaurant's regime sets
+	TaxCategory *TaxCategory `gorm:"column:tax_category" json:"tax_category,omitempty"`
 	// Relations
-	Ingredients []Ingredient `gorm:"foreignKey:MenuItemID;references:MenuItemID" json:"ingredients"`
+	Ingredients    []Ingredient    `gorm:"foreignKey:MenuItemID;references:MenuItemID" json:"ingredients"`
+	ModifierGroups []ModifierGroup `gorm:"foreignKey:MenuItemID;references:MenuItemID" json:"modifier_groups"`
+	ComboSlots     []ComboSlot     `gorm:"foreignKey:ComboItemID;references:MenuItemID" json:"combo_slots"`
+}
+
+// IsCombo reports whether the menu item is a bundle of other menu items.
+func (m MenuItem) IsCombo() bool {
+	return len(m.ComboSlots) > 0
 }
 
 type Category string
@@ -25,3 +35,12 @@ const (
 	Drinks    Category = "Drinks"
 	Side      Category = "Side"
 )
+
+// IsValid reports whether the category is one the menu uses.
+func (c Category) IsValid() bool {
+	switch c {
+	case Appetizer, Dessert, Main, Soup, Salad, Drinks, Side:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package models
+
+// ModifierGroup is a set of choices offered with a menu item, such as its side
+// dishes or the doneness of a steak. Guests pick between MinSelections and
+// MaxSelections of its modifiers; a required group needs at least one.
+type ModifierGroup struct {
+	ModifierGroupID string     `gorm:"primaryKey;column:modifier_group_id" json:"modifier_group_id"`
+	MenuItemID      string     `gorm:"column:menu_item_id" json:"menu_item_id"`
+	Name            string     `gorm:"column:name" json:"name"`
+	Required        bool       `gorm:"column:required" json:"required"`
+	MinSelections   int        `gorm:"column:min_selections" json:"min_selections"`
+	MaxSelections   int        `gorm:"column:max_selections" json:"max_selections"`
+	Position        int        `gorm:"column:position" json:"position"`
+	Modifiers       []Modifier `gorm:"foreignKey:ModifierGroupID;references:ModifierGroupID" json:"modifiers"`
+}
+
+// MinimumSelections is the number of modifiers a guest must pick from the group.
+func (g ModifierGroup) MinimumSelections() int {
+	if g.Required && g.MinSelections < 1 {
+		return 1
+	}
+	return g.MinSelections
+}
+
+// Modifier is one choice of a modifier group. PriceDelta is added to the price
+// of the menu item and Ingredients adjust its recipe.
+type Modifier struct {
+	ModifierID      string               `gorm:"primaryKey;column:modifier_id" json:"modifier_id"`
+	ModifierGroupID string               `gorm:"column:modifier_group_id" json:"modifier_group_id"`
+	Name            string               `gorm:"column:name" json:"name"`
+	PriceDelta      float64              `gorm:"column:price_delta" json:"price_delta"`
+	Position        int                  `gorm:"column:position" json:"position"`
+	Ingredients     []ModifierIngredient `gorm:"foreignKey:ModifierID;references:ModifierID" json:"ingredients"`
+}
+
+// ModifierIngredient is the amount of a raw ingredient a modifier adds to the
+// recipe of its menu item. Negative amounts take the ingredient out.
+type ModifierIngredient struct {
+	ModifierIngredientID string  `gorm:"primaryKey;column:modifier_ingredient_id" json:"modifier_ingredient_id"`
+	ModifierID           string  `gorm:"column:modifier_id" json:"modifier_id"`
+	RawIngredientID      string  `gorm:"column:raw_ingredient_id" json:"raw_ingredient_id"`
+	Amount               float64 `gorm:"column:amount" json:"amount"`
+	Unit                 string  `gorm:"column:unit" json:"unit"`
+}
+
+// OrderItemModifier is a modifier chosen for an order item, keeping the name
+// and price delta it had when the item was ordered. ModifierID is nil once the
+// modifier is removed from the menu.
+type OrderItemModifier struct {
+	OrderItemModifierID string    `gorm:"primaryKey;column:order_item_modifier_id"`
+	OrderItemID         string    `gorm:"column:order_item_id"`
+	ModifierID          *string   `gorm:"column:modifier_id"`
+	Name                string    `gorm:"column:name"`
+	PriceDelta          float64   `gorm:"column:price_delta"`
+	Modifier            *Modifier `gorm:"foreignKey:ModifierID;references:ModifierID"`
+}
+
+// WithModifiers returns a copy of the menu item whose recipe includes the
+// ingredient deltas of the given modifiers. Ingredients the modifiers take
+// out entirely are dropped.
+func (m MenuItem) WithModifiers(modifiers []Modifier) MenuItem {
+	amounts := map[string]float64{}
+	var recipe []Ingredient
+	for _, ingredient := range m.Ingredients {
+		if _, ok := amounts[ingredient.RawIngredientID]; !ok {
+			recipe = append(recipe, ingredient)
+		}
+		amounts[ingredient.RawIngredientID] += ingredient.Amount
+	}
+	for _, modifier := range modifiers {
+		for _, delta := range modifier.Ingredients {
+			if _, ok := amounts[delta.RawIngredientID]; !ok {
+				recipe = append(recipe, Ingredient{MenuItemID: m.MenuItemID, RawIngredientID: delta.RawIngredientID, Unit: delta.Unit})
+			}
+			amounts[delta.RawIngredientID] += delta.Amount
+		}
+	}
+
+	m.Ingredients = nil
+	for _, ingredient := range recipe {
+		if amounts[ingredient.RawIngredientID] <= 0 {
+			continue
+		}
+		ingredient.Amount = amounts[ingredient.RawIngredientID]
+		m.Ingredients = append(m.Ingredients, ingredient)
+	}
+	return m
+}
+
+// ChosenModifiers returns the modifiers chosen for the item that are still on
+// the menu.
+func (i OrderItem) ChosenModifiers() []Modifier {
+	var modifiers []Modifier
+	for _, chosen := range i.Modifiers {
+		if chosen.Modifier != nil {
+			modifiers = append(modifiers, *chosen.Modifier)
+		}
+	}
+	return modifiers
+}
+
+// SameModifiers reports whether the item was ordered with exactly the given
+// modifiers.
+func (i OrderItem) SameModifiers(modifiers []OrderItemModifier) bool {
+	if len(i.Modifiers) != len(modifiers) {
+		return false
+	}
+	ids := map[string]int{}
+	for _, chosen := range i.Modifiers {
+		if chosen.ModifierID != nil {
+			ids[*chosen.ModifierID]++
+		}
+	}
+	for _, chosen := range modifiers {
+		if chosen.ModifierID == nil || ids[*chosen.ModifierID] == 0 {
+			return false
+		}
+		ids[*chosen.ModifierID]--
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// OrderEvent is a change to an order pushed to the staff of its restaurant.
+type OrderEvent struct {
+	ID           uint64      `json:"id"`
+	Type         string      `json:"type"`
+	RestaurantID string      `json:"restaurant_id"`
+	OrderID      string      `json:"order_id"`
+	OrderItemID  string      `json:"order_item_id,omitempty"`
+	TableID      string      `json:"table_id,omitempty"`
+	MenuItemID   string      `json:"menu_item_id,omitempty"`
+	Observation  string      `json:"observation,omitempty"`
+	Quantity     int         `json:"quantity,omitempty"`
+	Status       OrderStatus `json:"status,omitempty"`
+	MergedInto   string      `json:"merged_into,omitempty"`
+	CreatedAt    time.Time   `json:"created_at"`
+}
+
+const (
+	OrderCreatedEvent       = "order_created"
+	OrderStatusChangedEvent = "order_status_changed"
+	ItemAddedEvent          = "item_added"
+	ItemStatusChangedEvent  = "item_status_changed"
+	ItemVoidedEvent         = "item_voided"
+	ItemMovedEvent          = "item_moved"
+	OrderTransferredEvent   = "order_transferred"
+	OrderMergedEvent        = "order_merged"
+)
+
+// VisibleTo reports whether staff with the given role receive the event. The
+// kitchen follows items from the moment their course is fired until they
+// leave the pass and waiters follow items from the moment they are ready;
+// admins see everything.
+func (e OrderEvent) VisibleTo(role string) bool {
+	switch role {
+	case RoleKitchen:
+		switch e.Type {
+		case OrderCreatedEvent, ItemVoidedEvent, OrderTransferredEvent, OrderMergedEvent:
+			return true
+		case ItemAddedEvent, ItemMovedEvent:
+			return e.Status != Held
+		case ItemStatusChangedEvent:
+			return e.Status == Pending || e.Status == Prepared || e.Status == Cancelled
+		case OrderStatusChangedEvent:
+			return e.Status == Cancelled
+		}
+		return false
+	case RoleWaiter:
+		switch e.Type {
+		case OrderCreatedEvent, OrderStatusChangedEvent, ItemVoidedEvent, ItemMovedEvent, OrderTransferredEvent, OrderMergedEvent:
+			return true
+		case ItemStatusChangedEvent:
+			return e.Status == Prepared || e.Status == Delivered || e.Status == Cancelled
+		}
+		return false
+	case RoleAdmin:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+package models
+
+import "time"
+
+// orderTransitions lists the statuses an order may move to from each status.
+// Paid and cancelled orders are final.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	Ordered:   {Prepared, Cancelled},
+	Prepared:  {Delivered, Cancelled},
+	Delivered: {Paid, Cancelled},
+}
+
+// orderItemTransitions lists the statuses an order item may move to from each
+// status. Held items wait for their course to be fired before reaching the
+// kitchen. Completed and cancelled items are final.
+var orderItemTransitions = map[OrderStatus][]OrderStatus{
+	Held:      {Pending, Cancelled},
+	Pending:   {Prepared, Cancelled},
+	Prepared:  {Delivered, Cancelled},
+	Delivered: {Completed, Cancelled},
+}
+
+// orderItemFlow is the path an item follows when it is not cancelled.
+var orderItemFlow = []OrderStatus{Held, Pending, Prepared, Delivered, Completed}
+
+func canTransition(transitions map[OrderStatus][]OrderStatus, from OrderStatus, to OrderStatus) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinal reports whether an order or item in this status can no longer change.
+func (s OrderStatus) IsFinal() bool {
+	return s == Paid || s == Cancelled || s == Completed
+}
+
+// TransitionTo moves the order to status and stamps the time it entered it.
+// Staying in the current status is allowed and changes nothing; any other
+// move not in the state machine is rejected by returning false.
+func (o *Order) TransitionTo(status OrderStatus, at time.Time) bool {
+	if o.Status == status {
+		return true
+	}
+	if !canTransition(orderTransitions, o.Status, status) {
+		return false
+	}
+	o.Status = status
+	o.stamp(at)
+	return true
+}
+
+// stamp records when the order entered its status and derives how long the
+// previous stage took.
+func (o *Order) stamp(at time.Time) {
+	switch o.Status {
+	case Prepared:
+		o.PreparedAt = &at
+		o.TimeToPrepare = secondsSince(&o.CreatedAt, at)
+	case Delivered:
+		o.DeliveredAt = &at
+		o.TimeToDeliver = secondsSince(o.PreparedAt, at)
+	case Paid:
+		o.PaidAt = &at
+		o.TimeToPay = secondsSince(o.DeliveredAt, at)
+	case Cancelled:
+		o.CancelledAt = &at
+	}
+}
+
+func secondsSince(since *time.Time, at time.Time) float64 {
+	if since == nil || since.IsZero() {
+		return 0
+	}
+	return at.Sub(*since).Seconds()
+}
+
+// TransitionTo moves the item to status and stamps the time it entered it,
+// following the same rules as Order.TransitionTo.
+func (i *OrderItem) TransitionTo(status OrderStatus, at time.Time) bool {
+	if i.Status == status {
+		return true
+	}
+	if !canTransition(orderItemTransitions, i.Status, status) {
+		return false
+	}
+	i.Status = status
+	i.stamp(at)
+	return true
+}
+
+// Fire releases a held item to the kitchen.
+func (i *OrderItem) Fire(at time.Time) bool {
+	if i.Status != Held {
+		return false
+	}
+	return i.TransitionTo(Pending, at)
+}
+
+// HasHeldItems reports whether any of the order's items still waits for its
+// course to be fired.
+func (o *Order) HasHeldItems() bool {
+	for _, item := range o.OrderItems {
+		if item.Status == Held {
+			return true
+		}
+	}
+	return false
+}
+
+// Recall sends a bumped item back to the kitchen. It is the only move back
+// along the item flow; PreparedAt keeps the previous bump until the next one.
+func (i *OrderItem) Recall() bool {
+	if i.Status != Prepared {
+		return false
+	}
+	i.Status = Pending
+	return true
+}
+
+// AdvanceTo walks the item forward along its normal flow until it reaches
+// status, stamping every step. Items already at or past status are left alone.
+func (i *OrderItem) AdvanceTo(status OrderStatus, at time.Time) {
+	if status == Cancelled {
+		i.TransitionTo(Cancelled, at)
+		return
+	}
+	current, target := flowIndex(i.Status), flowIndex(status)
+	if current < 0 || target <= current {
+		return
+	}
+	for _, next := range orderItemFlow[current+1 : target+1] {
+		i.TransitionTo(next, at)
+	}
+}
+
+func flowIndex(status OrderStatus) int {
+	for index, step := range orderItemFlow {
+		if step == status {
+			return index
+		}
+	}
+	return -1
+}
+
+func (i *OrderItem) stamp(at time.Time) {
+	switch i.Status {
+	case Pending:
+		i.FiredAt = &at
+	case Prepared:
+		i.PreparedAt = &at
+	case Delivered:
+		i.DeliveredAt = &at
+	case Completed:
+		i.CompletedAt = &at
+	case Cancelled:
+		i.CancelledAt = &at
+	}
+}
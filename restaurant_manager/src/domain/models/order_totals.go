@@ -0,0 +1,108 @@
+package models
+
+import "math"
+
+// OrderTotals is the price breakdown of an order. Total is what the guest owes:
+// the subtotal of its items less discounts, plus the taxes charged on top of
+// prices that do not include them. Taxes counts every tax, included or not,
+// and TaxLines breaks it down by category. The service charge is only
+// suggested on the total, since guests may decline it, and is never part of
+// it.
+type OrderTotals struct {
+	Subtotal      float64
+	Discounts     float64
+	Taxes         float64
+	ServiceCharge float64
+	Total         float64
+	TaxLines      []TaxLine
+	// ItemTaxes holds the tax worked out on each item line, by order item ID
+	ItemTaxes map[string]TaxLine
+}
+
+// ComputeTotals prices the order from its items and discount lines, never from
+// totals stored on it. Cancelled items are not charged. Each item is taxed at
+// the rate it was ordered with on what it costs after its discounts, order
+// discounts being spread over the items in proportion to their cost. The
+// service charge is the one the rules suggest on the total.
+func ComputeTotals(order *Order, rules []ServiceChargeRule) OrderTotals {
+	totals := OrderTotals{ItemTaxes: make(map[string]TaxLine)}
+	itemDiscounts := make(map[string]float64)
+	orderDiscount := 0.0
+	for _, discount := range order.Discounts {
+		totals.Discounts += discount.Amount
+		if discount.OrderItemID != nil {
+			itemDiscounts[*discount.OrderItemID] += discount.Amount
+		} else {
+			orderDiscount += discount.Amount
+		}
+	}
+
+	nets := make(map[string]float64)
+	netTotal := 0.0
+	for _, item := range order.OrderItems {
+		if item.Status == Cancelled {
+			continue
+		}
+		line := item.Price * float64(item.Quantity)
+		totals.Subtotal += line
+		nets[item.OrderItemID] = math.Max(line-itemDiscounts[item.OrderItemID], 0)
+		netTotal += nets[item.OrderItemID]
+	}
+	orderDiscount = math.Min(orderDiscount, netTotal)
+
+	exclusiveTaxes := 0.0
+	taxed := make([]OrderItem, 0, len(order.OrderItems))
+	for _, item := range order.OrderItems {
+		if item.Status == Cancelled {
+			continue
+		}
+		taxable := nets[item.OrderItemID]
+		if netTotal > 0 {
+			taxable -= orderDiscount * nets[item.OrderItemID] / netTotal
+		}
+		item.TaxBase, item.TaxAmount = lineTax(taxable, item.TaxRate, item.TaxIncluded)
+		totals.ItemTaxes[item.OrderItemID] = TaxLine{Category: item.TaxCategory, Rate: item.TaxRate, Base: item.TaxBase, Amount: item.TaxAmount}
+		totals.Taxes += item.TaxAmount
+		if !item.TaxIncluded {
+			exclusiveTaxes += item.TaxAmount
+		}
+		taxed = append(taxed, item)
+	}
+	totals.TaxLines = TaxLinesOf(taxed)
+
+	totals.Subtotal = RoundMoney(totals.Subtotal)
+	totals.Discounts = RoundMoney(math.Min(totals.Discounts, totals.Subtotal))
+	totals.Taxes = RoundMoney(totals.Taxes)
+	totals.Total = RoundMoney(totals.Subtotal - totals.Discounts + exclusiveTaxes)
+	if rule := ServiceChargeFor(rules, totals.Total); rule != nil {
+		totals.ServiceCharge = rule.On(totals.Total)
+	}
+	return totals
+}
+
+// SetTotals stores a price breakdown on the order and its item lines.
+func (o *Order) SetTotals(totals OrderTotals) {
+	o.Subtotal = totals.Subtotal
+	o.DiscountTotal = totals.Discounts
+	o.TaxTotal = totals.Taxes
+	o.ServiceCharge = totals.ServiceCharge
+	o.TotalPrice = totals.Total
+	for i := range o.OrderItems {
+		if tax, ok := totals.ItemTaxes[o.OrderItems[i].OrderItemID]; ok {
+			o.OrderItems[i].TaxBase = tax.Base
+			o.OrderItems[i].TaxAmount = tax.Amount
+		}
+	}
+}
+
+// Totals is the price breakdown stored on the order.
+func (o Order) Totals() OrderTotals {
+	return OrderTotals{
+		Subtotal:      o.Subtotal,
+		Discounts:     o.DiscountTotal,
+		Taxes:         o.TaxTotal,
+		ServiceCharge: o.ServiceCharge,
+		Total:         o.TotalPrice,
+		TaxLines:      TaxLinesOf(o.OrderItems),
+	}
+}
@@ -7,22 +7,76 @@ type Order struct {
 	TableID       string      `gorm:"column:table_id"`
 	RestaurantID  string      `gorm:"column:restaurant_id"`
 	Status        OrderStatus `gorm:"column:status"`
+	Subtotal      float64     `gorm:"column:subtotal"`
+	DiscountTotal float64     `gorm:"column:discount_total"`
+	TaxTotal      float64     `gorm:"column:tax_total"`
+	ServiceCharge float64     `gorm:"column:service_charge"`
 	TotalPrice    float64     `gorm:"column:total_price"`
 	Observation   *string     `gorm:"column:observation"`
 	TimeToPrepare float64     `gorm:"column:time_to_prepare_seconds"`
 	TimeToDeliver float64     `gorm:"column:time_to_deliver_seconds"`
 	TimeToPay     float64     `gorm:"column:time_to_pay_seconds"`
+	WaiterID      *string     `gorm:"column:waiter_id"`
+	CustomerID    *string     `gorm:"column:customer_id"`
 	CreatedAt     time.Time   `gorm:"column:created_at"`
+	PreparedAt    *time.Time  `gorm:"column:prepared_at"`
+	DeliveredAt   *time.Time  `gorm:"column:delivered_at"`
+	PaidAt        *time.Time  `gorm:"column:paid_at"`
+	CancelledAt   *time.Time  `gorm:"column:cancelled_at"`
+	// MergedInto is the order that took the items of an order merged into it
+	MergedInto *string `gorm:"column:merged_into"`
 
 	// Relations
-	OrderItems []OrderItem `gorm:"foreignKey:OrderID;references:OrderID"`
-	Table      Table       `gorm:"foreignKey:TableID;references:TableID"`
+	OrderItems []OrderItem     `gorm:"foreignKey:OrderID;references:OrderID"`
+	Table      Table           `gorm:"foreignKey:TableID;references:TableID"`
+	Discounts  []OrderDiscount `gorm:"foreignKey:OrderID;references:OrderID"`
+	Coupons    []OrderCoupon   `gorm:"foreignKey:OrderID;references:OrderID"`
 }
 
+// OrderStatusChange records an order entering a status. FromStatus is nil for
+// the status the order was created in.
+type OrderStatusChange struct {
+	HistoryID    string       `gorm:"primaryKey;column:history_id" json:"history_id"`
+	OrderID      string       `gorm:"column:order_id" json:"order_id"`
+	RestaurantID string       `gorm:"column:restaurant_id" json:"restaurant_id"`
+	FromStatus   *OrderStatus `gorm:"column:from_status" json:"from_status"`
+	ToStatus     OrderStatus  `gorm:"column:to_status" json:"to_status"`
+	ChangedAt    time.Time    `gorm:"column:changed_at" json:"changed_at"`
+}
+
+// TableName keeps the table named after the history rather than the pluralised
+// struct name.
+func (OrderStatusChange) TableName() string {
+	return "servu.order_status_history"
+}
+
+// KitchenPerformance aggregates the server-derived durations, in seconds, of
+// the orders created within one hour of the day or one day.
+type KitchenPerformance struct {
+	Bucket     string  `gorm:"column:bucket"`
+	Orders     int     `gorm:"column:orders"`
+	AvgPrepare float64 `gorm:"column:avg_prepare"`
+	P50Prepare float64 `gorm:"column:p50_prepare"`
+	P90Prepare float64 `gorm:"column:p90_prepare"`
+	AvgDeliver float64 `gorm:"column:avg_deliver"`
+	P50Deliver float64 `gorm:"column:p50_deliver"`
+	P90Deliver float64 `gorm:"column:p90_deliver"`
+	AvgPay     float64 `gorm:"column:avg_pay"`
+	P50Pay     float64 `gorm:"column:p50_pay"`
+	P90Pay     float64 `gorm:"column:p90_pay"`
+}
+
+const (
+	PerformanceByHour = "hour"
+	PerformanceByDay  = "day"
+)
+
 type OrderStatus string
 
 const (
 	Ordered   OrderStatus = "ordered"
+	Held      OrderStatus = "held"
+	Pending   OrderStatus = "pending"
 	Prepared  OrderStatus = "prepared"
 	Delivered OrderStatus = "delivered"
 	Paid      OrderStatus = "paid"
@@ -31,13 +85,88 @@ const (
 )
 
 type OrderItem struct {
-	OrderID     string      `gorm:"primaryKey;column:order_id"`
-	MenuItemID  string      `gorm:"primaryKey;column:menu_item_id"`
+	OrderItemID string      `gorm:"primaryKey;column:order_item_id"`
+	OrderID     string      `gorm:"column:order_id"`
+	MenuItemID  string      `gorm:"column:menu_item_id"`
 	Quantity    int         `gorm:"column:quantity"`
 	Price       float64     `gorm:"column:price"`
 	Status      OrderStatus `gorm:"column:status"`
-	Observation *string     `gorm:"primaryKey;column:observation"`
-	MenuItem    MenuItem    `gorm:"foreignKey:MenuItemID;references:MenuItemID"`
+	Observation *string     `gorm:"column:observation"`
+	Course      int         `gorm:"column:course;default:1"`
+	Seat        *int        `gorm:"column:seat"`
+	TaxCategory TaxCategory `gorm:"column:tax_category"`
+	TaxRate     float64     `gorm:"column:tax_rate"`
+	TaxIncluded bool        `gorm:"column:tax_included"`
+	TaxBase     float64     `gorm:"column:tax_base"`
+	TaxAmount   float64     `gorm:"column:tax_amount"`
+	// TicketedQuantity counts the units already sent to the kitchen on a ticket
+	TicketedQuantity int        `gorm:"column:ticketed_quantity"`
+	CreatedAt        time.Time  `gorm:"column:created_at;default:CURRENT_TIMESTAMP"`
+	FiredAt          *time.Time `gorm:"column:fired_at"`
+	PreparedAt       *time.Time `gorm:"column:prepared_at"`
+	DeliveredAt      *time.Time `gorm:"column:delivered_at"`
+	CompletedAt      *time.Time `gorm:"column:completed_at"`
+	CancelledAt      *time.Time `gorm:"column:cancelled_at"`
+	MenuItem         MenuItem   `gorm:"foreignKey:MenuItemID;references:MenuItemID"`
+
+	Modifiers  []OrderItemModifier  `gorm:"foreignKey:OrderItemID;references:OrderItemID"`
+	Components []OrderItemComponent `gorm:"foreignKey:OrderItemID;references:OrderItemID"`
+}
+
+// OrderItemRef identifies an order item by its ID or, for clients that predate
+// order item IDs, by its menu item and observation.
+type OrderItemRef struct {
+	OrderItemID string
+	MenuItemID  string
+	Observation string
+}
+
+// OrderItemMove names units of an order item to move to another order. A zero
+// quantity moves every unit.
+type OrderItemMove struct {
+	OrderItemID string
+	Quantity    int
+}
+
+// Matches reports whether the reference names the item.
+func (i OrderItem) Matches(ref OrderItemRef) bool {
+	if ref.OrderItemID != "" {
+		return i.OrderItemID == ref.OrderItemID && (ref.MenuItemID == "" || i.MenuItemID == ref.MenuItemID)
+	}
+	return i.MenuItemID == ref.MenuItemID && i.Observation != nil && *i.Observation == ref.Observation
+}
+
+// SameSeat reports whether the item was served to the given seat, an item
+// without a seat only matching another without one.
+func (i OrderItem) SameSeat(seat *int) bool {
+	if i.Seat == nil || seat == nil {
+		return i.Seat == nil && seat == nil
+	}
+	return *i.Seat == *seat
+}
+
+// Split takes quantity units off the item and returns them as a new, unsaved
+// item with the same menu item, price, taxes, status and choices. Units the
+// kitchen was already sent go first.
+func (i *OrderItem) Split(quantity int) OrderItem {
+	part := *i
+	part.OrderItemID = ""
+	part.Quantity = quantity
+	part.TicketedQuantity = min(quantity, i.TicketedQuantity)
+	part.TaxBase, part.TaxAmount = 0, 0
+	part.Modifiers = make([]OrderItemModifier, len(i.Modifiers))
+	for j, modifier := range i.Modifiers {
+		modifier.OrderItemModifierID = ""
+		part.Modifiers[j] = modifier
+	}
+	part.Components = make([]OrderItemComponent, len(i.Components))
+	for j, component := range i.Components {
+		component.OrderItemComponentID = ""
+		part.Components[j] = component
+	}
+	i.Quantity -= quantity
+	i.TicketedQuantity -= part.TicketedQuantity
+	return part
 }
 
 type VoidOrderItem struct {
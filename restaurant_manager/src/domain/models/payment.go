@@ -0,0 +1,202 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+type PaymentStatus string
+
+const (
+	PaymentPending   PaymentStatus = "pending"
+	PaymentCompleted PaymentStatus = "completed"
+	PaymentFailed    PaymentStatus = "failed"
+)
+
+type PaymentMethod string
+
+const (
+	PaymentCash   PaymentMethod = "cash"
+	PaymentCard   PaymentMethod = "card"
+	PaymentOnline PaymentMethod = "online"
+)
+
+// IsValid reports whether the method is one the payments table accepts.
+func (m PaymentMethod) IsValid() bool {
+	return m == PaymentCash || m == PaymentCard || m == PaymentOnline
+}
+
+// Payment is money taken towards an order. Its allocations name the item units
+// it covers; a payment without allocations covers part of the balance as a
+// whole, as when a check is split evenly. The tip is taken on top of the amount
+// and does not count towards the balance.
+type Payment struct {
+	PaymentID     string              `gorm:"primaryKey;column:payment_id"`
+	OrderID       string              `gorm:"column:order_id"`
+	RestaurantID  string              `gorm:"column:restaurant_id"`
+	Amount        float64             `gorm:"column:amount"`
+	Tip           float64             `gorm:"column:tip"`
+	Status        PaymentStatus       `gorm:"column:status"`
+	PaymentMethod PaymentMethod       `gorm:"column:payment_method"`
+	TransactionID *string             `gorm:"column:transaction_id"`
+	CreatedBy     *string             `gorm:"column:created_by"`
+	CreatedAt     time.Time           `gorm:"column:created_at"`
+	Allocations   []PaymentAllocation `gorm:"foreignKey:PaymentID;references:PaymentID"`
+}
+
+// PaymentAllocation records the units of an order item a payment covers.
+type PaymentAllocation struct {
+	PaymentAllocationID string  `gorm:"primaryKey;column:payment_allocation_id"`
+	PaymentID           string  `gorm:"column:payment_id"`
+	OrderItemID         string  `gorm:"column:order_item_id"`
+	Quantity            int     `gorm:"column:quantity"`
+	Amount              float64 `gorm:"column:amount"`
+}
+
+// ItemBalance is what remains to be paid for an order item, net of the
+// discount taken off it and with the tax charged on top of its price, if any.
+type ItemBalance struct {
+	Item         OrderItem
+	Discount     float64
+	AddedTax     float64
+	PaidQuantity int
+	Outstanding  float64
+}
+
+// Cost is what every unit of the item costs together.
+func (b ItemBalance) Cost() float64 {
+	return b.Item.Price*float64(b.Item.Quantity) - b.Discount + b.AddedTax
+}
+
+// UnpaidQuantity is the number of units no payment has been allocated to yet.
+func (b ItemBalance) UnpaidQuantity() int {
+	return b.Item.Quantity - b.PaidQuantity
+}
+
+// CostOf is what the given number of the item's unpaid units cost. Paying
+// every unpaid unit costs exactly what is outstanding, so rounding never leaves
+// cents behind.
+func (b ItemBalance) CostOf(quantity int) float64 {
+	if quantity >= b.UnpaidQuantity() {
+		return b.Outstanding
+	}
+	return RoundMoney(b.Cost() / float64(b.Item.Quantity) * float64(quantity))
+}
+
+// OrderBalance is the order's total, computed from its items as ComputeTotals
+// does, against the completed payments taken for it. Tips are kept apart, and
+// ServiceCharge is the rule suggested for the order, if any.
+type OrderBalance struct {
+	Total         float64
+	Paid          float64
+	Outstanding   float64
+	Tips          float64
+	Items         []ItemBalance
+	ServiceCharge *ServiceChargeRule
+}
+
+// NewOrderBalance computes the balance of the order. Cancelled items are not
+// charged, the order's discount lines come off the total and off the items
+// they name, and taxes charged on top of prices are added to both. Item
+// balances only account for allocated payments, so payments taken as an even
+// share lower the order's outstanding amount but no item's.
+func NewOrderBalance(order *Order, payments []Payment) OrderBalance {
+	paidUnits := make(map[string]int)
+	var balance OrderBalance
+	for _, payment := range payments {
+		if payment.Status != PaymentCompleted {
+			continue
+		}
+		balance.Paid += payment.Amount
+		balance.Tips += payment.Tip
+		for _, allocation := range payment.Allocations {
+			paidUnits[allocation.OrderItemID] += allocation.Quantity
+		}
+	}
+	totals := ComputeTotals(order, nil)
+	itemDiscounts := make(map[string]float64)
+	for _, discount := range order.Discounts {
+		if discount.OrderItemID != nil {
+			itemDiscounts[*discount.OrderItemID] += discount.Amount
+		}
+	}
+	for _, item := range order.OrderItems {
+		if item.Status == Cancelled {
+			continue
+		}
+		line := item.Price * float64(item.Quantity)
+		itemBalance := ItemBalance{
+			Item:         item,
+			Discount:     RoundMoney(min(itemDiscounts[item.OrderItemID], line)),
+			PaidQuantity: min(paidUnits[item.OrderItemID], item.Quantity),
+		}
+		if !item.TaxIncluded {
+			itemBalance.AddedTax = totals.ItemTaxes[item.OrderItemID].Amount
+		}
+		if item.Quantity > 0 {
+			itemBalance.Outstanding = RoundMoney(itemBalance.Cost() * float64(itemBalance.UnpaidQuantity()) / float64(item.Quantity))
+		}
+		balance.Items = append(balance.Items, itemBalance)
+	}
+	balance.Total = totals.Total
+	balance.Paid = RoundMoney(balance.Paid)
+	balance.Tips = RoundMoney(balance.Tips)
+	balance.Outstanding = RoundMoney(math.Max(balance.Total-balance.Paid, 0))
+	return balance
+}
+
+// Settled reports whether nothing remains to be paid.
+func (b OrderBalance) Settled() bool {
+	return b.Outstanding <= 0
+}
+
+// SuggestedServiceCharge is the service charge suggested on the order total.
+func (b OrderBalance) SuggestedServiceCharge() float64 {
+	if b.ServiceCharge == nil {
+		return 0
+	}
+	return b.ServiceCharge.On(b.Total)
+}
+
+// SplitEvenly divides the outstanding amount into ways shares that add up to
+// it exactly; the cents that do not divide evenly go to the first shares.
+func (b OrderBalance) SplitEvenly(ways int) []float64 {
+	if ways < 1 {
+		return nil
+	}
+	cents := int64(math.Round(b.Outstanding * 100))
+	shares := make([]float64, ways)
+	for i := range shares {
+		share := cents / int64(ways)
+		if int64(i) < cents%int64(ways) {
+			share++
+		}
+		shares[i] = float64(share) / 100
+	}
+	return shares
+}
+
+// PaymentMethodTotal sums the payments taken with one method.
+type PaymentMethodTotal struct {
+	Method PaymentMethod `gorm:"column:method"`
+	Amount float64       `gorm:"column:amount"`
+	Tips   float64       `gorm:"column:tips"`
+	Count  int           `gorm:"column:count"`
+}
+
+// TipShare is the tips collected on the orders a waiter served during one of
+// their shifts. Tips taken outside any recorded shift have no ShiftID.
+type TipShare struct {
+	UserID   *string    `gorm:"column:user_id"`
+	Name     *string    `gorm:"column:name"`
+	ShiftID  *string    `gorm:"column:shift_id"`
+	ClockIn  *time.Time `gorm:"column:clock_in"`
+	ClockOut *time.Time `gorm:"column:clock_out"`
+	Payments int        `gorm:"column:payments"`
+	Tips     float64    `gorm:"column:tips"`
+}
+
+// RoundMoney rounds an amount to cents, the precision money is stored with.
+func RoundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,299 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type PromotionKind string
+
+const (
+	PromotionPercentage PromotionKind = "percentage"
+	PromotionFixed      PromotionKind = "fixed"
+	PromotionBuyXGetY   PromotionKind = "buy_x_get_y"
+)
+
+type PromotionScope string
+
+const (
+	PromotionItemScope     PromotionScope = "item"
+	PromotionCategoryScope PromotionScope = "category"
+	PromotionOrderScope    PromotionScope = "order"
+)
+
+// Promotion takes Value percent or Value off the items it targets, or gives
+// FreeQuantity units away for every BuyQuantity ordered. Fixed discounts come
+// off every unit of an item and once off an order. HappyHourStart and
+// HappyHourEnd, "15:04" in the restaurant's time zone, limit it to a time of
+// day; a window ending before it starts runs past midnight.
+type Promotion struct {
+	PromotionID       string         `gorm:"primaryKey;column:promotion_id" json:"promotion_id"`
+	RestaurantID      string         `gorm:"column:restaurant_id" json:"restaurant_id"`
+	Name              string         `gorm:"column:name" json:"name"`
+	Kind              PromotionKind  `gorm:"column:kind" json:"kind"`
+	Scope             PromotionScope `gorm:"column:scope" json:"scope"`
+	Value             float64        `gorm:"column:value" json:"value"`
+	MenuItemID        *string        `gorm:"column:menu_item_id" json:"menu_item_id,omitempty"`
+	Category          *Category      `gorm:"column:category" json:"category,omitempty"`
+	BuyQuantity       *int           `gorm:"column:buy_quantity" json:"buy_quantity,omitempty"`
+	FreeQuantity      *int           `gorm:"column:free_quantity" json:"free_quantity,omitempty"`
+	MinimumOrderTotal float64        `gorm:"column:minimum_order_total" json:"minimum_order_total"`
+	StartsAt          *time.Time     `gorm:"column:starts_at" json:"starts_at,omitempty"`
+	EndsAt            *time.Time     `gorm:"column:ends_at" json:"ends_at,omitempty"`
+	HappyHourStart    *string        `gorm:"column:happy_hour_start" json:"happy_hour_start,omitempty"`
+	HappyHourEnd      *string        `gorm:"column:happy_hour_end" json:"happy_hour_end,omitempty"`
+	CouponCode        *string        `gorm:"column:coupon_code" json:"coupon_code,omitempty"`
+	UsageLimit        *int           `gorm:"column:usage_limit" json:"usage_limit,omitempty"`
+	TimesUsed         int            `gorm:"column:times_used" json:"times_used"`
+	Active            bool           `gorm:"column:active" json:"active"`
+	CreatedAt         time.Time      `gorm:"column:created_at;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// OrderCoupon is a coupon code redeemed on an order.
+type OrderCoupon struct {
+	OrderCouponID string    `gorm:"primaryKey;column:order_coupon_id"`
+	OrderID       string    `gorm:"column:order_id"`
+	PromotionID   string    `gorm:"column:promotion_id"`
+	Code          string    `gorm:"column:code"`
+	RedeemedAt    time.Time `gorm:"column:redeemed_at;default:CURRENT_TIMESTAMP"`
+}
+
+// OrderDiscount is a discount line of an order. Lines of item promotions name
+// the item they take money off.
+type OrderDiscount struct {
+	OrderDiscountID string    `gorm:"primaryKey;column:order_discount_id"`
+	OrderID         string    `gorm:"column:order_id"`
+	OrderItemID     *string   `gorm:"column:order_item_id"`
+	PromotionID     *string   `gorm:"column:promotion_id"`
+	Name            string    `gorm:"column:name"`
+	Amount          float64   `gorm:"column:amount"`
+	CreatedAt       time.Time `gorm:"column:created_at;default:CURRENT_TIMESTAMP"`
+}
+
+// Normalize clears the fields the promotion's scope and kind do not use.
+func (p *Promotion) Normalize() {
+	if p.Scope != PromotionItemScope {
+		p.MenuItemID = nil
+	}
+	if p.Scope != PromotionCategoryScope {
+		p.Category = nil
+	}
+	if p.Kind != PromotionBuyXGetY {
+		p.BuyQuantity, p.FreeQuantity = nil, nil
+	}
+	if p.CouponCode != nil && strings.TrimSpace(*p.CouponCode) == "" {
+		p.CouponCode = nil
+	}
+}
+
+// Validate reports what, if anything, makes the promotion unusable.
+func (p Promotion) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	switch p.Scope {
+	case PromotionItemScope:
+		if p.MenuItemID == nil {
+			return fmt.Errorf("item promotions need a menu_item_id")
+		}
+	case PromotionCategoryScope:
+		if p.Category == nil || !p.Category.IsValid() {
+			return fmt.Errorf("category promotions need a valid category")
+		}
+	case PromotionOrderScope:
+	default:
+		return fmt.Errorf("scope must be item, category or order")
+	}
+	switch p.Kind {
+	case PromotionPercentage:
+		if p.Value <= 0 || p.Value > 100 {
+			return fmt.Errorf("percentage must be between 0 and 100")
+		}
+	case PromotionFixed:
+		if p.Value <= 0 {
+			return fmt.Errorf("fixed discounts must be positive")
+		}
+	case PromotionBuyXGetY:
+		if p.Scope == PromotionOrderScope {
+			return fmt.Errorf("buy_x_get_y promotions apply to items or categories")
+		}
+		if p.BuyQuantity == nil || p.FreeQuantity == nil || *p.FreeQuantity < 1 || *p.BuyQuantity <= *p.FreeQuantity {
+			return fmt.Errorf("buy_x_get_y promotions give fewer units away than are bought")
+		}
+	default:
+		return fmt.Errorf("kind must be percentage, fixed or buy_x_get_y")
+	}
+	if (p.HappyHourStart == nil) != (p.HappyHourEnd == nil) {
+		return fmt.Errorf("happy hours need a start and an end")
+	}
+	if p.HappyHourStart != nil {
+		if _, ok := clockMinutes(*p.HappyHourStart); !ok {
+			return fmt.Errorf("happy_hour_start must look like 15:04")
+		}
+		if _, ok := clockMinutes(*p.HappyHourEnd); !ok {
+			return fmt.Errorf("happy_hour_end must look like 15:04")
+		}
+	}
+	if p.StartsAt != nil && p.EndsAt != nil && !p.EndsAt.After(*p.StartsAt) {
+		return fmt.Errorf("ends_at must come after starts_at")
+	}
+	if p.MinimumOrderTotal < 0 || (p.UsageLimit != nil && *p.UsageLimit < 1) {
+		return fmt.Errorf("minimum_order_total and usage_limit cannot be negative")
+	}
+	return nil
+}
+
+// IsCoupon reports whether the promotion only applies once its code is redeemed.
+func (p Promotion) IsCoupon() bool {
+	return p.CouponCode != nil
+}
+
+// Exhausted reports whether the coupon reached its usage limit.
+func (p Promotion) Exhausted() bool {
+	return p.UsageLimit != nil && p.TimesUsed >= *p.UsageLimit
+}
+
+// ValidAt reports whether the promotion runs at the given time, within its
+// date range and happy hour.
+func (p Promotion) ValidAt(at time.Time, loc *time.Location) bool {
+	if !p.Active {
+		return false
+	}
+	if (p.StartsAt != nil && at.Before(*p.StartsAt)) || (p.EndsAt != nil && !at.Before(*p.EndsAt)) {
+		return false
+	}
+	if p.HappyHourStart == nil || p.HappyHourEnd == nil {
+		return true
+	}
+	start, _ := clockMinutes(*p.HappyHourStart)
+	end, _ := clockMinutes(*p.HappyHourEnd)
+	local := at.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	if start <= end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+// Targets reports whether an item promotion applies to the order item.
+func (p Promotion) Targets(item OrderItem) bool {
+	switch p.Scope {
+	case PromotionItemScope:
+		return p.MenuItemID != nil && *p.MenuItemID == item.MenuItemID
+	case PromotionCategoryScope:
+		return p.Category != nil && *p.Category == item.MenuItem.Category
+	}
+	return false
+}
+
+// ItemDiscount is what the promotion takes off an order item line.
+func (p Promotion) ItemDiscount(item OrderItem) float64 {
+	line := item.Price * float64(item.Quantity)
+	var amount float64
+	switch p.Kind {
+	case PromotionPercentage:
+		amount = line * p.Value / 100
+	case PromotionFixed:
+		amount = min(p.Value, item.Price) * float64(item.Quantity)
+	case PromotionBuyXGetY:
+		free := item.Quantity / *p.BuyQuantity * *p.FreeQuantity
+		amount = item.Price * float64(free)
+	}
+	return RoundMoney(min(amount, line))
+}
+
+// OrderDiscount is what an order promotion takes off a subtotal.
+func (p Promotion) OrderDiscount(subtotal float64) float64 {
+	var amount float64
+	switch p.Kind {
+	case PromotionPercentage:
+		amount = subtotal * p.Value / 100
+	case PromotionFixed:
+		amount = p.Value
+	}
+	return RoundMoney(min(amount, subtotal))
+}
+
+// ApplyPromotions works out the discount lines of an order. Each item gets the
+// best item or category promotion running when it was ordered, then the best
+// order promotion running when the order was opened comes off what is left.
+// Promotions never stack on the same item or on the order. Coupon promotions
+// only count once redeemed on the order, and minimum totals are checked
+// against the order's subtotal before discounts.
+func ApplyPromotions(order *Order, promotions []Promotion, coupons []OrderCoupon, loc *time.Location) []OrderDiscount {
+	redeemed := make(map[string]bool, len(coupons))
+	for _, coupon := range coupons {
+		redeemed[coupon.PromotionID] = true
+	}
+	subtotal := 0.0
+	for _, item := range order.OrderItems {
+		if item.Status != Cancelled {
+			subtotal += item.Price * float64(item.Quantity)
+		}
+	}
+	eligible := func(promotion Promotion, at time.Time) bool {
+		return (!promotion.IsCoupon() || redeemed[promotion.PromotionID]) &&
+			subtotal >= promotion.MinimumOrderTotal && promotion.ValidAt(at, loc)
+	}
+
+	var discounts []OrderDiscount
+	remaining := subtotal
+	for _, item := range order.OrderItems {
+		if item.Status == Cancelled {
+			continue
+		}
+		var best *Promotion
+		bestAmount := 0.0
+		for i, promotion := range promotions {
+			if promotion.Scope == PromotionOrderScope || !promotion.Targets(item) || !eligible(promotion, item.CreatedAt) {
+				continue
+			}
+			if amount := promotion.ItemDiscount(item); amount > bestAmount {
+				best, bestAmount = &promotions[i], amount
+			}
+		}
+		if best != nil {
+			discounts = append(discounts, newOrderDiscount(order.OrderID, &item.OrderItemID, best, bestAmount))
+			remaining -= bestAmount
+		}
+	}
+
+	var best *Promotion
+	bestAmount := 0.0
+	for i, promotion := range promotions {
+		if promotion.Scope != PromotionOrderScope || !eligible(promotion, order.CreatedAt) {
+			continue
+		}
+		if amount := promotion.OrderDiscount(remaining); amount > bestAmount {
+			best, bestAmount = &promotions[i], amount
+		}
+	}
+	if best != nil {
+		discounts = append(discounts, newOrderDiscount(order.OrderID, nil, best, bestAmount))
+	}
+	return discounts
+}
+
+func newOrderDiscount(orderID string, orderItemID *string, promotion *Promotion, amount float64) OrderDiscount {
+	discount := OrderDiscount{
+		OrderID:     orderID,
+		PromotionID: &promotion.PromotionID,
+		Name:        promotion.Name,
+		Amount:      amount,
+	}
+	if orderItemID != nil {
+		id := *orderItemID
+		discount.OrderItemID = &id
+	}
+	return discount
+}
+
+// clockMinutes parses a "15:04" time of day into minutes after midnight.
+func clockMinutes(clock string) (int, bool) {
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, false
+	}
+	return parsed.Hour()*60 + parsed.Minute(), true
+}
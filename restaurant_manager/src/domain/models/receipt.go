@@ -0,0 +1,159 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// paymentMethodLabels name payment methods as they are printed on receipts.
+var paymentMethodLabels = map[PaymentMethod]string{
+	PaymentCash:   "Efectivo",
+	PaymentCard:   "Tarjeta",
+	PaymentOnline: "En línea",
+}
+
+// NewReceipt lays out the bill of the order for the guest: its items with their
+// modifiers, discounts, taxes and total, the tips and payments taken and what
+// is left to pay, between the restaurant's header and footer.
+func NewReceipt(order *Order, payments []Payment, restaurant Restaurant, printedAt time.Time) Ticket {
+	ticket := NewTicket(restaurant.PaperWidth)
+	ticket.Center(restaurant.Name, true)
+	seller := restaurant.InvoiceParty()
+	if seller.Name != restaurant.Name {
+		ticket.Center(seller.Name, false)
+	}
+	if seller.Nit != "" {
+		ticket.Center("NIT "+seller.Nit, false)
+	}
+	if restaurant.ReceiptHeader != nil && *restaurant.ReceiptHeader != "" {
+		ticket.Center(*restaurant.ReceiptHeader, false)
+	}
+	ticket.Rule()
+	writeOrderHeading(ticket, order, printedAt.In(restaurant.Location()))
+	ticket.Rule()
+
+	for _, item := range order.OrderItems {
+		if item.Status == Cancelled {
+			continue
+		}
+		ticket.Columns(fmt.Sprintf("%dx %s", item.Quantity, item.MenuItem.Name), FormatPesos(item.Price*float64(item.Quantity)), false)
+		for _, modifier := range item.Modifiers {
+			if modifier.PriceDelta != 0 {
+				ticket.Left(fmt.Sprintf("   + %s (%s)", modifier.Name, FormatPesos(modifier.PriceDelta)), false)
+			} else {
+				ticket.Left("   + "+modifier.Name, false)
+			}
+		}
+		for _, component := range item.Components {
+			ticket.Left(fmt.Sprintf("   - %dx %s", component.Quantity, component.MenuItem.Name), false)
+		}
+		if item.Observation != nil && *item.Observation != "" {
+			ticket.Left("   Nota: "+*item.Observation, false)
+		}
+	}
+	ticket.Rule()
+
+	totals := ComputeTotals(order, nil)
+	ticket.Columns("Subtotal", FormatPesos(totals.Subtotal), false)
+	for _, discount := range order.Discounts {
+		ticket.Columns(discount.Name, FormatPesos(-discount.Amount), false)
+	}
+	for _, line := range totals.TaxLines {
+		label := fmt.Sprintf("%s %g%%", line.Category.Tax(), line.Rate)
+		if order.hasIncludedTax(line.Category) {
+			label += " incluido"
+		}
+		ticket.Columns(label, FormatPesos(line.Amount), false)
+	}
+	ticket.Columns("TOTAL", FormatPesos(totals.Total), true)
+
+	balance := NewOrderBalance(order, payments)
+	if balance.Tips > 0 {
+		ticket.Columns("Propina", FormatPesos(balance.Tips), false)
+		ticket.Columns("TOTAL CON PROPINA", FormatPesos(totals.Total+balance.Tips), true)
+	} else if order.ServiceCharge > 0 {
+		ticket.Columns("Propina sugerida (voluntaria)", FormatPesos(order.ServiceCharge), false)
+	}
+
+	paid := false
+	for _, payment := range payments {
+		if payment.Status != PaymentCompleted {
+			continue
+		}
+		if !paid {
+			ticket.Rule()
+			ticket.Left("Pagos", true)
+			paid = true
+		}
+		ticket.Columns("   "+paymentMethodLabels[payment.PaymentMethod], FormatPesos(payment.Amount), false)
+	}
+	if balance.Outstanding > 0 {
+		ticket.Columns("Pendiente por pagar", FormatPesos(balance.Outstanding), true)
+	}
+
+	if restaurant.ReceiptFooter != nil && *restaurant.ReceiptFooter != "" {
+		ticket.Rule()
+		ticket.Center(*restaurant.ReceiptFooter, false)
+	}
+	return *ticket
+}
+
+// NewKitchenTicket lays out the items a station is to prepare. Each item's
+// quantity is the number of units the station has not been sent yet.
+func NewKitchenTicket(order *Order, station Station, items []OrderItem, restaurant Restaurant, printedAt time.Time) Ticket {
+	ticket := NewTicket(restaurant.PaperWidth)
+	ticket.Center(strings.ToUpper(station.Name), true)
+	writeOrderHeading(ticket, order, printedAt.In(restaurant.Location()))
+	ticket.Rule()
+	for _, item := range items {
+		ticket.Left(fmt.Sprintf("%dx %s", item.Quantity, item.MenuItem.Name), true)
+		for _, modifier := range item.Modifiers {
+			ticket.Left("   + "+modifier.Name, false)
+		}
+		for _, component := range item.Components {
+			ticket.Left(fmt.Sprintf("   - %dx %s", component.Quantity*item.Quantity, component.MenuItem.Name), false)
+		}
+		var placement []string
+		if item.Seat != nil {
+			placement = append(placement, fmt.Sprintf("Puesto %d", *item.Seat))
+		}
+		if item.Course > 1 {
+			placement = append(placement, fmt.Sprintf("Tiempo %d", item.Course))
+		}
+		if len(placement) > 0 {
+			ticket.Left("   "+strings.Join(placement, " · "), false)
+		}
+		if item.Observation != nil && *item.Observation != "" {
+			ticket.Left("   Nota: "+*item.Observation, false)
+		}
+	}
+	if order.Observation != nil && *order.Observation != "" {
+		ticket.Rule()
+		ticket.Left("Nota de la orden: "+*order.Observation, false)
+	}
+	return *ticket
+}
+
+// writeOrderHeading writes the table, order and time a ticket is printed for.
+func writeOrderHeading(ticket *Ticket, order *Order, printedAt time.Time) {
+	ticket.Columns(fmt.Sprintf("Mesa %d", order.Table.TableNumber), "Orden "+shortID(order.OrderID), true)
+	ticket.Left(printedAt.Format("2006-01-02 15:04"), false)
+}
+
+// hasIncludedTax reports whether the order's items of the category hold their
+// tax in their price.
+func (o *Order) hasIncludedTax(category TaxCategory) bool {
+	for _, item := range o.OrderItems {
+		if item.Status != Cancelled && item.TaxCategory == category {
+			return item.TaxIncluded
+		}
+	}
+	return false
+}
+
+// shortID is the first block of a UUID, which is how staff read orders out.
+func shortID(id string) string {
+	short, _, _ := strings.Cut(id, "-")
+	return strings.ToUpper(short)
+}
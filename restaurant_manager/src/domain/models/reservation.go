@@ -0,0 +1,150 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type ReservationStatus string
+
+const (
+	ReservationBooked    ReservationStatus = "booked"
+	ReservationSeated    ReservationStatus = "seated"
+	ReservationNoShow    ReservationStatus = "no_show"
+	ReservationCancelled ReservationStatus = "cancelled"
+)
+
+const (
+	// DefaultReservationDuration is how long a table is held for a party when
+	// the booking does not say.
+	DefaultReservationDuration = 90 * time.Minute
+	// ReservationHoldWindow is how long before a booking its tables stop
+	// taking walk-ins and show as reserved.
+	ReservationHoldWindow = 30 * time.Minute
+	// ReservationGracePeriod is how late a party may arrive before the
+	// booking is marked a no-show and its tables are released.
+	ReservationGracePeriod = 15 * time.Minute
+)
+
+// reservationTransitions lists the statuses a reservation may move to from
+// each status. A party marked a no-show may still be seated if it turns up
+// late; seated and cancelled reservations are final.
+var reservationTransitions = map[ReservationStatus][]ReservationStatus{
+	ReservationBooked: {ReservationSeated, ReservationNoShow, ReservationCancelled},
+	ReservationNoShow: {ReservationSeated},
+}
+
+func (s ReservationStatus) IsValid() bool {
+	switch s {
+	case ReservationBooked, ReservationSeated, ReservationNoShow, ReservationCancelled:
+		return true
+	}
+	return false
+}
+
+// Reservation books tables for a party from StartsAt for DurationMinutes.
+// Booked and seated reservations keep their tables from other bookings over
+// that time.
+type Reservation struct {
+	ReservationID   string             `gorm:"primaryKey;column:reservation_id" json:"reservation_id"`
+	RestaurantID    string             `gorm:"column:restaurant_id" json:"restaurant_id"`
+	GuestName       string             `gorm:"column:guest_name" json:"guest_name"`
+	Phone           string             `gorm:"column:phone" json:"phone"`
+	PartySize       int                `gorm:"column:party_size" json:"party_size"`
+	StartsAt        time.Time          `gorm:"column:starts_at" json:"starts_at"`
+	DurationMinutes int                `gorm:"column:duration_minutes" json:"duration_minutes"`
+	Status          ReservationStatus  `gorm:"column:status;default:booked" json:"status"`
+	Notes           *string            `gorm:"column:notes" json:"notes,omitempty"`
+	SeatedAt        *time.Time         `gorm:"column:seated_at" json:"seated_at,omitempty"`
+	CancelledAt     *time.Time         `gorm:"column:cancelled_at" json:"cancelled_at,omitempty"`
+	CreatedAt       time.Time          `gorm:"column:created_at;default:CURRENT_TIMESTAMP" json:"created_at"`
+	Tables          []ReservationTable `gorm:"foreignKey:ReservationID;references:ReservationID" json:"tables"`
+}
+
+// ReservationTable is a table assigned to a reservation.
+type ReservationTable struct {
+	ReservationID string `gorm:"primaryKey;column:reservation_id" json:"reservation_id"`
+	TableID       string `gorm:"primaryKey;column:table_id" json:"table_id"`
+	Table         Table  `gorm:"foreignKey:TableID;references:TableID" json:"table"`
+}
+
+// EndsAt is when the party is expected to give its tables back.
+func (r Reservation) EndsAt() time.Time {
+	return r.StartsAt.Add(time.Duration(r.DurationMinutes) * time.Minute)
+}
+
+// Overlaps reports whether the reservation holds its tables at any moment
+// between start and end.
+func (r Reservation) Overlaps(start time.Time, end time.Time) bool {
+	return r.StartsAt.Before(end) && r.EndsAt().After(start)
+}
+
+// TableIDs returns the IDs of the tables assigned to the reservation.
+func (r Reservation) TableIDs() []string {
+	ids := make([]string, len(r.Tables))
+	for i, table := range r.Tables {
+		ids[i] = table.TableID
+	}
+	return ids
+}
+
+// Normalize trims the guest's details and gives the reservation the default
+// duration when it has none.
+func (r *Reservation) Normalize() {
+	r.GuestName = strings.TrimSpace(r.GuestName)
+	r.Phone = strings.TrimSpace(r.Phone)
+	if r.DurationMinutes == 0 {
+		r.DurationMinutes = int(DefaultReservationDuration / time.Minute)
+	}
+	if r.Notes != nil && strings.TrimSpace(*r.Notes) == "" {
+		r.Notes = nil
+	}
+}
+
+// Validate reports what, if anything, keeps the reservation from being booked.
+func (r Reservation) Validate() error {
+	if r.GuestName == "" {
+		return fmt.Errorf("guest_name is required")
+	}
+	if r.PartySize <= 0 {
+		return fmt.Errorf("party_size must be at least 1")
+	}
+	if r.StartsAt.IsZero() {
+		return fmt.Errorf("starts_at is required")
+	}
+	if r.DurationMinutes <= 0 || r.DurationMinutes > 24*60 {
+		return fmt.Errorf("duration_minutes must be between 1 and 1440")
+	}
+	seen := make(map[string]bool, len(r.Tables))
+	for _, table := range r.Tables {
+		if table.TableID == "" || seen[table.TableID] {
+			return fmt.Errorf("table_ids must name distinct tables")
+		}
+		seen[table.TableID] = true
+	}
+	return nil
+}
+
+// TransitionTo moves the reservation to status and stamps the time it entered
+// it. Moves not in the state machine are rejected by returning false.
+func (r *Reservation) TransitionTo(status ReservationStatus, at time.Time) bool {
+	allowed := false
+	for _, next := range reservationTransitions[r.Status] {
+		if next == status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return false
+	}
+	r.Status = status
+	switch status {
+	case ReservationSeated:
+		r.SeatedAt = &at
+	case ReservationCancelled:
+		r.CancelledAt = &at
+	}
+	return true
+}
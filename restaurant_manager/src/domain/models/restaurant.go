@@ -8,5 +8,63 @@ type Restaurant struct {
 	Description  string    `gorm:"column:description" json:"description"`
 	OwnerID      string    `gorm:"column:owner_id" json:"owner_id"`
 	ImageURL     string    `gorm:"column:image_url" json:"image_url"`
-	CreatedAt    time.Time `gorm:"column:created_at" json:"created_at"`
+	TimeZone     string    `gorm:"column:time_zone;default:America/Bogota" json:"time_zone"`
+	TaxRegime    TaxRegime `gorm:"column:tax_regime;default:inc" json:"tax_regime"`
+	// PricesIncludeTax tells whether menu prices already hold their tax
+	PricesIncludeTax *bool `gorm:"column:prices_include_tax;default:true" json:"prices_include_tax,omitempty"`
+	// NitNumber and LegalName are what the restaurant invoices under
+	NitNumber *string `gorm:"column:nit_number" json:"nit_number,omitempty"`
+	LegalName *string `gorm:"column:legal_name" json:"legal_name,omitempty"`
+	// ReceiptHeader and ReceiptFooter are printed above and below its receipts
+	ReceiptHeader *string    `gorm:"column:receipt_header" json:"receipt_header,omitempty"`
+	ReceiptFooter *string    `gorm:"column:receipt_footer" json:"receipt_footer,omitempty"`
+	PaperWidth    PaperWidth `gorm:"column:paper_width;default:80" json:"paper_width"`
+	CreatedAt     time.Time  `gorm:"column:created_at" json:"created_at"`
+}
+
+// Location is the restaurant's time zone, UTC when it is not set or unknown.
+func (r Restaurant) Location() *time.Location {
+	if r.TimeZone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(r.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// InvoiceParty is the restaurant as the seller named on its invoices, under its
+// legal name when it has one.
+func (r Restaurant) InvoiceParty() InvoiceParty {
+	party := InvoiceParty{Name: r.Name}
+	if r.LegalName != nil && *r.LegalName != "" {
+		party.Name = *r.LegalName
+	}
+	if r.NitNumber != nil {
+		party.Nit = *r.NitNumber
+	}
+	return party
+}
+
+// TaxIncluded reports whether the restaurant's menu prices include their tax,
+// as they do unless stated otherwise.
+func (r Restaurant) TaxIncluded() bool {
+	return r.PricesIncludeTax == nil || *r.PricesIncludeTax
+}
+
+// TaxFor is the category the restaurant charges on a menu item: the item's own
+// or the regime's default. Restaurants not responsible for taxes charge none.
+func (r Restaurant) TaxFor(item MenuItem) TaxCategory {
+	if r.TaxRegime == RegimeNotResponsible {
+		return TaxNone
+	}
+	if item.TaxCategory != nil {
+		return *item.TaxCategory
+	}
+	regime := r.TaxRegime
+	if regime == "" {
+		regime = RegimeINC
+	}
+	return regime.DefaultCategory()
 }
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// ServiceChargeRule suggests a service charge, the "propina voluntaria", of a
+// percentage of the order total. Guests may decline it, so it is only ever
+// added to a payment as a tip.
+type ServiceChargeRule struct {
+	RuleID            string    `gorm:"primaryKey;column:rule_id" json:"rule_id"`
+	RestaurantID      string    `gorm:"column:restaurant_id" json:"restaurant_id"`
+	Name              string    `gorm:"column:name" json:"name"`
+	Percentage        float64   `gorm:"column:percentage" json:"percentage"`
+	MinimumOrderTotal float64   `gorm:"column:minimum_order_total" json:"minimum_order_total"`
+	Active            bool      `gorm:"column:active" json:"active"`
+	CreatedAt         time.Time `gorm:"column:created_at;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// IsValid reports whether the rule charges a sensible percentage.
+func (r ServiceChargeRule) IsValid() bool {
+	return r.Name != "" && r.Percentage > 0 && r.Percentage <= 100 && r.MinimumOrderTotal >= 0
+}
+
+// On returns the charge the rule suggests on an amount.
+func (r ServiceChargeRule) On(amount float64) float64 {
+	return RoundMoney(amount * r.Percentage / 100)
+}
+
+// ServiceChargeFor picks the rule suggested for an order total: the active rule
+// with the highest minimum the total reaches.
+func ServiceChargeFor(rules []ServiceChargeRule, total float64) *ServiceChargeRule {
+	var chosen *ServiceChargeRule
+	for i, rule := range rules {
+		if !rule.Active || total < rule.MinimumOrderTotal {
+			continue
+		}
+		if chosen == nil || rule.MinimumOrderTotal > chosen.MinimumOrderTotal {
+			chosen = &rules[i]
+		}
+	}
+	return chosen
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+type Shift struct {
+	ShiftID      string     `json:"shift_id" gorm:"primaryKey;column:shift_id"`
+	UserID       string     `json:"user_id" gorm:"column:user_id"`
+	RestaurantID string     `json:"restaurant_id" gorm:"column:restaurant_id"`
+	ClockIn      time.Time  `json:"clock_in" gorm:"column:clock_in"`
+	ClockOut     *time.Time `json:"clock_out,omitempty" gorm:"column:clock_out"`
+	Notes        string     `json:"notes" gorm:"column:notes"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"column:created_at;default:CURRENT_TIMESTAMP"`
+
+	User User `json:"-" gorm:"foreignKey:UserID;references:UserID"`
+}
+
+// ShiftHours is the time an employee worked within a date range.
+type ShiftHours struct {
+	UserID string
+	Name   string
+	Shifts int
+	Hours  float64
+}
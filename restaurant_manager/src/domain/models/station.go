@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+type Station struct {
+	StationID    string    `gorm:"primaryKey;column:station_id" json:"station_id"`
+	RestaurantID string    `gorm:"column:restaurant_id" json:"restaurant_id"`
+	Name         string    `gorm:"column:name" json:"name"`
+	Kind         string    `gorm:"column:kind" json:"kind"`
+	CreatedAt    time.Time `gorm:"column:created_at;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+const (
+	StationGrill   = "grill"
+	StationCold    = "cold"
+	StationBar     = "bar"
+	StationDessert = "dessert"
+)
+
+// defaultStationKinds routes menu items that have no station of their own.
+var defaultStationKinds = map[Category]string{
+	Appetizer: StationCold,
+	Salad:     StationCold,
+	Main:      StationGrill,
+	Soup:      StationGrill,
+	Side:      StationGrill,
+	Drinks:    StationBar,
+	Dessert:   StationDessert,
+}
+
+func IsStationKind(kind string) bool {
+	switch kind {
+	case StationGrill, StationCold, StationBar, StationDessert:
+		return true
+	}
+	return false
+}
+
+// DefaultStationKind returns the kind of station that prepares the category.
+func (c Category) DefaultStationKind() string {
+	return defaultStationKinds[c]
+}
+
+// StationCategories returns the categories routed by default to a kind of station.
+func StationCategories(kind string) []string {
+	var categories []string
+	for category, stationKind := range defaultStationKinds {
+		if stationKind == kind {
+			categories = append(categories, string(category))
+		}
+	}
+	return categories
+}
@@ -0,0 +1,123 @@
+package models
+
+import "sort"
+
+// TaxCategory is the tax a menu item is sold with. Restaurants charge the
+// impuesto nacional al consumo (INC) on what they serve, while goods sold as
+// such carry IVA at their own rate; some goods are exempt (IVA at 0%) or
+// excluded, which is taxed like TaxNone.
+type TaxCategory string
+
+const (
+	TaxNone  TaxCategory = "none"
+	TaxINC8  TaxCategory = "inc_8"
+	TaxIVA19 TaxCategory = "iva_19"
+	TaxIVA5  TaxCategory = "iva_5"
+	TaxIVA0  TaxCategory = "iva_0"
+)
+
+// The taxes categories are charged under.
+const (
+	TaxINC = "INC"
+	TaxIVA = "IVA"
+)
+
+var taxRates = map[TaxCategory]float64{
+	TaxNone:  0,
+	TaxINC8:  8,
+	TaxIVA19: 19,
+	TaxIVA5:  5,
+	TaxIVA0:  0,
+}
+
+// IsValid reports whether the category is one the tax engine knows.
+func (c TaxCategory) IsValid() bool {
+	_, ok := taxRates[c]
+	return ok
+}
+
+// Rate is the category's rate as a percentage.
+func (c TaxCategory) Rate() float64 {
+	return taxRates[c]
+}
+
+// Tax names the tax the category is charged under, empty for TaxNone.
+func (c TaxCategory) Tax() string {
+	switch c {
+	case TaxINC8:
+		return TaxINC
+	case TaxIVA19, TaxIVA5, TaxIVA0:
+		return TaxIVA
+	}
+	return ""
+}
+
+// TaxRegime is the regime a restaurant is registered under, which sets the
+// category of the items that do not name one.
+type TaxRegime string
+
+const (
+	RegimeINC            TaxRegime = "inc"
+	RegimeIVA            TaxRegime = "iva"
+	RegimeNotResponsible TaxRegime = "not_responsible"
+)
+
+// IsValid reports whether the regime is one the tax engine knows.
+func (r TaxRegime) IsValid() bool {
+	return r == RegimeINC || r == RegimeIVA || r == RegimeNotResponsible
+}
+
+// DefaultCategory is the category of the items that do not name one.
+func (r TaxRegime) DefaultCategory() TaxCategory {
+	switch r {
+	case RegimeINC:
+		return TaxINC8
+	case RegimeIVA:
+		return TaxIVA19
+	}
+	return TaxNone
+}
+
+// TaxLine is the tax charged at one rate: the amount taxed and the tax on it.
+type TaxLine struct {
+	Category TaxCategory `gorm:"column:tax_category"`
+	Rate     float64     `gorm:"column:tax_rate"`
+	Base     float64     `gorm:"column:base"`
+	Amount   float64     `gorm:"column:amount"`
+}
+
+// TaxLinesOf sums the taxes of the items, already worked out on them, by
+// category. Items that are cancelled or carry no tax are left out.
+func TaxLinesOf(items []OrderItem) []TaxLine {
+	var lines []TaxLine
+	index := make(map[TaxCategory]int)
+	for _, item := range items {
+		if item.Status == Cancelled || item.TaxCategory == "" || item.TaxCategory == TaxNone {
+			continue
+		}
+		i, ok := index[item.TaxCategory]
+		if !ok {
+			i = len(lines)
+			index[item.TaxCategory] = i
+			lines = append(lines, TaxLine{Category: item.TaxCategory, Rate: item.TaxRate})
+		}
+		lines[i].Base = RoundMoney(lines[i].Base + item.TaxBase)
+		lines[i].Amount = RoundMoney(lines[i].Amount + item.TaxAmount)
+	}
+	sort.Slice(lines, func(i, j int) bool { return lines[i].Category < lines[j].Category })
+	return lines
+}
+
+// lineTax splits a taxable amount into its base and tax. Prices that include
+// the tax hold it already; otherwise it comes on top.
+func lineTax(taxable float64, rate float64, included bool) (base float64, tax float64) {
+	if rate <= 0 {
+		return RoundMoney(taxable), 0
+	}
+	if included {
+		base = RoundMoney(taxable / (1 + rate/100))
+		return base, RoundMoney(taxable - base)
+	}
+	base = RoundMoney(taxable)
+	return base, RoundMoney(base * rate / 100)
+}
@@ -0,0 +1,152 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// PaperWidth is the width, in millimetres, of the roll a restaurant prints on.
+type PaperWidth int
+
+const (
+	Paper58mm PaperWidth = 58
+	Paper80mm PaperWidth = 80
+)
+
+func (w PaperWidth) IsValid() bool {
+	return w == Paper58mm || w == Paper80mm
+}
+
+// Columns is how many characters of the printer's standard font fit across
+// the paper; rolls of unknown width are taken for 80mm.
+func (w PaperWidth) Columns() int {
+	if w == Paper58mm {
+		return 32
+	}
+	return 48
+}
+
+// TicketLine is a line of a ticket, already padded to the ticket's width.
+type TicketLine struct {
+	Text string
+	Bold bool
+}
+
+// Ticket is a receipt or kitchen ticket laid out in a fixed-width font, so
+// every format it is rendered to prints it the same way.
+type Ticket struct {
+	Paper PaperWidth
+	Lines []TicketLine
+}
+
+func NewTicket(paper PaperWidth) *Ticket {
+	if !paper.IsValid() {
+		paper = Paper80mm
+	}
+	return &Ticket{Paper: paper}
+}
+
+// Center writes the text centred, wrapping it over as many lines as it needs.
+func (t *Ticket) Center(text string, bold bool) {
+	width := t.Paper.Columns()
+	for _, line := range wrapText(text, width) {
+		padding := (width - utf8.RuneCountInString(line)) / 2
+		t.add(strings.Repeat(" ", padding)+line, bold)
+	}
+}
+
+// Left writes the text from the left margin, indenting the lines it wraps onto.
+func (t *Ticket) Left(text string, bold bool) {
+	indent := len(text) - len(strings.TrimLeft(text, " "))
+	for i, line := range wrapText(strings.TrimLeft(text, " "), t.Paper.Columns()-indent-2) {
+		if i == 0 {
+			t.add(strings.Repeat(" ", indent)+line, bold)
+		} else {
+			t.add(strings.Repeat(" ", indent+2)+line, bold)
+		}
+	}
+}
+
+// Columns writes the left text with the right text flush against the right
+// margin, wrapping the left text when both do not fit on one line.
+func (t *Ticket) Columns(left string, right string, bold bool) {
+	width := t.Paper.Columns()
+	rightWidth := utf8.RuneCountInString(right)
+	indent := len(left) - len(strings.TrimLeft(left, " "))
+	lines := wrapText(strings.TrimLeft(left, " "), width-indent-rightWidth-1)
+	for i, line := range lines {
+		prefix := strings.Repeat(" ", indent)
+		if i > 0 {
+			prefix += "  "
+		}
+		line = prefix + line
+		if i == len(lines)-1 {
+			line += strings.Repeat(" ", max(width-utf8.RuneCountInString(line)-rightWidth, 1)) + right
+		}
+		t.add(line, bold)
+	}
+}
+
+// Rule writes a line of dashes across the paper.
+func (t *Ticket) Rule() {
+	t.add(strings.Repeat("-", t.Paper.Columns()), false)
+}
+
+func (t *Ticket) Blank() {
+	t.add("", false)
+}
+
+func (t *Ticket) add(text string, bold bool) {
+	t.Lines = append(t.Lines, TicketLine{Text: strings.TrimRight(text, " "), Bold: bold})
+}
+
+// wrapText breaks the text into lines of at most width characters, between
+// words where it can.
+func wrapText(text string, width int) []string {
+	width = max(width, 1)
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			for utf8.RuneCountInString(word) > width {
+				if line != "" {
+					lines = append(lines, line)
+					line = ""
+				}
+				runes := []rune(word)
+				lines = append(lines, string(runes[:width]))
+				word = string(runes[width:])
+			}
+			switch {
+			case line == "":
+				line = word
+			case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width:
+				line += " " + word
+			default:
+				lines = append(lines, line)
+				line = word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// FormatPesos writes an amount as Colombian pesos, as in $1.234.567,89.
+func FormatPesos(amount float64) string {
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	whole := fmt.Sprintf("%.2f", RoundMoney(amount))
+	integer, cents := whole[:len(whole)-3], whole[len(whole)-2:]
+	var grouped strings.Builder
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			grouped.WriteByte('.')
+		}
+		grouped.WriteRune(digit)
+	}
+	return sign + "$" + grouped.String() + "," + cents
+}
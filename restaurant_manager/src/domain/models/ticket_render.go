@@ -0,0 +1,184 @@
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// TicketFormat is what tickets are rendered to: plain text, ESC/POS commands
+// for thermal printers, or a PDF sized to the paper roll.
+type TicketFormat string
+
+const (
+	TicketText   TicketFormat = "text"
+	TicketEscPos TicketFormat = "escpos"
+	TicketPDF    TicketFormat = "pdf"
+)
+
+func (f TicketFormat) IsValid() bool {
+	return f == TicketText || f == TicketEscPos || f == TicketPDF
+}
+
+// ContentType is the media type tickets rendered to the format are served as.
+func (f TicketFormat) ContentType() string {
+	switch f {
+	case TicketEscPos:
+		return "application/octet-stream"
+	case TicketPDF:
+		return "application/pdf"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// RenderTickets renders the tickets, one after the other, to the format.
+func RenderTickets(format TicketFormat, tickets []Ticket) []byte {
+	switch format {
+	case TicketEscPos:
+		return renderEscPos(tickets)
+	case TicketPDF:
+		return renderPDF(tickets)
+	}
+	return renderText(tickets)
+}
+
+func renderText(tickets []Ticket) []byte {
+	var out bytes.Buffer
+	for i, ticket := range tickets {
+		if i > 0 {
+			out.WriteString("\n\n")
+		}
+		for _, line := range ticket.Lines {
+			out.WriteString(line.Text)
+			out.WriteByte('\n')
+		}
+	}
+	return out.Bytes()
+}
+
+// cp850 holds the code page 850 bytes of the non-ASCII characters Spanish
+// tickets use; printers are switched to that code page before printing.
+var cp850 = map[rune]byte{
+	'á': 0xA0, 'é': 0x82, 'í': 0xA1, 'ó': 0xA2, 'ú': 0xA3, 'ü': 0x81,
+	'ñ': 0xA4, 'Ñ': 0xA5, '¿': 0xA8, '¡': 0xAD, '·': 0xFA,
+	'Á': 0xB5, 'É': 0x90, 'Í': 0xD6, 'Ó': 0xE0, 'Ú': 0xE9, 'Ü': 0x9A,
+}
+
+// renderEscPos writes the tickets as ESC/POS commands: each ticket is printed
+// in the printer's standard font, fed past the tear bar and cut.
+func renderEscPos(tickets []Ticket) []byte {
+	var out bytes.Buffer
+	out.Write([]byte{0x1B, '@'})       // initialise
+	out.Write([]byte{0x1B, 't', 0x02}) // code page 850
+	for _, ticket := range tickets {
+		for _, line := range ticket.Lines {
+			if line.Bold {
+				out.Write([]byte{0x1B, 'E', 1})
+			}
+			for _, r := range line.Text {
+				switch {
+				case r < 0x80:
+					out.WriteByte(byte(r))
+				case cp850[r] != 0:
+					out.WriteByte(cp850[r])
+				default:
+					out.WriteByte('?')
+				}
+			}
+			if line.Bold {
+				out.Write([]byte{0x1B, 'E', 0})
+			}
+			out.WriteByte('\n')
+		}
+		out.Write([]byte{0x1B, 'd', 3})          // feed three lines
+		out.Write([]byte{0x1D, 'V', 0x42, 0x00}) // partial cut
+	}
+	return out.Bytes()
+}
+
+// PDF pages are as wide as the paper roll and as long as their ticket, with
+// the lines set in Courier sized so a ticket's columns fill the printable width.
+const (
+	pdfPointsPerMM  = 72 / 25.4
+	pdfMargin       = 8.0
+	pdfCharWidth    = 0.6 // of the font size, for Courier
+	pdfLineSpacing  = 1.2 // of the font size
+	pdfRegularFont  = "F1"
+	pdfBoldFont     = "F2"
+	pdfFirstPageObj = 5
+)
+
+// renderPDF writes the tickets as a PDF with a page for each.
+func renderPDF(tickets []Ticket) []byte {
+	// Objects 1 to 4 are the catalog, the page tree and the two fonts; each
+	// page is followed by its content stream.
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>",
+	}
+	var kids []string
+	for i, ticket := range tickets {
+		pageObj := pdfFirstPageObj + 2*i
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageObj))
+
+		width := float64(ticket.Paper) * pdfPointsPerMM
+		size := (width - 2*pdfMargin) / (pdfCharWidth * float64(ticket.Paper.Columns()))
+		leading := size * pdfLineSpacing
+		height := 2*pdfMargin + leading*float64(max(len(ticket.Lines), 1))
+
+		var content strings.Builder
+		fmt.Fprintf(&content, "BT\n%.2f TL\n%.2f %.2f Td\n", leading, pdfMargin, height-pdfMargin-size)
+		for _, line := range ticket.Lines {
+			font := pdfRegularFont
+			if line.Bold {
+				font = pdfBoldFont
+			}
+			fmt.Fprintf(&content, "/%s %.2f Tf\n(%s) Tj\nT*\n", font, size, pdfString(line.Text))
+		}
+		content.WriteString("ET")
+
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /%s 3 0 R /%s 4 0 R >> >> /Contents %d 0 R >>",
+				width, height, pdfRegularFont, pdfBoldFont, pageObj+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(tickets))
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return out.Bytes()
+}
+
+// pdfString escapes the text for a PDF string in WinAnsi encoding, which
+// matches Latin-1 for the characters tickets use.
+func pdfString(text string) string {
+	var out strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			out.WriteByte('\\')
+			out.WriteByte(byte(r))
+		case r < 0x80:
+			out.WriteByte(byte(r))
+		case r >= 0xA0 && r <= 0xFF:
+			fmt.Fprintf(&out, "\\%03o", r)
+		default:
+			out.WriteByte('?')
+		}
+	}
+	return out.String()
+}
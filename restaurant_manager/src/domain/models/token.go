@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+type RefreshToken struct {
+	TokenID       string     `gorm:"primaryKey;column:token_id"`
+	UserID        string     `gorm:"column:user_id"`
+	TokenHash     string     `gorm:"column:token_hash"`
+	AccessTokenID string     `gorm:"column:access_token_id"`
+	ExpiresAt     time.Time  `gorm:"column:expires_at"`
+	RevokedAt     *time.Time `gorm:"column:revoked_at"`
+	ReplacedBy    *string    `gorm:"column:replaced_by"`
+	CreatedAt     time.Time  `gorm:"column:created_at"`
+}
+
+// RevokedToken is an access token (by jti) that must be rejected until it expires.
+type RevokedToken struct {
+	TokenID   string    `gorm:"primaryKey;column:token_id"`
+	UserID    string    `gorm:"column:user_id"`
+	ExpiresAt time.Time `gorm:"column:expires_at"`
+	RevokedAt time.Time `gorm:"column:revoked_at"`
+}
+
+// PinSession is an access token (by jti) issued when a staff member logs in
+// with their PIN. It has no refresh token, so it is recorded on its own.
+type PinSession struct {
+	TokenID      string    `gorm:"primaryKey;column:token_id"`
+	UserID       string    `gorm:"column:user_id"`
+	RestaurantID string    `gorm:"column:restaurant_id"`
+	ExpiresAt    time.Time `gorm:"column:expires_at"`
+	CreatedAt    time.Time `gorm:"column:created_at"`
+}
+
+const (
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailVerification = "email_verification"
+)
+
+// UserToken is a single-use token mailed to a user to reset their password or
+// verify their email address.
+type UserToken struct {
+	TokenID   string     `gorm:"primaryKey;column:token_id"`
+	UserID    string     `gorm:"column:user_id"`
+	Purpose   string     `gorm:"column:purpose"`
+	TokenHash string     `gorm:"column:token_hash"`
+	ExpiresAt time.Time  `gorm:"column:expires_at"`
+	UsedAt    *time.Time `gorm:"column:used_at"`
+	CreatedAt time.Time  `gorm:"column:created_at"`
+}
@@ -0,0 +1,275 @@
+package models
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// NewInvoice issues the order's invoice under the resolution's next number and
+// writes it as a UBL 2.1 document. Lines are invoiced at their tax base, so
+// discounts are already taken off them and prices that include tax are shown
+// without it. Signing the document is left to whoever transmits it.
+func NewInvoice(order *Order, seller InvoiceParty, buyer InvoiceParty, resolution InvoiceResolution, issuedAt time.Time) (Invoice, error) {
+	totals := ComputeTotals(order, nil)
+	invoice := Invoice{
+		RestaurantID: order.RestaurantID,
+		OrderID:      order.OrderID,
+		ResolutionID: resolution.ResolutionID,
+		Sequence:     resolution.NextNumber,
+		Number:       resolution.Prefix + strconv.FormatInt(resolution.NextNumber, 10),
+		BuyerName:    buyer.Name,
+		BuyerNit:     buyer.Nit,
+		Status:       InvoicePending,
+		IssuedAt:     issuedAt.UTC(),
+	}
+
+	document := ublInvoice{
+		Xmlns:                "urn:oasis:names:specification:ubl:schema:xsd:Invoice-2",
+		XmlnsCac:             "urn:oasis:names:specification:ubl:schema:xsd:CommonAggregateComponents-2",
+		XmlnsCbc:             "urn:oasis:names:specification:ubl:schema:xsd:CommonBasicComponents-2",
+		XmlnsExt:             "urn:oasis:names:specification:ubl:schema:xsd:CommonExtensionComponents-2",
+		XmlnsSts:             "dian:gov:co:facturaelectronica:Structures-2-1",
+		UBLVersionID:         "UBL 2.1",
+		CustomizationID:      "10",
+		ProfileID:            "DIAN 2.1: Factura Electrónica de Venta",
+		ProfileExecutionID:   resolution.Environment.Code(),
+		ID:                   invoice.Number,
+		IssueDate:            issuedAt.Format(time.DateOnly),
+		IssueTime:            issuedAt.Format("15:04:05-07:00"),
+		InvoiceTypeCode:      "01",
+		DocumentCurrencyCode: "COP",
+		Extensions: ublExtensions{Control: ublInvoiceControl{
+			Authorization: resolution.ResolutionNumber,
+			StartDate:     resolution.ValidFrom.Format(time.DateOnly),
+			EndDate:       resolution.ValidTo.Format(time.DateOnly),
+			Prefix:        resolution.Prefix,
+			From:          resolution.RangeFrom,
+			To:            resolution.RangeTo,
+		}},
+		Supplier: ublPartyOf(seller),
+		Customer: ublPartyOf(buyer),
+	}
+
+	taxable := 0.0
+	for _, item := range order.OrderItems {
+		if item.Status == Cancelled {
+			continue
+		}
+		tax := totals.ItemTaxes[item.OrderItemID]
+		invoice.Subtotal += tax.Base
+		line := ublInvoiceLine{
+			ID:                  len(document.Lines) + 1,
+			Quantity:            ublQuantity{UnitCode: "94", Value: item.Quantity},
+			LineExtensionAmount: ublAmountOf(tax.Base),
+			Item:                ublItem{Description: item.MenuItem.Name},
+			Price: ublPrice{
+				PriceAmount:  ublAmountOf(tax.Base / float64(max(item.Quantity, 1))),
+				BaseQuantity: ublQuantity{UnitCode: "94", Value: 1},
+			},
+		}
+		if tax.Category != TaxNone && tax.Category != "" {
+			taxable += tax.Base
+			line.TaxTotal = []ublTaxTotal{ublTaxTotalOf([]TaxLine{tax})}
+		}
+		document.Lines = append(document.Lines, line)
+	}
+	document.LineCount = len(document.Lines)
+	if len(document.Lines) == 0 {
+		return Invoice{}, fmt.Errorf("the order has nothing to invoice")
+	}
+
+	taxes := make(map[string]float64)
+	schemes := make(map[string][]TaxLine)
+	var names []string
+	for _, line := range totals.TaxLines {
+		name := line.Category.Tax()
+		if _, seen := schemes[name]; !seen {
+			names = append(names, name)
+		}
+		schemes[name] = append(schemes[name], line)
+		taxes[name] += line.Amount
+		invoice.TaxTotal += line.Amount
+	}
+	for _, name := range names {
+		document.TaxTotals = append(document.TaxTotals, ublTaxTotalOf(schemes[name]))
+	}
+
+	invoice.Subtotal = RoundMoney(invoice.Subtotal)
+	invoice.TaxTotal = RoundMoney(invoice.TaxTotal)
+	invoice.Total = totals.Total
+	inclusive := RoundMoney(invoice.Subtotal + invoice.TaxTotal)
+	document.Totals = ublMonetaryTotal{
+		LineExtensionAmount: ublAmountOf(invoice.Subtotal),
+		TaxExclusiveAmount:  ublAmountOf(taxable),
+		TaxInclusiveAmount:  ublAmountOf(inclusive),
+		PayableAmount:       ublAmountOf(invoice.Total),
+	}
+	if rounding := RoundMoney(invoice.Total - inclusive); rounding != 0 {
+		document.Totals.PayableRoundingAmount = &ublAmount{CurrencyID: "COP", Value: formatAmount(rounding)}
+	}
+
+	invoice.Cufe = ComputeCufe(invoice, issuedAt, seller.Nit, taxes, resolution)
+	document.UUID = ublUUID{SchemeID: resolution.Environment.Code(), SchemeName: "CUFE-SHA384", Value: invoice.Cufe}
+
+	body, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return Invoice{}, err
+	}
+	invoice.Document = xml.Header + string(body)
+	return invoice, nil
+}
+
+// The UBL elements below carry their namespace prefixes in their names, as
+// encoding/xml writes names verbatim.
+
+type ublInvoice struct {
+	XMLName              xml.Name         `xml:"Invoice"`
+	Xmlns                string           `xml:"xmlns,attr"`
+	XmlnsCac             string           `xml:"xmlns:cac,attr"`
+	XmlnsCbc             string           `xml:"xmlns:cbc,attr"`
+	XmlnsExt             string           `xml:"xmlns:ext,attr"`
+	XmlnsSts             string           `xml:"xmlns:sts,attr"`
+	Extensions           ublExtensions    `xml:"ext:UBLExtensions"`
+	UBLVersionID         string           `xml:"cbc:UBLVersionID"`
+	CustomizationID      string           `xml:"cbc:CustomizationID"`
+	ProfileID            string           `xml:"cbc:ProfileID"`
+	ProfileExecutionID   string           `xml:"cbc:ProfileExecutionID"`
+	ID                   string           `xml:"cbc:ID"`
+	UUID                 ublUUID          `xml:"cbc:UUID"`
+	IssueDate            string           `xml:"cbc:IssueDate"`
+	IssueTime            string           `xml:"cbc:IssueTime"`
+	InvoiceTypeCode      string           `xml:"cbc:InvoiceTypeCode"`
+	DocumentCurrencyCode string           `xml:"cbc:DocumentCurrencyCode"`
+	LineCount            int              `xml:"cbc:LineCountNumeric"`
+	Supplier             ublParty         `xml:"cac:AccountingSupplierParty"`
+	Customer             ublParty         `xml:"cac:AccountingCustomerParty"`
+	TaxTotals            []ublTaxTotal    `xml:"cac:TaxTotal"`
+	Totals               ublMonetaryTotal `xml:"cac:LegalMonetaryTotal"`
+	Lines                []ublInvoiceLine `xml:"cac:InvoiceLine"`
+}
+
+type ublExtensions struct {
+	Control ublInvoiceControl `xml:"ext:UBLExtension>ext:ExtensionContent>sts:DianExtensions>sts:InvoiceControl"`
+}
+
+type ublInvoiceControl struct {
+	Authorization string `xml:"sts:InvoiceAuthorization"`
+	StartDate     string `xml:"sts:AuthorizationPeriod>cbc:StartDate"`
+	EndDate       string `xml:"sts:AuthorizationPeriod>cbc:EndDate"`
+	Prefix        string `xml:"sts:AuthorizedInvoices>sts:Prefix,omitempty"`
+	From          int64  `xml:"sts:AuthorizedInvoices>sts:From"`
+	To            int64  `xml:"sts:AuthorizedInvoices>sts:To"`
+}
+
+type ublUUID struct {
+	SchemeID   string `xml:"schemeID,attr"`
+	SchemeName string `xml:"schemeName,attr"`
+	Value      string `xml:",chardata"`
+}
+
+type ublParty struct {
+	Name      string      `xml:"cac:Party>cac:PartyName>cbc:Name"`
+	TaxScheme ublPartyTax `xml:"cac:Party>cac:PartyTaxScheme"`
+}
+
+type ublPartyTax struct {
+	RegistrationName string       `xml:"cbc:RegistrationName"`
+	CompanyID        ublCompanyID `xml:"cbc:CompanyID"`
+	TaxSchemeID      string       `xml:"cac:TaxScheme>cbc:ID"`
+	TaxSchemeName    string       `xml:"cac:TaxScheme>cbc:Name"`
+}
+
+type ublCompanyID struct {
+	SchemeAgencyID string `xml:"schemeAgencyID,attr"`
+	SchemeID       string `xml:"schemeID,attr,omitempty"`
+	SchemeName     string `xml:"schemeName,attr"`
+	Value          string `xml:",chardata"`
+}
+
+type ublAmount struct {
+	CurrencyID string `xml:"currencyID,attr"`
+	Value      string `xml:",chardata"`
+}
+
+type ublQuantity struct {
+	UnitCode string `xml:"unitCode,attr"`
+	Value    int    `xml:",chardata"`
+}
+
+type ublTaxTotal struct {
+	TaxAmount ublAmount        `xml:"cbc:TaxAmount"`
+	Subtotals []ublTaxSubtotal `xml:"cac:TaxSubtotal"`
+}
+
+type ublTaxSubtotal struct {
+	TaxableAmount ublAmount `xml:"cbc:TaxableAmount"`
+	TaxAmount     ublAmount `xml:"cbc:TaxAmount"`
+	Percent       string    `xml:"cac:TaxCategory>cbc:Percent"`
+	SchemeID      string    `xml:"cac:TaxCategory>cac:TaxScheme>cbc:ID"`
+	SchemeName    string    `xml:"cac:TaxCategory>cac:TaxScheme>cbc:Name"`
+}
+
+type ublMonetaryTotal struct {
+	LineExtensionAmount   ublAmount  `xml:"cbc:LineExtensionAmount"`
+	TaxExclusiveAmount    ublAmount  `xml:"cbc:TaxExclusiveAmount"`
+	TaxInclusiveAmount    ublAmount  `xml:"cbc:TaxInclusiveAmount"`
+	PayableRoundingAmount *ublAmount `xml:"cbc:PayableRoundingAmount,omitempty"`
+	PayableAmount         ublAmount  `xml:"cbc:PayableAmount"`
+}
+
+type ublInvoiceLine struct {
+	ID                  int           `xml:"cbc:ID"`
+	Quantity            ublQuantity   `xml:"cbc:InvoicedQuantity"`
+	LineExtensionAmount ublAmount     `xml:"cbc:LineExtensionAmount"`
+	TaxTotal            []ublTaxTotal `xml:"cac:TaxTotal"`
+	Item                ublItem       `xml:"cac:Item"`
+	Price               ublPrice      `xml:"cac:Price"`
+}
+
+type ublItem struct {
+	Description string `xml:"cbc:Description"`
+}
+
+type ublPrice struct {
+	PriceAmount  ublAmount   `xml:"cbc:PriceAmount"`
+	BaseQuantity ublQuantity `xml:"cbc:BaseQuantity"`
+}
+
+func ublAmountOf(amount float64) ublAmount {
+	return ublAmount{CurrencyID: "COP", Value: formatAmount(amount)}
+}
+
+// ublPartyOf names a party by its NIT, scheme 31 of the tax authority (195).
+func ublPartyOf(party InvoiceParty) ublParty {
+	number, checkDigit := NitParts(party.Nit)
+	return ublParty{
+		Name: party.Name,
+		TaxScheme: ublPartyTax{
+			RegistrationName: party.Name,
+			CompanyID:        ublCompanyID{SchemeAgencyID: "195", SchemeID: checkDigit, SchemeName: "31", Value: number},
+			TaxSchemeID:      InvoiceTaxSchemes[TaxIVA],
+			TaxSchemeName:    TaxIVA,
+		},
+	}
+}
+
+// ublTaxTotalOf totals the tax lines of one tax.
+func ublTaxTotalOf(lines []TaxLine) ublTaxTotal {
+	total := ublTaxTotal{}
+	amount := 0.0
+	for _, line := range lines {
+		name := line.Category.Tax()
+		amount += line.Amount
+		total.Subtotals = append(total.Subtotals, ublTaxSubtotal{
+			TaxableAmount: ublAmountOf(line.Base),
+			TaxAmount:     ublAmountOf(line.Amount),
+			Percent:       formatAmount(line.Rate),
+			SchemeID:      InvoiceTaxSchemes[name],
+			SchemeName:    name,
+		})
+	}
+	total.TaxAmount = ublAmountOf(amount)
+	return total
+}
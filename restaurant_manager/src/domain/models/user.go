@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type User struct {
 	UserID       string    `gorm:"primaryKey;column:user_id" json:"user_id"`
@@ -13,4 +16,27 @@ type User struct {
 	RestaurantId *string   `gorm:"column:restaurant_id" json:"restaurant_id,omitempty"`
 	NitNumber    *string   `gorm:"column:nit_number" json:"nit_number,omitempty"`
 	CreatedAt    time.Time `gorm:"column:created_at" json:"-"`
+	// EmailVerifiedAt is nil until the user follows the verification link
+	EmailVerifiedAt *time.Time `gorm:"column:email_verified_at" json:"email_verified_at,omitempty"`
+	// Staff PIN for quick-login on shared terminals, never serialized
+	PinHash           *string    `gorm:"column:pin_hash" json:"-"`
+	PinFailedAttempts int        `gorm:"column:pin_failed_attempts" json:"-"`
+	PinLockedUntil    *time.Time `gorm:"column:pin_locked_until" json:"-"`
 }
+
+// MarshalJSON leaves the password hash out of every serialized user. The
+// password field is still accepted when decoding requests.
+func (u User) MarshalJSON() ([]byte, error) {
+	type user User
+	return json.Marshal(struct {
+		user
+		PasswordHash string `json:"password,omitempty"`
+	}{user: user(u)})
+}
+
+const (
+	RoleAdmin    = "admin"
+	RoleWaiter   = "waiter"
+	RoleCustomer = "customer"
+	RoleKitchen  = "kitchen"
+)
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+type WaitlistStatus string
+
+const (
+	WaitlistWaiting WaitlistStatus = "waiting"
+	WaitlistSeated  WaitlistStatus = "seated"
+	WaitlistLeft    WaitlistStatus = "left"
+)
+
+const (
+	// DefaultTableTurn is how long a party is expected to stay at a table
+	// while the restaurant has no paid orders to learn it from.
+	DefaultTableTurn = 60 * time.Minute
+	// waitQuoteStep is what quoted waits are rounded up to, as staff say
+	// them out loud.
+	waitQuoteStep = 5 * time.Minute
+)
+
+// WaitlistEntry is a walk-in party waiting for a table, quoted a wait when it
+// joined the line.
+type WaitlistEntry struct {
+	EntryID           string         `gorm:"primaryKey;column:entry_id" json:"entry_id"`
+	RestaurantID      string         `gorm:"column:restaurant_id" json:"restaurant_id"`
+	GuestName         string         `gorm:"column:guest_name" json:"guest_name"`
+	Phone             string         `gorm:"column:phone" json:"phone"`
+	PartySize         int            `gorm:"column:party_size" json:"party_size"`
+	QuotedWaitMinutes int            `gorm:"column:quoted_wait_minutes" json:"quoted_wait_minutes"`
+	Status            WaitlistStatus `gorm:"column:status;default:waiting" json:"status"`
+	TableID           *string        `gorm:"column:table_id" json:"table_id,omitempty"`
+	CreatedAt         time.Time      `gorm:"column:created_at;default:CURRENT_TIMESTAMP" json:"created_at"`
+	SeatedAt          *time.Time     `gorm:"column:seated_at" json:"seated_at,omitempty"`
+	LeftAt            *time.Time     `gorm:"column:left_at" json:"left_at,omitempty"`
+}
+
+// TableOccupancy is when the oldest open order at a table was taken.
+type TableOccupancy struct {
+	TableID string    `gorm:"column:table_id"`
+	Since   time.Time `gorm:"column:since"`
+}
+
+// Validate reports what, if anything, keeps the party from joining the line.
+func (e *WaitlistEntry) Validate() error {
+	e.GuestName = strings.TrimSpace(e.GuestName)
+	e.Phone = strings.TrimSpace(e.Phone)
+	if e.GuestName == "" {
+		return fmt.Errorf("guest_name is required")
+	}
+	if e.PartySize <= 0 {
+		return fmt.Errorf("party_size must be at least 1")
+	}
+	if e.QuotedWaitMinutes < 0 {
+		return fmt.Errorf("quoted_wait_minutes cannot be negative")
+	}
+	return nil
+}
+
+// QuoteWait estimates how long a party joining the line behind ahead other
+// parties waits for a table. Free tables seat the first parties right away;
+// the rest take the busy tables as they are expected to free up, busyFor
+// being how long each is expected to stay busy, and every further round
+// of the busy tables takes another turn.
+func QuoteWait(free int, busyFor []time.Duration, ahead int, turn time.Duration) time.Duration {
+	if ahead < free {
+		return 0
+	}
+	ahead -= free
+	var wait time.Duration
+	if len(busyFor) == 0 {
+		wait = time.Duration(ahead+1) * turn
+	} else {
+		sorted := slices.Clone(busyFor)
+		slices.Sort(sorted)
+		wait = sorted[ahead%len(sorted)] + time.Duration(ahead/len(sorted))*turn
+	}
+	return (wait + waitQuoteStep - 1).Truncate(waitQuoteStep)
+}
+
+// RemainingTurn is how much longer a table occupied since the given time is
+// expected to stay busy. Tables past their turn are expected to free up
+// within a quote step.
+func RemainingTurn(since time.Time, now time.Time, turn time.Duration) time.Duration {
+	return max(turn-now.Sub(since), waitQuoteStep)
+}
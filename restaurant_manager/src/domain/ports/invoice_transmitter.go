@@ -0,0 +1,9 @@
+package ports
+
+import "restaurant_manager/src/domain/models"
+
+// InvoiceTransmitter sends issued invoices to the tax authority and returns
+// its answer.
+type InvoiceTransmitter interface {
+	Transmit(invoice models.Invoice) (models.InvoiceTransmission, error)
+}
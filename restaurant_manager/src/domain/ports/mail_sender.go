@@ -0,0 +1,5 @@
+package ports
+
+type MailSender interface {
+	SendMail(to string, subject string, body string) error
+}
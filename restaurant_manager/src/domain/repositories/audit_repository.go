@@ -0,0 +1,8 @@
+package repositories
+
+import "restaurant_manager/src/domain/models"
+
+type AuditRepository interface {
+	CreateAuditEvent(event *models.AuditEvent) error
+	GetAuditEvents(filter models.AuditFilter) ([]models.AuditEvent, error)
+}
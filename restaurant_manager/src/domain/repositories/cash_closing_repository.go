@@ -8,8 +8,9 @@ import (
 type CashClosingRepository interface {
 	CreateCashClosing(cashClosing *models.CashClosing) error
 	GetCashClosingByDate(restaurantID string, date time.Time) (*models.CashClosing, error)
+	GetCashClosingByID(restaurantID string, cashClosingID string) (*models.CashClosing, error)
 	GetCashClosingHistory(restaurantID string, startDate, endDate time.Time) ([]models.CashClosing, error)
 	UpdateCashClosing(cashClosing *models.CashClosing) error
-	DeleteCashClosing(cashClosingID string) error
+	DeleteCashClosing(restaurantID string, cashClosingID string) error
 	GetCashClosingStats(restaurantID string, startDate, endDate time.Time) (*models.CashClosing, error)
 }
@@ -0,0 +1,14 @@
+package repositories
+
+import "restaurant_manager/src/domain/models"
+
+type InvoiceRepository interface {
+	CreateResolution(resolution *models.InvoiceResolution) (string, error)
+	GetResolutions(restaurantID string) ([]models.InvoiceResolution, error)
+	LockResolution(restaurantID string, day string) (*models.InvoiceResolution, error)
+	AdvanceResolution(resolutionID string, nextNumber int64) error
+	CreateInvoice(invoice *models.Invoice) (string, error)
+	GetInvoiceByOrder(restaurantID string, orderID string) (*models.Invoice, error)
+	UpdateInvoiceTransmission(invoice *models.Invoice) error
+	WithTransaction(fn func(txRepo InvoiceRepository) error) error
+}
@@ -6,9 +6,13 @@ import (
 
 type MenuRepository interface {
 	AddMenuItem(menuItem *models.MenuItem) (string, error)
-	DeleteMenuItem(menuItemID string) error
+	DeleteMenuItem(restaurantID string, menuItemID string) error
 	UpdateMenuItem(menuItem *models.MenuItem) error
+	SetMenuItemAvailable(restaurantID string, menuItemID string, available bool) error
 	GetMenuItemsByRestaurantID(restaurantID string) ([]models.MenuItem, error)
-	GetMenuItemByID(menuItemID string) (*models.MenuItem, error)
+	GetMenuItemByID(restaurantID string, menuItemID string) (*models.MenuItem, error)
 	WithTransaction(fn func(txRepo MenuRepository) error) error
+	CreateModifierGroup(group *models.ModifierGroup) (string, error)
+	DeleteModifierGroup(menuItemID string, modifierGroupID string) error
+	ReplaceComboSlots(comboItemID string, slots []models.ComboSlot) error
 }
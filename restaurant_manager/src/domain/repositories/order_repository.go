@@ -2,22 +2,44 @@ package repositories
 
 import (
 	"restaurant_manager/src/domain/models"
+	"time"
 )
 
 type OrderRepository interface {
 	CreateOrder(order *models.Order) (string, error)
-	DeleteOrder(orderID string) error
+	DeleteOrder(restaurantID string, orderID string) error
 	UpdateOrder(order *models.Order) error
-	GetOrder(orderID string) (*models.Order, error)
+	UpdateOrderTotals(order *models.Order) error
+	GetOrder(restaurantID string, orderID string) (*models.Order, error)
 	GetOrderByRestaurantID(restaurantID string, status string, tableID string, startDate string, endDate string) ([]models.Order, error)
 	AddOrderItem(orderItem *models.OrderItem) (string, error)
 	UpdateOrderItem(orderItem *models.OrderItem) error
+	UpdateOrderItemTaxes(items []models.OrderItem) error
+	ClaimTicketedUnits(orderItemID string, ticketed int, quantity int) (bool, error)
+	UpdateOrderItemQuantity(orderItem *models.OrderItem) error
+	MoveOrderItem(orderItemID string, orderID string) error
+	MoveOrderCoupons(fromOrderID string, toOrderID string) error
+	LockOrders(restaurantID string, orderIDs []string) ([]string, error)
+	HasOpenOrders(restaurantID string, tableID string) (bool, error)
+	LockTable(restaurantID string, tableID string) (*models.Table, error)
+	UpdateTableStatus(restaurantID string, tableID string, status models.TableStatus) error
+	GetTaxTotals(restaurantID string, startDate time.Time, endDate time.Time) ([]models.TaxLine, error)
 	DeleteOrderItem(orderID string, menuItemID string) error
-	GetOrderItems(orderID string) ([]models.OrderItem, error)
-	GetOrderItem(orderID string, menuItemID string, observation string) (*models.OrderItem, error)
+	GetOrderItems(restaurantID string, orderID string) ([]models.OrderItem, error)
+	GetOrderItem(restaurantID string, orderID string, menuItemID string, observation string) (*models.OrderItem, error)
+	GetOrderItemByID(restaurantID string, orderID string, orderItemID string) (*models.OrderItem, error)
 	WithTransaction(fn func(txRepo OrderRepository) error) error
+	Payments() PaymentRepository
+	Inventory() InventoryRepository
+	Menu() MenuRepository
 	AddVoidOrderItem(voidOrderItem *models.VoidOrderItem) error
 	GetVoidOrderItems(restaurantID string) ([]models.VoidOrderItem, error)
-	DeleteVoidOrderItem(voidOrderItemID string) error
-	GetVoidOrderItemByID(voidOrderItemID string) (*models.VoidOrderItem, error)
+	DeleteVoidOrderItem(restaurantID string, voidOrderItemID string) error
+	GetVoidOrderItemByID(restaurantID string, voidOrderItemID string) (*models.VoidOrderItem, error)
+	LockVoidOrderItem(restaurantID string, voidOrderItemID string) (*models.VoidOrderItem, error)
+	AddOrderStatusChange(change *models.OrderStatusChange) error
+	GetOrderStatusHistory(restaurantID string, orderID string) ([]models.OrderStatusChange, error)
+	ReplaceOrderDiscounts(orderID string, discounts []models.OrderDiscount) error
+	RedeemCoupon(coupon *models.OrderCoupon) (bool, error)
+	GetKitchenPerformance(restaurantID string, groupBy string, timeZone string, startDate time.Time, endDate time.Time) ([]models.KitchenPerformance, error)
 }
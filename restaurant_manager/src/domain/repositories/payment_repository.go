@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"restaurant_manager/src/domain/models"
+	"time"
+)
+
+type PaymentRepository interface {
+	CreatePayment(payment *models.Payment) (string, error)
+	GetPayments(restaurantID string, orderID string) ([]models.Payment, error)
+	GetPaymentMethodTotals(restaurantID string, startDate time.Time, endDate time.Time) ([]models.PaymentMethodTotal, error)
+	GetTipShares(restaurantID string, startDate time.Time, endDate time.Time) ([]models.TipShare, error)
+	LockOrder(restaurantID string, orderID string) (*models.Order, error)
+	CreateServiceChargeRule(rule *models.ServiceChargeRule) (string, error)
+	GetServiceChargeRule(restaurantID string, ruleID string) (*models.ServiceChargeRule, error)
+	GetServiceChargeRules(restaurantID string) ([]models.ServiceChargeRule, error)
+	UpdateServiceChargeRule(rule *models.ServiceChargeRule) error
+	DeleteServiceChargeRule(restaurantID string, ruleID string) error
+	WithTransaction(fn func(txRepo PaymentRepository) error) error
+}
@@ -0,0 +1,13 @@
+package repositories
+
+import "restaurant_manager/src/domain/models"
+
+type PromotionRepository interface {
+	CreatePromotion(promotion *models.Promotion) (string, error)
+	GetPromotion(restaurantID string, promotionID string) (*models.Promotion, error)
+	GetPromotions(restaurantID string) ([]models.Promotion, error)
+	GetActivePromotions(restaurantID string) ([]models.Promotion, error)
+	GetPromotionByCode(restaurantID string, code string) (*models.Promotion, error)
+	UpdatePromotion(promotion *models.Promotion) error
+	DeletePromotion(restaurantID string, promotionID string) error
+}
@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	"restaurant_manager/src/domain/models"
+	"time"
+)
+
+type ReservationRepository interface {
+	CreateReservation(reservation *models.Reservation) (string, error)
+	GetReservation(restaurantID string, reservationID string) (*models.Reservation, error)
+	GetReservations(restaurantID string, startDate time.Time, endDate time.Time) ([]models.Reservation, error)
+	UpdateReservation(reservation *models.Reservation) error
+	UpdateReservationStatus(reservation *models.Reservation) error
+	LockTables(restaurantID string, tableIDs []string) ([]string, error)
+	UpdateTableStatus(restaurantID string, tableID string, status models.TableStatus) error
+	GetConflictingReservations(restaurantID string, tableIDs []string, startsAt time.Time, endsAt time.Time, excludeID string) ([]models.Reservation, error)
+	SyncTableStatuses(restaurantID string, holdUntil time.Time, noShowBefore time.Time) error
+	CreateWaitlistEntry(entry *models.WaitlistEntry) (string, error)
+	GetWaitlistEntry(restaurantID string, entryID string) (*models.WaitlistEntry, error)
+	GetWaitlist(restaurantID string) ([]models.WaitlistEntry, error)
+	UpdateWaitlistEntryStatus(entry *models.WaitlistEntry) error
+	GetAverageTableTurn(restaurantID string, since time.Time) (time.Duration, error)
+	GetTableOccupancy(restaurantID string) ([]models.TableOccupancy, error)
+	WithTransaction(fn func(txRepo ReservationRepository) error) error
+}
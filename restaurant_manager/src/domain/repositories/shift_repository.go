@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"restaurant_manager/src/domain/models"
+	"time"
+)
+
+type ShiftRepository interface {
+	CreateShift(shift *models.Shift) (string, error)
+	GetOpenShift(userID string) (*models.Shift, error)
+	CloseShift(shiftID string, clockOut time.Time) error
+	HasOverlappingShift(userID string, clockIn time.Time, clockOut *time.Time) (bool, error)
+	GetShifts(restaurantID string, userID string, startDate, endDate time.Time) ([]models.Shift, error)
+}
@@ -0,0 +1,14 @@
+package repositories
+
+import "restaurant_manager/src/domain/models"
+
+type StationRepository interface {
+	CreateStation(station *models.Station) (string, error)
+	GetStation(restaurantID string, stationID string) (*models.Station, error)
+	GetStations(restaurantID string) ([]models.Station, error)
+	GetDefaultStation(restaurantID string, kind string) (*models.Station, error)
+	UpdateStation(station *models.Station) error
+	DeleteStation(restaurantID string, stationID string) error
+	GetStationItems(restaurantID string, stationID string, categories []string, status models.OrderStatus) ([]models.OrderItem, error)
+	GetLastBumpedItem(restaurantID string, stationID string, categories []string) (*models.OrderItem, error)
+}
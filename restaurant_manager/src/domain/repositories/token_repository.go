@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"restaurant_manager/src/domain/models"
+	"time"
+)
+
+type TokenRepository interface {
+	CreateRefreshToken(token *models.RefreshToken) (string, error)
+	GetRefreshTokenByHash(tokenHash string) (*models.RefreshToken, error)
+	RevokeRefreshToken(tokenID string, replacedBy *string) error
+	RevokeUserRefreshTokens(userID string) error
+	GetRefreshTokensCreatedAfter(userID string, createdAfter time.Time) ([]models.RefreshToken, error)
+	CreatePinSession(session *models.PinSession) error
+	GetActivePinSessions(userID string, at time.Time) ([]models.PinSession, error)
+	AddRevokedToken(token *models.RevokedToken) error
+	GetRevokedTokens(after time.Time) ([]models.RevokedToken, error)
+	FindRevokedToken(tokenID string) (*models.RevokedToken, error)
+	CreateUserToken(token *models.UserToken) (string, error)
+	GetUserTokenByHash(tokenHash string, purpose string) (*models.UserToken, error)
+	UseUserToken(tokenID string) (bool, error)
+	InvalidateUserTokens(userID string, purpose string) error
+	WithTransaction(fn func(txRepo TokenRepository) error) error
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"restaurant_manager/src/domain/models"
+	"time"
 )
 
 type UserRepository interface {
@@ -11,4 +12,9 @@ type UserRepository interface {
 	UpdateUser(user *models.User) error
 	DeleteUser(userID string) error
 	GetUserById(userID string) (*models.User, error)
+	UpdatePassword(userID string, passwordHash string) error
+	MarkEmailVerified(userID string) error
+	UpdatePin(userID string, pinHash string) error
+	RecordPinFailure(userID string, maxAttempts int, lockedUntil time.Time) error
+	ResetPinFailures(userID string) error
 }
@@ -0,0 +1,101 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"restaurant_manager/tests/integration/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const seedInventoryID = "eeeeeee1-eeee-eeee-eeee-eeeeeeeeeee1"
+
+func getAuditEvents(fixture *TestFixture, token string, query string) (int, []map[string]interface{}) {
+	req, _ := http.NewRequest("GET", "/restaurants/"+seedRestaurantID+"/audit"+query, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+
+	var events []map[string]interface{}
+	json.Unmarshal(response.Body.Bytes(), &events)
+	return response.Code, events
+}
+
+func TestInventoryEditIsAudited(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+
+	body, _ := json.Marshal([]map[string]interface{}{
+		{"inventory_id": seedInventoryID, "quantity": 750},
+	})
+	req, _ := http.NewRequest("PUT", "/inventory?restaurant_id="+seedRestaurantID, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	code, events := getAuditEvents(fixture, token, "?entity_type=inventory&entity_id="+seedInventoryID)
+	assert.Equal(t, http.StatusOK, code)
+	if assert.Len(t, events, 1) {
+		event := events[0]
+		assert.Equal(t, "update", event["action"])
+		assert.Equal(t, "11111111-1111-1111-1111-111111111111", event["actor_id"])
+		assert.Equal(t, "Alicia Administradora", event["actor_name"])
+		assert.Equal(t, map[string]interface{}{"quantity": float64(1000)}, event["before"])
+		assert.Equal(t, map[string]interface{}{"quantity": float64(750)}, event["after"])
+	}
+
+	code, events = getAuditEvents(fixture, token, "?entity_type=cash_closing")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, events, 0)
+}
+
+func TestAuditEventsAreAppendOnly(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	err := fixture.Mock.Db.Exec(`INSERT INTO servu.audit_events (restaurant_id, entity_type, entity_id, action)
+		VALUES (?, 'inventory', ?, 'update')`, seedRestaurantID, seedInventoryID).Error
+	assert.NoError(t, err)
+
+	err = fixture.Mock.Db.Exec(`DELETE FROM servu.audit_events`).Error
+	assert.Error(t, err)
+
+	err = fixture.Mock.Db.Exec(`UPDATE servu.audit_events SET action = 'delete'`).Error
+	assert.Error(t, err)
+}
+
+func TestWaiterCannotReadAudit(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "bob@waiter.com", "waiter123")
+
+	code, _ := getAuditEvents(fixture, token, "")
+	assert.Equal(t, http.StatusForbidden, code)
+}
+
+func TestSoldOutMenuItemIsAuditedForTheOrderingUser(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+
+	// Exactly what one pasta needs of an ingredient is left
+	fixture.Mock.Db.Exec(`UPDATE servu.inventories SET quantity = 200 WHERE inventory_id = 'eeeeeee4-eeee-eeee-eeee-eeeeeeeeeee4'`)
+	createPastaOrder(t, fixture, token, 1)
+
+	var available bool
+	fixture.Mock.Db.Raw(`SELECT available FROM servu.menu_items WHERE menu_item_id = ?`, seedPastaID).Scan(&available)
+	assert.False(t, available)
+
+	code, events := getAuditEvents(fixture, token, "?entity_type=menu_item&entity_id="+seedPastaID)
+	assert.Equal(t, http.StatusOK, code)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, seedAliceID, events[0]["actor_id"])
+		assert.Equal(t, map[string]interface{}{"available": false}, events[0]["after"])
+	}
+}
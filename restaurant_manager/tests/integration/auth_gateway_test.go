@@ -0,0 +1,224 @@
+package integration
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"os"
+	"path/filepath"
+	appUtils "restaurant_manager/src/application/utils"
+	"restaurant_manager/src/config"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func login(t *testing.T, fixture *TestFixture, email, password string) map[string]interface{} {
+	body, _ := json.Marshal(map[string]string{"email": email, "password": password})
+	req, _ := http.NewRequest("POST", "/login", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	var resp map[string]interface{}
+	json.Unmarshal(response.Body.Bytes(), &resp)
+	return resp
+}
+
+func refresh(fixture *TestFixture, refreshToken string) (int, map[string]interface{}) {
+	body, _ := json.Marshal(map[string]string{"refresh_token": refreshToken})
+	req, _ := http.NewRequest("POST", "/token/refresh", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+
+	var resp map[string]interface{}
+	json.Unmarshal(response.Body.Bytes(), &resp)
+	return response.Code, resp
+}
+
+func getTables(fixture *TestFixture, token string) int {
+	req, _ := http.NewRequest("GET", "/tables?restaurant_id="+seedRestaurantID, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return fixture.Mock.ExecuteRequest(req, fixture.Router).Code
+}
+
+func TestLoginReturnsRefreshToken(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	resp := login(t, fixture, "alice@admin.com", "admin123")
+	assert.NotEmpty(t, resp["token"])
+	assert.NotEmpty(t, resp["refresh_token"])
+	assert.Equal(t, float64(15*60), resp["expires_in"])
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	loginResp := login(t, fixture, "alice@admin.com", "admin123")
+	refreshToken := loginResp["refresh_token"].(string)
+
+	t.Run("Refresh issues a new pair", func(t *testing.T) {
+		code, resp := refresh(fixture, refreshToken)
+		assert.Equal(t, http.StatusOK, code)
+		assert.NotEqual(t, refreshToken, resp["refresh_token"])
+		assert.Equal(t, http.StatusOK, getTables(fixture, resp["token"].(string)))
+
+		t.Run("Reusing a rotated token revokes the session", func(t *testing.T) {
+			code, _ := refresh(fixture, refreshToken)
+			assert.Equal(t, http.StatusUnauthorized, code)
+
+			code, _ = refresh(fixture, resp["refresh_token"].(string))
+			assert.Equal(t, http.StatusUnauthorized, code)
+			assert.Equal(t, http.StatusUnauthorized, getTables(fixture, resp["token"].(string)))
+		})
+	})
+
+	t.Run("Unknown token", func(t *testing.T) {
+		code, _ := refresh(fixture, "not-a-refresh-token")
+		assert.Equal(t, http.StatusUnauthorized, code)
+	})
+}
+
+func TestLogoutRevokesTokens(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	loginResp := login(t, fixture, "alice@admin.com", "admin123")
+	token := loginResp["token"].(string)
+	refreshToken := loginResp["refresh_token"].(string)
+	assert.Equal(t, http.StatusOK, getTables(fixture, token))
+
+	body, _ := json.Marshal(map[string]string{"refresh_token": refreshToken})
+	req, _ := http.NewRequest("POST", "/logout", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	assert.Equal(t, http.StatusUnauthorized, getTables(fixture, token))
+	code, _ := refresh(fixture, refreshToken)
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	var revoked int
+	fixture.Mock.Db.Raw(`SELECT COUNT(*) FROM servu.revoked_tokens`).Scan(&revoked)
+	assert.Equal(t, 1, revoked)
+}
+
+func TestTokenRevokedByAnotherInstanceIsRejected(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := login(t, fixture, "alice@admin.com", "admin123")["token"].(string)
+	assert.Equal(t, http.StatusOK, getTables(fixture, token))
+
+	// Another instance only shares the revocation through the database
+	payload, _ := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[1])
+	var claims map[string]interface{}
+	json.Unmarshal(payload, &claims)
+	result := fixture.Mock.Db.Exec(`INSERT INTO servu.revoked_tokens (token_id, user_id, expires_at)
+		VALUES (?, '11111111-1111-1111-1111-111111111111', NOW() + INTERVAL '1 hour')`, claims["jti"])
+	assert.NoError(t, result.Error)
+
+	assert.Equal(t, http.StatusUnauthorized, getTables(fixture, token))
+}
+
+func TestJWKSPublishesSigningKey(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	req, _ := http.NewRequest("GET", "/.well-known/jwks.json", nil)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	var jwks struct {
+		Keys []map[string]string `json:"keys"`
+	}
+	json.Unmarshal(response.Body.Bytes(), &jwks)
+	assert.Len(t, jwks.Keys, 1)
+	assert.Equal(t, "RS256", jwks.Keys[0]["alg"])
+	assert.Empty(t, jwks.Keys[0]["d"])
+
+	token := login(t, fixture, "alice@admin.com", "admin123")["token"].(string)
+	header, _ := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[0])
+	var jwtHeader map[string]string
+	json.Unmarshal(header, &jwtHeader)
+	assert.Equal(t, jwks.Keys[0]["kid"], jwtHeader["kid"])
+}
+
+// writeKeyPair generates an RSA key pair and writes it as PEM files, returning
+// the private and public key paths.
+func writeKeyPair(t *testing.T) (string, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	privateDER, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	privatePath, publicPath := filepath.Join(dir, "private.key"), filepath.Join(dir, "public.key")
+	os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0600)
+	os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0644)
+	return privatePath, publicPath
+}
+
+func TestTokensSignedWithARotatedOutKeyStillVerify(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+	defer appUtils.SetJWT(fixture.Mock.Cfg)
+
+	oldToken := login(t, fixture, "alice@admin.com", "admin123")["token"].(string)
+
+	// The old key stays configured for verification only
+	previous := fixture.Mock.Cfg.RestaurantManager.JWT.SigningKeys()[0]
+	privatePath, publicPath := writeKeyPair(t)
+	rotated := *fixture.Mock.Cfg
+	rotated.RestaurantManager.JWT.ActiveKeyID = "next"
+	rotated.RestaurantManager.JWT.Keys = []config.JWTKey{
+		{KeyID: "next", PrivateKeyPath: privatePath, PublicKeyPath: publicPath},
+		{PublicKeyPath: previous.PublicKeyPath},
+	}
+	assert.NoError(t, appUtils.SetJWT(&rotated))
+
+	assert.Equal(t, http.StatusOK, getTables(fixture, oldToken))
+
+	newToken := login(t, fixture, "alice@admin.com", "admin123")["token"].(string)
+	header, _ := base64.RawURLEncoding.DecodeString(strings.Split(newToken, ".")[0])
+	var jwtHeader map[string]string
+	json.Unmarshal(header, &jwtHeader)
+	assert.Equal(t, "next", jwtHeader["kid"])
+	assert.Equal(t, http.StatusOK, getTables(fixture, newToken))
+
+	req, _ := http.NewRequest("GET", "/.well-known/jwks.json", nil)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	var jwks struct {
+		Keys []map[string]string `json:"keys"`
+	}
+	json.Unmarshal(response.Body.Bytes(), &jwks)
+	assert.Len(t, jwks.Keys, 2)
+}
+
+func TestMissingActiveSigningKeyIsRejected(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	previous := fixture.Mock.Cfg.RestaurantManager.JWT.SigningKeys()[0]
+	misconfigured := *fixture.Mock.Cfg
+	misconfigured.RestaurantManager.JWT.ActiveKeyID = "missing"
+	misconfigured.RestaurantManager.JWT.Keys = []config.JWTKey{previous}
+	assert.Error(t, appUtils.SetJWT(&misconfigured))
+
+	misconfigured.RestaurantManager.JWT.ActiveKeyID = ""
+	misconfigured.RestaurantManager.JWT.Keys = []config.JWTKey{{PrivateKeyPath: "missing.key", PublicKeyPath: previous.PublicKeyPath}}
+	assert.Error(t, appUtils.SetJWT(&misconfigured))
+
+	// A failed load leaves the keys in use untouched
+	login(t, fixture, "alice@admin.com", "admin123")
+}
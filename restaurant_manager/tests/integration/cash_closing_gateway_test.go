@@ -245,7 +245,7 @@ func TestDeleteCashClosing(t *testing.T) {
 	token := utils.LoginAndGetToken(t, fixture.Router, "john@example.com", "admin123")
 
 	// Delete cash closing
-	req, _ := http.NewRequest("DELETE", fmt.Sprintf("/cash-closings/%s", cashClosingID), nil)
+	req, _ := http.NewRequest("DELETE", fmt.Sprintf("/cash-closings/%s?restaurant_id=%s", cashClosingID, restaurantID), nil)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 
@@ -0,0 +1,91 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"restaurant_manager/src/application/interfaces/handlers/dto"
+	"restaurant_manager/tests/integration/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	seedPastaID = "ccccccc2-cccc-cccc-cccc-ccccccccccc2"
+	seedFriesID = "ccccccc3-cccc-cccc-cccc-ccccccccccc3"
+	seedRiceID  = "ccccccc5-cccc-cccc-cccc-ccccccccccc5"
+)
+
+func orderCombo(fixture *TestFixture, token string, comboID string, picks []dto.OrderItemComponentDTO) (int, string) {
+	body, _ := json.Marshal(dto.OrderDTO{
+		TableID:      seedTableID,
+		RestaurantID: seedRestaurantID,
+		Items: []dto.OrderItemDTO{
+			{MenuItemID: comboID, Quantity: 1, Observation: "Sin observaciones", Components: picks},
+		},
+	})
+	req, _ := http.NewRequest("POST", "/orders", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+
+	var created map[string]string
+	json.Unmarshal(response.Body.Bytes(), &created)
+	return response.Code, created["order_id"]
+}
+
+func TestComboIsPricedAsBundleAndDeductsComponents(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+
+	var comboID string
+	fixture.Mock.Db.Raw(`INSERT INTO servu.menu_items (restaurant_id, name, description, price, available, category, image_url, side_dishes)
+		VALUES (?, 'Combo Pasta', 'Pasta con dos acompañantes', 30000, TRUE, 'Main', '', 0)
+		RETURNING menu_item_id`, seedRestaurantID).Scan(&comboID)
+
+	pasta, side := seedPastaID, "Side"
+	body, _ := json.Marshal([]dto.ComboSlotDTO{
+		{Name: "Plato", MenuItemID: &pasta},
+		{Name: "Acompañantes", Category: &side, Quantity: 2, Position: 1},
+	})
+	req, _ := http.NewRequest("PUT", "/menus/"+seedRestaurantID+"/items/"+comboID+"/combo-slots", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+
+	var sidesSlotID string
+	fixture.Mock.Db.Raw(`SELECT slot_id FROM servu.combo_slots WHERE combo_item_id = ? AND category = 'Side'`, comboID).Scan(&sidesSlotID)
+
+	// Two sides are required
+	code, _ := orderCombo(fixture, token, comboID, []dto.OrderItemComponentDTO{
+		{SlotID: sidesSlotID, MenuItemID: seedFriesID, Quantity: 1},
+	})
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	var pastaStock, potatoStock float64
+	fixture.Mock.Db.Raw(`SELECT quantity FROM servu.inventories WHERE raw_ingredient_id = 53`).Scan(&pastaStock)
+	fixture.Mock.Db.Raw(`SELECT quantity FROM servu.inventories WHERE raw_ingredient_id = 107`).Scan(&potatoStock)
+
+	code, orderID := orderCombo(fixture, token, comboID, []dto.OrderItemComponentDTO{
+		{SlotID: sidesSlotID, MenuItemID: seedFriesID, Quantity: 1},
+		{SlotID: sidesSlotID, MenuItemID: seedRiceID, Quantity: 1},
+	})
+	assert.Equal(t, http.StatusOK, code)
+
+	var price float64
+	fixture.Mock.Db.Raw(`SELECT price FROM servu.order_items WHERE order_id = ?`, orderID).Scan(&price)
+	assert.Equal(t, 30000.0, price)
+
+	var components int64
+	fixture.Mock.Db.Raw(`SELECT COUNT(*) FROM servu.order_item_components c
+		JOIN servu.order_items i ON i.order_item_id = c.order_item_id WHERE i.order_id = ?`, orderID).Scan(&components)
+	assert.Equal(t, int64(3), components)
+
+	var pastaAfter, potatoAfter float64
+	fixture.Mock.Db.Raw(`SELECT quantity FROM servu.inventories WHERE raw_ingredient_id = 53`).Scan(&pastaAfter)
+	fixture.Mock.Db.Raw(`SELECT quantity FROM servu.inventories WHERE raw_ingredient_id = 107`).Scan(&potatoAfter)
+	assert.Equal(t, pastaStock-200, pastaAfter)
+	assert.Equal(t, potatoStock-200, potatoAfter)
+}
@@ -0,0 +1,100 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"restaurant_manager/src/application/interfaces/handlers/dto"
+	"restaurant_manager/tests/integration/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeldCourseIsFiredToTheKitchen(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+
+	body, _ := json.Marshal(dto.OrderDTO{
+		TableID:      seedTableID,
+		RestaurantID: seedRestaurantID,
+		Items: []dto.OrderItemDTO{
+			{MenuItemID: "ccccccc2-cccc-cccc-cccc-ccccccccccc2", Quantity: 1, Observation: "Sin observaciones", Course: 1},
+			{MenuItemID: "ccccccc3-cccc-cccc-cccc-ccccccccccc3", Quantity: 2, Observation: "Sin observaciones", Course: 2, Hold: true},
+		},
+	})
+	req, _ := http.NewRequest("POST", "/orders", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	var created map[string]string
+	json.Unmarshal(response.Body.Bytes(), &created)
+	orderID := created["order_id"]
+
+	var statuses []string
+	fixture.Mock.Db.Raw(`SELECT status FROM servu.order_items WHERE order_id = ? ORDER BY course`, orderID).Scan(&statuses)
+	assert.Equal(t, []string{"pending", "held"}, statuses)
+
+	req, _ = http.NewRequest("POST", "/orders/"+orderID+"/courses/2/fire?restaurant_id="+seedRestaurantID, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	var fired []dto.OrderItemDTO
+	json.Unmarshal(response.Body.Bytes(), &fired)
+	assert.Len(t, fired, 1)
+	if len(fired) == 1 {
+		assert.Equal(t, "pending", fired[0].Status)
+		assert.Equal(t, 2, fired[0].Course)
+		assert.NotNil(t, fired[0].FiredAt)
+	}
+
+	req, _ = http.NewRequest("POST", "/orders/"+orderID+"/courses/2/fire?restaurant_id="+seedRestaurantID, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestOrderWithHeldCourseCannotAdvance(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+
+	body, _ := json.Marshal(dto.OrderDTO{
+		TableID:      seedTableID,
+		RestaurantID: seedRestaurantID,
+		Items: []dto.OrderItemDTO{
+			{MenuItemID: "ccccccc2-cccc-cccc-cccc-ccccccccccc2", Quantity: 1, Observation: "Sin observaciones", Course: 1},
+			{MenuItemID: "ccccccc3-cccc-cccc-cccc-ccccccccccc3", Quantity: 2, Observation: "Sin observaciones", Course: 2, Hold: true},
+		},
+	})
+	req, _ := http.NewRequest("POST", "/orders", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	var created map[string]string
+	json.Unmarshal(response.Body.Bytes(), &created)
+	orderID := created["order_id"]
+
+	updateStatus := func(status string) int {
+		body, _ := json.Marshal(dto.OrderDTO{OrderID: orderID, RestaurantID: seedRestaurantID, Status: status})
+		req, _ := http.NewRequest("PUT", "/orders", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		return fixture.Mock.ExecuteRequest(req, fixture.Router).Code
+	}
+
+	assert.Equal(t, http.StatusConflict, updateStatus("prepared"))
+	var statuses []string
+	fixture.Mock.Db.Raw(`SELECT status FROM servu.order_items WHERE order_id = ? ORDER BY course`, orderID).Scan(&statuses)
+	assert.Equal(t, []string{"pending", "held"}, statuses)
+
+	req, _ = http.NewRequest("POST", "/orders/"+orderID+"/courses/2/fire?restaurant_id="+seedRestaurantID, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	assert.Equal(t, http.StatusOK, fixture.Mock.ExecuteRequest(req, fixture.Router).Code)
+	assert.Equal(t, http.StatusNoContent, updateStatus("prepared"))
+}
@@ -0,0 +1,62 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"restaurant_manager/src/application/interfaces/handlers/dto"
+	"restaurant_manager/tests/integration/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// streamEvents reads the event stream for a short while, replaying every
+// event the restaurant still keeps.
+func streamEvents(fixture *TestFixture, token string) (int, string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", "/restaurants/"+seedRestaurantID+"/events", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Last-Event-ID", "1")
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	return response.Code, response.Body.String()
+}
+
+func TestEventsAreFilteredByRole(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	adminToken := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+	waiterToken := utils.LoginAndGetToken(t, fixture.Router, "bob@waiter.com", "waiter123")
+
+	body, _ := json.Marshal(dto.OrderDTO{
+		TableID:      seedTableID,
+		RestaurantID: seedRestaurantID,
+		Items: []dto.OrderItemDTO{
+			{MenuItemID: "ccccccc2-cccc-cccc-cccc-ccccccccccc2", Quantity: 1, Observation: "Sin observaciones"},
+		},
+	})
+	req, _ := http.NewRequest("POST", "/orders", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	assert.Equal(t, http.StatusNoContent, updateOrderItemStatus(fixture, adminToken, "Sin cebolla", "prepared"))
+
+	code, stream := streamEvents(fixture, adminToken)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, stream, "event: order_created")
+	assert.Contains(t, stream, "event: item_added")
+	assert.Contains(t, stream, "event: item_status_changed")
+
+	code, stream = streamEvents(fixture, waiterToken)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, stream, "event: order_created")
+	assert.NotContains(t, stream, "event: item_added")
+	assert.Contains(t, stream, "event: item_status_changed")
+	assert.Contains(t, stream, `"status":"prepared"`)
+}
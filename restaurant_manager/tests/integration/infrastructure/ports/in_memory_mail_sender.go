@@ -0,0 +1,38 @@
+package utils
+
+import "sync"
+
+type SentMail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// InMemoryMailSender records mails instead of sending them.
+type InMemoryMailSender struct {
+	mu    sync.Mutex
+	Mails []SentMail
+}
+
+func NewInMemoryMailSender() *InMemoryMailSender {
+	return &InMemoryMailSender{}
+}
+
+func (m *InMemoryMailSender) SendMail(to string, subject string, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Mails = append(m.Mails, SentMail{To: to, Subject: subject, Body: body})
+	return nil
+}
+
+// LastMailTo returns the most recent mail sent to the given address.
+func (m *InMemoryMailSender) LastMailTo(to string) (SentMail, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.Mails) - 1; i >= 0; i-- {
+		if m.Mails[i].To == to {
+			return m.Mails[i], true
+		}
+	}
+	return SentMail{}, false
+}
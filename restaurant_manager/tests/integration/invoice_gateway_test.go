@@ -0,0 +1,166 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"restaurant_manager/src/application/interfaces/handlers/dto"
+	"restaurant_manager/tests/integration/utils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const seedAliceID = "11111111-1111-1111-1111-111111111111"
+
+func issueInvoice(fixture *TestFixture, token string, orderID string, buyerID string) (int, dto.InvoiceDTO) {
+	body, _ := json.Marshal(dto.InvoiceRequest{BuyerID: buyerID})
+	req, _ := http.NewRequest("POST", "/orders/"+orderID+"/invoice?restaurant_id="+seedRestaurantID, bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	var invoice dto.InvoiceDTO
+	json.Unmarshal(response.Body.Bytes(), &invoice)
+	return response.Code, invoice
+}
+
+func createPaidPastaOrder(t *testing.T, fixture *TestFixture, token string) string {
+	orderID := createPastaOrder(t, fixture, token, 2)
+	fixture.Mock.Db.Exec(`UPDATE servu.orders SET status = 'delivered' WHERE order_id = ?`, orderID)
+	code, result := recordPayment(fixture, token, orderID, dto.PaymentRequest{Method: "card", Amount: 50000})
+	assert.Equal(t, http.StatusCreated, code)
+	assert.True(t, result.Balance.Settled)
+	return orderID
+}
+
+func TestElectronicInvoicesAreNumberedAndDownloadable(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+
+	openOrderID := createPastaOrder(t, fixture, token, 1)
+	code, _ := issueInvoice(fixture, token, openOrderID, seedAliceID)
+	assert.Equal(t, http.StatusConflict, code)
+
+	orderID := createPaidPastaOrder(t, fixture, token)
+
+	// The restaurant needs a NIT and a numbering resolution before invoicing
+	code, _ = issueInvoice(fixture, token, orderID, seedAliceID)
+	assert.Equal(t, http.StatusConflict, code)
+
+	body, _ := json.Marshal(map[string]string{"nit_number": "900123456-7", "legal_name": "El Sabor Colombiano S.A.S."})
+	req, _ := http.NewRequest("PUT", "/restaurants/"+seedRestaurantID, bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	code, _ = issueInvoice(fixture, token, orderID, seedAliceID)
+	assert.Equal(t, http.StatusConflict, code)
+
+	today := time.Now().UTC()
+	resolution := dto.InvoiceResolutionDTO{
+		ResolutionNumber: "18760000001",
+		Prefix:           "SETP",
+		RangeFrom:        990000001,
+		RangeTo:          990000000,
+		ValidFrom:        today.AddDate(0, 0, -1).Format("2006-01-02"),
+		ValidTo:          today.AddDate(1, 0, 0).Format("2006-01-02"),
+		TechnicalKey:     "fc8eac422eba16e22ffd8c6f94b3f40a6e38162c",
+	}
+	body, _ = json.Marshal(resolution)
+	req, _ = http.NewRequest("POST", "/restaurants/"+seedRestaurantID+"/invoice-resolutions", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+
+	resolution.RangeTo = 990000002
+	body, _ = json.Marshal(resolution)
+	req, _ = http.NewRequest("POST", "/restaurants/"+seedRestaurantID+"/invoice-resolutions", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusCreated, response.Code)
+
+	code, _ = issueInvoice(fixture, token, orderID, "")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, invoice := issueInvoice(fixture, token, orderID, seedAliceID)
+	assert.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, "SETP990000001", invoice.Number)
+	assert.Len(t, invoice.Cufe, 96)
+	assert.Equal(t, "NIT001", invoice.BuyerNit)
+	assert.Equal(t, 46296.30, invoice.Subtotal)
+	assert.Equal(t, 3703.70, invoice.TaxTotal)
+	assert.Equal(t, 50000.0, invoice.Total)
+	assert.Equal(t, "accepted", invoice.Status)
+
+	// Invoicing again returns the same invoice
+	code, again := issueInvoice(fixture, token, orderID, seedAliceID)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, invoice.Number, again.Number)
+	assert.Equal(t, invoice.Cufe, again.Cufe)
+
+	req, _ = http.NewRequest("GET", "/orders/"+orderID+"/invoice?restaurant_id="+seedRestaurantID, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "application/xml", response.Header().Get("Content-Type"))
+	document := response.Body.String()
+	assert.True(t, strings.Contains(document, "<cbc:ID>SETP990000001</cbc:ID>"))
+	assert.True(t, strings.Contains(document, invoice.Cufe))
+	assert.True(t, strings.Contains(document, `schemeID="7" schemeName="31">900123456</cbc:CompanyID>`))
+	assert.True(t, strings.Contains(document, `<cbc:PayableAmount currencyID="COP">50000.00</cbc:PayableAmount>`))
+
+	code, next := issueInvoice(fixture, token, createPaidPastaOrder(t, fixture, token), seedAliceID)
+	assert.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, "SETP990000002", next.Number)
+
+	code, _ = issueInvoice(fixture, token, createPaidPastaOrder(t, fixture, token), seedAliceID)
+	assert.Equal(t, http.StatusConflict, code)
+
+	req, _ = http.NewRequest("GET", "/restaurants/"+seedRestaurantID+"/invoice-resolutions", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	var resolutions []dto.InvoiceResolutionDTO
+	json.Unmarshal(response.Body.Bytes(), &resolutions)
+	if assert.Len(t, resolutions, 1) {
+		assert.Equal(t, int64(990000003), resolutions[0].NextNumber)
+		assert.Empty(t, resolutions[0].TechnicalKey)
+	}
+}
+
+func TestInvoiceBuyerMustBelongToTheOrder(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+	fixture.Mock.Db.Exec(`UPDATE servu.restaurants SET nit_number = '900123456-7' WHERE restaurant_id = ?`, seedRestaurantID)
+	today := time.Now().UTC()
+	body, _ := json.Marshal(dto.InvoiceResolutionDTO{
+		ResolutionNumber: "18760000001",
+		Prefix:           "SETP",
+		RangeFrom:        990000001,
+		RangeTo:          990000010,
+		ValidFrom:        today.AddDate(0, 0, -1).Format("2006-01-02"),
+		ValidTo:          today.AddDate(1, 0, 0).Format("2006-01-02"),
+		TechnicalKey:     "fc8eac422eba16e22ffd8c6f94b3f40a6e38162c",
+	})
+	req, _ := http.NewRequest("POST", "/restaurants/"+seedRestaurantID+"/invoice-resolutions", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	assert.Equal(t, http.StatusCreated, fixture.Mock.ExecuteRequest(req, fixture.Router).Code)
+
+	var strangerID string
+	fixture.Mock.Db.Raw(`INSERT INTO servu.users (name, email, password_hash, role, phone, nit_number)
+		VALUES ('Stranger', 'stranger@example.com', '$2a$10$OadQYtj4KxIpkjOQ/zw62euZ00cLJDUmUGMJ5bdGU2TE1.6GwKsoa', 'customer', '3000000000', '800000000-1')
+		RETURNING user_id`).Scan(&strangerID)
+
+	orderID := createPaidPastaOrder(t, fixture, token)
+	code, _ := issueInvoice(fixture, token, orderID, strangerID)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	// Staff of the restaurant may take the invoice
+	code, invoice := issueInvoice(fixture, token, orderID, "22222222-2222-2222-2222-222222222222")
+	assert.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, "NIT002", invoice.BuyerNit)
+}
@@ -106,9 +106,12 @@ func TestDeleteMenu(t *testing.T) {
 		log.Err(result.Error)
 	}
 
+	token := utils.LoginAndGetToken(t, fixture.Router, "john@example.com", "admin123")
+
 	// Use the correct endpoint for deleting a menu item
 	connStr := fmt.Sprintf("/menus/%s/items/%s", restaurantID, menuItemID)
 	req, _ := http.NewRequest("DELETE", connStr, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
 	req.Header.Set("Content-Type", "application/json")
 
 	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
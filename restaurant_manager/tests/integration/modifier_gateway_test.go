@@ -0,0 +1,155 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"restaurant_manager/src/application/interfaces/handlers/dto"
+	"restaurant_manager/tests/integration/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const seedMainCourseID = "ccccccc1-cccc-cccc-cccc-ccccccccccc1"
+
+func getModifierGroups(t *testing.T, fixture *TestFixture, token string) []dto.ModifierGroupDTO {
+	req, _ := http.NewRequest("GET", "/menus/"+seedRestaurantID+"/items/"+seedMainCourseID+"/modifier-groups", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	var groups []dto.ModifierGroupDTO
+	json.Unmarshal(response.Body.Bytes(), &groups)
+	return groups
+}
+
+func createOrderWithModifiers(fixture *TestFixture, token string, modifierIDs ...string) *http.Response {
+	modifiers := make([]dto.OrderItemModifierDTO, len(modifierIDs))
+	for i, id := range modifierIDs {
+		modifiers[i] = dto.OrderItemModifierDTO{ModifierID: id}
+	}
+	body, _ := json.Marshal(dto.OrderDTO{
+		TableID:      seedTableID,
+		RestaurantID: seedRestaurantID,
+		Items: []dto.OrderItemDTO{
+			{MenuItemID: seedMainCourseID, Quantity: 1, Observation: "Sin observaciones", Modifiers: modifiers},
+		},
+	})
+	req, _ := http.NewRequest("POST", "/orders", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	return fixture.Mock.ExecuteRequest(req, fixture.Router).Result()
+}
+
+func TestSideDishesAreMigratedToModifiers(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+
+	groups := getModifierGroups(t, fixture, token)
+	assert.Len(t, groups, 1)
+	if len(groups) == 1 {
+		assert.Equal(t, "Guarnición", groups[0].Name)
+		assert.False(t, groups[0].Required)
+		assert.Equal(t, 2, groups[0].MaxSelections)
+		assert.Len(t, groups[0].Modifiers, 4)
+	}
+}
+
+func TestOrderItemModifiersArePricedAndValidated(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+
+	body, _ := json.Marshal(dto.ModifierGroupDTO{
+		Name:          "Término",
+		Required:      true,
+		MinSelections: 1,
+		MaxSelections: 1,
+		Modifiers: []dto.ModifierDTO{
+			{Name: "Término medio"},
+			{Name: "Bien cocido", PriceDelta: 2000, Position: 1},
+		},
+	})
+	req, _ := http.NewRequest("POST", "/menus/"+seedRestaurantID+"/items/"+seedMainCourseID+"/modifier-groups", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusCreated, response.Code)
+
+	var doneness, side dto.ModifierDTO
+	for _, group := range getModifierGroups(t, fixture, token) {
+		switch group.Name {
+		case "Término":
+			doneness = group.Modifiers[1]
+		case "Guarnición":
+			side = group.Modifiers[0]
+		}
+	}
+	assert.Equal(t, "Bien cocido", doneness.Name)
+
+	// The doneness group is required
+	assert.Equal(t, http.StatusBadRequest, createOrderWithModifiers(fixture, token, side.ID).StatusCode)
+
+	response2 := createOrderWithModifiers(fixture, token, doneness.ID, side.ID)
+	assert.Equal(t, http.StatusOK, response2.StatusCode)
+	var created map[string]string
+	json.NewDecoder(response2.Body).Decode(&created)
+
+	var item struct {
+		OrderItemID string
+		Price       float64
+	}
+	fixture.Mock.Db.Raw(`SELECT order_item_id, price FROM servu.order_items WHERE order_id = ?`, created["order_id"]).Scan(&item)
+	assert.Equal(t, 52000.0, item.Price)
+
+	var chosen int64
+	fixture.Mock.Db.Raw(`SELECT COUNT(*) FROM servu.order_item_modifiers WHERE order_item_id = ?`, item.OrderItemID).Scan(&chosen)
+	assert.Equal(t, int64(2), chosen)
+
+	// Items are addressed by their ID rather than their observation
+	body, _ = json.Marshal(map[string]string{"order_item_id": item.OrderItemID, "status": "prepared"})
+	req, _ = http.NewRequest("PUT", "/orders/"+created["order_id"]+"/items/"+seedMainCourseID+"?restaurant_id="+seedRestaurantID, bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+
+	var status string
+	fixture.Mock.Db.Raw(`SELECT status FROM servu.order_items WHERE order_item_id = ?`, item.OrderItemID).Scan(&status)
+	assert.Equal(t, "prepared", status)
+}
+
+func TestSidesSentWithADishAreIncluded(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+
+	// The menu page sends each side chosen for a dish as an item of its own
+	side := func(menuItemID string, dish string) dto.OrderItemDTO {
+		return dto.OrderItemDTO{MenuItemID: menuItemID, Quantity: 1, Observation: "Guarnición de " + dish}
+	}
+	body, _ := json.Marshal(dto.OrderDTO{
+		TableID:      seedTableID,
+		RestaurantID: seedRestaurantID,
+		Items: []dto.OrderItemDTO{
+			{MenuItemID: seedMainCourseID, Quantity: 1, Observation: "Sin observaciones"},
+			side("ccccccc3-cccc-cccc-cccc-ccccccccccc3", "Bife a la Criolla"),
+			side("ccccccc4-cccc-cccc-cccc-ccccccccccc4", "Bife a la Criolla"),
+			side("ccccccc3-cccc-cccc-cccc-ccccccccccc3", "Bife a la Criolla"),
+			side("ccccccc5-cccc-cccc-cccc-ccccccccccc5", "Pasta Alfredo"),
+		},
+	})
+	req, _ := http.NewRequest("POST", "/orders", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	var created map[string]string
+	json.Unmarshal(response.Body.Bytes(), &created)
+
+	var prices []float64
+	fixture.Mock.Db.Raw(`SELECT price FROM servu.order_items WHERE order_id = ? ORDER BY price, menu_item_id`, created["order_id"]).Scan(&prices)
+	// The dish includes two sides; a third one, and one for a dish not on the order, are charged
+	assert.Equal(t, []float64{0, 0, 7000, 8000, 50000}, prices)
+}
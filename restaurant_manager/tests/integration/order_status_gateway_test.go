@@ -0,0 +1,160 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"restaurant_manager/src/application/interfaces/handlers/dto"
+	"restaurant_manager/tests/integration/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func updateOrderStatus(fixture *TestFixture, token string, status string) int {
+	body, _ := json.Marshal(dto.OrderDTO{
+		OrderID:      seedOrderID,
+		RestaurantID: seedRestaurantID,
+		Status:       status,
+	})
+	req, _ := http.NewRequest("PUT", "/orders", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	return fixture.Mock.ExecuteRequest(req, fixture.Router).Code
+}
+
+func updateOrderItemStatus(fixture *TestFixture, token string, observation string, status string) int {
+	body, _ := json.Marshal(map[string]string{"observation": observation, "status": status})
+	req, _ := http.NewRequest("PUT", "/orders/"+seedOrderID+"/items/ccccccc1-cccc-cccc-cccc-ccccccccccc1?restaurant_id="+seedRestaurantID, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	return fixture.Mock.ExecuteRequest(req, fixture.Router).Code
+}
+
+func TestOrderFollowsStateMachine(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+
+	assert.Equal(t, http.StatusConflict, updateOrderStatus(fixture, token, "paid"))
+	assert.Equal(t, http.StatusNoContent, updateOrderStatus(fixture, token, "prepared"))
+	assert.Equal(t, http.StatusConflict, updateOrderStatus(fixture, token, "ordered"))
+
+	var order struct {
+		Status     string
+		PreparedAt *time.Time
+	}
+	fixture.Mock.Db.Raw(`SELECT status, prepared_at FROM servu.orders WHERE order_id = ?`, seedOrderID).Scan(&order)
+	assert.Equal(t, "prepared", order.Status)
+	assert.NotNil(t, order.PreparedAt)
+
+	var pendingItems int64
+	fixture.Mock.Db.Raw(`SELECT COUNT(*) FROM servu.order_items WHERE order_id = ? AND (status <> 'prepared' OR prepared_at IS NULL)`, seedOrderID).Scan(&pendingItems)
+	assert.Equal(t, int64(0), pendingItems)
+
+	// The bill is settled while the order is still in the kitchen
+	req, _ := http.NewRequest("GET", "/orders/"+seedOrderID+"/balance?restaurant_id="+seedRestaurantID, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	var balance dto.BalanceDTO
+	json.Unmarshal(fixture.Mock.ExecuteRequest(req, fixture.Router).Body.Bytes(), &balance)
+	code, _ := recordPayment(fixture, token, seedOrderID, dto.PaymentRequest{Method: "cash", Amount: balance.Outstanding})
+	assert.Equal(t, http.StatusCreated, code)
+
+	assert.Equal(t, http.StatusNoContent, updateOrderStatus(fixture, token, "delivered"))
+	assert.Equal(t, http.StatusNoContent, updateOrderStatus(fixture, token, "paid"))
+	assert.Equal(t, http.StatusConflict, updateOrderStatus(fixture, token, "cancelled"))
+
+	var completedItems int64
+	fixture.Mock.Db.Raw(`SELECT COUNT(*) FROM servu.order_items WHERE order_id = ? AND status = 'completed' AND completed_at IS NOT NULL`, seedOrderID).Scan(&completedItems)
+	assert.Equal(t, int64(3), completedItems)
+}
+
+func TestOrderItemFollowsStateMachine(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+
+	assert.Equal(t, http.StatusConflict, updateOrderItemStatus(fixture, token, "Sin cebolla", "completed"))
+	assert.Equal(t, http.StatusConflict, updateOrderItemStatus(fixture, token, "Sin cebolla", "unknown"))
+	assert.Equal(t, http.StatusNoContent, updateOrderItemStatus(fixture, token, "Sin cebolla", "prepared"))
+	assert.Equal(t, http.StatusConflict, updateOrderItemStatus(fixture, token, "Sin cebolla", "pending"))
+
+	var preparedAt *time.Time
+	fixture.Mock.Db.Raw(`SELECT prepared_at FROM servu.order_items WHERE order_id = ? AND observation = 'Sin cebolla'`, seedOrderID).Scan(&preparedAt)
+	assert.NotNil(t, preparedAt)
+}
+
+func TestOrderStatusHistoryAndKitchenPerformance(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+
+	assert.Equal(t, http.StatusNoContent, updateOrderStatus(fixture, token, "prepared"))
+	assert.Equal(t, http.StatusNoContent, updateOrderStatus(fixture, token, "delivered"))
+	// Nothing has been paid yet, so the order cannot be closed by hand
+	assert.Equal(t, http.StatusConflict, updateOrderStatus(fixture, token, "paid"))
+
+	req, _ := http.NewRequest("GET", "/orders/"+seedOrderID+"/history?restaurant_id="+seedRestaurantID, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	var history []map[string]interface{}
+	json.Unmarshal(response.Body.Bytes(), &history)
+	if assert.Len(t, history, 2) {
+		assert.Equal(t, "ordered", history[0]["from_status"])
+		assert.Equal(t, "prepared", history[0]["to_status"])
+		assert.Equal(t, "delivered", history[1]["to_status"])
+	}
+
+	var preparedAt, deliveredAt time.Time
+	var timeToDeliver float64
+	fixture.Mock.Db.Raw(`SELECT prepared_at FROM servu.orders WHERE order_id = ?`, seedOrderID).Scan(&preparedAt)
+	fixture.Mock.Db.Raw(`SELECT delivered_at FROM servu.orders WHERE order_id = ?`, seedOrderID).Scan(&deliveredAt)
+	fixture.Mock.Db.Raw(`SELECT time_to_deliver_seconds FROM servu.orders WHERE order_id = ?`, seedOrderID).Scan(&timeToDeliver)
+	assert.InDelta(t, deliveredAt.Sub(preparedAt).Seconds(), timeToDeliver, 0.01)
+
+	req, _ = http.NewRequest("GET", "/restaurants/"+seedRestaurantID+"/kitchen-performance?group_by=day", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	var performance []dto.KitchenPerformanceDTO
+	json.Unmarshal(response.Body.Bytes(), &performance)
+	assert.NotEmpty(t, performance)
+
+	req, _ = http.NewRequest("GET", "/restaurants/"+seedRestaurantID+"/kitchen-performance?group_by=week", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
+
+func TestKitchenPerformanceIsBucketedInRestaurantTimeZone(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+
+	// 03:30 UTC is still the evening before in Bogotá
+	fixture.Mock.Db.Exec(`UPDATE servu.orders SET created_at = '2026-03-10 03:30:00' WHERE order_id = ?`, seedOrderID)
+
+	buckets := func(groupBy string) []string {
+		req, _ := http.NewRequest("GET", "/restaurants/"+seedRestaurantID+"/kitchen-performance?group_by="+groupBy+"&start_date=2026-03-09&end_date=2026-03-10", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+		assert.Equal(t, http.StatusOK, response.Code)
+		var performance []dto.KitchenPerformanceDTO
+		json.Unmarshal(response.Body.Bytes(), &performance)
+		var names []string
+		for _, bucket := range performance {
+			names = append(names, bucket.Bucket)
+		}
+		return names
+	}
+	assert.Equal(t, []string{"22"}, buckets("hour"))
+	assert.Equal(t, []string{"2026-03-09"}, buckets("day"))
+}
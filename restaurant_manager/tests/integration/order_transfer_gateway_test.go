@@ -0,0 +1,126 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"restaurant_manager/src/application/interfaces/handlers/dto"
+	"restaurant_manager/tests/integration/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createTable(t *testing.T, fixture *TestFixture, number int) string {
+	var tableID string
+	result := fixture.Mock.Db.Raw(`INSERT INTO servu.tables (restaurant_id, table_number, qr_code)
+		VALUES (?, ?, 'QR_CODE')
+		RETURNING table_id`, seedRestaurantID, number).Scan(&tableID)
+	assert.NoError(t, result.Error)
+	return tableID
+}
+
+func createPastaOrderAt(t *testing.T, fixture *TestFixture, token string, tableID string, quantity int) string {
+	body, _ := json.Marshal(dto.OrderDTO{
+		TableID:      tableID,
+		RestaurantID: seedRestaurantID,
+		Items:        []dto.OrderItemDTO{{MenuItemID: seedPastaID, Quantity: quantity, Observation: "Sin observaciones"}},
+	})
+	req, _ := http.NewRequest("POST", "/orders", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	var created map[string]string
+	json.Unmarshal(response.Body.Bytes(), &created)
+	return created["order_id"]
+}
+
+func postOrderMove(fixture *TestFixture, token string, path string, request any) (int, dto.OrderDTO) {
+	body, _ := json.Marshal(request)
+	req, _ := http.NewRequest("POST", path+"?restaurant_id="+seedRestaurantID, bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	var order dto.OrderDTO
+	json.Unmarshal(response.Body.Bytes(), &order)
+	return response.Code, order
+}
+
+func tableStatus(fixture *TestFixture, tableID string) string {
+	var status string
+	fixture.Mock.Db.Raw(`SELECT status FROM servu.tables WHERE table_id = ?`, tableID).Scan(&status)
+	return status
+}
+
+func TestOrdersAreTransferredMergedAndSplit(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+	terrace := createTable(t, fixture, 41)
+	window := createTable(t, fixture, 42)
+
+	first := createPastaOrderAt(t, fixture, token, terrace, 2)
+	assert.Equal(t, "occupied", tableStatus(fixture, terrace))
+
+	code, order := postOrderMove(fixture, token, "/orders/"+first+"/transfer", dto.TransferOrderRequest{TableID: window})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, window, order.TableID)
+	assert.Equal(t, "available", tableStatus(fixture, terrace))
+	assert.Equal(t, "occupied", tableStatus(fixture, window))
+
+	// The second seed table is occupied
+	code, _ = postOrderMove(fixture, token, "/orders/"+first+"/transfer", dto.TransferOrderRequest{TableID: "bbbbbbb2-bbbb-bbbb-bbbb-bbbbbbbbbbb2"})
+	assert.Equal(t, http.StatusConflict, code)
+
+	// One of the two pastas moves to a new order at the terrace
+	second := createPastaOrderAt(t, fixture, token, terrace, 1)
+	pasta := order.Items[0].OrderItemID
+	code, _ = postOrderMove(fixture, token, "/orders/"+first+"/items/move", dto.MoveOrderItemsRequest{
+		TargetOrderID: second,
+		Items:         []dto.MovedOrderItemDTO{{OrderItemID: pasta, Quantity: 3}},
+	})
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, order = postOrderMove(fixture, token, "/orders/"+first+"/items/move", dto.MoveOrderItemsRequest{
+		TargetOrderID: second,
+		Items:         []dto.MovedOrderItemDTO{{OrderItemID: pasta, Quantity: 1}},
+	})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, second, order.OrderID)
+	assert.Len(t, order.Items, 2)
+	assert.Equal(t, 50000.0, order.TotalPrice)
+
+	var remaining int
+	fixture.Mock.Db.Raw(`SELECT quantity FROM servu.order_items WHERE order_item_id = ?`, pasta).Scan(&remaining)
+	assert.Equal(t, 1, remaining)
+
+	// The terrace joins the window
+	code, order = postOrderMove(fixture, token, "/orders/merge", dto.MergeOrdersRequest{TargetOrderID: first, OrderIDs: []string{second}})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, first, order.OrderID)
+	assert.Len(t, order.Items, 3)
+	assert.Equal(t, 75000.0, order.TotalPrice)
+	assert.Equal(t, "available", tableStatus(fixture, terrace))
+	assert.Equal(t, "occupied", tableStatus(fixture, window))
+
+	var merged struct {
+		Status     string
+		MergedInto string
+	}
+	fixture.Mock.Db.Raw(`SELECT status, merged_into FROM servu.orders WHERE order_id = ?`, second).Scan(&merged)
+	assert.Equal(t, "cancelled", merged.Status)
+	assert.Equal(t, first, merged.MergedInto)
+
+	code, _ = postOrderMove(fixture, token, "/orders/merge", dto.MergeOrdersRequest{TargetOrderID: first, OrderIDs: []string{second}})
+	assert.Equal(t, http.StatusConflict, code)
+
+	// Items already paid for stay with their order
+	third := createPastaOrderAt(t, fixture, token, terrace, 1)
+	fixture.Mock.Db.Exec(`UPDATE servu.orders SET status = 'delivered' WHERE order_id = ?`, first)
+	code, _ = recordPayment(fixture, token, first, dto.PaymentRequest{Method: "cash", Amount: 25000})
+	assert.Equal(t, http.StatusCreated, code)
+	code, _ = postOrderMove(fixture, token, "/orders/"+first+"/items/move", dto.MoveOrderItemsRequest{
+		TargetOrderID: third,
+		Items:         []dto.MovedOrderItemDTO{{OrderItemID: pasta}},
+	})
+	assert.Equal(t, http.StatusConflict, code)
+}
@@ -7,6 +7,7 @@ import (
 	"restaurant_manager/src/application/interfaces/handlers/dto"
 	"restaurant_manager/tests/integration/utils"
 	"testing"
+	"time"
 
 	"restaurant_manager/src/domain/models"
 
@@ -67,7 +68,10 @@ func TestCreateOrder(t *testing.T) {
 	}
 	orderJSON, _ := json.Marshal(orderData)
 
+	token := utils.LoginAndGetToken(t, fixture.Router, "john@example.com", "admin123")
+
 	req, _ := http.NewRequest("POST", "/orders", bytes.NewBuffer(orderJSON))
+	req.Header.Set("Authorization", "Bearer "+token)
 
 	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
 	assert.Equal(t, http.StatusOK, response.Code)
@@ -131,7 +135,10 @@ func TestDeleteOrder(t *testing.T) {
 	}
 	orderJSON, _ := json.Marshal(orderData)
 
+	token := utils.LoginAndGetToken(t, fixture.Router, "john@example.com", "admin123")
+
 	req, _ := http.NewRequest("POST", "/orders", bytes.NewBuffer(orderJSON))
+	req.Header.Set("Authorization", "Bearer "+token)
 
 	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
 	assert.Equal(t, http.StatusOK, response.Code)
@@ -143,7 +150,8 @@ func TestDeleteOrder(t *testing.T) {
 	assert.NotEmpty(t, orderID)
 
 	// Delete the order
-	deleteReq, _ := http.NewRequest("DELETE", "/orders/"+orderID, nil)
+	deleteReq, _ := http.NewRequest("DELETE", "/orders/"+orderID+"?restaurant_id="+restaurantID, nil)
+	deleteReq.Header.Set("Authorization", "Bearer "+token)
 	deleteResponse := fixture.Mock.ExecuteRequest(deleteReq, fixture.Router)
 	assert.Equal(t, http.StatusNoContent, deleteResponse.Code)
 }
@@ -260,7 +268,10 @@ func TestRecoverVoidOrderItem(t *testing.T) {
 	}
 	orderJSON, _ := json.Marshal(orderData)
 
+	token := utils.LoginAndGetToken(t, fixture.Router, "john@example.com", "admin123")
+
 	req, _ := http.NewRequest("POST", "/orders", bytes.NewBuffer(orderJSON))
+	req.Header.Set("Authorization", "Bearer "+token)
 	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
 	assert.Equal(t, http.StatusOK, response.Code)
 
@@ -279,11 +290,13 @@ func TestRecoverVoidOrderItem(t *testing.T) {
 	voidItemJSON, _ := json.Marshal(voidItemData)
 
 	voidReq, _ := http.NewRequest("POST", "/orders/"+orderID+"/items/"+menuItemID+"/void", bytes.NewBuffer(voidItemJSON))
+	voidReq.Header.Set("Authorization", "Bearer "+token)
 	voidResponse := fixture.Mock.ExecuteRequest(voidReq, fixture.Router)
 	assert.Equal(t, http.StatusNoContent, voidResponse.Code)
 
 	// Get void order items to get the void order item ID
 	getVoidReq, _ := http.NewRequest("GET", "/restaurants/"+restaurantID+"/order-items/void", nil)
+	getVoidReq.Header.Set("Authorization", "Bearer "+token)
 	getVoidResponse := fixture.Mock.ExecuteRequest(getVoidReq, fixture.Router)
 	assert.Equal(t, http.StatusOK, getVoidResponse.Code)
 
@@ -312,6 +325,7 @@ func TestRecoverVoidOrderItem(t *testing.T) {
 	newOrderJSON, _ := json.Marshal(newOrderData)
 
 	newOrderReq, _ := http.NewRequest("POST", "/orders", bytes.NewBuffer(newOrderJSON))
+	newOrderReq.Header.Set("Authorization", "Bearer "+token)
 	newOrderResponse := fixture.Mock.ExecuteRequest(newOrderReq, fixture.Router)
 	assert.Equal(t, http.StatusOK, newOrderResponse.Code)
 
@@ -332,12 +346,14 @@ func TestRecoverVoidOrderItem(t *testing.T) {
 		log.Err(result.Error)
 	}
 
-	recoveryReq, _ := http.NewRequest("POST", "/void-order-items/"+voidOrderItemIDFromDB+"/recover", bytes.NewBuffer(recoveryJSON))
+	recoveryReq, _ := http.NewRequest("POST", "/void-order-items/"+voidOrderItemIDFromDB+"/recover?restaurant_id="+restaurantID, bytes.NewBuffer(recoveryJSON))
+	recoveryReq.Header.Set("Authorization", "Bearer "+token)
 	recoveryResponse := fixture.Mock.ExecuteRequest(recoveryReq, fixture.Router)
 	assert.Equal(t, http.StatusNoContent, recoveryResponse.Code)
 
 	// Verify the void item was deleted
 	getVoidReqAfter, _ := http.NewRequest("GET", "/restaurants/"+restaurantID+"/order-items/void", nil)
+	getVoidReqAfter.Header.Set("Authorization", "Bearer "+token)
 	getVoidResponseAfter := fixture.Mock.ExecuteRequest(getVoidReqAfter, fixture.Router)
 	assert.Equal(t, http.StatusOK, getVoidResponseAfter.Code)
 
@@ -346,7 +362,8 @@ func TestRecoverVoidOrderItem(t *testing.T) {
 	assert.Len(t, voidItemsAfter, 0)
 
 	// Verify the new order has the recovered item (quantity should be increased)
-	getOrderReq, _ := http.NewRequest("GET", "/orders/"+newOrderID, nil)
+	getOrderReq, _ := http.NewRequest("GET", "/orders/"+newOrderID+"?restaurant_id="+restaurantID, nil)
+	getOrderReq.Header.Set("Authorization", "Bearer "+token)
 	getOrderResponse := fixture.Mock.ExecuteRequest(getOrderReq, fixture.Router)
 	assert.Equal(t, http.StatusOK, getOrderResponse.Code)
 
@@ -412,7 +429,10 @@ func TestRecoverVoidOrderItemNoMatchingItem(t *testing.T) {
 	}
 	orderJSON, _ := json.Marshal(orderData)
 
+	token := utils.LoginAndGetToken(t, fixture.Router, "john@example.com", "admin123")
+
 	req, _ := http.NewRequest("POST", "/orders", bytes.NewBuffer(orderJSON))
+	req.Header.Set("Authorization", "Bearer "+token)
 	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
 	assert.Equal(t, http.StatusOK, response.Code)
 
@@ -431,6 +451,7 @@ func TestRecoverVoidOrderItemNoMatchingItem(t *testing.T) {
 	voidItemJSON, _ := json.Marshal(voidItemData)
 
 	voidReq, _ := http.NewRequest("POST", "/orders/"+orderID+"/items/"+menuItemID+"/void", bytes.NewBuffer(voidItemJSON))
+	voidReq.Header.Set("Authorization", "Bearer "+token)
 	voidResponse := fixture.Mock.ExecuteRequest(voidReq, fixture.Router)
 	assert.Equal(t, http.StatusNoContent, voidResponse.Code)
 
@@ -445,6 +466,7 @@ func TestRecoverVoidOrderItemNoMatchingItem(t *testing.T) {
 	newOrderJSON, _ := json.Marshal(newOrderData)
 
 	newOrderReq, _ := http.NewRequest("POST", "/orders", bytes.NewBuffer(newOrderJSON))
+	newOrderReq.Header.Set("Authorization", "Bearer "+token)
 	newOrderResponse := fixture.Mock.ExecuteRequest(newOrderReq, fixture.Router)
 	assert.Equal(t, http.StatusOK, newOrderResponse.Code)
 
@@ -465,12 +487,14 @@ func TestRecoverVoidOrderItemNoMatchingItem(t *testing.T) {
 		log.Err(result.Error)
 	}
 
-	recoveryReq, _ := http.NewRequest("POST", "/void-order-items/"+voidOrderItemIDFromDB+"/recover", bytes.NewBuffer(recoveryJSON))
+	recoveryReq, _ := http.NewRequest("POST", "/void-order-items/"+voidOrderItemIDFromDB+"/recover?restaurant_id="+restaurantID, bytes.NewBuffer(recoveryJSON))
+	recoveryReq.Header.Set("Authorization", "Bearer "+token)
 	recoveryResponse := fixture.Mock.ExecuteRequest(recoveryReq, fixture.Router)
 	assert.Equal(t, http.StatusInternalServerError, recoveryResponse.Code) // Should fail because no matching item
 
 	// Verify the void item still exists (was not deleted)
 	getVoidReq, _ := http.NewRequest("GET", "/restaurants/"+restaurantID+"/order-items/void", nil)
+	getVoidReq.Header.Set("Authorization", "Bearer "+token)
 	getVoidResponse := fixture.Mock.ExecuteRequest(getVoidReq, fixture.Router)
 	assert.Equal(t, http.StatusOK, getVoidResponse.Code)
 
@@ -521,6 +545,8 @@ func TestGetOrderByRestaurantID(t *testing.T) {
 		RETURNING menu_item_id`, restaurantID).Scan(&menuItemID2)
 	assert.NoError(t, result.Error)
 
+	token := utils.LoginAndGetToken(t, fixture.Router, "test@example.com", "admin123")
+
 	// Create orders with different statuses and dates
 	orderData1 := dto.OrderDTO{
 		TableID:      tableID1,
@@ -541,6 +567,7 @@ func TestGetOrderByRestaurantID(t *testing.T) {
 	}
 	orderJSON1, _ := json.Marshal(orderData1)
 	req1, _ := http.NewRequest("POST", "/orders", bytes.NewBuffer(orderJSON1))
+	req1.Header.Set("Authorization", "Bearer "+token)
 	response1 := fixture.Mock.ExecuteRequest(req1, fixture.Router)
 	assert.Equal(t, http.StatusOK, response1.Code)
 
@@ -563,12 +590,14 @@ func TestGetOrderByRestaurantID(t *testing.T) {
 	}
 	orderJSON2, _ := json.Marshal(orderData2)
 	req2, _ := http.NewRequest("POST", "/orders", bytes.NewBuffer(orderJSON2))
+	req2.Header.Set("Authorization", "Bearer "+token)
 	response2 := fixture.Mock.ExecuteRequest(req2, fixture.Router)
 	assert.Equal(t, http.StatusOK, response2.Code)
 
 	// Test 1: Get all orders by restaurant ID
 	t.Run("GetAllOrdersByRestaurantID", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/orders?restaurant_id="+restaurantID, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
 		response := fixture.Mock.ExecuteRequest(req, fixture.Router)
 		assert.Equal(t, http.StatusOK, response.Code)
 
@@ -581,6 +610,7 @@ func TestGetOrderByRestaurantID(t *testing.T) {
 	// Test 2: Filter by status
 	t.Run("FilterByStatus", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/orders?restaurant_id="+restaurantID+"&status=ordered", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
 		response := fixture.Mock.ExecuteRequest(req, fixture.Router)
 		assert.Equal(t, http.StatusOK, response.Code)
 
@@ -594,6 +624,7 @@ func TestGetOrderByRestaurantID(t *testing.T) {
 	// Test 3: Filter by table ID
 	t.Run("FilterByTableID", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/orders?restaurant_id="+restaurantID+"&table_id="+tableID1, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
 		response := fixture.Mock.ExecuteRequest(req, fixture.Router)
 		assert.Equal(t, http.StatusOK, response.Code)
 
@@ -608,6 +639,7 @@ func TestGetOrderByRestaurantID(t *testing.T) {
 	t.Run("FilterByDateRange", func(t *testing.T) {
 		today := "2024-01-01"
 		req, _ := http.NewRequest("GET", "/orders?restaurant_id="+restaurantID+"&start_date="+today+"&end_date="+today, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
 		response := fixture.Mock.ExecuteRequest(req, fixture.Router)
 		assert.Equal(t, http.StatusOK, response.Code)
 
@@ -621,6 +653,7 @@ func TestGetOrderByRestaurantID(t *testing.T) {
 	// Test 5: Combined filters
 	t.Run("CombinedFilters", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/orders?restaurant_id="+restaurantID+"&status=ordered&table_id="+tableID1, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
 		response := fixture.Mock.ExecuteRequest(req, fixture.Router)
 		assert.Equal(t, http.StatusOK, response.Code)
 
@@ -635,6 +668,7 @@ func TestGetOrderByRestaurantID(t *testing.T) {
 	// Test 6: Empty result
 	t.Run("EmptyResult", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/orders?restaurant_id="+restaurantID+"&status=paid", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
 		response := fixture.Mock.ExecuteRequest(req, fixture.Router)
 		assert.Equal(t, http.StatusOK, response.Code)
 
@@ -647,18 +681,15 @@ func TestGetOrderByRestaurantID(t *testing.T) {
 	// Test 7: Invalid restaurant ID
 	t.Run("InvalidRestaurantID", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/orders?restaurant_id=invalid-id", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
 		response := fixture.Mock.ExecuteRequest(req, fixture.Router)
-		assert.Equal(t, http.StatusOK, response.Code)
-
-		var orders []dto.OrderDTO
-		err := json.Unmarshal(response.Body.Bytes(), &orders)
-		assert.NoError(t, err)
-		assert.Len(t, orders, 0)
+		assert.Equal(t, http.StatusForbidden, response.Code)
 	})
 
 	// Test 8: Missing restaurant ID
 	t.Run("MissingRestaurantID", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/orders", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
 		response := fixture.Mock.ExecuteRequest(req, fixture.Router)
 		assert.Equal(t, http.StatusOK, response.Code)
 
@@ -702,6 +733,8 @@ func TestGetOrderByRestaurantIDWithMultipleStatuses(t *testing.T) {
 	statuses := []string{"ordered", "prepared", "delivered", "paid", "cancelled"}
 	var orderIDs []string
 
+	token := utils.LoginAndGetToken(t, fixture.Router, "test@example.com", "admin123")
+
 	for _, status := range statuses {
 		orderData := dto.OrderDTO{
 			TableID:      tableID,
@@ -720,8 +753,13 @@ func TestGetOrderByRestaurantIDWithMultipleStatuses(t *testing.T) {
 			},
 			TotalPrice: 10.99,
 		}
+		// Paid and cancelled orders take no items
+		if status == "paid" || status == "cancelled" {
+			orderData.Items = nil
+		}
 		orderJSON, _ := json.Marshal(orderData)
 		req, _ := http.NewRequest("POST", "/orders", bytes.NewBuffer(orderJSON))
+		req.Header.Set("Authorization", "Bearer "+token)
 		response := fixture.Mock.ExecuteRequest(req, fixture.Router)
 		assert.Equal(t, http.StatusOK, response.Code)
 
@@ -734,6 +772,7 @@ func TestGetOrderByRestaurantIDWithMultipleStatuses(t *testing.T) {
 	for _, status := range statuses {
 		t.Run("FilterByStatus_"+status, func(t *testing.T) {
 			req, _ := http.NewRequest("GET", "/orders?restaurant_id="+restaurantID+"&status="+status, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
 			response := fixture.Mock.ExecuteRequest(req, fixture.Router)
 			assert.Equal(t, http.StatusOK, response.Code)
 
@@ -774,6 +813,8 @@ func TestGetOrderByRestaurantIDWithDateFiltering(t *testing.T) {
 		RETURNING menu_item_id`, restaurantID).Scan(&menuItemID)
 	assert.NoError(t, result.Error)
 
+	token := utils.LoginAndGetToken(t, fixture.Router, "test@example.com", "admin123")
+
 	// Create multiple orders
 	for i := 0; i < 3; i++ {
 		orderData := dto.OrderDTO{
@@ -795,6 +836,7 @@ func TestGetOrderByRestaurantIDWithDateFiltering(t *testing.T) {
 		}
 		orderJSON, _ := json.Marshal(orderData)
 		req, _ := http.NewRequest("POST", "/orders", bytes.NewBuffer(orderJSON))
+		req.Header.Set("Authorization", "Bearer "+token)
 		response := fixture.Mock.ExecuteRequest(req, fixture.Router)
 		assert.Equal(t, http.StatusOK, response.Code)
 	}
@@ -806,6 +848,7 @@ func TestGetOrderByRestaurantIDWithDateFiltering(t *testing.T) {
 
 		// Test with start_date only
 		req, _ := http.NewRequest("GET", "/orders?restaurant_id="+restaurantID+"&start_date="+today, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
 		response := fixture.Mock.ExecuteRequest(req, fixture.Router)
 		assert.Equal(t, http.StatusOK, response.Code)
 
@@ -816,6 +859,7 @@ func TestGetOrderByRestaurantIDWithDateFiltering(t *testing.T) {
 
 		// Test with end_date only
 		req, _ = http.NewRequest("GET", "/orders?restaurant_id="+restaurantID+"&end_date="+tomorrow, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
 		response = fixture.Mock.ExecuteRequest(req, fixture.Router)
 		assert.Equal(t, http.StatusOK, response.Code)
 
@@ -825,6 +869,7 @@ func TestGetOrderByRestaurantIDWithDateFiltering(t *testing.T) {
 
 		// Test with both dates
 		req, _ = http.NewRequest("GET", "/orders?restaurant_id="+restaurantID+"&start_date="+today+"&end_date="+today, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
 		response = fixture.Mock.ExecuteRequest(req, fixture.Router)
 		assert.Equal(t, http.StatusOK, response.Code)
 
@@ -833,3 +878,209 @@ func TestGetOrderByRestaurantIDWithDateFiltering(t *testing.T) {
 		assert.Len(t, orders, 3) // Should return orders from today only
 	})
 }
+
+func TestOrderTotalsAreComputedByServer(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+
+	body, _ := json.Marshal(dto.ServiceChargeRuleDTO{Name: "Propina voluntaria", Percentage: 10})
+	req, _ := http.NewRequest("POST", "/restaurants/"+seedRestaurantID+"/service-charge-rules", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusCreated, response.Code)
+
+	body, _ = json.Marshal(dto.OrderDTO{
+		TableID:      seedTableID,
+		RestaurantID: seedRestaurantID,
+		TotalPrice:   1,
+		Items:        []dto.OrderItemDTO{{MenuItemID: seedPastaID, Quantity: 2, Observation: "Sin observaciones"}},
+	})
+	req, _ = http.NewRequest("POST", "/orders", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	var created map[string]string
+	json.Unmarshal(response.Body.Bytes(), &created)
+	orderID := created["order_id"]
+
+	findOrder := func() dto.OrderDTO {
+		req, _ := http.NewRequest("GET", "/orders?restaurant_id="+seedRestaurantID+"&status=ordered", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+		var orders []dto.OrderDTO
+		json.Unmarshal(response.Body.Bytes(), &orders)
+		for _, order := range orders {
+			if order.OrderID == orderID {
+				return order
+			}
+		}
+		t.Fatalf("order %s not listed", orderID)
+		return dto.OrderDTO{}
+	}
+
+	order := findOrder()
+	assert.Equal(t, 50000.0, order.TotalPrice)
+	// Prices include the 8% INC charged by default
+	assert.Equal(t, &dto.PriceBreakdownDTO{
+		Subtotal:      50000,
+		Taxes:         3703.70,
+		ServiceCharge: 5000,
+		Total:         50000,
+		TaxLines:      []dto.TaxLineDTO{{Tax: "INC", Category: "inc_8", Rate: 8, Base: 46296.30, Amount: 3703.70}},
+	}, order.Breakdown)
+
+	body, _ = json.Marshal(map[string]string{"observation": "Sin observaciones"})
+	req, _ = http.NewRequest("DELETE", "/orders/"+orderID+"/items/"+seedPastaID+"?restaurant_id="+seedRestaurantID, bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+
+	// Paid orders and their completed items no longer change
+	paidOrderID := "fffffff3-ffff-ffff-ffff-fffffffffff3"
+	body, _ = json.Marshal(map[string]string{"observation": "Sin observaciones"})
+	req, _ = http.NewRequest("DELETE", "/orders/"+paidOrderID+"/items/"+seedPastaID+"?restaurant_id="+seedRestaurantID, bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	assert.Equal(t, http.StatusConflict, fixture.Mock.ExecuteRequest(req, fixture.Router).Code)
+	body, _ = json.Marshal([]dto.OrderItemDTO{{MenuItemID: seedPastaID, Quantity: 1, Observation: "Sin observaciones"}})
+	req, _ = http.NewRequest("POST", "/orders/"+paidOrderID+"/items?restaurant_id="+seedRestaurantID, bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	assert.Equal(t, http.StatusConflict, fixture.Mock.ExecuteRequest(req, fixture.Router).Code)
+	var quantity int
+	fixture.Mock.Db.Raw(`SELECT SUM(quantity) FROM servu.order_items WHERE order_id = ?`, paidOrderID).Scan(&quantity)
+	assert.Equal(t, 1, quantity)
+
+	// Totals and tables sent with an update are ignored
+	body, _ = json.Marshal(dto.OrderDTO{OrderID: orderID, RestaurantID: seedRestaurantID, TableID: "bbbbbbb2-bbbb-bbbb-bbbb-bbbbbbbbbbb2", TotalPrice: 99})
+	req, _ = http.NewRequest("PUT", "/orders", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	var tableID string
+	fixture.Mock.Db.Raw(`SELECT table_id FROM servu.orders WHERE order_id = ?`, orderID).Scan(&tableID)
+	assert.Equal(t, seedTableID, tableID)
+
+	order = findOrder()
+	assert.Equal(t, 25000.0, order.TotalPrice)
+	assert.Equal(t, &dto.PriceBreakdownDTO{
+		Subtotal:      25000,
+		Taxes:         1851.85,
+		ServiceCharge: 2500,
+		Total:         25000,
+		TaxLines:      []dto.TaxLineDTO{{Tax: "INC", Category: "inc_8", Rate: 8, Base: 23148.15, Amount: 1851.85}},
+	}, order.Breakdown)
+}
+
+func TestTaxesAreChargedPerLineAndReportedInCashClosing(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+
+	// The pasta carries 19% IVA on top of its price
+	fixture.Mock.Db.Exec(`UPDATE servu.restaurants SET prices_include_tax = FALSE WHERE restaurant_id = ?`, seedRestaurantID)
+	fixture.Mock.Db.Exec(`UPDATE servu.menu_items SET tax_category = 'iva_19' WHERE menu_item_id = ?`, seedPastaID)
+
+	body, _ := json.Marshal(dto.OrderDTO{
+		TableID:      seedTableID,
+		RestaurantID: seedRestaurantID,
+		Items:        []dto.OrderItemDTO{{MenuItemID: seedPastaID, Quantity: 2, Observation: "Sin observaciones"}},
+	})
+	req, _ := http.NewRequest("POST", "/orders", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	var created map[string]string
+	json.Unmarshal(response.Body.Bytes(), &created)
+	orderID := created["order_id"]
+
+	var item struct {
+		TaxCategory string
+		TaxBase     float64
+		TaxAmount   float64
+	}
+	fixture.Mock.Db.Raw(`SELECT tax_category, tax_base, tax_amount FROM servu.order_items WHERE order_id = ?`, orderID).Scan(&item)
+	assert.Equal(t, "iva_19", item.TaxCategory)
+	assert.Equal(t, 50000.0, item.TaxBase)
+	assert.Equal(t, 9500.0, item.TaxAmount)
+
+	fixture.Mock.Db.Exec(`UPDATE servu.orders SET status = 'delivered' WHERE order_id = ?`, orderID)
+	code, result := recordPayment(fixture, token, orderID, dto.PaymentRequest{Method: "card", Amount: 50000})
+	assert.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, 59500.0, result.Balance.Total)
+	assert.False(t, result.Balance.Settled)
+	code, result = recordPayment(fixture, token, orderID, dto.PaymentRequest{Method: "cash", Amount: 9500})
+	assert.Equal(t, http.StatusCreated, code)
+	assert.True(t, result.Balance.Settled)
+
+	today := time.Now().UTC().Format("2006-01-02")
+	req, _ = http.NewRequest("GET", "/cash-closings/data?restaurant_id="+seedRestaurantID+"&date="+today, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	var closing dto.CashClosingData
+	json.Unmarshal(response.Body.Bytes(), &closing)
+	assert.Equal(t, 59500.0, closing.TotalSales)
+	assert.Equal(t, 9500.0, closing.TotalTaxes)
+	assert.Equal(t, []dto.TaxLineDTO{{Tax: "IVA", Category: "iva_19", Rate: 19, Base: 50000, Amount: 9500}}, closing.Taxes)
+}
+
+func TestOrderRequestsWithoutBodyAreRejected(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+
+	for _, method := range []string{"POST", "PUT"} {
+		for _, body := range []string{"", "{"} {
+			req, _ := http.NewRequest(method, "/orders", bytes.NewBufferString(body))
+			req.Header.Set("Authorization", "Bearer "+token)
+			response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+			assert.Equal(t, http.StatusBadRequest, response.Code, method+" "+body)
+		}
+	}
+}
+
+func TestAddOrderItemRejectsInvalidBody(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+
+	for _, body := range []string{"", "{", "[]", "{}"} {
+		req, _ := http.NewRequest("POST", "/orders/"+seedOrderID+"/items?restaurant_id="+seedRestaurantID, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+		assert.Equal(t, http.StatusBadRequest, response.Code, body)
+	}
+}
+
+func TestVoidsStayOffSettledOrders(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+	voidPasta := func(orderID string) int {
+		body, _ := json.Marshal(map[string]string{"restaurantId": seedRestaurantID, "observation": "Sin observaciones"})
+		req, _ := http.NewRequest("POST", "/orders/"+orderID+"/items/"+seedPastaID+"/void", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		return fixture.Mock.ExecuteRequest(req, fixture.Router).Code
+	}
+
+	openOrderID := createPastaOrder(t, fixture, token, 2)
+	paidOrderID := createPaidPastaOrder(t, fixture, token)
+	assert.Equal(t, http.StatusConflict, voidPasta(paidOrderID))
+	assert.Equal(t, http.StatusNoContent, voidPasta(openOrderID))
+
+	var voidOrderItemID string
+	fixture.Mock.Db.Raw(`SELECT void_order_item_id FROM servu.void_order_items WHERE restaurant_id = ?`, seedRestaurantID).Scan(&voidOrderItemID)
+	body, _ := json.Marshal(dto.RecoverVoidOrderItemDTO{TargetOrderID: paidOrderID})
+	req, _ := http.NewRequest("POST", "/void-order-items/"+voidOrderItemID+"/recover?restaurant_id="+seedRestaurantID, bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	assert.Equal(t, http.StatusConflict, fixture.Mock.ExecuteRequest(req, fixture.Router).Code)
+
+	var voids int64
+	fixture.Mock.Db.Raw(`SELECT COUNT(*) FROM servu.void_order_items WHERE void_order_item_id = ?`, voidOrderItemID).Scan(&voids)
+	assert.Equal(t, int64(1), voids)
+}
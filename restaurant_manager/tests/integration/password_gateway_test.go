@@ -0,0 +1,103 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var mailedToken = regexp.MustCompile(`token=([A-Za-z0-9_-]+)`)
+
+func mailedTokenFor(t *testing.T, fixture *TestFixture, email string) string {
+	mail, ok := fixture.Mock.Mail.LastMailTo(email)
+	if !ok {
+		t.Fatalf("No mail sent to %s", email)
+	}
+	match := mailedToken.FindStringSubmatch(mail.Body)
+	if match == nil {
+		t.Fatalf("No token in mail to %s", email)
+	}
+	return match[1]
+}
+
+func postJSON(fixture *TestFixture, path string, body interface{}) int {
+	payload, _ := json.Marshal(body)
+	req, _ := http.NewRequest("POST", path, bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	return fixture.Mock.ExecuteRequest(req, fixture.Router).Code
+}
+
+func TestPasswordReset(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	oldToken := login(t, fixture, "alice@admin.com", "admin123")["token"].(string)
+
+	code := postJSON(fixture, "/password/forgot", map[string]string{"email": "alice@admin.com"})
+	assert.Equal(t, http.StatusOK, code)
+	token := mailedTokenFor(t, fixture, "alice@admin.com")
+
+	code = postJSON(fixture, "/password/reset", map[string]string{"token": token, "password": "newsecret1"})
+	assert.Equal(t, http.StatusOK, code)
+
+	t.Run("New password works and old sessions are revoked", func(t *testing.T) {
+		login(t, fixture, "alice@admin.com", "newsecret1")
+		assert.Equal(t, http.StatusUnauthorized, getTables(fixture, oldToken))
+	})
+
+	t.Run("Token is single use", func(t *testing.T) {
+		code := postJSON(fixture, "/password/reset", map[string]string{"token": token, "password": "another1"})
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("Unknown email is not disclosed", func(t *testing.T) {
+		code := postJSON(fixture, "/password/forgot", map[string]string{"email": "nobody@example.com"})
+		assert.Equal(t, http.StatusOK, code)
+		_, sent := fixture.Mock.Mail.LastMailTo("nobody@example.com")
+		assert.False(t, sent)
+	})
+}
+
+func TestPasswordResetTokenExpires(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	postJSON(fixture, "/password/forgot", map[string]string{"email": "alice@admin.com"})
+	token := mailedTokenFor(t, fixture, "alice@admin.com")
+	fixture.Mock.Db.Exec(`UPDATE servu.user_tokens SET expires_at = NOW() - INTERVAL '1 minute'`)
+
+	code := postJSON(fixture, "/password/reset", map[string]string{"token": token, "password": "newsecret1"})
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestEmailVerification(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	code := postJSON(fixture, "/register", map[string]string{
+		"name":     "John Doe",
+		"email":    "john@example.com",
+		"password": "securepass1",
+		"phone":    "1234567890",
+	})
+	assert.Equal(t, http.StatusCreated, code)
+
+	var verified bool
+	fixture.Mock.Db.Raw(`SELECT email_verified_at IS NOT NULL FROM servu.users WHERE email = 'john@example.com'`).Scan(&verified)
+	assert.False(t, verified)
+
+	token := mailedTokenFor(t, fixture, "john@example.com")
+	req, _ := http.NewRequest("GET", "/verify-email?token="+token, nil)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	fixture.Mock.Db.Raw(`SELECT email_verified_at IS NOT NULL FROM servu.users WHERE email = 'john@example.com'`).Scan(&verified)
+	assert.True(t, verified)
+
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
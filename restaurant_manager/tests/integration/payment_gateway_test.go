@@ -0,0 +1,167 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"restaurant_manager/src/application/interfaces/handlers/dto"
+	"restaurant_manager/tests/integration/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func recordPayment(fixture *TestFixture, token string, orderID string, request dto.PaymentRequest) (int, dto.PaymentResultDTO) {
+	body, _ := json.Marshal(request)
+	req, _ := http.NewRequest("POST", "/orders/"+orderID+"/payments?restaurant_id="+seedRestaurantID, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+
+	var result dto.PaymentResultDTO
+	json.Unmarshal(response.Body.Bytes(), &result)
+	return response.Code, result
+}
+
+func TestSplitPaymentsSettleOrder(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+
+	seat1, seat2 := 1, 2
+	body, _ := json.Marshal(dto.OrderDTO{
+		TableID:      seedTableID,
+		RestaurantID: seedRestaurantID,
+		Items: []dto.OrderItemDTO{
+			{MenuItemID: seedPastaID, Quantity: 1, Observation: "Sin observaciones", Seat: &seat1},
+			{MenuItemID: seedPastaID, Quantity: 1, Observation: "Sin observaciones", Seat: &seat2},
+			{MenuItemID: seedPastaID, Quantity: 2, Observation: "Para compartir"},
+		},
+	})
+	req, _ := http.NewRequest("POST", "/orders", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	var created map[string]string
+	json.Unmarshal(response.Body.Bytes(), &created)
+	orderID := created["order_id"]
+
+	req, _ = http.NewRequest("GET", "/orders/"+orderID+"/balance?split=3&restaurant_id="+seedRestaurantID, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	var balance dto.BalanceDTO
+	json.Unmarshal(response.Body.Bytes(), &balance)
+	assert.Equal(t, 100000.0, balance.Total)
+	assert.Equal(t, []float64{33333.34, 33333.33, 33333.33}, balance.Split)
+	assert.Equal(t, []dto.SeatBalanceDTO{{Seat: 1, Outstanding: 25000}, {Seat: 2, Outstanding: 25000}}, balance.Seats)
+
+	code, result := recordPayment(fixture, token, orderID, dto.PaymentRequest{Method: "cash", Seat: &seat1})
+	assert.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, 25000.0, result.Payment.Amount)
+	assert.Equal(t, 75000.0, result.Balance.Outstanding)
+
+	code, _ = recordPayment(fixture, token, orderID, dto.PaymentRequest{Method: "cash", Seat: &seat1})
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = recordPayment(fixture, token, orderID, dto.PaymentRequest{Method: "cheque", Amount: 1000})
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = recordPayment(fixture, token, orderID, dto.PaymentRequest{Method: "card", Amount: 80000})
+	assert.Equal(t, http.StatusConflict, code)
+
+	var sharedItemID string
+	fixture.Mock.Db.Raw(`SELECT order_item_id FROM servu.order_items WHERE order_id = ? AND seat IS NULL`, orderID).Scan(&sharedItemID)
+	code, result = recordPayment(fixture, token, orderID, dto.PaymentRequest{
+		Method: "card",
+		Items:  []dto.PaymentAllocationDTO{{OrderItemID: sharedItemID, Quantity: 1}},
+	})
+	assert.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, 25000.0, result.Payment.Amount)
+	assert.Equal(t, 50000.0, result.Balance.Outstanding)
+
+	// Settling an order that is still in the kitchen leaves it open
+	code, result = recordPayment(fixture, token, orderID, dto.PaymentRequest{Method: "online", Amount: 20000})
+	assert.Equal(t, http.StatusCreated, code)
+	assert.False(t, result.Balance.Settled)
+
+	fixture.Mock.Db.Exec(`UPDATE servu.orders SET status = 'delivered' WHERE order_id = ?`, orderID)
+	code, result = recordPayment(fixture, token, orderID, dto.PaymentRequest{Method: "cash", Amount: 30000})
+	assert.Equal(t, http.StatusCreated, code)
+	assert.True(t, result.Balance.Settled)
+
+	var order struct {
+		Status string
+	}
+	fixture.Mock.Db.Raw(`SELECT status FROM servu.orders WHERE order_id = ?`, orderID).Scan(&order)
+	assert.Equal(t, "paid", order.Status)
+
+	var tableStatus string
+	fixture.Mock.Db.Raw(`SELECT status FROM servu.tables WHERE table_id = ?`, seedTableID).Scan(&tableStatus)
+	assert.Equal(t, "available", tableStatus)
+
+	code, _ = recordPayment(fixture, token, orderID, dto.PaymentRequest{Method: "cash", Amount: 1000})
+	assert.Equal(t, http.StatusConflict, code)
+}
+
+func TestServiceChargeIsTakenAsTip(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+
+	body, _ := json.Marshal(dto.ServiceChargeRuleDTO{Name: "Propina voluntaria", Percentage: 10})
+	req, _ := http.NewRequest("POST", "/restaurants/"+seedRestaurantID+"/service-charge-rules", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusCreated, response.Code)
+
+	body, _ = json.Marshal(dto.OrderDTO{
+		TableID:      seedTableID,
+		RestaurantID: seedRestaurantID,
+		Items: []dto.OrderItemDTO{
+			{MenuItemID: seedPastaID, Quantity: 2, Observation: "Sin observaciones"},
+		},
+	})
+	req, _ = http.NewRequest("POST", "/orders", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	var created map[string]string
+	json.Unmarshal(response.Body.Bytes(), &created)
+	orderID := created["order_id"]
+
+	req, _ = http.NewRequest("GET", "/orders/"+orderID+"/balance?restaurant_id="+seedRestaurantID, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	var balance dto.BalanceDTO
+	json.Unmarshal(response.Body.Bytes(), &balance)
+	if assert.NotNil(t, balance.ServiceCharge) {
+		assert.Equal(t, 5000.0, balance.ServiceCharge.Suggested)
+	}
+
+	code, result := recordPayment(fixture, token, orderID, dto.PaymentRequest{Method: "cash", Amount: 50000, ServiceCharge: true})
+	assert.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, 5000.0, result.Payment.Tip)
+	assert.Equal(t, 0.0, result.Balance.Outstanding)
+	assert.Equal(t, 5000.0, result.Balance.Tips)
+
+	req, _ = http.NewRequest("GET", "/restaurants/"+seedRestaurantID+"/tips", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	var shares []dto.TipShareDTO
+	json.Unmarshal(response.Body.Bytes(), &shares)
+	if assert.Len(t, shares, 1) {
+		assert.Equal(t, 5000.0, shares[0].Tips)
+		assert.Equal(t, 1, shares[0].Payments)
+	}
+
+	today := time.Now().UTC().Format("2006-01-02")
+	req, _ = http.NewRequest("GET", "/cash-closings/data?restaurant_id="+seedRestaurantID+"&date="+today, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	var closing dto.CashClosingData
+	json.Unmarshal(response.Body.Bytes(), &closing)
+	assert.Equal(t, 5000.0, closing.TotalTips)
+}
@@ -0,0 +1,129 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"restaurant_manager/tests/integration/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const seedWaiterID = "22222222-2222-2222-2222-222222222222"
+
+func setWaiterPin(t *testing.T, fixture *TestFixture, pin string) int {
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+	body, _ := json.Marshal(map[string]string{"pin": pin})
+	req, _ := http.NewRequest("PUT", "/users/"+seedWaiterID+"/pin", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	return fixture.Mock.ExecuteRequest(req, fixture.Router).Code
+}
+
+func pinLogin(fixture *TestFixture, restaurantID string, pin string) (int, map[string]interface{}) {
+	body, _ := json.Marshal(map[string]string{"user_id": seedWaiterID, "pin": pin})
+	req, _ := http.NewRequest("POST", "/restaurants/"+restaurantID+"/pin-login", bytes.NewBuffer(body))
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+
+	var resp map[string]interface{}
+	json.Unmarshal(response.Body.Bytes(), &resp)
+	return response.Code, resp
+}
+
+func TestSetPinValidatesFormat(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	for _, pin := range []string{"", "123", "1234567", "12a4"} {
+		assert.Equal(t, http.StatusBadRequest, setWaiterPin(t, fixture, pin), pin)
+	}
+	assert.Equal(t, http.StatusOK, setWaiterPin(t, fixture, "4821"))
+
+	var pinHash string
+	fixture.Mock.Db.Raw(`SELECT pin_hash FROM servu.users WHERE user_id = ?`, seedWaiterID).Scan(&pinHash)
+	assert.NotEqual(t, "4821", pinHash)
+}
+
+func TestPinLoginRecordsWaiterOnOrders(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	assert.Equal(t, http.StatusOK, setWaiterPin(t, fixture, "4821"))
+
+	code, _ := pinLogin(fixture, "cccccccc-cccc-cccc-cccc-cccccccccccc", "4821")
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	code, resp := pinLogin(fixture, seedRestaurantID, "4821")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, float64(10*60), resp["expires_in"])
+	assert.Equal(t, seedRestaurantID, resp["restaurant_id"])
+	token := resp["token"].(string)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"table_id":      seedTableID,
+		"restaurant_id": seedRestaurantID,
+		"status":        "ordered",
+		"items":         []map[string]interface{}{},
+	})
+	req, _ := http.NewRequest("POST", "/orders", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	var created map[string]string
+	json.Unmarshal(response.Body.Bytes(), &created)
+	var waiterID string
+	fixture.Mock.Db.Raw(`SELECT waiter_id FROM servu.orders WHERE order_id = ?`, created["order_id"]).Scan(&waiterID)
+	assert.Equal(t, seedWaiterID, waiterID)
+}
+
+func TestPinLoginLocksAfterRepeatedFailures(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	assert.Equal(t, http.StatusOK, setWaiterPin(t, fixture, "4821"))
+
+	for i := 0; i < 5; i++ {
+		code, _ := pinLogin(fixture, seedRestaurantID, "0000")
+		assert.Equal(t, http.StatusUnauthorized, code)
+	}
+
+	code, _ := pinLogin(fixture, seedRestaurantID, "4821")
+	assert.Equal(t, http.StatusTooManyRequests, code)
+
+	// Setting a new PIN lifts the lock
+	assert.Equal(t, http.StatusOK, setWaiterPin(t, fixture, "4821"))
+	code, _ = pinLogin(fixture, seedRestaurantID, "4821")
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestRevokingUserEndsPinSessions(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	assert.Equal(t, http.StatusOK, setWaiterPin(t, fixture, "4821"))
+	code, resp := pinLogin(fixture, seedRestaurantID, "4821")
+	assert.Equal(t, http.StatusOK, code)
+	pinToken := resp["token"].(string)
+
+	listOrders := func() int {
+		req, _ := http.NewRequest("GET", "/orders?restaurant_id="+seedRestaurantID+"&status=ordered", nil)
+		req.Header.Set("Authorization", "Bearer "+pinToken)
+		return fixture.Mock.ExecuteRequest(req, fixture.Router).Code
+	}
+	assert.Equal(t, http.StatusOK, listOrders())
+
+	// Changing the waiter's password revokes every session they have open
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+	body, _ := json.Marshal(map[string]string{
+		"name":     "Roberto Mesero",
+		"email":    "bob@waiter.com",
+		"phone":    "555-0002",
+		"password": "newsecret1",
+	})
+	req, _ := http.NewRequest("PUT", "/users?id="+seedWaiterID, bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	assert.Equal(t, http.StatusOK, fixture.Mock.ExecuteRequest(req, fixture.Router).Code)
+
+	assert.Equal(t, http.StatusUnauthorized, listOrders())
+}
@@ -0,0 +1,115 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"restaurant_manager/src/application/interfaces/handlers/dto"
+	"restaurant_manager/tests/integration/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createPromotion(t *testing.T, fixture *TestFixture, token string, promotion dto.PromotionDTO) string {
+	body, _ := json.Marshal(promotion)
+	req, _ := http.NewRequest("POST", "/restaurants/"+seedRestaurantID+"/promotions", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusCreated, response.Code)
+	var created map[string]string
+	json.Unmarshal(response.Body.Bytes(), &created)
+	return created["promotion_id"]
+}
+
+func createPastaOrder(t *testing.T, fixture *TestFixture, token string, quantity int) string {
+	body, _ := json.Marshal(dto.OrderDTO{
+		TableID:      seedTableID,
+		RestaurantID: seedRestaurantID,
+		Items:        []dto.OrderItemDTO{{MenuItemID: seedPastaID, Quantity: quantity, Observation: "Sin observaciones"}},
+	})
+	req, _ := http.NewRequest("POST", "/orders", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	var created map[string]string
+	json.Unmarshal(response.Body.Bytes(), &created)
+	return created["order_id"]
+}
+
+func redeemCoupon(fixture *TestFixture, token string, orderID string, code string) (int, []dto.OrderDiscountDTO) {
+	body, _ := json.Marshal(dto.CouponRequest{Code: code})
+	req, _ := http.NewRequest("POST", "/orders/"+orderID+"/coupons?restaurant_id="+seedRestaurantID, bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	var discounts []dto.OrderDiscountDTO
+	json.Unmarshal(response.Body.Bytes(), &discounts)
+	return response.Code, discounts
+}
+
+func TestPromotionsAndCouponsDiscountOrders(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+
+	buy, free := 2, 1
+	pasta := seedPastaID
+	createPromotion(t, fixture, token, dto.PromotionDTO{
+		Name: "2x1 en pasta", Kind: "buy_x_get_y", Scope: "item", MenuItemID: &pasta, BuyQuantity: &buy, FreeQuantity: &free,
+	})
+	coupon, limit := "bienvenida", 1
+	createPromotion(t, fixture, token, dto.PromotionDTO{
+		Name: "Bienvenida", Kind: "percentage", Scope: "order", Value: 10, CouponCode: &coupon, UsageLimit: &limit,
+	})
+
+	// A happy hour that is not running does not apply
+	later := time.Now().Add(2 * time.Hour).UTC()
+	start, end := later.Format("15:04"), later.Add(time.Hour).Format("15:04")
+	category := "Main"
+	createPromotion(t, fixture, token, dto.PromotionDTO{
+		Name: "Hora feliz", Kind: "percentage", Scope: "category", Value: 50, Category: &category, HappyHourStart: &start, HappyHourEnd: &end,
+	})
+
+	invalid, _ := json.Marshal(dto.PromotionDTO{Name: "Sin valor", Kind: "percentage", Scope: "order"})
+	req, _ := http.NewRequest("POST", "/restaurants/"+seedRestaurantID+"/promotions", bytes.NewBuffer(invalid))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+
+	orderID := createPastaOrder(t, fixture, token, 2)
+
+	req, _ = http.NewRequest("GET", "/orders/"+orderID+"/balance?restaurant_id="+seedRestaurantID, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	var balance dto.BalanceDTO
+	json.Unmarshal(response.Body.Bytes(), &balance)
+	assert.Equal(t, 25000.0, balance.Total)
+	if assert.Len(t, balance.Items, 1) {
+		assert.Equal(t, 25000.0, balance.Items[0].Discount)
+		assert.Equal(t, 25000.0, balance.Items[0].Outstanding)
+	}
+
+	code, discounts := redeemCoupon(fixture, token, orderID, "BIENVENIDA")
+	assert.Equal(t, http.StatusOK, code)
+	amounts := make([]float64, len(discounts))
+	for i, discount := range discounts {
+		amounts[i] = discount.Amount
+	}
+	assert.ElementsMatch(t, []float64{25000, 2500}, amounts)
+	code, _ = redeemCoupon(fixture, token, orderID, "bienvenida")
+	assert.Equal(t, http.StatusConflict, code)
+	code, _ = redeemCoupon(fixture, token, orderID, "NOEXISTE")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	otherOrderID := createPastaOrder(t, fixture, token, 1)
+	code, _ = redeemCoupon(fixture, token, otherOrderID, "bienvenida")
+	assert.Equal(t, http.StatusConflict, code)
+
+	code, _ = recordPayment(fixture, token, orderID, dto.PaymentRequest{Method: "cash", Amount: 25000})
+	assert.Equal(t, http.StatusConflict, code)
+	code, result := recordPayment(fixture, token, orderID, dto.PaymentRequest{Method: "cash", Amount: 22500})
+	assert.Equal(t, http.StatusCreated, code)
+	assert.True(t, result.Balance.Settled)
+}
@@ -0,0 +1,119 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"restaurant_manager/src/application/interfaces/handlers/dto"
+	"restaurant_manager/tests/integration/utils"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func printKitchenTickets(fixture *TestFixture, token string, orderID string, format string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", "/orders/"+orderID+"/kitchen-tickets?format="+format+"&restaurant_id="+seedRestaurantID, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return fixture.Mock.ExecuteRequest(req, fixture.Router)
+}
+
+func TestReceiptsListItemsTaxesAndPayments(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+
+	body, _ := json.Marshal(map[string]any{"receipt_header": "Calle 10 # 5-20", "receipt_footer": "¡Gracias por su visita!", "paper_width": 58})
+	req, _ := http.NewRequest("PUT", "/restaurants/"+seedRestaurantID, bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	orderID := createPastaOrder(t, fixture, token, 2)
+	fixture.Mock.Db.Exec(`UPDATE servu.orders SET status = 'delivered' WHERE order_id = ?`, orderID)
+	code, _ := recordPayment(fixture, token, orderID, dto.PaymentRequest{Method: "cash", Amount: 20000, Tip: 2000})
+	assert.Equal(t, http.StatusCreated, code)
+
+	req, _ = http.NewRequest("GET", "/orders/"+orderID+"/receipt?restaurant_id="+seedRestaurantID, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "text/plain; charset=utf-8", response.Header().Get("Content-Type"))
+	receipt := response.Body.String()
+	for _, line := range strings.Split(strings.TrimRight(receipt, "\n"), "\n") {
+		assert.LessOrEqual(t, len([]rune(line)), 32, line)
+	}
+	assert.Contains(t, receipt, "Calle 10 # 5-20")
+	assert.Contains(t, receipt, "$50.000,00")
+	assert.Contains(t, receipt, "INC 8% incluido")
+	assert.Contains(t, receipt, "$3.703,70")
+	assert.Contains(t, receipt, "Propina")
+	assert.Contains(t, receipt, "Efectivo")
+	assert.Contains(t, receipt, "$30.000,00")
+	assert.Contains(t, receipt, "¡Gracias por su visita!")
+
+	req, _ = http.NewRequest("GET", "/orders/"+orderID+"/receipt?format=pdf&restaurant_id="+seedRestaurantID, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "application/pdf", response.Header().Get("Content-Type"))
+	assert.True(t, bytes.HasPrefix(response.Body.Bytes(), []byte("%PDF-")))
+
+	req, _ = http.NewRequest("GET", "/orders/"+orderID+"/receipt?format=escpos&restaurant_id="+seedRestaurantID, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.True(t, bytes.HasPrefix(response.Body.Bytes(), []byte{0x1B, '@'}))
+	assert.True(t, bytes.HasSuffix(response.Body.Bytes(), []byte{0x1D, 'V', 0x42, 0x00}))
+
+	req, _ = http.NewRequest("GET", "/orders/"+orderID+"/receipt?format=docx&restaurant_id="+seedRestaurantID, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+
+	body, _ = json.Marshal(map[string]any{"paper_width": 70})
+	req, _ = http.NewRequest("PUT", "/restaurants/"+seedRestaurantID, bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
+
+func TestKitchenTicketsOnlyCarryNewItems(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+
+	body, _ := json.Marshal(dto.StationRequest{RestaurantID: seedRestaurantID, Name: "Parrilla", Kind: "grill"})
+	req, _ := http.NewRequest("POST", "/stations", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusCreated, response.Code)
+
+	orderID := createPastaOrder(t, fixture, token, 2)
+	response = printKitchenTickets(fixture, token, orderID, "text")
+	assert.Equal(t, http.StatusOK, response.Code)
+	ticket := response.Body.String()
+	assert.Contains(t, ticket, "PARRILLA")
+	assert.Contains(t, ticket, "2x ")
+	assert.Contains(t, ticket, "Nota: Sin observaciones")
+
+	// Everything was sent already
+	response = printKitchenTickets(fixture, token, orderID, "text")
+	assert.Equal(t, http.StatusNoContent, response.Code)
+
+	// Adding to the item only sends the added unit
+	body, _ = json.Marshal([]dto.OrderItemDTO{{MenuItemID: seedPastaID, Quantity: 1, Observation: "Sin observaciones"}})
+	req, _ = http.NewRequest("POST", "/orders/"+orderID+"/items?restaurant_id="+seedRestaurantID, bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	response = printKitchenTickets(fixture, token, orderID, "escpos")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "application/octet-stream", response.Header().Get("Content-Type"))
+	assert.True(t, bytes.Contains(response.Body.Bytes(), []byte("1x ")))
+	assert.False(t, bytes.Contains(response.Body.Bytes(), []byte("3x ")))
+}
@@ -0,0 +1,199 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"restaurant_manager/src/application/interfaces/handlers/dto"
+	"restaurant_manager/tests/integration/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func reservationRequest(fixture *TestFixture, token string, method string, path string, request any) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(request)
+	req, _ := http.NewRequest(method, "/restaurants/"+seedRestaurantID+path, bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	return fixture.Mock.ExecuteRequest(req, fixture.Router)
+}
+
+func createReservation(t *testing.T, fixture *TestFixture, token string, request dto.ReservationRequest) string {
+	response := reservationRequest(fixture, token, "POST", "/reservations", request)
+	assert.Equal(t, http.StatusCreated, response.Code, response.Body.String())
+	var created map[string]string
+	json.Unmarshal(response.Body.Bytes(), &created)
+	return created["reservation_id"]
+}
+
+func TestReservationsHoldTablesAndDetectConflicts(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+	patio := createTable(t, fixture, 51)
+	bar := createTable(t, fixture, 52)
+	soon := time.Now().UTC().Add(20 * time.Minute).Truncate(time.Minute)
+
+	// A booking starting within the hold window reserves its table right away
+	dinner := createReservation(t, fixture, token, dto.ReservationRequest{
+		GuestName: "Camila Rojas",
+		Phone:     "3001234567",
+		PartySize: 4,
+		StartsAt:  soon,
+		TableIDs:  []string{patio},
+	})
+	assert.Equal(t, "reserved", tableStatus(fixture, patio))
+
+	response := reservationRequest(fixture, token, "POST", "/reservations", dto.ReservationRequest{
+		GuestName: "Andrés Gómez",
+		PartySize: 6,
+		StartsAt:  soon.Add(time.Hour),
+		TableIDs:  []string{bar, patio},
+	})
+	assert.Equal(t, http.StatusConflict, response.Code)
+	assert.Equal(t, "available", tableStatus(fixture, bar))
+
+	// The patio is free again once the first party's 90 minutes are up
+	late := createReservation(t, fixture, token, dto.ReservationRequest{
+		GuestName: "Andrés Gómez",
+		PartySize: 6,
+		StartsAt:  soon.Add(90 * time.Minute),
+		TableIDs:  []string{patio},
+	})
+
+	response = reservationRequest(fixture, token, "POST", "/reservations", dto.ReservationRequest{
+		GuestName: "Lucía Pérez",
+		PartySize: 2,
+		StartsAt:  time.Now().Add(-2 * time.Hour),
+	})
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+
+	response = reservationRequest(fixture, token, "PUT", "/reservations/"+late, dto.ReservationRequest{
+		GuestName:       "Andrés Gómez",
+		PartySize:       8,
+		StartsAt:        soon.Add(30 * time.Minute),
+		DurationMinutes: 120,
+		TableIDs:        []string{patio},
+	})
+	assert.Equal(t, http.StatusConflict, response.Code)
+	response = reservationRequest(fixture, token, "PUT", "/reservations/"+late, dto.ReservationRequest{
+		GuestName:       "Andrés Gómez",
+		PartySize:       8,
+		StartsAt:        soon,
+		DurationMinutes: 120,
+		TableIDs:        []string{bar},
+	})
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	assert.Equal(t, "reserved", tableStatus(fixture, bar))
+
+	bogota, _ := time.LoadLocation("America/Bogota")
+	req, _ := http.NewRequest("GET", "/restaurants/"+seedRestaurantID+"/reservations?date="+soon.In(bogota).Format("2006-01-02"), nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	var reservations []dto.ReservationDTO
+	json.Unmarshal(response.Body.Bytes(), &reservations)
+	assert.NotEmpty(t, reservations)
+	assert.Equal(t, dinner, reservations[0].ReservationID)
+	assert.Equal(t, 51, reservations[0].Tables[0].TableNumber)
+	assert.True(t, reservations[0].EndsAt.Equal(soon.Add(90*time.Minute)))
+
+	// Seating the party occupies its table; it can only be seated once
+	response = reservationRequest(fixture, token, "PUT", "/reservations/"+dinner+"/status", dto.ReservationStatusRequest{Status: "seated"})
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "occupied", tableStatus(fixture, patio))
+	response = reservationRequest(fixture, token, "PUT", "/reservations/"+dinner+"/status", dto.ReservationStatusRequest{Status: "cancelled"})
+	assert.Equal(t, http.StatusConflict, response.Code)
+
+	// Cancelling gives the held table back to walk-ins
+	response = reservationRequest(fixture, token, "PUT", "/reservations/"+late+"/status", dto.ReservationStatusRequest{Status: "cancelled"})
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "available", tableStatus(fixture, bar))
+	response = reservationRequest(fixture, token, "PUT", "/reservations/"+late, dto.ReservationRequest{
+		GuestName: "Andrés Gómez",
+		PartySize: 8,
+		StartsAt:  soon,
+	})
+	assert.Equal(t, http.StatusConflict, response.Code)
+
+	// Parties that do not turn up within the grace period become no-shows
+	missed := createReservation(t, fixture, token, dto.ReservationRequest{
+		GuestName: "Mateo Díaz",
+		PartySize: 2,
+		StartsAt:  time.Now().UTC(),
+		TableIDs:  []string{bar},
+	})
+	assert.Equal(t, "reserved", tableStatus(fixture, bar))
+	fixture.Mock.Db.Exec(`UPDATE servu.reservations SET starts_at = starts_at - INTERVAL '20 minutes' WHERE reservation_id = ?`, missed)
+	reservationRequest(fixture, token, "GET", "/reservations", nil)
+	var status string
+	fixture.Mock.Db.Raw(`SELECT status FROM servu.reservations WHERE reservation_id = ?`, missed).Scan(&status)
+	assert.Equal(t, "no_show", status)
+	assert.Equal(t, "available", tableStatus(fixture, bar))
+
+	// A no-show arriving late cannot take a table held for the next booking
+	createReservation(t, fixture, token, dto.ReservationRequest{
+		GuestName: "Sara Molina",
+		PartySize: 2,
+		StartsAt:  soon,
+		TableIDs:  []string{bar},
+	})
+	assert.Equal(t, "reserved", tableStatus(fixture, bar))
+	response = reservationRequest(fixture, token, "PUT", "/reservations/"+missed+"/status", dto.ReservationStatusRequest{Status: "seated"})
+	assert.Equal(t, http.StatusConflict, response.Code)
+	assert.Equal(t, "reserved", tableStatus(fixture, bar))
+}
+
+func TestWalkInsWaitInLineWithQuotedWaits(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+
+	// The first seed table is free, so the first party is seated right away
+	var first dto.WaitlistEntryDTO
+	response := reservationRequest(fixture, token, "POST", "/waitlist", dto.WaitlistRequest{GuestName: "Valentina", PartySize: 2})
+	assert.Equal(t, http.StatusCreated, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &first)
+	assert.Equal(t, 1, first.Position)
+	assert.Equal(t, 0, first.QuotedWaitMinutes)
+
+	// The next one waits for the occupied table to turn
+	var second dto.WaitlistEntryDTO
+	response = reservationRequest(fixture, token, "POST", "/waitlist", dto.WaitlistRequest{GuestName: "Santiago", Phone: "3109876543", PartySize: 4})
+	assert.Equal(t, http.StatusCreated, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &second)
+	assert.Equal(t, 2, second.Position)
+	assert.Greater(t, second.QuotedWaitMinutes, 0)
+	assert.Zero(t, second.QuotedWaitMinutes%5)
+
+	quoted := 15
+	response = reservationRequest(fixture, token, "POST", "/waitlist", dto.WaitlistRequest{GuestName: "Isabella", PartySize: 3, QuotedWaitMinutes: &quoted})
+	assert.Equal(t, http.StatusCreated, response.Code)
+	response = reservationRequest(fixture, token, "POST", "/waitlist", dto.WaitlistRequest{GuestName: "Sin grupo"})
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+
+	response = reservationRequest(fixture, token, "PUT", "/waitlist/"+first.EntryID+"/status", dto.WaitlistStatusRequest{Status: "seated", TableID: seedTableID})
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "occupied", tableStatus(fixture, seedTableID))
+	response = reservationRequest(fixture, token, "PUT", "/waitlist/"+second.EntryID+"/status", dto.WaitlistStatusRequest{Status: "seated", TableID: seedTableID})
+	assert.Equal(t, http.StatusConflict, response.Code)
+	response = reservationRequest(fixture, token, "PUT", "/waitlist/"+second.EntryID+"/status", dto.WaitlistStatusRequest{Status: "left"})
+	assert.Equal(t, http.StatusOK, response.Code)
+	response = reservationRequest(fixture, token, "PUT", "/waitlist/"+second.EntryID+"/status", dto.WaitlistStatusRequest{Status: "seated"})
+	assert.Equal(t, http.StatusConflict, response.Code)
+
+	req, _ := http.NewRequest("GET", "/restaurants/"+seedRestaurantID+"/waitlist", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	var waitlist []dto.WaitlistEntryDTO
+	json.Unmarshal(response.Body.Bytes(), &waitlist)
+	assert.Len(t, waitlist, 1)
+	assert.Equal(t, "Isabella", waitlist[0].GuestName)
+	assert.Equal(t, 1, waitlist[0].Position)
+	assert.Equal(t, 15, waitlist[0].QuotedWaitMinutes)
+}
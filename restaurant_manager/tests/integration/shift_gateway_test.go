@@ -0,0 +1,79 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"restaurant_manager/tests/integration/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClockInAndOut(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "bob@waiter.com", "waiter123")
+	clock := func(action string) int {
+		req, _ := http.NewRequest("POST", "/shifts/clock-"+action, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		return fixture.Mock.ExecuteRequest(req, fixture.Router).Code
+	}
+
+	assert.Equal(t, http.StatusConflict, clock("out"))
+	assert.Equal(t, http.StatusCreated, clock("in"))
+	assert.Equal(t, http.StatusConflict, clock("in"))
+	assert.Equal(t, http.StatusOK, clock("out"))
+
+	var shifts int
+	fixture.Mock.Db.Raw(`SELECT COUNT(*) FROM servu.shifts WHERE user_id = ? AND clock_out IS NOT NULL`, seedWaiterID).Scan(&shifts)
+	assert.Equal(t, 1, shifts)
+}
+
+func TestCreateShiftRejectsOverlap(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+	createShift := func(clockIn, clockOut string) int {
+		body, _ := json.Marshal(map[string]string{
+			"user_id":       seedWaiterID,
+			"restaurant_id": seedRestaurantID,
+			"clock_in":      clockIn,
+			"clock_out":     clockOut,
+		})
+		req, _ := http.NewRequest("POST", "/shifts", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		return fixture.Mock.ExecuteRequest(req, fixture.Router).Code
+	}
+
+	assert.Equal(t, http.StatusCreated, createShift("2025-03-01T08:00:00Z", "2025-03-01T16:00:00Z"))
+	assert.Equal(t, http.StatusConflict, createShift("2025-03-01T15:00:00Z", "2025-03-01T20:00:00Z"))
+	assert.Equal(t, http.StatusBadRequest, createShift("2025-03-01T20:00:00Z", "2025-03-01T18:00:00Z"))
+	assert.Equal(t, http.StatusCreated, createShift("2025-03-01T16:00:00Z", "2025-03-01T20:00:00Z"))
+}
+
+func TestShiftHoursReport(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	fixture.Mock.Db.Exec(`INSERT INTO servu.shifts (user_id, restaurant_id, clock_in, clock_out) VALUES
+		(?, ?, '2025-03-01 08:00:00', '2025-03-01 16:00:00'),
+		(?, ?, '2025-03-02 22:00:00', '2025-03-03 02:30:00')`,
+		seedWaiterID, seedRestaurantID, seedWaiterID, seedRestaurantID)
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+	req, _ := http.NewRequest("GET", "/shifts/report?restaurant_id="+seedRestaurantID+"&start_date=2025-03-01&end_date=2025-03-02", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	var report []map[string]interface{}
+	json.Unmarshal(response.Body.Bytes(), &report)
+	assert.Len(t, report, 1)
+	assert.Equal(t, seedWaiterID, report[0]["user_id"])
+	assert.Equal(t, float64(2), report[0]["shifts"])
+	// The overnight shift only counts until the end of the range
+	assert.Equal(t, float64(10), report[0]["hours"])
+}
@@ -0,0 +1,81 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"restaurant_manager/src/application/interfaces/handlers/dto"
+	"restaurant_manager/tests/integration/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// getStationQueue returns the items waiting at a station.
+func getStationQueue(t *testing.T, fixture *TestFixture, token string, stationID string) []dto.StationQueueItem {
+	req, _ := http.NewRequest("GET", "/stations/"+stationID+"/queue?restaurant_id="+seedRestaurantID, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	var queue []dto.StationQueueItem
+	json.Unmarshal(response.Body.Bytes(), &queue)
+	return queue
+}
+
+func TestStationQueueBumpAndRecall(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+
+	body, _ := json.Marshal(dto.StationRequest{RestaurantID: seedRestaurantID, Name: "Parrilla", Kind: "grill"})
+	req, _ := http.NewRequest("POST", "/stations", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusCreated, response.Code)
+
+	var created map[string]string
+	json.Unmarshal(response.Body.Bytes(), &created)
+	stationID := created["station_id"]
+	assert.NotEmpty(t, stationID)
+
+	queue := getStationQueue(t, fixture, token, stationID)
+	assert.NotEmpty(t, queue)
+	for _, item := range queue {
+		assert.Equal(t, "pending", item.Status)
+	}
+
+	first := queue[0]
+	body, _ = json.Marshal(dto.StationItemRequest{OrderID: first.OrderID, MenuItemID: first.MenuItemID, Observation: first.Observation})
+	req, _ = http.NewRequest("POST", "/stations/"+stationID+"/bump?restaurant_id="+seedRestaurantID, bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	assert.Len(t, getStationQueue(t, fixture, token, stationID), len(queue)-1)
+
+	req, _ = http.NewRequest("POST", "/stations/"+stationID+"/recall?restaurant_id="+seedRestaurantID, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	var recalled dto.StationQueueItem
+	json.Unmarshal(response.Body.Bytes(), &recalled)
+	assert.Equal(t, first.OrderID, recalled.OrderID)
+	assert.Equal(t, first.Observation, recalled.Observation)
+	assert.Equal(t, "pending", recalled.Status)
+	assert.Len(t, getStationQueue(t, fixture, token, stationID), len(queue))
+}
+
+func TestStationRejectsUnknownKind(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+
+	body, _ := json.Marshal(dto.StationRequest{RestaurantID: seedRestaurantID, Name: "Horno", Kind: "oven"})
+	req, _ := http.NewRequest("POST", "/stations", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
@@ -86,7 +86,7 @@ func TestDeleteTable(t *testing.T) {
 		log.Err(result.Error)
 	}
 
-	constStr := fmt.Sprintf("/tables/%s", tableID)
+	constStr := fmt.Sprintf("/tables/%s?restaurant_id=%s", tableID, restaurantID)
 
 	req, _ := http.NewRequest("DELETE", constStr, nil)
 	req.Header.Set("Content-Type", "application/json")
@@ -126,7 +126,7 @@ func TestGetTable(t *testing.T) {
 		log.Err(result.Error)
 	}
 
-	constStr := fmt.Sprintf("/tables/%s", tableID)
+	constStr := fmt.Sprintf("/tables/%s?restaurant_id=%s", tableID, restaurantID)
 
 	req, _ := http.NewRequest("GET", constStr, nil)
 	req.Header.Set("Content-Type", "application/json")
@@ -0,0 +1,179 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"restaurant_manager/src/application/interfaces/handlers/dto"
+	"restaurant_manager/tests/integration/utils"
+	"testing"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	seedRestaurantID = "aaaaaaa1-aaaa-aaaa-aaaa-aaaaaaaaaaa1"
+	seedOrderID      = "fffffff1-ffff-ffff-ffff-fffffffffff1"
+	seedTableID      = "bbbbbbb1-bbbb-bbbb-bbbb-bbbbbbbbbbb1"
+)
+
+// setupOtherTenant creates an admin owning a restaurant other than the seeded one
+// and returns that restaurant's ID together with the admin's token.
+func setupOtherTenant(t *testing.T, fixture *TestFixture) (string, string) {
+	var userID, restaurantID string
+
+	result := fixture.Mock.Db.Raw(`INSERT INTO servu.users (name, email, password_hash, role, phone)
+		VALUES ('John Doe', 'john@example.com', '$2a$10$OadQYtj4KxIpkjOQ/zw62euZ00cLJDUmUGMJ5bdGU2TE1.6GwKsoa', 'admin', '1234567890')
+		RETURNING user_id`).Scan(&userID)
+	if result.Error != nil {
+		log.Err(result.Error)
+	}
+
+	result = fixture.Mock.Db.Raw(`INSERT INTO servu.restaurants (name, owner_id)
+		VALUES ('Other Restaurant', ?)
+		RETURNING restaurant_id`, userID).Scan(&restaurantID)
+	if result.Error != nil {
+		log.Err(result.Error)
+	}
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "john@example.com", "admin123")
+	return restaurantID, token
+}
+
+func TestCrossTenantRestaurantIsForbidden(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	_, token := setupOtherTenant(t, fixture)
+
+	paths := []string{
+		"/orders?restaurant_id=" + seedRestaurantID + "&status=ordered",
+		"/inventory?restaurant_id=" + seedRestaurantID,
+		"/cash-closings/history?restaurant_id=" + seedRestaurantID,
+		"/restaurants/" + seedRestaurantID + "/order-items/void",
+		"/orders/" + seedOrderID + "?restaurant_id=" + seedRestaurantID,
+	}
+	for _, path := range paths {
+		req, _ := http.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+		assert.Equal(t, http.StatusForbidden, response.Code, path)
+	}
+}
+
+func TestAdminRestaurantClaimRequiresOwnership(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	result := fixture.Mock.Db.Exec(`INSERT INTO servu.users (name, email, password_hash, role, phone, restaurant_id)
+		VALUES ('Mallory', 'mallory@example.com', '$2a$10$OadQYtj4KxIpkjOQ/zw62euZ00cLJDUmUGMJ5bdGU2TE1.6GwKsoa', 'admin', '1234567890', ?)`, seedRestaurantID)
+	assert.NoError(t, result.Error)
+	token := utils.LoginAndGetToken(t, fixture.Router, "mallory@example.com", "admin123")
+
+	req, _ := http.NewRequest("GET", "/inventory", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusForbidden, response.Code)
+}
+
+func TestCrossTenantEntityIsNotFound(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	restaurantID, token := setupOtherTenant(t, fixture)
+
+	req, _ := http.NewRequest("GET", "/orders/"+seedOrderID+"?restaurant_id="+restaurantID, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	req, _ = http.NewRequest("GET", "/tables/"+seedTableID+"?restaurant_id="+restaurantID, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	var status string
+	fixture.Mock.Db.Raw(`SELECT status FROM servu.orders WHERE order_id = ?`, seedOrderID).Scan(&status)
+	assert.Equal(t, "ordered", status)
+}
+
+func TestWaiterCannotActOnAnotherRestaurant(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	restaurantID, _ := setupOtherTenant(t, fixture)
+	token := utils.LoginAndGetToken(t, fixture.Router, "bob@waiter.com", "waiter123")
+
+	req, _ := http.NewRequest("GET", "/tables?restaurant_id="+restaurantID, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusForbidden, response.Code)
+
+	req, _ = http.NewRequest("GET", "/tables?restaurant_id="+seedRestaurantID, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+}
+
+// createCustomer adds a customer with the password admin123 and returns their token.
+func createCustomer(t *testing.T, fixture *TestFixture, name string, email string) string {
+	result := fixture.Mock.Db.Exec(`INSERT INTO servu.users (name, email, password_hash, role, phone)
+		VALUES (?, ?, '$2a$10$OadQYtj4KxIpkjOQ/zw62euZ00cLJDUmUGMJ5bdGU2TE1.6GwKsoa', 'customer', '3000000000')`, name, email)
+	assert.NoError(t, result.Error)
+	return utils.LoginAndGetToken(t, fixture.Router, email, "admin123")
+}
+
+func TestCustomerCannotReachAnotherCustomersOrder(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	restaurantID, _ := setupOtherTenant(t, fixture)
+	var tableID string
+	fixture.Mock.Db.Raw(`INSERT INTO servu.tables (restaurant_id, table_number, qr_code)
+		VALUES (?, 7, 'QR_CODE')
+		RETURNING table_id`, restaurantID).Scan(&tableID)
+
+	ana := createCustomer(t, fixture, "Ana", "ana@customer.com")
+	beto := createCustomer(t, fixture, "Beto", "beto@customer.com")
+
+	body, _ := json.Marshal(dto.OrderDTO{TableID: tableID, RestaurantID: restaurantID})
+	req, _ := http.NewRequest("POST", "/orders", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+beto)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	var created map[string]string
+	json.Unmarshal(response.Body.Bytes(), &created)
+	orderID := created["order_id"]
+	scope := "?restaurant_id=" + restaurantID
+
+	req, _ = http.NewRequest("GET", "/orders/"+orderID+scope, nil)
+	req.Header.Set("Authorization", "Bearer "+beto)
+	response = fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	requests := []struct {
+		method string
+		path   string
+		body   string
+		code   int
+	}{
+		{"GET", "/orders/" + orderID + scope, "", http.StatusNotFound},
+		{"GET", "/orders/" + orderID + "/items" + scope, "", http.StatusNotFound},
+		{"POST", "/orders/" + orderID + "/items" + scope, `[{"menu_item_id": "25000", "quantity": 1}]`, http.StatusNotFound},
+		{"GET", "/orders/" + orderID + "/receipt" + scope, "", http.StatusNotFound},
+		{"GET", "/orders/" + orderID + "/invoice" + scope, "", http.StatusNotFound},
+		{"POST", "/orders/" + orderID + "/invoice" + scope, "", http.StatusNotFound},
+		{"GET", "/tables/" + tableID + scope, "", http.StatusForbidden},
+	}
+	for _, request := range requests {
+		req, _ := http.NewRequest(request.method, request.path, bytes.NewBufferString(request.body))
+		req.Header.Set("Authorization", "Bearer "+ana)
+		response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+		assert.Equal(t, request.code, response.Code, request.method+" "+request.path)
+	}
+
+	var items int64
+	fixture.Mock.Db.Raw(`SELECT COUNT(*) FROM servu.order_items WHERE order_id = ?`, orderID).Scan(&items)
+	assert.Zero(t, items)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"net/http"
+	"restaurant_manager/tests/integration/utils"
 	"testing"
 
 	_ "github.com/lib/pq"
@@ -18,7 +19,7 @@ func TestRegistryUser(t *testing.T) {
 	userData := map[string]string{
 		"name":         "John Doe",
 		"email":        "john@example.com",
-		"password":     "securepass",
+		"password":     "securepass1",
 		"role":         "admin",
 		"id_number":    "1234567890",
 		"phone":        "1234567890",
@@ -75,3 +76,139 @@ func TestLoginUser(t *testing.T) {
 	// Check if login message is correct
 	assert.NotEmpty(t, responseBody["token"])
 }
+
+func TestRegisterUserHashesPassword(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	userData := map[string]string{
+		"name":     "John Doe",
+		"email":    "john@example.com",
+		"password": "securepass1",
+		"phone":    "1234567890",
+	}
+	userJSON, _ := json.Marshal(userData)
+	req, _ := http.NewRequest("POST", "/register", bytes.NewBuffer(userJSON))
+	req.Header.Set("Content-Type", "application/json")
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusCreated, response.Code)
+
+	var passwordHash string
+	fixture.Mock.Db.Raw(`SELECT password_hash FROM servu.users WHERE email = 'john@example.com'`).Scan(&passwordHash)
+	assert.NotEqual(t, "securepass1", passwordHash)
+	assert.Equal(t, http.StatusOK, postJSON(fixture, "/login", map[string]string{"email": "john@example.com", "password": "securepass1"}))
+}
+
+func TestRegisterUserRejectsWeakPassword(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	for _, password := range []string{"", "short1", "onlyletters", "1234567890"} {
+		code := postJSON(fixture, "/register", map[string]string{
+			"name":     "John Doe",
+			"email":    "john@example.com",
+			"password": password,
+			"phone":    "1234567890",
+		})
+		assert.Equal(t, http.StatusBadRequest, code, password)
+	}
+}
+
+func TestUpdateUserRehashesPassword(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+	update := func(password string) int {
+		userJSON, _ := json.Marshal(map[string]string{
+			"name":     "Alice",
+			"email":    "alice@admin.com",
+			"phone":    "555-0001",
+			"password": password,
+		})
+		req, _ := http.NewRequest("PUT", "/users?id=11111111-1111-1111-1111-111111111111", bytes.NewBuffer(userJSON))
+		req.Header.Set("Authorization", "Bearer "+token)
+		return fixture.Mock.ExecuteRequest(req, fixture.Router).Code
+	}
+
+	assert.Equal(t, http.StatusBadRequest, update("weak"))
+	assert.Equal(t, http.StatusOK, update("newsecret1"))
+
+	var passwordHash string
+	fixture.Mock.Db.Raw(`SELECT password_hash FROM servu.users WHERE email = 'alice@admin.com'`).Scan(&passwordHash)
+	assert.NotEqual(t, "newsecret1", passwordHash)
+	utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "newsecret1")
+}
+
+func TestUsersResponseOmitsPassword(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+	req, _ := http.NewRequest("GET", "/users?restaurantId=aaaaaaa1-aaaa-aaaa-aaaa-aaaaaaaaaaa1&role=waiter", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	response := fixture.Mock.ExecuteRequest(req, fixture.Router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.NotContains(t, response.Body.String(), "password")
+	assert.NotContains(t, response.Body.String(), "$2a$")
+}
+
+func TestRegisteredUserCannotClaimAnotherRestaurant(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	code := postJSON(fixture, "/register", map[string]string{
+		"name":          "Mallory",
+		"email":         "mallory@example.com",
+		"password":      "securepass1",
+		"phone":         "1234567890",
+		"restaurant_id": seedRestaurantID,
+	})
+	assert.Equal(t, http.StatusCreated, code)
+
+	var restaurantID *string
+	fixture.Mock.Db.Raw(`SELECT restaurant_id FROM servu.users WHERE email = 'mallory@example.com'`).Scan(&restaurantID)
+	assert.Nil(t, restaurantID)
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "mallory@example.com", "securepass1")
+	req, _ := http.NewRequest("GET", "/inventory?restaurant_id="+seedRestaurantID, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	assert.Equal(t, http.StatusForbidden, fixture.Mock.ExecuteRequest(req, fixture.Router).Code)
+}
+
+func TestAdminCreatesStaffForOwnRestaurant(t *testing.T) {
+	fixture := NewTestFixture(t)
+	defer fixture.TearDown()
+
+	createStaff := func(token string, user map[string]string) int {
+		userJSON, _ := json.Marshal(user)
+		req, _ := http.NewRequest("POST", "/users", bytes.NewBuffer(userJSON))
+		req.Header.Set("Authorization", "Bearer "+token)
+		return fixture.Mock.ExecuteRequest(req, fixture.Router).Code
+	}
+	waiter := map[string]string{
+		"name":          "Carol",
+		"email":         "carol@waiter.com",
+		"password":      "securepass1",
+		"phone":         "1234567890",
+		"role":          "waiter",
+		"restaurant_id": seedRestaurantID,
+	}
+
+	_, otherToken := setupOtherTenant(t, fixture)
+	assert.Equal(t, http.StatusForbidden, createStaff(otherToken, waiter))
+
+	token := utils.LoginAndGetToken(t, fixture.Router, "alice@admin.com", "admin123")
+	waiter["role"] = "admin"
+	assert.Equal(t, http.StatusBadRequest, createStaff(token, waiter))
+	waiter["role"] = "waiter"
+	assert.Equal(t, http.StatusCreated, createStaff(token, waiter))
+
+	var created struct {
+		Role         string
+		RestaurantID string
+	}
+	fixture.Mock.Db.Raw(`SELECT role, restaurant_id FROM servu.users WHERE email = 'carol@waiter.com'`).Scan(&created)
+	assert.Equal(t, "waiter", created.Role)
+	assert.Equal(t, seedRestaurantID, created.RestaurantID)
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	appports "restaurant_manager/src/application/infrastructure/ports"
 	"restaurant_manager/src/application/infrastructure/repositories"
 	"restaurant_manager/src/application/interfaces/handlers"
 	"restaurant_manager/src/application/interfaces/routes"
@@ -21,8 +22,9 @@ import (
 )
 
 type MockImpl struct {
-	Db  *gorm.DB
-	Cfg *config.Properties
+	Db   *gorm.DB
+	Cfg  *config.Properties
+	Mail *infraports.InMemoryMailSender
 }
 
 func NewMock(t *testing.T) *MockImpl {
@@ -30,8 +32,10 @@ func NewMock(t *testing.T) *MockImpl {
 	// Load config
 	cfg := config.LoadConfig()
 	config.ConnectDB(cfg)
-	utils.SetJWT(cfg)
-	return &MockImpl{Db: config.DB}
+	if err := utils.SetJWT(cfg); err != nil {
+		t.Fatalf("failed to load JWT keys: %v", err)
+	}
+	return &MockImpl{Db: config.DB, Cfg: cfg, Mail: infraports.NewInMemoryMailSender()}
 }
 
 func (m MockImpl) SetRoutes(localstackContainer testcontainers.Container) *mux.Router {
@@ -46,33 +50,64 @@ func (m MockImpl) SetRoutes(localstackContainer testcontainers.Container) *mux.R
 	ingredientRepo := repositories.NewIngredientRepository(config.DB)
 	rawIngredientRepo := repositories.NewRawIngredientsRepository(config.DB)
 	cashClosingRepo := repositories.NewCashClosingRepository(config.DB)
+	tokenRepo := repositories.NewTokenRepository(config.DB)
+	shiftRepo := repositories.NewShiftRepository(config.DB)
+	auditRepo := repositories.NewAuditRepository(config.DB)
+	stationRepo := repositories.NewStationRepository(config.DB)
+	paymentRepo := repositories.NewPaymentRepository(config.DB)
+	promotionRepo := repositories.NewPromotionRepository(config.DB)
+	invoiceRepo := repositories.NewInvoiceRepository(config.DB)
+	reservationRepo := repositories.NewReservationRepository(config.DB)
 
 	s3Manager := infraports.InitLocalstackS3(localstackContainer)
 
 	// Services
-	userService := services.NewUserService(userRepo)
+	auditService := services.NewAuditService(auditRepo)
+	eventHub := services.NewEventHub()
+	userService := services.NewUserService(userRepo, tokenRepo, m.Mail, m.Cfg.RestaurantManager.Mail.ResetPasswordURL, m.Cfg.RestaurantManager.Mail.VerifyEmailURL)
 	ingredientService := services.NewIngredientsService(ingredientRepo)
-	menuService := services.NewMenuService(menuRepo, &s3Manager, ingredientService)
+	menuService := services.NewMenuService(menuRepo, &s3Manager, ingredientService, auditService)
 	tableService := services.NewTableService(tableRepo, m.Cfg.RestaurantManager.QRTemplate)
-	inventoryService := services.NewInventoryService(inventoryRepo, menuService)
+	inventoryService := services.NewInventoryService(inventoryRepo, menuService, auditService)
 	restaurantService := services.NewRestaurantService(restaurantRepo, &s3Manager)
-	orderService := services.NewOrderService(orderRepo, tableService, menuService, inventoryService)
+	promotionService := services.NewPromotionService(promotionRepo, restaurantRepo)
+	orderService := services.NewOrderService(orderRepo, tableService, menuService, inventoryService, auditService, eventHub, promotionService, paymentRepo, restaurantRepo)
+	stationService := services.NewStationService(stationRepo, orderService)
+	paymentService := services.NewPaymentService(paymentRepo, orderService)
 	rawIngredientsService := services.NewRawIngredientsService(rawIngredientRepo)
-	cashClosingService := services.NewCashClosingService(cashClosingRepo, orderRepo, menuRepo)
+	invoiceService := services.NewInvoiceService(invoiceRepo, orderRepo, restaurantRepo, userRepo, appports.NewLocalInvoiceTransmitter())
+	receiptService := services.NewReceiptService(orderRepo, paymentRepo, restaurantRepo, stationRepo)
+	reservationService := services.NewReservationService(reservationRepo, tableService, restaurantRepo)
+	cashClosingService := services.NewCashClosingService(cashClosingRepo, orderRepo, menuRepo, paymentRepo, auditService)
+	tenantService := services.NewTenantService(restaurantRepo)
+	shiftService := services.NewShiftService(shiftRepo, userRepo)
+	tokenService := services.NewTokenService(tokenRepo, userRepo, m.Cfg.RestaurantManager.JWT.RefreshTokenDuration(), m.Cfg.RestaurantManager.JWT.PinTokenDuration())
+	utils.SetRevocationList(tokenService)
 
 	// Handlers
-	userHandler := handlers.NewUserHandler(userService)
+	userHandler := handlers.NewUserHandler(userService, tenantService, tokenService)
 	restaurantHandler := handlers.NewRestaurantHandler(restaurantService)
 	menuHandler := handlers.NewMenuHandler(menuService)
-	orderHandler := handlers.NewOrderHandler(orderService)
-	tableHandler := handlers.NewTableHandler(tableService)
+	orderHandler := handlers.NewOrderHandler(orderService, tenantService)
+	tableHandler := handlers.NewTableHandler(tableService, tenantService)
 	inventoryHandler := handlers.NewInventoryHandler(inventoryService)
 	ingredientHandler := handlers.NewIngredientHandler(ingredientService)
 	rawIngredientsHandler := handlers.NewRawIngredientsHandler(rawIngredientsService)
 	cashClosingHandler := handlers.NewCashClosingHandler(cashClosingService)
+	shiftHandler := handlers.NewShiftHandler(shiftService, tenantService)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	eventHandler := handlers.NewEventHandler(eventHub)
+	stationHandler := handlers.NewStationHandler(stationService, tenantService)
+	paymentHandler := handlers.NewPaymentHandler(paymentService)
+	promotionHandler := handlers.NewPromotionHandler(promotionService)
+	invoiceHandler := handlers.NewInvoiceHandler(invoiceService)
+	receiptHandler := handlers.NewReceiptHandler(receiptService)
+	reservationHandler := handlers.NewReservationHandler(reservationService)
 
 	// Setup routes
+	authMiddleware := routes.NewAuthMiddleware(tenantService)
 	router := routes.SetupRoutes(
+		authMiddleware,
 		userHandler,
 		restaurantHandler,
 		menuHandler,
@@ -82,6 +117,15 @@ func (m MockImpl) SetRoutes(localstackContainer testcontainers.Container) *mux.R
 		ingredientHandler,
 		rawIngredientsHandler,
 		cashClosingHandler,
+		shiftHandler,
+		auditHandler,
+		eventHandler,
+		stationHandler,
+		paymentHandler,
+		promotionHandler,
+		invoiceHandler,
+		receiptHandler,
+		reservationHandler,
 	)
 	return router
 }